@@ -11,8 +11,10 @@ import (
 	"github.com/IamStubborN/petstore/db"
 	"github.com/IamStubborN/petstore/fileserver"
 	"github.com/IamStubborN/petstore/logger"
+	"github.com/IamStubborN/petstore/payments"
 	"github.com/IamStubborN/petstore/workers"
 	"github.com/IamStubborN/petstore/workers/api/auth"
+	"github.com/IamStubborN/petstore/workers/api/mware"
 
 	"go.uber.org/zap"
 )
@@ -26,10 +28,12 @@ func NewApp() *App {
 	app := &App{}
 	cfg := initConfig()
 	app.Logger = initLogger(cfg)
-	app.Workers = initWorkers(cfg)
 	db.InitDatabase(cfg)
 	fileserver.InitMinio(cfg)
+	payments.InitProvider(cfg)
 	auth.InitJWTAuth(cfg)
+	mware.InitIdempotency(cfg)
+	app.Workers = initWorkers(cfg)
 
 	return app
 }
@@ -14,6 +14,10 @@ type (
 		DB         DB         `mapstructure:"db"`
 		FileServer FileServer `mapstructure:"file_server"`
 		Invoice    Invoice    `mapstructure:"invoice"`
+		Payments   Payments   `mapstructure:"payments"`
+		Refunds    Refunds    `mapstructure:"refunds"`
+		Outbox     Outbox     `mapstructure:"outbox"`
+		Webhooks   Webhooks   `mapstructure:"webhooks"`
 		Services   []string   `mapstructure:"services"`
 		JWT        JWT        `mapstructure:"jwt"`
 	}
@@ -23,16 +27,66 @@ type (
 		TTL      time.Duration `mapstructure:"ttl"`
 	}
 
+	// API serves the HTTP API, IdempotencyBodyLimit caps in bytes the bodies
+	// kept in memory for requests with an Idempotency-Key.
 	API struct {
-		Port     int `mapstructure:"port"`
-		WTimeout int `mapstructure:"write_timeout"`
-		RTimeout int `mapstructure:"read_timeout"`
-		GTimeout int `mapstructure:"graceful_timeout"`
+		Port                 int   `mapstructure:"port"`
+		WTimeout             int   `mapstructure:"write_timeout"`
+		RTimeout             int   `mapstructure:"read_timeout"`
+		GTimeout             int   `mapstructure:"graceful_timeout"`
+		IdempotencyBodyLimit int64 `mapstructure:"idempotency_body_limit"`
 	}
 
+	// Invoice sets when the invoices are generated and Formats
+	// the files they are rendered to: md, csv, json, html or pdf.
+	// Schedule is a cron expression, an invoice covers the time from
+	// one run to the next and is generated at the end of it. Without
+	// Schedule the invoices cover Frequency from GenerateTime in UTC.
+	// The periods missed while the worker was down are generated on start.
 	Invoice struct {
+		Schedule     string        `mapstructure:"schedule"`
 		Frequency    time.Duration `mapstructure:"freq"`
 		GenerateTime string        `mapstructure:"generate_time"`
+		Formats      []string      `mapstructure:"formats"`
+	}
+
+	// Payments picks the payment provider, fake answers in process
+	// after Delay, http calls the gateway at Endpoint.
+	Payments struct {
+		Provider string        `mapstructure:"provider"`
+		Delay    time.Duration `mapstructure:"delay"`
+		Endpoint string        `mapstructure:"endpoint"`
+		Secret   string        `mapstructure:"secret"`
+		Timeout  time.Duration `mapstructure:"timeout"`
+	}
+
+	// Refunds paces the retries of the refunds the payment provider
+	// didn't answer, every Interval up to BatchSize due refunds are made.
+	Refunds struct {
+		Interval  time.Duration `mapstructure:"interval"`
+		BatchSize int           `mapstructure:"batch_size"`
+	}
+
+	// Outbox picks the publisher of outbox events, log writes them
+	// to the log, http posts them to Endpoint. Every Interval up to
+	// BatchSize due events are published at a time.
+	Outbox struct {
+		Publisher string        `mapstructure:"publisher"`
+		Interval  time.Duration `mapstructure:"interval"`
+		BatchSize int           `mapstructure:"batch_size"`
+		Endpoint  string        `mapstructure:"endpoint"`
+		Timeout   time.Duration `mapstructure:"timeout"`
+	}
+
+	// Webhooks paces the webhook deliveries, a delivery is tried
+	// MaxAttempts times and a webhook is disabled after MaxFailures
+	// failed attempts in a row.
+	Webhooks struct {
+		Interval    time.Duration `mapstructure:"interval"`
+		BatchSize   int           `mapstructure:"batch_size"`
+		Timeout     time.Duration `mapstructure:"timeout"`
+		MaxAttempts int           `mapstructure:"max_attempts"`
+		MaxFailures int           `mapstructure:"max_failures"`
 	}
 
 	FileServer struct {
@@ -59,6 +113,7 @@ type (
 		Timeout         string `mapstructure:"timeout"`
 		Retry           int    `mapstructure:"retry"`
 		RandomDataCount int    `mapstructure:"random_data_count"`
+		AllowCreateTags bool   `mapstructure:"allow_create_tags"`
 	}
 )
 
@@ -103,5 +158,17 @@ func catchParamsFromEnv(config *Config) *Config {
 		config.FileServer.Port = viper.GetString("MINIO_PORT")
 	}
 
+	if viper.IsSet("PAYMENTS_ENDPOINT") {
+		config.Payments.Endpoint = viper.GetString("PAYMENTS_ENDPOINT")
+	}
+
+	if viper.IsSet("PAYMENTS_SECRET") {
+		config.Payments.Secret = viper.GetString("PAYMENTS_SECRET")
+	}
+
+	if viper.IsSet("OUTBOX_ENDPOINT") {
+		config.Outbox.Endpoint = viper.GetString("OUTBOX_ENDPOINT")
+	}
+
 	return config
 }
@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"time"
 
 	"github.com/IamStubborN/petstore/config"
 	"github.com/IamStubborN/petstore/db/models"
@@ -18,25 +19,44 @@ type Storage interface {
 	StoreDI
 	PetDI
 	UserDI
+	CategoryDI
+	TagDI
+	CartDI
+	CouponDI
+	PaymentDI
+	RefundDI
+	AnalyticsDI
+	IdempotencyDI
+	OutboxDI
+	EventStreamDI
+	WebhookDI
+	InvoiceDI
 	Close() error
 }
 
 type StoreDI interface {
 	GetInventories(ctx context.Context) (map[string]int64, error)
+	GetInventory(ctx context.Context, filter models.InventoryFilter) (models.Inventory, error)
 	CreateOrder(ctx context.Context, order *models.Order) (*models.Order, error)
 	FindOrderByID(ctx context.Context, orderID int64) (*models.Order, error)
-	DeleteOrderByID(ctx context.Context, orderID int64) error
+	FindOrders(ctx context.Context, filter models.OrderFilter) (*models.OrderList, string, error)
+	DeleteOrderByID(ctx context.Context, orderID, userID int64) (*models.Order, error)
+	ApproveOrder(ctx context.Context, orderID, userID int64) (*models.Order, error)
+	DeliverOrder(ctx context.Context, orderID, userID int64) (*models.Order, error)
+	CancelOrder(ctx context.Context, orderID, userID int64) (*models.Order, error)
 	CreateInvoiceByDates(ctx context.Context, from, to string) ([]*models.InvoiceItem, error)
 }
 
 type PetDI interface {
-	AddPetToStore(ctx context.Context, pet *models.Pet) (*models.Pet, error)
-	UpdatePetInStoreByBody(ctx context.Context, pet *models.Pet) (*models.Pet, error)
-	UpdatePetInStoreByForm(ctx context.Context, id int64, name, status string) error
-	FindPetsByStatus(ctx context.Context, status []string) (*models.PetList, error)
+	AddPetToStore(ctx context.Context, pet *models.Pet, userID int64) (*models.Pet, error)
+	UpdatePetInStoreByBody(ctx context.Context, pet *models.Pet, userID int64) (*models.Pet, error)
+	UpdatePetInStoreByForm(ctx context.Context, id int64, name, status string, userID int64) error
+	FindPetsByStatus(ctx context.Context, filter models.PetFilter) (*models.PetList, string, error)
+	FindPetsByTags(ctx context.Context, tags []string, matchAll bool) (*models.PetList, error)
 	GetPetByID(ctx context.Context, id int64) (*models.Pet, error)
 	DeletePetByID(ctx context.Context, id int64) error
-	UpdatePetPhotosByID(ctx context.Context, id int64, imagesURL []string) error
+	AddPetPhotosByID(ctx context.Context, id int64, imagesURL []string) error
+	GetPetStatusHistory(ctx context.Context, id int64) (models.PetStatusHistory, error)
 }
 
 type UserDI interface {
@@ -46,6 +66,97 @@ type UserDI interface {
 	GetUserByName(ctx context.Context, username string) (*models.User, error)
 	UpdateUser(ctx context.Context, user *models.User) error
 	DeleteUser(ctx context.Context, username string) error
+	SetUserStatus(ctx context.Context, username string, status int64) (*models.User, error)
+}
+
+type CategoryDI interface {
+	CreateCategory(ctx context.Context, category *models.Category) (*models.Category, error)
+	GetCategories(ctx context.Context) (models.CategoryList, error)
+	GetCategoryByID(ctx context.Context, id int64) (*models.Category, error)
+	UpdateCategory(ctx context.Context, category *models.Category) error
+	DeleteCategory(ctx context.Context, id int64) error
+}
+
+type TagDI interface {
+	CreateTag(ctx context.Context, tag *models.Tag) (*models.Tag, error)
+	GetTags(ctx context.Context) (models.TagList, error)
+	RenameTag(ctx context.Context, id int64, name string) error
+	DeleteTag(ctx context.Context, id int64) error
+}
+
+type CartDI interface {
+	GetCart(ctx context.Context, userID int64) (*models.Cart, error)
+	AddCartItem(ctx context.Context, userID int64, item *models.CartItem) (*models.Cart, error)
+	RemoveCartItem(ctx context.Context, userID, petID int64) error
+	CheckoutCart(ctx context.Context, userID int64, coupons []string) (*models.Order, error)
+}
+
+type CouponDI interface {
+	CreateCoupon(ctx context.Context, coupon *models.Coupon) (*models.Coupon, error)
+	GetCoupons(ctx context.Context) (models.CouponList, error)
+	DeleteCoupon(ctx context.Context, id int64) error
+}
+
+type PaymentDI interface {
+	CreatePayment(ctx context.Context, payment *models.Payment) (*models.Payment, error)
+	GetPayments(ctx context.Context, orderID int64) (models.PaymentList, error)
+	UpdatePayment(ctx context.Context, payment *models.Payment) error
+}
+
+// RefundDI is the refund ledger, a refund is reserved as pending before
+// the payment provider is called and its attempts are saved after, the
+// pending refunds left behind are claimed for retries.
+type RefundDI interface {
+	ReserveRefund(ctx context.Context, refund *models.Refund) (*models.Refund, error)
+	SaveRefund(ctx context.Context, refund *models.Refund) error
+	ClaimRefunds(ctx context.Context, limit int) (models.RefundList, error)
+	GetRefunds(ctx context.Context, orderID int64) (models.RefundList, error)
+}
+
+type AnalyticsDI interface {
+	GetSales(ctx context.Context, filter models.AnalyticsFilter) (models.CategorySalesList, error)
+	GetTopCategories(ctx context.Context, filter models.AnalyticsFilter) (models.CategorySalesList, error)
+	GetOrderValue(ctx context.Context, filter models.AnalyticsFilter) (*models.OrderValue, error)
+	GetUserValues(ctx context.Context, filter models.AnalyticsFilter) (models.UserValueList, error)
+}
+
+type IdempotencyDI interface {
+	ReserveIdempotencyKey(ctx context.Context, key *models.IdempotencyKey) (*models.IdempotencyKey, bool, error)
+	SaveIdempotentResponse(ctx context.Context, key *models.IdempotencyKey) error
+	ReleaseIdempotencyKey(ctx context.Context, userID int64, key string) error
+}
+
+type OutboxDI interface {
+	PublishEvents(ctx context.Context, limit int, publish func(context.Context, *models.Event) error) (int, error)
+}
+
+// EventStreamDI streams the outbox events as they are committed,
+// the events with ids above after are replayed first, after 0
+// streams only the new events. The channel is closed when ctx is done
+// or when the client falls too far behind and has to resume.
+type EventStreamDI interface {
+	StreamEvents(ctx context.Context, after int64) (<-chan *models.Event, error)
+}
+
+type WebhookDI interface {
+	CreateWebhook(ctx context.Context, webhook *models.Webhook) (*models.Webhook, error)
+	GetWebhooks(ctx context.Context) (models.WebhookList, error)
+	DeleteWebhook(ctx context.Context, id int64) error
+	EnableWebhook(ctx context.Context, id int64) (*models.Webhook, error)
+	GetWebhookDeliveries(ctx context.Context, webhookID int64, limit int) (models.WebhookDeliveryList, error)
+	DeliverWebhooks(ctx context.Context, policy models.WebhookPolicy,
+		deliver func(context.Context, *models.WebhookDelivery) error) (int, error)
+}
+
+// InvoiceDI is the registry of the generated invoice files, the invoice
+// period is the end of the last period the invoice worker completed.
+type InvoiceDI interface {
+	AddInvoiceFile(ctx context.Context, file *models.InvoiceFile) (*models.InvoiceFile, error)
+	GetInvoiceFiles(ctx context.Context, limit int) (models.InvoiceFileList, error)
+	GetInvoiceFile(ctx context.Context, number int64) (*models.InvoiceFile, error)
+	GetPeriodInvoiceFiles(ctx context.Context, from, to time.Time) (models.InvoiceFileList, error)
+	GetInvoicePeriod(ctx context.Context) (time.Time, error)
+	CompleteInvoicePeriod(ctx context.Context, to time.Time) error
 }
 
 func InitDatabase(cfg *config.Config) {
@@ -71,6 +182,54 @@ func GetUserDI() UserDI {
 	return storage
 }
 
+func GetCategoryDI() CategoryDI {
+	return storage
+}
+
+func GetTagDI() TagDI {
+	return storage
+}
+
+func GetCartDI() CartDI {
+	return storage
+}
+
+func GetCouponDI() CouponDI {
+	return storage
+}
+
+func GetPaymentDI() PaymentDI {
+	return storage
+}
+
+func GetRefundDI() RefundDI {
+	return storage
+}
+
+func GetAnalyticsDI() AnalyticsDI {
+	return storage
+}
+
+func GetIdempotencyDI() IdempotencyDI {
+	return storage
+}
+
+func GetOutboxDI() OutboxDI {
+	return storage
+}
+
+func GetEventStreamDI() EventStreamDI {
+	return storage
+}
+
+func GetWebhookDI() WebhookDI {
+	return storage
+}
+
+func GetInvoiceDI() InvoiceDI {
+	return storage
+}
+
 func Close() error {
 	if err := storage.Close(); err != nil {
 		return errors.Wrap(err, "can't close database")
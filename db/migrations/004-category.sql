@@ -0,0 +1,10 @@
+-- +migrate Up
+-- +migrate StatementBegin
+create unique index if not exists category_name_uindex
+    on category (name);
+-- +migrate StatementEnd
+
+-- +migrate Down
+-- +migrate StatementBegin
+drop index if exists category_name_uindex;
+-- +migrate StatementEnd
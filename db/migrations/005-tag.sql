@@ -0,0 +1,10 @@
+-- +migrate Up
+-- +migrate StatementBegin
+create unique index if not exists tag_name_uindex
+    on tag (name);
+-- +migrate StatementEnd
+
+-- +migrate Down
+-- +migrate StatementBegin
+drop index if exists tag_name_uindex;
+-- +migrate StatementEnd
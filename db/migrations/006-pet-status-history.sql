@@ -0,0 +1,31 @@
+-- +migrate Up
+-- +migrate StatementBegin
+create table if not exists pet_status_history
+(
+    id bigserial not null
+        constraint pet_status_history_pk
+            primary key,
+    pet_id bigint not null
+        constraint pet_id___fk
+            references pet
+            on update cascade on delete cascade,
+    from_status_id bigint
+        constraint from_status_id___fk
+            references pet_status,
+    to_status_id bigint not null
+        constraint to_status_id___fk
+            references pet_status,
+    user_id bigint not null,
+    changed_at timestamp with time zone default now() not null
+);
+
+alter table pet_status_history owner to petstore;
+
+create index if not exists pet_status_history_pet_id_index
+    on pet_status_history (pet_id, changed_at);
+-- +migrate StatementEnd
+
+-- +migrate Down
+-- +migrate StatementBegin
+drop table if exists pet_status_history;
+-- +migrate StatementEnd
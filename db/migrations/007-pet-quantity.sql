@@ -0,0 +1,44 @@
+-- +migrate Up
+-- +migrate StatementBegin
+alter table pet add column if not exists quantity integer default 1 not null
+    constraint pet_quantity_check
+        check (quantity >= 0);
+
+create or replace view pet_info(id, category_id, category_name, price, name, photo_urls, pet_status_id,
+                     pet_status_name, quantity) as
+SELECT p.id,
+       p.category_id,
+       ct.name           AS category_name,
+       ct.price,
+       p.name,
+       p.photo_urls,
+       ps.id             AS pet_status_id,
+       ps.name           AS pet_status_name,
+       p.quantity
+FROM (pet p
+         JOIN category ct ON ((p.category_id = ct.id))
+         JOIN pet_status ps ON ((p.pet_status_id = ps.id)))
+GROUP BY p.id, p.category_id, ct.name, ct.price, p.name, p.photo_urls, ps.id, ps.name, p.quantity;
+-- +migrate StatementEnd
+
+-- +migrate Down
+-- +migrate StatementBegin
+drop view if exists pet_info;
+
+create or replace view pet_info(id, category_id, category_name, price, name, photo_urls, pet_status_id,
+                     pet_status_name) as
+SELECT p.id,
+       p.category_id,
+       ct.name           AS category_name,
+       ct.price,
+       p.name,
+       p.photo_urls,
+       ps.id             AS pet_status_id,
+       ps.name           AS pet_status_name
+FROM (pet p
+         JOIN category ct ON ((p.category_id = ct.id))
+         JOIN pet_status ps ON ((p.pet_status_id = ps.id)))
+GROUP BY p.id, p.category_id, ct.name, ct.price, p.name, p.photo_urls, ps.id, ps.name;
+
+alter table pet drop column if exists quantity;
+-- +migrate StatementEnd
@@ -0,0 +1,11 @@
+-- +migrate Up
+-- +migrate StatementBegin
+insert into order_status (name)
+select 'cancelled'
+where not exists(select 1 from order_status where name = 'cancelled');
+-- +migrate StatementEnd
+
+-- +migrate Down
+-- +migrate StatementBegin
+delete from order_status where name = 'cancelled';
+-- +migrate StatementEnd
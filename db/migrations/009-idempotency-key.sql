@@ -0,0 +1,21 @@
+-- +migrate Up
+-- +migrate StatementBegin
+create table if not exists idempotency_key
+(
+    user_id bigint not null,
+    key varchar(255) not null,
+    request_hash char(64) not null,
+    status_code integer default 0 not null,
+    body bytea,
+    created_at timestamp with time zone default now() not null,
+    constraint idempotency_key_pk
+        primary key (user_id, key)
+);
+
+alter table idempotency_key owner to petstore;
+-- +migrate StatementEnd
+
+-- +migrate Down
+-- +migrate StatementBegin
+drop table if exists idempotency_key;
+-- +migrate StatementEnd
@@ -0,0 +1,166 @@
+-- +migrate Up
+-- +migrate StatementBegin
+create table if not exists order_item
+(
+    id bigserial not null
+        constraint order_item_pk
+            primary key,
+    order_id bigint not null
+        constraint order_id___fk
+            references "order"
+            on update cascade on delete cascade,
+    pet_id bigint not null
+        constraint pet_id___fk
+            references pet
+            on update cascade on delete cascade,
+    quantity integer not null
+        constraint order_item_quantity_check
+            check (quantity > 0),
+    constraint order_item_order_id_pet_id_key
+        unique (order_id, pet_id)
+);
+
+alter table order_item owner to petstore;
+
+insert into order_item (order_id, pet_id, quantity)
+select id, pet_id, quantity from "order"
+on conflict do nothing;
+
+create table if not exists cart_item
+(
+    user_id bigint not null
+        constraint user_id___fk
+            references "user"
+            on update cascade on delete cascade,
+    pet_id bigint not null
+        constraint pet_id___fk
+            references pet
+            on update cascade on delete cascade,
+    quantity integer not null
+        constraint cart_item_quantity_check
+            check (quantity > 0),
+    added_at timestamp with time zone default now() not null,
+    constraint cart_item_pk
+        primary key (user_id, pet_id)
+);
+
+alter table cart_item owner to petstore;
+
+drop view if exists invoice_info;
+drop view if exists order_info;
+
+alter table "order" drop column if exists pet_id;
+alter table "order" drop column if exists quantity;
+
+create or replace view order_item_info(id, order_id, pet_id, pet_name, pet_status, category, quantity, price) as
+SELECT oi.id,
+       oi.order_id,
+       oi.pet_id,
+       p.name  AS pet_name,
+       ps.name AS pet_status,
+       c.name  AS category,
+       oi.quantity,
+       c.price
+FROM (((order_item oi
+    JOIN pet p ON ((oi.pet_id = p.id)))
+    JOIN pet_status ps ON ((p.pet_status_id = ps.id)))
+    JOIN category c ON ((p.category_id = c.id)));
+
+alter table order_item_info owner to petstore;
+
+-- pet_id is kept for single pet orders, it's 0 when the order has several pets
+create or replace view order_info(id, user_id, pet_id, quantity, ship_date, order_status, complete) as
+SELECT o.id,
+       o.user_id,
+       CASE WHEN count(oi.id) = 1 THEN min(oi.pet_id) ELSE 0 END AS pet_id,
+       coalesce(sum(oi.quantity), 0)                             AS quantity,
+       o.ship_date,
+       os.name                                                   AS order_status,
+       o.complete
+FROM (("order" o
+    JOIN order_status os ON ((o.order_status_id = os.id)))
+    LEFT JOIN order_item oi ON ((oi.order_id = o.id)))
+GROUP BY o.id, os.name;
+
+alter table order_info owner to petstore;
+
+create view invoice_info(id, line, user_name, pet, category, ship_date, quantity, price) as
+SELECT o.id,
+       row_number() OVER (PARTITION BY o.id ORDER BY oi.id) AS line,
+       u.user_name,
+       oi.pet_name                                          AS pet,
+       oi.category,
+       o.ship_date,
+       oi.quantity,
+       oi.price
+FROM (("order" o
+    JOIN "user" u ON ((o.user_id = u.id)))
+    JOIN order_item_info oi ON ((oi.order_id = o.id)));
+
+alter table invoice_info owner to petstore;
+-- +migrate StatementEnd
+
+-- +migrate Down
+-- +migrate StatementBegin
+drop view if exists invoice_info;
+drop view if exists order_info;
+drop view if exists order_item_info;
+
+alter table "order" add column if not exists pet_id bigint
+    constraint pet_id___fk
+        references pet
+        on update cascade on delete cascade;
+alter table "order" add column if not exists quantity integer;
+
+-- orders of several pets keep only their first item
+update "order" o
+set pet_id   = oi.pet_id,
+    quantity = oi.quantity
+FROM (SELECT DISTINCT ON (order_id) order_id, pet_id, quantity
+      FROM order_item
+      ORDER BY order_id, id) oi
+WHERE oi.order_id = o.id;
+
+delete from "order" where pet_id is null;
+
+alter table "order" alter column pet_id set not null;
+alter table "order" alter column quantity set not null;
+
+create or replace view order_info(id, user_id, pet_id, pet_name, quantity, pet_status, ship_date, order_status, complete) as
+SELECT o.id,
+       o.user_id,
+       o.pet_id,
+       p.name  AS pet_name,
+       o.quantity,
+       ps.name AS pet_status,
+       o.ship_date,
+       os.name AS order_status,
+       o.complete
+FROM (((("order" o
+    JOIN pet p ON ((o.pet_id = p.id)))
+    JOIN pet_status ps ON ((p.pet_status_id = ps.id)))
+    JOIN "user" u ON ((o.user_id = u.id)))
+    JOIN order_status os ON ((o.order_status_id = os.id)))
+GROUP BY o.id, u.user_name, p.name, o.quantity, ps.name, os.name;
+
+alter table order_info owner to petstore;
+
+create view invoice_info(id, user_name, pet, category, ship_date, quantity, price) as
+SELECT o.id,
+       u.user_name,
+       p.name AS pet,
+       c.name AS category,
+       o.ship_date,
+       o.quantity,
+       c.price
+FROM ((("order" o
+    JOIN "user" u ON ((o.user_id = u.id)))
+    JOIN pet p ON ((o.pet_id = p.id)))
+    JOIN category c ON ((p.category_id = c.id)))
+GROUP BY o.id, u.user_name, p.name, c.name, o.ship_date, o.quantity, c.price;
+
+alter table invoice_info owner to petstore;
+
+drop table if exists cart_item;
+drop table if exists order_item;
+-- +migrate StatementEnd
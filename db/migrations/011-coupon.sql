@@ -0,0 +1,190 @@
+-- +migrate Up
+-- +migrate StatementBegin
+create table if not exists coupon
+(
+    id bigserial not null
+        constraint coupon_pk
+            primary key,
+    code varchar(50) not null
+        constraint coupon_code_key
+            unique,
+    kind varchar(10) not null
+        constraint coupon_kind_check
+            check (kind in ('percent', 'fixed')),
+    value numeric(15,2) not null
+        constraint coupon_value_check
+            check (value > 0),
+    category_id bigint
+        constraint category_id___fk
+            references category
+            on update cascade on delete cascade,
+    valid_from timestamp with time zone default now() not null,
+    valid_to timestamp with time zone,
+    max_uses integer default 0 not null
+        constraint coupon_max_uses_check
+            check (max_uses >= 0),
+    max_uses_per_user integer default 0 not null
+        constraint coupon_max_uses_per_user_check
+            check (max_uses_per_user >= 0),
+    stackable boolean default false not null
+);
+
+alter table coupon owner to petstore;
+
+create table if not exists coupon_use
+(
+    coupon_id bigint not null
+        constraint coupon_id___fk
+            references coupon
+            on update cascade on delete cascade,
+    order_id bigint not null
+        constraint order_id___fk
+            references "order"
+            on update cascade on delete cascade,
+    user_id bigint not null,
+    constraint coupon_use_pk
+        primary key (coupon_id, order_id)
+);
+
+alter table coupon_use owner to petstore;
+
+create index if not exists coupon_use_coupon_id_user_id_index
+    on coupon_use (coupon_id, user_id);
+
+alter table order_item add column if not exists price numeric(15,2) default 0 not null;
+alter table order_item add column if not exists discount numeric(15,2) default 0 not null;
+
+update order_item oi
+set price = c.price
+FROM pet p
+         JOIN category c ON p.category_id = c.id
+WHERE oi.pet_id = p.id;
+
+alter table "order" add column if not exists gross numeric(15,2) default 0 not null;
+alter table "order" add column if not exists discount numeric(15,2) default 0 not null;
+
+update "order" o
+set gross = oi.gross
+FROM (SELECT order_id, sum(price * quantity) AS gross
+      FROM order_item
+      GROUP BY order_id) oi
+WHERE oi.order_id = o.id;
+
+drop view if exists invoice_info;
+drop view if exists order_info;
+drop view if exists order_item_info;
+
+create or replace view order_item_info(id, order_id, pet_id, pet_name, pet_status, category_id, category, quantity,
+                                       price, discount) as
+SELECT oi.id,
+       oi.order_id,
+       oi.pet_id,
+       p.name  AS pet_name,
+       ps.name AS pet_status,
+       c.id    AS category_id,
+       c.name  AS category,
+       oi.quantity,
+       oi.price,
+       oi.discount
+FROM (((order_item oi
+    JOIN pet p ON ((oi.pet_id = p.id)))
+    JOIN pet_status ps ON ((p.pet_status_id = ps.id)))
+    JOIN category c ON ((p.category_id = c.id)));
+
+alter table order_item_info owner to petstore;
+
+-- pet_id is kept for single pet orders, it's 0 when the order has several pets
+create or replace view order_info(id, user_id, pet_id, quantity, gross, discount, ship_date, order_status, complete) as
+SELECT o.id,
+       o.user_id,
+       CASE WHEN count(oi.id) = 1 THEN min(oi.pet_id) ELSE 0 END AS pet_id,
+       coalesce(sum(oi.quantity), 0)                             AS quantity,
+       o.gross,
+       o.discount,
+       o.ship_date,
+       os.name                                                   AS order_status,
+       o.complete
+FROM (("order" o
+    JOIN order_status os ON ((o.order_status_id = os.id)))
+    LEFT JOIN order_item oi ON ((oi.order_id = o.id)))
+GROUP BY o.id, os.name;
+
+alter table order_info owner to petstore;
+
+create view invoice_info(id, line, user_name, pet, category, ship_date, quantity, price, discount) as
+SELECT o.id,
+       row_number() OVER (PARTITION BY o.id ORDER BY oi.id) AS line,
+       u.user_name,
+       oi.pet_name                                          AS pet,
+       oi.category,
+       o.ship_date,
+       oi.quantity,
+       oi.price,
+       oi.discount
+FROM (("order" o
+    JOIN "user" u ON ((o.user_id = u.id)))
+    JOIN order_item_info oi ON ((oi.order_id = o.id)));
+
+alter table invoice_info owner to petstore;
+-- +migrate StatementEnd
+
+-- +migrate Down
+-- +migrate StatementBegin
+drop view if exists invoice_info;
+drop view if exists order_info;
+drop view if exists order_item_info;
+
+create or replace view order_item_info(id, order_id, pet_id, pet_name, pet_status, category, quantity, price) as
+SELECT oi.id,
+       oi.order_id,
+       oi.pet_id,
+       p.name  AS pet_name,
+       ps.name AS pet_status,
+       c.name  AS category,
+       oi.quantity,
+       c.price
+FROM (((order_item oi
+    JOIN pet p ON ((oi.pet_id = p.id)))
+    JOIN pet_status ps ON ((p.pet_status_id = ps.id)))
+    JOIN category c ON ((p.category_id = c.id)));
+
+alter table order_item_info owner to petstore;
+
+create or replace view order_info(id, user_id, pet_id, quantity, ship_date, order_status, complete) as
+SELECT o.id,
+       o.user_id,
+       CASE WHEN count(oi.id) = 1 THEN min(oi.pet_id) ELSE 0 END AS pet_id,
+       coalesce(sum(oi.quantity), 0)                             AS quantity,
+       o.ship_date,
+       os.name                                                   AS order_status,
+       o.complete
+FROM (("order" o
+    JOIN order_status os ON ((o.order_status_id = os.id)))
+    LEFT JOIN order_item oi ON ((oi.order_id = o.id)))
+GROUP BY o.id, os.name;
+
+alter table order_info owner to petstore;
+
+create view invoice_info(id, line, user_name, pet, category, ship_date, quantity, price) as
+SELECT o.id,
+       row_number() OVER (PARTITION BY o.id ORDER BY oi.id) AS line,
+       u.user_name,
+       oi.pet_name                                          AS pet,
+       oi.category,
+       o.ship_date,
+       oi.quantity,
+       oi.price
+FROM (("order" o
+    JOIN "user" u ON ((o.user_id = u.id)))
+    JOIN order_item_info oi ON ((oi.order_id = o.id)));
+
+alter table invoice_info owner to petstore;
+
+alter table "order" drop column if exists discount;
+alter table "order" drop column if exists gross;
+alter table order_item drop column if exists discount;
+alter table order_item drop column if exists price;
+
+drop table if exists coupon_use;
+drop table if exists coupon;
+-- +migrate StatementEnd
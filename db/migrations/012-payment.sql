@@ -0,0 +1,44 @@
+-- +migrate Up
+-- +migrate StatementBegin
+create table if not exists payment
+(
+    id bigserial not null
+        constraint payment_pk
+            primary key,
+    order_id bigint not null
+        constraint order_id___fk
+            references "order"
+            on update cascade on delete cascade,
+    provider varchar(50) not null,
+    reference varchar(100) default '' not null,
+    amount numeric(15,2) not null
+        constraint payment_amount_check
+            check (amount >= 0),
+    status varchar(20) not null
+        constraint payment_status_check
+            check (status in ('pending', 'authorized', 'captured', 'declined', 'failed', 'refunded')),
+    reason text default '' not null,
+    created_at timestamp with time zone default now() not null,
+    updated_at timestamp with time zone default now() not null
+);
+
+alter table payment owner to petstore;
+
+-- an order is paid once, declined and failed attempts may be repeated
+create unique index if not exists payment_order_id_active_key
+    on payment (order_id)
+    where status in ('pending', 'authorized', 'captured');
+
+-- orders approved before payments existed were paid outside the store
+insert into payment (order_id, provider, reference, amount, status)
+select o.id, 'manual', 'order-' || o.id, o.gross - o.discount, 'captured'
+FROM "order" o
+         JOIN order_status os ON o.order_status_id = os.id
+WHERE os.name in ('approved', 'delivered')
+on conflict do nothing;
+-- +migrate StatementEnd
+
+-- +migrate Down
+-- +migrate StatementBegin
+drop table if exists payment;
+-- +migrate StatementEnd
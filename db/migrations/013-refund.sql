@@ -0,0 +1,99 @@
+-- +migrate Up
+-- +migrate StatementBegin
+create table if not exists refund
+(
+    id bigserial not null
+        constraint refund_pk
+            primary key,
+    order_id bigint not null
+        constraint order_id___fk
+            references "order"
+            on update cascade on delete cascade,
+    payment_id bigint not null
+        constraint payment_id___fk
+            references payment
+            on update cascade on delete cascade,
+    amount numeric(15,2) not null
+        constraint refund_amount_check
+            check (amount > 0),
+    reason text not null,
+    user_id bigint default 0 not null,
+    created_at timestamp with time zone default now() not null
+);
+
+alter table refund owner to petstore;
+
+create index if not exists refund_payment_id_index
+    on refund (payment_id);
+
+create index if not exists refund_created_at_index
+    on refund (created_at);
+
+-- deleted orders are hidden from the store, invoices still list them
+alter table "order" add column if not exists deleted_at timestamp with time zone;
+
+drop view if exists order_info;
+
+create or replace view order_info(id, user_id, pet_id, quantity, gross, discount, ship_date, order_status, complete) as
+SELECT o.id,
+       o.user_id,
+       CASE WHEN count(oi.id) = 1 THEN min(oi.pet_id) ELSE 0 END AS pet_id,
+       coalesce(sum(oi.quantity), 0)                             AS quantity,
+       o.gross,
+       o.discount,
+       o.ship_date,
+       os.name                                                   AS order_status,
+       o.complete
+FROM (("order" o
+    JOIN order_status os ON ((o.order_status_id = os.id)))
+    LEFT JOIN order_item oi ON ((oi.order_id = o.id)))
+WHERE o.deleted_at IS NULL
+GROUP BY o.id, os.name;
+
+alter table order_info owner to petstore;
+
+-- refunds are invoiced in the period they are made, as negative lines
+create view invoice_refund_info(id, line, user_name, pet, category, ship_date, quantity, price, discount) as
+SELECT r.order_id                      AS id,
+       1::bigint                       AS line,
+       u.user_name,
+       'Refund'::varchar               AS pet,
+       r.reason                        AS category,
+       r.created_at AT TIME ZONE 'UTC' AS ship_date,
+       1                               AS quantity,
+       -r.amount                       AS price,
+       0::numeric(15,2)                AS discount
+FROM ((refund r
+    JOIN "order" o ON ((r.order_id = o.id)))
+    JOIN "user" u ON ((o.user_id = u.id)));
+
+alter table invoice_refund_info owner to petstore;
+-- +migrate StatementEnd
+
+-- +migrate Down
+-- +migrate StatementBegin
+drop view if exists invoice_refund_info;
+drop view if exists order_info;
+
+create or replace view order_info(id, user_id, pet_id, quantity, gross, discount, ship_date, order_status, complete) as
+SELECT o.id,
+       o.user_id,
+       CASE WHEN count(oi.id) = 1 THEN min(oi.pet_id) ELSE 0 END AS pet_id,
+       coalesce(sum(oi.quantity), 0)                             AS quantity,
+       o.gross,
+       o.discount,
+       o.ship_date,
+       os.name                                                   AS order_status,
+       o.complete
+FROM (("order" o
+    JOIN order_status os ON ((o.order_status_id = os.id)))
+    LEFT JOIN order_item oi ON ((oi.order_id = o.id)))
+GROUP BY o.id, os.name;
+
+alter table order_info owner to petstore;
+
+delete from "order" where deleted_at is not null;
+alter table "order" drop column if exists deleted_at;
+
+drop table if exists refund;
+-- +migrate StatementEnd
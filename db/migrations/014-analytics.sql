@@ -0,0 +1,15 @@
+-- +migrate Up
+-- +migrate StatementBegin
+-- analytics and invoices select orders by ship date
+create index if not exists order_ship_date_index
+    on "order" (ship_date);
+
+create index if not exists order_item_order_id_index
+    on order_item (order_id);
+-- +migrate StatementEnd
+
+-- +migrate Down
+-- +migrate StatementBegin
+drop index if exists order_item_order_id_index;
+drop index if exists order_ship_date_index;
+-- +migrate StatementEnd
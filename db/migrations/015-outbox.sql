@@ -0,0 +1,30 @@
+-- +migrate Up
+-- +migrate StatementBegin
+create table if not exists outbox
+(
+    id bigserial not null
+        constraint outbox_pk
+            primary key,
+    aggregate varchar(20) not null,
+    aggregate_key varchar(100) not null,
+    event varchar(50) not null,
+    payload jsonb not null,
+    created_at timestamp with time zone default now() not null,
+    attempts integer default 0 not null,
+    next_attempt_at timestamp with time zone default now() not null,
+    last_error text default '' not null,
+    published_at timestamp with time zone
+);
+
+alter table outbox owner to petstore;
+
+-- the outbox worker polls only the events left to publish
+create index if not exists outbox_next_attempt_at_index
+    on outbox (next_attempt_at, id)
+    where published_at is null;
+-- +migrate StatementEnd
+
+-- +migrate Down
+-- +migrate StatementBegin
+drop table if exists outbox;
+-- +migrate StatementEnd
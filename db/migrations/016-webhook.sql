@@ -0,0 +1,58 @@
+-- +migrate Up
+-- +migrate StatementBegin
+create table if not exists webhook
+(
+    id bigserial not null
+        constraint webhook_pk
+            primary key,
+    url varchar(2000) not null,
+    events varchar(50)[] not null,
+    secret varchar(200) not null,
+    active boolean default true not null,
+    failures integer default 0 not null,
+    created_at timestamp with time zone default now() not null,
+    disabled_at timestamp with time zone
+);
+
+alter table webhook owner to petstore;
+
+create table if not exists webhook_delivery
+(
+    id bigserial not null
+        constraint webhook_delivery_pk
+            primary key,
+    webhook_id bigint not null
+        constraint webhook_id___fk
+            references webhook
+            on update cascade on delete cascade,
+    event_id bigint not null
+        constraint event_id___fk
+            references outbox
+            on update cascade on delete cascade,
+    status varchar(20) default 'pending' not null
+        constraint webhook_delivery_status_check
+            check (status in ('pending', 'delivered', 'failed')),
+    attempts integer default 0 not null,
+    response_code integer default 0 not null,
+    last_error text default '' not null,
+    created_at timestamp with time zone default now() not null,
+    next_attempt_at timestamp with time zone default now() not null,
+    delivered_at timestamp with time zone
+);
+
+alter table webhook_delivery owner to petstore;
+
+-- the webhook worker polls only the deliveries left to make
+create index if not exists webhook_delivery_next_attempt_at_index
+    on webhook_delivery (next_attempt_at, id)
+    where status = 'pending';
+
+create index if not exists webhook_delivery_webhook_id_index
+    on webhook_delivery (webhook_id, id);
+-- +migrate StatementEnd
+
+-- +migrate Down
+-- +migrate StatementBegin
+drop table if exists webhook_delivery;
+drop table if exists webhook;
+-- +migrate StatementEnd
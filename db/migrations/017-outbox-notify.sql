@@ -0,0 +1,25 @@
+-- +migrate Up
+-- +migrate StatementBegin
+-- listeners are notified of the outbox event ids when the events are committed
+create or replace function outbox_notify() returns trigger as
+$$
+begin
+    perform pg_notify('outbox_event', new.id::text);
+    return new;
+end;
+$$ language plpgsql;
+
+drop trigger if exists outbox_notify on outbox;
+
+create trigger outbox_notify
+    after insert
+    on outbox
+    for each row
+execute procedure outbox_notify();
+-- +migrate StatementEnd
+
+-- +migrate Down
+-- +migrate StatementBegin
+drop trigger if exists outbox_notify on outbox;
+drop function if exists outbox_notify();
+-- +migrate StatementEnd
@@ -0,0 +1,34 @@
+-- +migrate Up
+-- +migrate StatementBegin
+create table if not exists invoice
+(
+    number bigserial not null
+        constraint invoice_pk
+            primary key,
+    period_from timestamp with time zone not null,
+    period_to timestamp with time zone not null,
+    format varchar(10) not null,
+    object_key varchar(300) not null
+        constraint invoice_object_key_key
+            unique,
+    checksum char(64) not null,
+    size bigint not null,
+    total_price numeric(15,2) not null,
+    total_discount numeric(15,2) not null,
+    total_refund numeric(15,2) not null,
+    total numeric(15,2) not null,
+    created_at timestamp with time zone default now() not null,
+    constraint invoice_period_check
+        check (period_from <= period_to)
+);
+
+alter table invoice owner to petstore;
+
+create index if not exists invoice_period_index
+    on invoice (period_from, period_to);
+-- +migrate StatementEnd
+
+-- +migrate Down
+-- +migrate StatementBegin
+drop table if exists invoice;
+-- +migrate StatementEnd
@@ -0,0 +1,20 @@
+-- +migrate Up
+-- +migrate StatementBegin
+create table if not exists invoice_period
+(
+    id boolean default true not null
+        constraint invoice_period_pk
+            primary key
+        constraint invoice_period_id_check
+            check (id),
+    period_to timestamp with time zone not null,
+    updated_at timestamp with time zone default now() not null
+);
+
+alter table invoice_period owner to petstore;
+-- +migrate StatementEnd
+
+-- +migrate Down
+-- +migrate StatementBegin
+drop table if exists invoice_period;
+-- +migrate StatementEnd
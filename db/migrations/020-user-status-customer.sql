@@ -0,0 +1,14 @@
+-- +migrate Up
+-- +migrate StatementBegin
+insert into user_status (id, name, allowed_methods)
+values (5, 'customer', '{GET,POST,PUT,DELETE}')
+on conflict (id) do nothing;
+
+select setval('user_status_id_seq', (select max(id) from user_status));
+-- +migrate StatementEnd
+
+-- +migrate Down
+-- +migrate StatementBegin
+update "user" set user_status_id = 2 where user_status_id = 5;
+delete from user_status where id = 5;
+-- +migrate StatementEnd
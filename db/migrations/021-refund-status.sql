@@ -0,0 +1,69 @@
+-- +migrate Up
+-- +migrate StatementBegin
+-- refunds are reserved as pending before the payment provider is called,
+-- the refunds made before are completed
+alter table refund add column if not exists status varchar(20) default 'completed' not null
+    constraint refund_status_check
+        check (status in ('pending', 'completed', 'failed'));
+alter table refund add column if not exists attempts integer default 0 not null;
+alter table refund add column if not exists last_error text default '' not null;
+alter table refund add column if not exists next_attempt_at timestamp with time zone default now() not null;
+alter table refund add column if not exists completed_at timestamp with time zone;
+
+update refund set completed_at = created_at where status = 'completed';
+
+-- the refund worker polls only the refunds left to make
+create index if not exists refund_next_attempt_at_index
+    on refund (next_attempt_at, id)
+    where status = 'pending';
+
+-- refunds are invoiced in the period they are completed
+drop view if exists invoice_refund_info;
+
+create view invoice_refund_info(id, line, user_name, pet, category, ship_date, quantity, price, discount) as
+SELECT r.order_id                        AS id,
+       1::bigint                         AS line,
+       u.user_name,
+       'Refund'::varchar                 AS pet,
+       r.reason                          AS category,
+       r.completed_at AT TIME ZONE 'UTC' AS ship_date,
+       1                                 AS quantity,
+       -r.amount                         AS price,
+       0::numeric(15,2)                  AS discount
+FROM ((refund r
+    JOIN "order" o ON ((r.order_id = o.id)))
+    JOIN "user" u ON ((o.user_id = u.id)))
+WHERE r.status = 'completed';
+
+alter table invoice_refund_info owner to petstore;
+-- +migrate StatementEnd
+
+-- +migrate Down
+-- +migrate StatementBegin
+drop view if exists invoice_refund_info;
+
+delete from refund where status <> 'completed';
+
+create view invoice_refund_info(id, line, user_name, pet, category, ship_date, quantity, price, discount) as
+SELECT r.order_id                      AS id,
+       1::bigint                       AS line,
+       u.user_name,
+       'Refund'::varchar               AS pet,
+       r.reason                        AS category,
+       r.created_at AT TIME ZONE 'UTC' AS ship_date,
+       1                               AS quantity,
+       -r.amount                       AS price,
+       0::numeric(15,2)                AS discount
+FROM ((refund r
+    JOIN "order" o ON ((r.order_id = o.id)))
+    JOIN "user" u ON ((o.user_id = u.id)));
+
+alter table invoice_refund_info owner to petstore;
+
+drop index if exists refund_next_attempt_at_index;
+alter table refund drop column if exists completed_at;
+alter table refund drop column if exists next_attempt_at;
+alter table refund drop column if exists last_error;
+alter table refund drop column if exists attempts;
+alter table refund drop column if exists status;
+-- +migrate StatementEnd
@@ -0,0 +1,129 @@
+//go:generate easyjson -all analytics.go
+
+package models
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	AnalyticsPeriodDay   = "day"
+	AnalyticsPeriodWeek  = "week"
+	AnalyticsPeriodMonth = "month"
+
+	DefaultAnalyticsLimit = 10
+)
+
+// SalesStatuses are the statuses of sold orders, an order is sold once
+// it's approved and approval needs a captured payment.
+var SalesStatuses = []string{OrderStatusApproved, OrderStatusDelivered}
+
+// IsSold tells whether the order with the status counts in sales.
+func IsSold(status string) bool {
+	for _, sold := range SalesStatuses {
+		if status == sold {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AnalyticsFilter selects the sold orders shipped in the inclusive range,
+// Period groups the sales report and Limit bounds the rankings.
+// Refunds count against the period of their order's ship date.
+type AnalyticsFilter struct {
+	From   time.Time
+	To     time.Time
+	Period string
+	Limit  int
+}
+
+func (f *AnalyticsFilter) Validate() error {
+	if f.From.IsZero() || f.To.IsZero() {
+		return errors.New("date range must have both ends")
+	}
+
+	if f.To.Before(f.From) {
+		return errors.New("date range is empty")
+	}
+
+	switch f.Period {
+	case "":
+		f.Period = AnalyticsPeriodDay
+	case AnalyticsPeriodDay, AnalyticsPeriodWeek, AnalyticsPeriodMonth:
+	default:
+		return errors.Errorf("unknown period %s", f.Period)
+	}
+
+	if f.Limit == 0 {
+		f.Limit = DefaultAnalyticsLimit
+	}
+
+	if f.Limit < 0 || f.Limit > MaxPageLimit {
+		return errors.Errorf("limit must be between 1 and %d", MaxPageLimit)
+	}
+
+	return nil
+}
+
+// PeriodStart returns the first day of the period holding t, weeks start
+// on Monday like date_trunc of postgres.
+func (f *AnalyticsFilter) PeriodStart(t time.Time) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+
+	switch f.Period {
+	case AnalyticsPeriodWeek:
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	case AnalyticsPeriodMonth:
+		return day.AddDate(0, 0, 1-day.Day())
+	default:
+		return day
+	}
+}
+
+// easyjson:json
+type CategorySalesList []*CategorySales
+
+// CategorySales sums the sold pets of the category, Revenue is the price
+// of the items less their discounts and their share of the completed refunds
+// of the order. Period is the first day of the period, it's empty in the rankings.
+// easyjson:json
+type CategorySales struct {
+	Period     string  `json:"period,omitempty" db:"period"`
+	CategoryID int64   `json:"category_id" db:"category_id"`
+	Category   string  `json:"category" db:"category"`
+	Units      int64   `json:"units" db:"units"`
+	Revenue    float64 `json:"revenue" db:"revenue"`
+}
+
+// OrderValue sums the totals of the sold orders less their completed refunds.
+// easyjson:json
+type OrderValue struct {
+	Orders  int64   `json:"orders" db:"orders"`
+	Revenue float64 `json:"revenue" db:"revenue"`
+	Average float64 `json:"average" db:"-"`
+}
+
+// SetAverage computes the average order value, it's 0 without orders.
+func (v *OrderValue) SetAverage() {
+	v.Revenue = RoundPrice(v.Revenue)
+	v.Average = 0
+	if v.Orders != 0 {
+		v.Average = RoundPrice(v.Revenue / float64(v.Orders))
+	}
+}
+
+// easyjson:json
+type UserValueList []*UserValue
+
+// UserValue sums the sold orders of the customer less their completed refunds.
+// easyjson:json
+type UserValue struct {
+	UserID   int64   `json:"user_id" db:"user_id"`
+	UserName string  `json:"user_name" db:"user_name"`
+	Orders   int64   `json:"orders" db:"orders"`
+	Revenue  float64 `json:"revenue" db:"revenue"`
+}
@@ -0,0 +1,533 @@
+// Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
+
+package models
+
+import (
+	json "encoding/json"
+	easyjson "github.com/mailru/easyjson"
+	jlexer "github.com/mailru/easyjson/jlexer"
+	jwriter "github.com/mailru/easyjson/jwriter"
+)
+
+// suppress unused package warning
+var (
+	_ *json.RawMessage
+	_ *jlexer.Lexer
+	_ *jwriter.Writer
+	_ easyjson.Marshaler
+)
+
+func easyjsonDfaeaa7eDecodeGithubComIamStubborNPetstoreDbModels(in *jlexer.Lexer, out *UserValueList) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+		*out = nil
+	} else {
+		in.Delim('[')
+		if *out == nil {
+			if !in.IsDelim(']') {
+				*out = make(UserValueList, 0, 8)
+			} else {
+				*out = UserValueList{}
+			}
+		} else {
+			*out = (*out)[:0]
+		}
+		for !in.IsDelim(']') {
+			var v1 *UserValue
+			if in.IsNull() {
+				in.Skip()
+				v1 = nil
+			} else {
+				if v1 == nil {
+					v1 = new(UserValue)
+				}
+				(*v1).UnmarshalEasyJSON(in)
+			}
+			*out = append(*out, v1)
+			in.WantComma()
+		}
+		in.Delim(']')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonDfaeaa7eEncodeGithubComIamStubborNPetstoreDbModels(out *jwriter.Writer, in UserValueList) {
+	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		out.RawString("null")
+	} else {
+		out.RawByte('[')
+		for v2, v3 := range in {
+			if v2 > 0 {
+				out.RawByte(',')
+			}
+			if v3 == nil {
+				out.RawString("null")
+			} else {
+				(*v3).MarshalEasyJSON(out)
+			}
+		}
+		out.RawByte(']')
+	}
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v UserValueList) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonDfaeaa7eEncodeGithubComIamStubborNPetstoreDbModels(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v UserValueList) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonDfaeaa7eEncodeGithubComIamStubborNPetstoreDbModels(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *UserValueList) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonDfaeaa7eDecodeGithubComIamStubborNPetstoreDbModels(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *UserValueList) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonDfaeaa7eDecodeGithubComIamStubborNPetstoreDbModels(l, v)
+}
+func easyjsonDfaeaa7eDecodeGithubComIamStubborNPetstoreDbModels1(in *jlexer.Lexer, out *UserValue) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "user_id":
+			out.UserID = int64(in.Int64())
+		case "user_name":
+			out.UserName = string(in.String())
+		case "orders":
+			out.Orders = int64(in.Int64())
+		case "revenue":
+			out.Revenue = float64(in.Float64())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonDfaeaa7eEncodeGithubComIamStubborNPetstoreDbModels1(out *jwriter.Writer, in UserValue) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"user_id\":"
+		out.RawString(prefix[1:])
+		out.Int64(int64(in.UserID))
+	}
+	{
+		const prefix string = ",\"user_name\":"
+		out.RawString(prefix)
+		out.String(string(in.UserName))
+	}
+	{
+		const prefix string = ",\"orders\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.Orders))
+	}
+	{
+		const prefix string = ",\"revenue\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.Revenue))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v UserValue) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonDfaeaa7eEncodeGithubComIamStubborNPetstoreDbModels1(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v UserValue) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonDfaeaa7eEncodeGithubComIamStubborNPetstoreDbModels1(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *UserValue) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonDfaeaa7eDecodeGithubComIamStubborNPetstoreDbModels1(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *UserValue) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonDfaeaa7eDecodeGithubComIamStubborNPetstoreDbModels1(l, v)
+}
+func easyjsonDfaeaa7eDecodeGithubComIamStubborNPetstoreDbModels2(in *jlexer.Lexer, out *OrderValue) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "orders":
+			out.Orders = int64(in.Int64())
+		case "revenue":
+			out.Revenue = float64(in.Float64())
+		case "average":
+			out.Average = float64(in.Float64())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonDfaeaa7eEncodeGithubComIamStubborNPetstoreDbModels2(out *jwriter.Writer, in OrderValue) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"orders\":"
+		out.RawString(prefix[1:])
+		out.Int64(int64(in.Orders))
+	}
+	{
+		const prefix string = ",\"revenue\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.Revenue))
+	}
+	{
+		const prefix string = ",\"average\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.Average))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v OrderValue) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonDfaeaa7eEncodeGithubComIamStubborNPetstoreDbModels2(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v OrderValue) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonDfaeaa7eEncodeGithubComIamStubborNPetstoreDbModels2(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *OrderValue) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonDfaeaa7eDecodeGithubComIamStubborNPetstoreDbModels2(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *OrderValue) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonDfaeaa7eDecodeGithubComIamStubborNPetstoreDbModels2(l, v)
+}
+func easyjsonDfaeaa7eDecodeGithubComIamStubborNPetstoreDbModels3(in *jlexer.Lexer, out *CategorySalesList) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+		*out = nil
+	} else {
+		in.Delim('[')
+		if *out == nil {
+			if !in.IsDelim(']') {
+				*out = make(CategorySalesList, 0, 8)
+			} else {
+				*out = CategorySalesList{}
+			}
+		} else {
+			*out = (*out)[:0]
+		}
+		for !in.IsDelim(']') {
+			var v4 *CategorySales
+			if in.IsNull() {
+				in.Skip()
+				v4 = nil
+			} else {
+				if v4 == nil {
+					v4 = new(CategorySales)
+				}
+				(*v4).UnmarshalEasyJSON(in)
+			}
+			*out = append(*out, v4)
+			in.WantComma()
+		}
+		in.Delim(']')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonDfaeaa7eEncodeGithubComIamStubborNPetstoreDbModels3(out *jwriter.Writer, in CategorySalesList) {
+	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		out.RawString("null")
+	} else {
+		out.RawByte('[')
+		for v5, v6 := range in {
+			if v5 > 0 {
+				out.RawByte(',')
+			}
+			if v6 == nil {
+				out.RawString("null")
+			} else {
+				(*v6).MarshalEasyJSON(out)
+			}
+		}
+		out.RawByte(']')
+	}
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v CategorySalesList) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonDfaeaa7eEncodeGithubComIamStubborNPetstoreDbModels3(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v CategorySalesList) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonDfaeaa7eEncodeGithubComIamStubborNPetstoreDbModels3(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *CategorySalesList) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonDfaeaa7eDecodeGithubComIamStubborNPetstoreDbModels3(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *CategorySalesList) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonDfaeaa7eDecodeGithubComIamStubborNPetstoreDbModels3(l, v)
+}
+func easyjsonDfaeaa7eDecodeGithubComIamStubborNPetstoreDbModels4(in *jlexer.Lexer, out *CategorySales) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "period":
+			out.Period = string(in.String())
+		case "category_id":
+			out.CategoryID = int64(in.Int64())
+		case "category":
+			out.Category = string(in.String())
+		case "units":
+			out.Units = int64(in.Int64())
+		case "revenue":
+			out.Revenue = float64(in.Float64())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonDfaeaa7eEncodeGithubComIamStubborNPetstoreDbModels4(out *jwriter.Writer, in CategorySales) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	if in.Period != "" {
+		const prefix string = ",\"period\":"
+		first = false
+		out.RawString(prefix[1:])
+		out.String(string(in.Period))
+	}
+	{
+		const prefix string = ",\"category_id\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int64(int64(in.CategoryID))
+	}
+	{
+		const prefix string = ",\"category\":"
+		out.RawString(prefix)
+		out.String(string(in.Category))
+	}
+	{
+		const prefix string = ",\"units\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.Units))
+	}
+	{
+		const prefix string = ",\"revenue\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.Revenue))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v CategorySales) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonDfaeaa7eEncodeGithubComIamStubborNPetstoreDbModels4(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v CategorySales) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonDfaeaa7eEncodeGithubComIamStubborNPetstoreDbModels4(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *CategorySales) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonDfaeaa7eDecodeGithubComIamStubborNPetstoreDbModels4(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *CategorySales) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonDfaeaa7eDecodeGithubComIamStubborNPetstoreDbModels4(l, v)
+}
+func easyjsonDfaeaa7eDecodeGithubComIamStubborNPetstoreDbModels5(in *jlexer.Lexer, out *AnalyticsFilter) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "From":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.From).UnmarshalJSON(data))
+			}
+		case "To":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.To).UnmarshalJSON(data))
+			}
+		case "Period":
+			out.Period = string(in.String())
+		case "Limit":
+			out.Limit = int(in.Int())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonDfaeaa7eEncodeGithubComIamStubborNPetstoreDbModels5(out *jwriter.Writer, in AnalyticsFilter) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"From\":"
+		out.RawString(prefix[1:])
+		out.Raw((in.From).MarshalJSON())
+	}
+	{
+		const prefix string = ",\"To\":"
+		out.RawString(prefix)
+		out.Raw((in.To).MarshalJSON())
+	}
+	{
+		const prefix string = ",\"Period\":"
+		out.RawString(prefix)
+		out.String(string(in.Period))
+	}
+	{
+		const prefix string = ",\"Limit\":"
+		out.RawString(prefix)
+		out.Int(int(in.Limit))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v AnalyticsFilter) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonDfaeaa7eEncodeGithubComIamStubborNPetstoreDbModels5(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v AnalyticsFilter) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonDfaeaa7eEncodeGithubComIamStubborNPetstoreDbModels5(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *AnalyticsFilter) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonDfaeaa7eDecodeGithubComIamStubborNPetstoreDbModels5(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *AnalyticsFilter) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonDfaeaa7eDecodeGithubComIamStubborNPetstoreDbModels5(l, v)
+}
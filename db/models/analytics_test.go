@@ -0,0 +1,87 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAnalyticsFilter_Validate(t *testing.T) {
+	from := time.Date(2019, 9, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2019, 9, 30, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		filter     AnalyticsFilter
+		wantPeriod string
+		wantLimit  int
+		wantErr    bool
+	}{
+		{name: "defaults", filter: AnalyticsFilter{From: from, To: to},
+			wantPeriod: AnalyticsPeriodDay, wantLimit: DefaultAnalyticsLimit},
+		{name: "month", filter: AnalyticsFilter{From: from, To: to, Period: AnalyticsPeriodMonth, Limit: 3},
+			wantPeriod: AnalyticsPeriodMonth, wantLimit: 3},
+		{name: "single day", filter: AnalyticsFilter{From: from, To: from},
+			wantPeriod: AnalyticsPeriodDay, wantLimit: DefaultAnalyticsLimit},
+		{name: "open range", filter: AnalyticsFilter{From: from}, wantErr: true},
+		{name: "empty range", filter: AnalyticsFilter{From: to, To: from}, wantErr: true},
+		{name: "unknown period", filter: AnalyticsFilter{From: from, To: to, Period: "year"}, wantErr: true},
+		{name: "negative limit", filter: AnalyticsFilter{From: from, To: to, Limit: -1}, wantErr: true},
+		{name: "too big limit", filter: AnalyticsFilter{From: from, To: to, Limit: MaxPageLimit + 1}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.filter.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if err == nil && (tt.filter.Period != tt.wantPeriod || tt.filter.Limit != tt.wantLimit) {
+				t.Errorf("Validate() period = %s, limit = %d, want %s, %d",
+					tt.filter.Period, tt.filter.Limit, tt.wantPeriod, tt.wantLimit)
+			}
+		})
+	}
+}
+
+func TestAnalyticsFilter_PeriodStart(t *testing.T) {
+	shipped := time.Date(2019, 9, 5, 15, 35, 12, 0, time.UTC) // Thursday
+
+	tests := []struct {
+		name   string
+		period string
+		want   time.Time
+	}{
+		{name: "day", period: AnalyticsPeriodDay, want: time.Date(2019, 9, 5, 0, 0, 0, 0, time.UTC)},
+		{name: "week", period: AnalyticsPeriodWeek, want: time.Date(2019, 9, 2, 0, 0, 0, 0, time.UTC)},
+		{name: "month", period: AnalyticsPeriodMonth, want: time.Date(2019, 9, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &AnalyticsFilter{Period: tt.period}
+			if got := f.PeriodStart(shipped); !got.Equal(tt.want) {
+				t.Errorf("PeriodStart() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	sunday := time.Date(2019, 9, 8, 23, 0, 0, 0, time.UTC)
+	f := &AnalyticsFilter{Period: AnalyticsPeriodWeek}
+	if got := f.PeriodStart(sunday); !got.Equal(time.Date(2019, 9, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("PeriodStart() of Sunday = %v, want the Monday before", got)
+	}
+}
+
+func TestOrderValue_SetAverage(t *testing.T) {
+	v := OrderValue{Orders: 3, Revenue: 100}
+	v.SetAverage()
+	if v.Average != 33.33 {
+		t.Errorf("SetAverage() average = %v, want 33.33", v.Average)
+	}
+
+	empty := OrderValue{}
+	empty.SetAverage()
+	if empty.Average != 0 {
+		t.Errorf("SetAverage() average without orders = %v, want 0", empty.Average)
+	}
+}
@@ -0,0 +1,38 @@
+//go:generate easyjson -all cart.go
+
+package models
+
+// Cart keeps the pets a user is going to order, checkout turns
+// the whole cart into a single order.
+// easyjson:json
+type Cart struct {
+	UserID int64        `json:"user_id"`
+	Items  CartItemList `json:"items"`
+}
+
+// easyjson:json
+type CartItemList []*CartItem
+
+// easyjson:json
+type CartItem struct {
+	PetID    int64   `json:"pet_id" db:"pet_id" validate:"nonzero"`
+	PetName  string  `json:"pet_name,omitempty" db:"pet_name"`
+	Price    float64 `json:"price,omitempty" db:"price"`
+	Quantity int32   `json:"quantity" db:"quantity" validate:"min=1"`
+}
+
+// OrderItems returns the cart content as the items of an order.
+func (c *Cart) OrderItems() OrderItemList {
+	items := make(OrderItemList, 0, len(c.Items))
+	for _, item := range c.Items {
+		items = append(items, &OrderItem{PetID: item.PetID, Quantity: item.Quantity})
+	}
+
+	return items
+}
+
+// CartCheckout is the optional body of the cart checkout.
+// easyjson:json
+type CartCheckout struct {
+	Coupons []string `json:"coupons"`
+}
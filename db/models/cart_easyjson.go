@@ -0,0 +1,355 @@
+// Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
+
+package models
+
+import (
+	json "encoding/json"
+	easyjson "github.com/mailru/easyjson"
+	jlexer "github.com/mailru/easyjson/jlexer"
+	jwriter "github.com/mailru/easyjson/jwriter"
+)
+
+// suppress unused package warning
+var (
+	_ *json.RawMessage
+	_ *jlexer.Lexer
+	_ *jwriter.Writer
+	_ easyjson.Marshaler
+)
+
+func easyjsonDdb0949aDecodeGithubComIamStubborNPetstoreDbModels(in *jlexer.Lexer, out *CartItemList) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+		*out = nil
+	} else {
+		in.Delim('[')
+		if *out == nil {
+			if !in.IsDelim(']') {
+				*out = make(CartItemList, 0, 8)
+			} else {
+				*out = CartItemList{}
+			}
+		} else {
+			*out = (*out)[:0]
+		}
+		for !in.IsDelim(']') {
+			var v1 *CartItem
+			if in.IsNull() {
+				in.Skip()
+				v1 = nil
+			} else {
+				if v1 == nil {
+					v1 = new(CartItem)
+				}
+				(*v1).UnmarshalEasyJSON(in)
+			}
+			*out = append(*out, v1)
+			in.WantComma()
+		}
+		in.Delim(']')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonDdb0949aEncodeGithubComIamStubborNPetstoreDbModels(out *jwriter.Writer, in CartItemList) {
+	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		out.RawString("null")
+	} else {
+		out.RawByte('[')
+		for v2, v3 := range in {
+			if v2 > 0 {
+				out.RawByte(',')
+			}
+			if v3 == nil {
+				out.RawString("null")
+			} else {
+				(*v3).MarshalEasyJSON(out)
+			}
+		}
+		out.RawByte(']')
+	}
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v CartItemList) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonDdb0949aEncodeGithubComIamStubborNPetstoreDbModels(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v CartItemList) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonDdb0949aEncodeGithubComIamStubborNPetstoreDbModels(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *CartItemList) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonDdb0949aDecodeGithubComIamStubborNPetstoreDbModels(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *CartItemList) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonDdb0949aDecodeGithubComIamStubborNPetstoreDbModels(l, v)
+}
+func easyjsonDdb0949aDecodeGithubComIamStubborNPetstoreDbModels1(in *jlexer.Lexer, out *CartItem) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "pet_id":
+			out.PetID = int64(in.Int64())
+		case "pet_name":
+			out.PetName = string(in.String())
+		case "price":
+			out.Price = float64(in.Float64())
+		case "quantity":
+			out.Quantity = int32(in.Int32())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonDdb0949aEncodeGithubComIamStubborNPetstoreDbModels1(out *jwriter.Writer, in CartItem) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"pet_id\":"
+		out.RawString(prefix[1:])
+		out.Int64(int64(in.PetID))
+	}
+	if in.PetName != "" {
+		const prefix string = ",\"pet_name\":"
+		out.RawString(prefix)
+		out.String(string(in.PetName))
+	}
+	if in.Price != 0 {
+		const prefix string = ",\"price\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.Price))
+	}
+	{
+		const prefix string = ",\"quantity\":"
+		out.RawString(prefix)
+		out.Int32(int32(in.Quantity))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v CartItem) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonDdb0949aEncodeGithubComIamStubborNPetstoreDbModels1(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v CartItem) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonDdb0949aEncodeGithubComIamStubborNPetstoreDbModels1(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *CartItem) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonDdb0949aDecodeGithubComIamStubborNPetstoreDbModels1(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *CartItem) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonDdb0949aDecodeGithubComIamStubborNPetstoreDbModels1(l, v)
+}
+func easyjsonDdb0949aDecodeGithubComIamStubborNPetstoreDbModels2(in *jlexer.Lexer, out *CartCheckout) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "coupons":
+			if in.IsNull() {
+				in.Skip()
+				out.Coupons = nil
+			} else {
+				in.Delim('[')
+				if out.Coupons == nil {
+					if !in.IsDelim(']') {
+						out.Coupons = make([]string, 0, 4)
+					} else {
+						out.Coupons = []string{}
+					}
+				} else {
+					out.Coupons = (out.Coupons)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v4 string
+					v4 = string(in.String())
+					out.Coupons = append(out.Coupons, v4)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonDdb0949aEncodeGithubComIamStubborNPetstoreDbModels2(out *jwriter.Writer, in CartCheckout) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"coupons\":"
+		out.RawString(prefix[1:])
+		if in.Coupons == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v5, v6 := range in.Coupons {
+				if v5 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v6))
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v CartCheckout) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonDdb0949aEncodeGithubComIamStubborNPetstoreDbModels2(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v CartCheckout) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonDdb0949aEncodeGithubComIamStubborNPetstoreDbModels2(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *CartCheckout) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonDdb0949aDecodeGithubComIamStubborNPetstoreDbModels2(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *CartCheckout) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonDdb0949aDecodeGithubComIamStubborNPetstoreDbModels2(l, v)
+}
+func easyjsonDdb0949aDecodeGithubComIamStubborNPetstoreDbModels3(in *jlexer.Lexer, out *Cart) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "user_id":
+			out.UserID = int64(in.Int64())
+		case "items":
+			(out.Items).UnmarshalEasyJSON(in)
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonDdb0949aEncodeGithubComIamStubborNPetstoreDbModels3(out *jwriter.Writer, in Cart) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"user_id\":"
+		out.RawString(prefix[1:])
+		out.Int64(int64(in.UserID))
+	}
+	{
+		const prefix string = ",\"items\":"
+		out.RawString(prefix)
+		(in.Items).MarshalEasyJSON(out)
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Cart) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonDdb0949aEncodeGithubComIamStubborNPetstoreDbModels3(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Cart) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonDdb0949aEncodeGithubComIamStubborNPetstoreDbModels3(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Cart) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonDdb0949aDecodeGithubComIamStubborNPetstoreDbModels3(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Cart) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonDdb0949aDecodeGithubComIamStubborNPetstoreDbModels3(l, v)
+}
@@ -2,6 +2,9 @@
 
 package models
 
+// easyjson:json
+type CategoryList []*Category
+
 // easyjson:json
 type Category struct {
 	ID    int64   `json:"id" db:"id"`
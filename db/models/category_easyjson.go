@@ -17,7 +17,85 @@ var (
 	_ easyjson.Marshaler
 )
 
-func easyjson6a91a67cDecodeGithubComIamStubborNPetstoreDbModels(in *jlexer.Lexer, out *Category) {
+func easyjson6a91a67cDecodeGithubComIamStubborNPetstoreDbModels(in *jlexer.Lexer, out *CategoryList) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+		*out = nil
+	} else {
+		in.Delim('[')
+		if *out == nil {
+			if !in.IsDelim(']') {
+				*out = make(CategoryList, 0, 8)
+			} else {
+				*out = CategoryList{}
+			}
+		} else {
+			*out = (*out)[:0]
+		}
+		for !in.IsDelim(']') {
+			var v1 *Category
+			if in.IsNull() {
+				in.Skip()
+				v1 = nil
+			} else {
+				if v1 == nil {
+					v1 = new(Category)
+				}
+				(*v1).UnmarshalEasyJSON(in)
+			}
+			*out = append(*out, v1)
+			in.WantComma()
+		}
+		in.Delim(']')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson6a91a67cEncodeGithubComIamStubborNPetstoreDbModels(out *jwriter.Writer, in CategoryList) {
+	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		out.RawString("null")
+	} else {
+		out.RawByte('[')
+		for v2, v3 := range in {
+			if v2 > 0 {
+				out.RawByte(',')
+			}
+			if v3 == nil {
+				out.RawString("null")
+			} else {
+				(*v3).MarshalEasyJSON(out)
+			}
+		}
+		out.RawByte(']')
+	}
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v CategoryList) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson6a91a67cEncodeGithubComIamStubborNPetstoreDbModels(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v CategoryList) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson6a91a67cEncodeGithubComIamStubborNPetstoreDbModels(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *CategoryList) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson6a91a67cDecodeGithubComIamStubborNPetstoreDbModels(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *CategoryList) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson6a91a67cDecodeGithubComIamStubborNPetstoreDbModels(l, v)
+}
+func easyjson6a91a67cDecodeGithubComIamStubborNPetstoreDbModels1(in *jlexer.Lexer, out *Category) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -52,7 +130,7 @@ func easyjson6a91a67cDecodeGithubComIamStubborNPetstoreDbModels(in *jlexer.Lexer
 		in.Consumed()
 	}
 }
-func easyjson6a91a67cEncodeGithubComIamStubborNPetstoreDbModels(out *jwriter.Writer, in Category) {
+func easyjson6a91a67cEncodeGithubComIamStubborNPetstoreDbModels1(out *jwriter.Writer, in Category) {
 	out.RawByte('{')
 	first := true
 	_ = first
@@ -77,23 +155,23 @@ func easyjson6a91a67cEncodeGithubComIamStubborNPetstoreDbModels(out *jwriter.Wri
 // MarshalJSON supports json.Marshaler interface
 func (v Category) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjson6a91a67cEncodeGithubComIamStubborNPetstoreDbModels(&w, v)
+	easyjson6a91a67cEncodeGithubComIamStubborNPetstoreDbModels1(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
 func (v Category) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjson6a91a67cEncodeGithubComIamStubborNPetstoreDbModels(w, v)
+	easyjson6a91a67cEncodeGithubComIamStubborNPetstoreDbModels1(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
 func (v *Category) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjson6a91a67cDecodeGithubComIamStubborNPetstoreDbModels(&r, v)
+	easyjson6a91a67cDecodeGithubComIamStubborNPetstoreDbModels1(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
 func (v *Category) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjson6a91a67cDecodeGithubComIamStubborNPetstoreDbModels(l, v)
+	easyjson6a91a67cDecodeGithubComIamStubborNPetstoreDbModels1(l, v)
 }
@@ -0,0 +1,168 @@
+//go:generate easyjson -all coupon.go
+
+package models
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	CouponKindPercent = "percent"
+	CouponKindFixed   = "fixed"
+)
+
+// easyjson:json
+type CouponList []*Coupon
+
+// Coupon discounts the order items of its category, or of all
+// categories when CategoryID is 0. Zero limits mean no limit and
+// a coupon without ValidTo never expires.
+// easyjson:json
+type Coupon struct {
+	ID             int64      `json:"id" db:"id"`
+	Code           string     `json:"code" db:"code" validate:"nonzero,max=50"`
+	Kind           string     `json:"kind" db:"kind" validate:"nonzero"`
+	Value          float64    `json:"value" db:"value"`
+	CategoryID     int64      `json:"category_id,omitempty" db:"category_id"`
+	ValidFrom      time.Time  `json:"valid_from" db:"valid_from"`
+	ValidTo        *time.Time `json:"valid_to,omitempty" db:"valid_to"`
+	MaxUses        int32      `json:"max_uses" db:"max_uses" validate:"min=0"`
+	MaxUsesPerUser int32      `json:"max_uses_per_user" db:"max_uses_per_user" validate:"min=0"`
+	Stackable      bool       `json:"stackable" db:"stackable"`
+}
+
+// Validate checks the coupon values the validator tags can't check.
+func (c *Coupon) Validate() error {
+	switch c.Kind {
+	case CouponKindPercent:
+		if c.Value <= 0 || c.Value > 100 {
+			return errors.New("percent discount must be between 0 and 100")
+		}
+	case CouponKindFixed:
+		if c.Value <= 0 {
+			return errors.New("fixed discount must be positive")
+		}
+	default:
+		return errors.Errorf("unknown coupon kind %s", c.Kind)
+	}
+
+	if c.ValidTo != nil && !c.ValidTo.After(c.ValidFrom) {
+		return errors.New("coupon validity window is empty")
+	}
+
+	return nil
+}
+
+// CheckCoupons returns an error wrapped around ErrConflict when the coupons
+// can't be applied together at the moment now, only stackable coupons combine.
+func CheckCoupons(coupons []*Coupon, now time.Time) error {
+	for _, coupon := range coupons {
+		if now.Before(coupon.ValidFrom) {
+			return errors.Wrapf(ErrConflict, "coupon %s isn't active yet", coupon.Code)
+		}
+
+		if coupon.ValidTo != nil && !now.Before(*coupon.ValidTo) {
+			return errors.Wrapf(ErrConflict, "coupon %s is expired", coupon.Code)
+		}
+
+		if len(coupons) > 1 && !coupon.Stackable {
+			return errors.Wrapf(ErrConflict, "coupon %s can't be combined with other coupons", coupon.Code)
+		}
+	}
+
+	return nil
+}
+
+// CheckCouponUses returns an error wrapped around ErrConflict when the coupon
+// is used up, uses are counted for everybody and for the ordering user.
+func CheckCouponUses(coupon *Coupon, uses, userUses int32) error {
+	if coupon.MaxUses > 0 && uses >= coupon.MaxUses {
+		return errors.Wrapf(ErrConflict, "coupon %s is used up", coupon.Code)
+	}
+
+	if coupon.MaxUsesPerUser > 0 && userUses >= coupon.MaxUsesPerUser {
+		return errors.Wrapf(ErrConflict, "coupon %s is already used", coupon.Code)
+	}
+
+	return nil
+}
+
+// ApplyCoupons sets the gross price and the discount of the order and of its
+// priced items. Percentage coupons go before fixed ones, a fixed discount is
+// shared between the items in proportion to their price and no item
+// is discounted below zero. A coupon of a category the order doesn't have
+// is an error wrapped around ErrConflict.
+func (o *Order) ApplyCoupons(coupons []*Coupon) error {
+	sorted := make([]*Coupon, len(coupons))
+	copy(sorted, coupons)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Kind == CouponKindPercent && sorted[j].Kind != CouponKindPercent
+	})
+
+	for _, item := range o.Items {
+		item.Discount = 0
+	}
+
+	for _, coupon := range sorted {
+		var matched OrderItemList
+		var left float64
+		for _, item := range o.Items {
+			if coupon.CategoryID == 0 || coupon.CategoryID == item.CategoryID {
+				matched = append(matched, item)
+				left += item.gross() - item.Discount
+			}
+		}
+
+		if len(matched) == 0 {
+			return errors.Wrapf(ErrConflict, "coupon %s doesn't apply to the order", coupon.Code)
+		}
+
+		switch coupon.Kind {
+		case CouponKindPercent:
+			for _, item := range matched {
+				item.Discount += RoundPrice((item.gross() - item.Discount) * coupon.Value / 100)
+			}
+		case CouponKindFixed:
+			if left <= 0 {
+				continue
+			}
+
+			amount := RoundPrice(math.Min(coupon.Value, left))
+			var shared float64
+			for idx, item := range matched {
+				share := amount - shared
+				if idx < len(matched)-1 {
+					share = RoundPrice(amount * (item.gross() - item.Discount) / left)
+				}
+
+				item.Discount += share
+				shared += share
+			}
+		}
+	}
+
+	o.Gross, o.Discount = 0, 0
+	for _, item := range o.Items {
+		item.Discount = RoundPrice(item.Discount)
+		o.Gross += item.gross()
+		o.Discount += item.Discount
+	}
+
+	o.Gross = RoundPrice(o.Gross)
+	o.Discount = RoundPrice(o.Discount)
+
+	return nil
+}
+
+func (i *OrderItem) gross() float64 {
+	return i.Price * float64(i.Quantity)
+}
+
+// RoundPrice rounds the price to cents.
+func RoundPrice(price float64) float64 {
+	return math.Round(price*100) / 100
+}
@@ -0,0 +1,239 @@
+// Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
+
+package models
+
+import (
+	json "encoding/json"
+	easyjson "github.com/mailru/easyjson"
+	jlexer "github.com/mailru/easyjson/jlexer"
+	jwriter "github.com/mailru/easyjson/jwriter"
+	time "time"
+)
+
+// suppress unused package warning
+var (
+	_ *json.RawMessage
+	_ *jlexer.Lexer
+	_ *jwriter.Writer
+	_ easyjson.Marshaler
+)
+
+func easyjson1c3847aeDecodeGithubComIamStubborNPetstoreDbModels(in *jlexer.Lexer, out *CouponList) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+		*out = nil
+	} else {
+		in.Delim('[')
+		if *out == nil {
+			if !in.IsDelim(']') {
+				*out = make(CouponList, 0, 8)
+			} else {
+				*out = CouponList{}
+			}
+		} else {
+			*out = (*out)[:0]
+		}
+		for !in.IsDelim(']') {
+			var v1 *Coupon
+			if in.IsNull() {
+				in.Skip()
+				v1 = nil
+			} else {
+				if v1 == nil {
+					v1 = new(Coupon)
+				}
+				(*v1).UnmarshalEasyJSON(in)
+			}
+			*out = append(*out, v1)
+			in.WantComma()
+		}
+		in.Delim(']')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson1c3847aeEncodeGithubComIamStubborNPetstoreDbModels(out *jwriter.Writer, in CouponList) {
+	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		out.RawString("null")
+	} else {
+		out.RawByte('[')
+		for v2, v3 := range in {
+			if v2 > 0 {
+				out.RawByte(',')
+			}
+			if v3 == nil {
+				out.RawString("null")
+			} else {
+				(*v3).MarshalEasyJSON(out)
+			}
+		}
+		out.RawByte(']')
+	}
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v CouponList) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson1c3847aeEncodeGithubComIamStubborNPetstoreDbModels(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v CouponList) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson1c3847aeEncodeGithubComIamStubborNPetstoreDbModels(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *CouponList) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson1c3847aeDecodeGithubComIamStubborNPetstoreDbModels(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *CouponList) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson1c3847aeDecodeGithubComIamStubborNPetstoreDbModels(l, v)
+}
+func easyjson1c3847aeDecodeGithubComIamStubborNPetstoreDbModels1(in *jlexer.Lexer, out *Coupon) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "id":
+			out.ID = int64(in.Int64())
+		case "code":
+			out.Code = string(in.String())
+		case "kind":
+			out.Kind = string(in.String())
+		case "value":
+			out.Value = float64(in.Float64())
+		case "category_id":
+			out.CategoryID = int64(in.Int64())
+		case "valid_from":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.ValidFrom).UnmarshalJSON(data))
+			}
+		case "valid_to":
+			if in.IsNull() {
+				in.Skip()
+				out.ValidTo = nil
+			} else {
+				if out.ValidTo == nil {
+					out.ValidTo = new(time.Time)
+				}
+				if data := in.Raw(); in.Ok() {
+					in.AddError((*out.ValidTo).UnmarshalJSON(data))
+				}
+			}
+		case "max_uses":
+			out.MaxUses = int32(in.Int32())
+		case "max_uses_per_user":
+			out.MaxUsesPerUser = int32(in.Int32())
+		case "stackable":
+			out.Stackable = bool(in.Bool())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson1c3847aeEncodeGithubComIamStubborNPetstoreDbModels1(out *jwriter.Writer, in Coupon) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"id\":"
+		out.RawString(prefix[1:])
+		out.Int64(int64(in.ID))
+	}
+	{
+		const prefix string = ",\"code\":"
+		out.RawString(prefix)
+		out.String(string(in.Code))
+	}
+	{
+		const prefix string = ",\"kind\":"
+		out.RawString(prefix)
+		out.String(string(in.Kind))
+	}
+	{
+		const prefix string = ",\"value\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.Value))
+	}
+	if in.CategoryID != 0 {
+		const prefix string = ",\"category_id\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.CategoryID))
+	}
+	{
+		const prefix string = ",\"valid_from\":"
+		out.RawString(prefix)
+		out.Raw((in.ValidFrom).MarshalJSON())
+	}
+	if in.ValidTo != nil {
+		const prefix string = ",\"valid_to\":"
+		out.RawString(prefix)
+		out.Raw((*in.ValidTo).MarshalJSON())
+	}
+	{
+		const prefix string = ",\"max_uses\":"
+		out.RawString(prefix)
+		out.Int32(int32(in.MaxUses))
+	}
+	{
+		const prefix string = ",\"max_uses_per_user\":"
+		out.RawString(prefix)
+		out.Int32(int32(in.MaxUsesPerUser))
+	}
+	{
+		const prefix string = ",\"stackable\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.Stackable))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Coupon) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson1c3847aeEncodeGithubComIamStubborNPetstoreDbModels1(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Coupon) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson1c3847aeEncodeGithubComIamStubborNPetstoreDbModels1(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Coupon) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson1c3847aeDecodeGithubComIamStubborNPetstoreDbModels1(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Coupon) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson1c3847aeDecodeGithubComIamStubborNPetstoreDbModels1(l, v)
+}
@@ -0,0 +1,131 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestOrder_ApplyCoupons(t *testing.T) {
+	items := func() OrderItemList {
+		return OrderItemList{
+			{PetID: 1, CategoryID: 1, Quantity: 1, Price: 35.00},
+			{PetID: 2, CategoryID: 2, Quantity: 3, Price: 49.99},
+		}
+	}
+	spring := &Coupon{Code: "SPRING10", Kind: CouponKindPercent, Value: 10}
+	dogs := &Coupon{Code: "DOGS5", Kind: CouponKindFixed, Value: 5, CategoryID: 2}
+	big := &Coupon{Code: "BIG", Kind: CouponKindFixed, Value: 500}
+	horses := &Coupon{Code: "HORSES", Kind: CouponKindPercent, Value: 10, CategoryID: 3}
+
+	tests := []struct {
+		name          string
+		coupons       []*Coupon
+		wantDiscounts []float64
+		wantGross     float64
+		wantDiscount  float64
+		wantErr       bool
+	}{
+		{name: "no coupons", wantDiscounts: []float64{0, 0}, wantGross: 184.97},
+		{name: "percent", coupons: []*Coupon{spring},
+			wantDiscounts: []float64{3.50, 15.00}, wantGross: 184.97, wantDiscount: 18.50},
+		{name: "fixed of category", coupons: []*Coupon{dogs},
+			wantDiscounts: []float64{0, 5.00}, wantGross: 184.97, wantDiscount: 5.00},
+		{name: "percent goes first", coupons: []*Coupon{dogs, spring},
+			wantDiscounts: []float64{3.50, 20.00}, wantGross: 184.97, wantDiscount: 23.50},
+		{name: "fixed is shared and capped", coupons: []*Coupon{big},
+			wantDiscounts: []float64{35.00, 149.97}, wantGross: 184.97, wantDiscount: 184.97},
+		{name: "coupon of missing category", coupons: []*Coupon{horses}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := Order{Items: items()}
+
+			err := order.ApplyCoupons(tt.coupons)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ApplyCoupons() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			var discounts []float64
+			for _, item := range order.Items {
+				discounts = append(discounts, item.Discount)
+			}
+
+			if !reflect.DeepEqual(discounts, tt.wantDiscounts) {
+				t.Errorf("ApplyCoupons() item discounts = %v, want %v", discounts, tt.wantDiscounts)
+			}
+
+			if order.Gross != tt.wantGross || order.Discount != tt.wantDiscount {
+				t.Errorf("ApplyCoupons() gross, discount = %v, %v, want %v, %v",
+					order.Gross, order.Discount, tt.wantGross, tt.wantDiscount)
+			}
+		})
+	}
+}
+
+func TestCheckCoupons(t *testing.T) {
+	now := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	before := now.Add(-time.Hour)
+	after := now.Add(time.Hour)
+
+	tests := []struct {
+		name    string
+		coupons []*Coupon
+		wantErr bool
+	}{
+		{name: "active", coupons: []*Coupon{{Code: "A", ValidFrom: before, ValidTo: &after}}},
+		{name: "not active yet", coupons: []*Coupon{{Code: "A", ValidFrom: after}}, wantErr: true},
+		{name: "expired", coupons: []*Coupon{{Code: "A", ValidFrom: before, ValidTo: &now}}, wantErr: true},
+		{name: "stackable", coupons: []*Coupon{
+			{Code: "A", ValidFrom: before, Stackable: true},
+			{Code: "B", ValidFrom: before, Stackable: true},
+		}},
+		{name: "not stackable", coupons: []*Coupon{
+			{Code: "A", ValidFrom: before, Stackable: true},
+			{Code: "B", ValidFrom: before},
+		}, wantErr: true},
+		{name: "single not stackable", coupons: []*Coupon{{Code: "B", ValidFrom: before}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckCoupons(tt.coupons, now)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckCoupons() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if err != nil && errors.Cause(err) != ErrConflict {
+				t.Errorf("CheckCoupons() error = %v, want ErrConflict", err)
+			}
+		})
+	}
+}
+
+func TestCheckCouponUses(t *testing.T) {
+	tests := []struct {
+		name     string
+		coupon   *Coupon
+		uses     int32
+		userUses int32
+		wantErr  bool
+	}{
+		{name: "no limits", coupon: &Coupon{}, uses: 100, userUses: 10},
+		{name: "under limits", coupon: &Coupon{MaxUses: 10, MaxUsesPerUser: 2}, uses: 9, userUses: 1},
+		{name: "used up", coupon: &Coupon{MaxUses: 10}, uses: 10, wantErr: true},
+		{name: "used by user", coupon: &Coupon{MaxUsesPerUser: 1}, uses: 3, userUses: 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckCouponUses(tt.coupon, tt.uses, tt.userUses)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckCouponUses() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"encoding/base64"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Cursor points to the last row of a page, rows are continued
+// after the (Key, ID) pair in the sort order of the listing.
+type Cursor struct {
+	Key string
+	ID  int64
+}
+
+func (c Cursor) Encode() string {
+	raw := strconv.FormatInt(c.ID, 10) + ":" + c.Key
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeCursor parses the cursor from Encode, an empty string
+// gives the zero cursor that points before the first row.
+func DecodeCursor(encoded string) (Cursor, error) {
+	if encoded == "" {
+		return Cursor{}, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return Cursor{}, errors.Wrap(err, "can't decode cursor")
+	}
+
+	parts := strings.SplitN(string(raw), ":", 2)
+	if len(parts) != 2 {
+		return Cursor{}, errors.New("cursor is malformed")
+	}
+
+	id, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return Cursor{}, errors.Wrap(err, "cursor is malformed")
+	}
+
+	return Cursor{Key: parts[1], ID: id}, nil
+}
@@ -0,0 +1,11 @@
+package models
+
+import "github.com/pkg/errors"
+
+// Providers wrap these errors, so handlers can pick
+// a response code with errors.Cause.
+var (
+	ErrNotFound          = errors.New("not found")
+	ErrConflict          = errors.New("conflict")
+	ErrInvalidTransition = errors.New("invalid status transition")
+)
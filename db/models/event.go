@@ -0,0 +1,179 @@
+//go:generate easyjson -all event.go
+
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/mailru/easyjson"
+	"github.com/pkg/errors"
+)
+
+const (
+	AggregatePet   = "pet"
+	AggregateOrder = "order"
+	AggregateUser  = "user"
+
+	EventPetCreated       = "pet.created"
+	EventPetUpdated       = "pet.updated"
+	EventPetStatusChanged = "pet.status_changed"
+	EventPetDeleted       = "pet.deleted"
+	EventOrderCreated     = "order.created"
+	EventOrderApproved    = "order.approved"
+	EventOrderDelivered   = "order.delivered"
+	EventOrderCancelled   = "order.cancelled"
+	EventOrderDeleted     = "order.deleted"
+	EventUserCreated      = "user.created"
+	EventUserUpdated      = "user.updated"
+	EventUserDeleted      = "user.deleted"
+)
+
+// EventLease is the time claimed events are left to the worker publishing
+// them, an event it doesn't save in time is published again.
+const EventLease = 10 * time.Minute
+
+const (
+	firstRetryDelay = time.Second
+	maxRetryDelay   = time.Hour
+)
+
+// eventTypes lists the events written to the outbox.
+var eventTypes = []string{
+	EventPetCreated, EventPetUpdated, EventPetStatusChanged, EventPetDeleted,
+	EventOrderCreated, EventOrderApproved, EventOrderDelivered, EventOrderCancelled, EventOrderDeleted,
+	EventUserCreated, EventUserUpdated, EventUserDeleted,
+}
+
+// inventoryEvents lists the events that may change the pets count by status.
+var inventoryEvents = []string{
+	EventPetCreated, EventPetStatusChanged, EventPetDeleted,
+	EventOrderCreated, EventOrderApproved, EventOrderDelivered, EventOrderCancelled, EventOrderDeleted,
+}
+
+// orderStatusEvents names the events of the order workflow statuses.
+var orderStatusEvents = map[string]string{
+	OrderStatusPlaced:    EventOrderCreated,
+	OrderStatusApproved:  EventOrderApproved,
+	OrderStatusDelivered: EventOrderDelivered,
+	OrderStatusCancelled: EventOrderCancelled,
+}
+
+// easyjson:json
+type EventList []*Event
+
+// Event is a change of a pet, an order or a user written to the outbox
+// with the change. Key is the id of the pet or the order and the user name
+// of the user. Events are delivered at least once, so consumers should
+// skip the ids they have seen. Inventory is the inventory change made by
+// the event, the event bus sets it on the inventory events it publishes.
+// easyjson:json
+type Event struct {
+	ID            int64            `json:"id" db:"id"`
+	Aggregate     string           `json:"aggregate" db:"aggregate"`
+	Key           string           `json:"key" db:"aggregate_key"`
+	Type          string           `json:"type" db:"event"`
+	Payload       json.RawMessage  `json:"payload" db:"payload"`
+	CreatedAt     time.Time        `json:"created_at" db:"created_at"`
+	Attempts      int              `json:"-" db:"attempts"`
+	NextAttemptAt time.Time        `json:"-" db:"next_attempt_at"`
+	LastError     string           `json:"-" db:"last_error"`
+	Inventory     *InventoryChange `json:"-" db:"-"`
+}
+
+// NewEvent marshals the payload of the event.
+func NewEvent(aggregate, key, eventType string, payload easyjson.Marshaler) (*Event, error) {
+	data, err := easyjson.Marshal(payload)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't marshal %s event payload", eventType)
+	}
+
+	return &Event{
+		Aggregate: aggregate,
+		Key:       key,
+		Type:      eventType,
+		Payload:   data,
+	}, nil
+}
+
+// OrderStatusEvent names the event of the order moving to the status.
+func OrderStatusEvent(status string) string {
+	return orderStatusEvents[status]
+}
+
+// Fail counts the failed delivery and schedules the next one.
+func (e *Event) Fail(err error, now time.Time) {
+	e.Attempts++
+	e.LastError = err.Error()
+	e.NextAttemptAt = now.Add(retryDelay(e.Attempts))
+}
+
+// IsEventType tells whether events of the type are written to the outbox.
+func IsEventType(eventType string) bool {
+	for _, t := range eventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsInventoryEvent tells whether events of the type may change the inventory.
+func IsInventoryEvent(eventType string) bool {
+	for _, t := range inventoryEvents {
+		if t == eventType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// retryDelay is the wait after the failed attempt,
+// the delay doubles with every attempt up to an hour.
+func retryDelay(attempts int) time.Duration {
+	delay := firstRetryDelay
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+
+	return delay
+}
+
+// UserPayload is the user in events, the password is left out.
+// easyjson:json
+type UserPayload struct {
+	ID         int64  `json:"id,omitempty"`
+	Username   string `json:"user_name"`
+	Email      string `json:"email,omitempty"`
+	FirstName  string `json:"first_name,omitempty"`
+	LastName   string `json:"last_name,omitempty"`
+	Phone      string `json:"phone,omitempty"`
+	UserStatus int64  `json:"user_status_id,omitempty"`
+}
+
+func NewUserPayload(user *User) *UserPayload {
+	return &UserPayload{
+		ID:         user.ID,
+		Username:   user.Username,
+		Email:      user.Email,
+		FirstName:  user.FirstName,
+		LastName:   user.LastName,
+		Phone:      user.Phone,
+		UserStatus: user.UserStatus,
+	}
+}
+
+// PetPayload is the pet in events of changes that don't carry
+// the whole pet, only the changed fields are set.
+// easyjson:json
+type PetPayload struct {
+	ID        int64    `json:"id"`
+	Name      string   `json:"name,omitempty"`
+	Status    string   `json:"status,omitempty"`
+	PhotoURLs []string `json:"photo_urls,omitempty"`
+}
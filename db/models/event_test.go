@@ -0,0 +1,55 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestEvent_Fail(t *testing.T) {
+	now := time.Date(2019, 9, 5, 15, 35, 12, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		attempts  int
+		wantDelay time.Duration
+	}{
+		{name: "first failure", attempts: 0, wantDelay: time.Second},
+		{name: "second failure", attempts: 1, wantDelay: 2 * time.Second},
+		{name: "fifth failure", attempts: 4, wantDelay: 16 * time.Second},
+		{name: "capped", attempts: 12, wantDelay: time.Hour},
+		{name: "capped after many", attempts: 100, wantDelay: time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &Event{Attempts: tt.attempts}
+			e.Fail(errors.New("endpoint is down"), now)
+
+			if e.Attempts != tt.attempts+1 || e.LastError != "endpoint is down" {
+				t.Errorf("Fail() attempts = %d, last error = %s", e.Attempts, e.LastError)
+			}
+
+			if got := e.NextAttemptAt.Sub(now); got != tt.wantDelay {
+				t.Errorf("Fail() delay = %v, want %v", got, tt.wantDelay)
+			}
+		})
+	}
+}
+
+func TestNewEvent(t *testing.T) {
+	user := &User{ID: 1, Username: "admin", Password: "secret", Email: "itu@gmail.com"}
+
+	event, err := NewEvent(AggregateUser, user.Username, EventUserCreated, NewUserPayload(user))
+	if err != nil {
+		t.Fatalf("NewEvent() error = %v", err)
+	}
+
+	want := `{"id":1,"user_name":"admin","email":"itu@gmail.com"}`
+	if string(event.Payload) != want {
+		t.Errorf("NewEvent() payload = %s, want %s", event.Payload, want)
+	}
+
+	if OrderStatusEvent(OrderStatusDelivered) != EventOrderDelivered {
+		t.Errorf("OrderStatusEvent() = %s, want %s", OrderStatusEvent(OrderStatusDelivered), EventOrderDelivered)
+	}
+}
@@ -0,0 +1,15 @@
+package models
+
+// IdempotencyKey keeps the first response to a request sent with
+// an Idempotency-Key header, StatusCode is 0 while the request is running.
+type IdempotencyKey struct {
+	UserID      int64  `db:"user_id"`
+	Key         string `db:"key"`
+	RequestHash string `db:"request_hash"`
+	StatusCode  int    `db:"status_code"`
+	Body        []byte `db:"body"`
+}
+
+func (k *IdempotencyKey) IsCompleted() bool {
+	return k.StatusCode != 0
+}
@@ -0,0 +1,138 @@
+//go:generate easyjson -all inventory.go
+
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	InventoryGroupCategory = "category"
+	InventoryGroupStatus   = "status"
+	InventoryGroupTag      = "tag"
+)
+
+// inventoryGroups lists the breakdowns in the order of the inventory columns.
+var inventoryGroups = []string{InventoryGroupCategory, InventoryGroupStatus, InventoryGroupTag}
+
+// InventoryFilter breaks the inventory down by pet category, status and tag,
+// the ship dates of orders bound the ordered quantity inclusively.
+type InventoryFilter struct {
+	GroupBy  []string
+	ShipFrom time.Time
+	ShipTo   time.Time
+}
+
+// ParseInventoryGroups splits the comma separated group_by value,
+// an empty value groups by pet status like the flat inventory.
+func ParseInventoryGroups(value string) []string {
+	if value == "" {
+		return []string{InventoryGroupStatus}
+	}
+
+	return strings.Split(value, ",")
+}
+
+// Validate checks the groups and puts them in the order of the inventory columns.
+func (f *InventoryFilter) Validate() error {
+	isGrouped := make(map[string]bool, len(f.GroupBy))
+	for _, group := range f.GroupBy {
+		if !isInventoryGroup(group) {
+			return errors.Errorf("unknown inventory group %s", group)
+		}
+		isGrouped[group] = true
+	}
+
+	if len(isGrouped) == 0 {
+		return errors.New("inventory must be grouped")
+	}
+
+	f.GroupBy = f.GroupBy[:0]
+	for _, group := range inventoryGroups {
+		if isGrouped[group] {
+			f.GroupBy = append(f.GroupBy, group)
+		}
+	}
+
+	if !f.ShipFrom.IsZero() && !f.ShipTo.IsZero() && f.ShipTo.Before(f.ShipFrom) {
+		return errors.New("ship date range is empty")
+	}
+
+	return nil
+}
+
+// IsGroupedBy tells whether the inventory is broken down by the group.
+func (f *InventoryFilter) IsGroupedBy(group string) bool {
+	for _, g := range f.GroupBy {
+		if g == group {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isInventoryGroup(group string) bool {
+	for _, g := range inventoryGroups {
+		if g == group {
+			return true
+		}
+	}
+
+	return false
+}
+
+// easyjson:json
+type Inventory []*InventoryItem
+
+// InventoryItem is one line of the inventory breakdown, the fields the
+// inventory isn't grouped by are empty. Ordered counts the pets in orders
+// that aren't cancelled, OnHand counts the pets in stock. A pet with several
+// tags is counted under each of them and a pet without tags has an empty tag.
+// easyjson:json
+type InventoryItem struct {
+	Category string `json:"category,omitempty" db:"category"`
+	Status   string `json:"status,omitempty" db:"status"`
+	Tag      string `json:"tag,omitempty" db:"tag"`
+	Ordered  int64  `json:"ordered" db:"ordered"`
+	OnHand   int64  `json:"on_hand" db:"on_hand"`
+}
+
+// InventoryChange is the change of the pets count by status made by
+// the event, Inventory is the count after it and Delta lists only
+// the statuses that changed.
+// easyjson:json
+type InventoryChange struct {
+	EventID   int64            `json:"event_id"`
+	Inventory map[string]int64 `json:"inventory"`
+	Delta     map[string]int64 `json:"delta"`
+}
+
+// InventoryDelta compares the pets count by status before and after
+// a change, it returns nil when nothing changed.
+func InventoryDelta(before, after map[string]int64) map[string]int64 {
+	var delta map[string]int64
+	add := func(status string, diff int64) {
+		if diff == 0 {
+			return
+		}
+		if delta == nil {
+			delta = make(map[string]int64)
+		}
+		delta[status] = diff
+	}
+
+	for status, count := range after {
+		add(status, count-before[status])
+	}
+	for status, count := range before {
+		if _, ok := after[status]; !ok {
+			add(status, -count)
+		}
+	}
+
+	return delta
+}
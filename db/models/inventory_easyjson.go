@@ -0,0 +1,471 @@
+// Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
+
+package models
+
+import (
+	json "encoding/json"
+	easyjson "github.com/mailru/easyjson"
+	jlexer "github.com/mailru/easyjson/jlexer"
+	jwriter "github.com/mailru/easyjson/jwriter"
+)
+
+// suppress unused package warning
+var (
+	_ *json.RawMessage
+	_ *jlexer.Lexer
+	_ *jwriter.Writer
+	_ easyjson.Marshaler
+)
+
+func easyjson6f8bf452DecodeGithubComIamStubborNPetstoreDbModels(in *jlexer.Lexer, out *InventoryItem) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "category":
+			out.Category = string(in.String())
+		case "status":
+			out.Status = string(in.String())
+		case "tag":
+			out.Tag = string(in.String())
+		case "ordered":
+			out.Ordered = int64(in.Int64())
+		case "on_hand":
+			out.OnHand = int64(in.Int64())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson6f8bf452EncodeGithubComIamStubborNPetstoreDbModels(out *jwriter.Writer, in InventoryItem) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	if in.Category != "" {
+		const prefix string = ",\"category\":"
+		first = false
+		out.RawString(prefix[1:])
+		out.String(string(in.Category))
+	}
+	if in.Status != "" {
+		const prefix string = ",\"status\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Status))
+	}
+	if in.Tag != "" {
+		const prefix string = ",\"tag\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Tag))
+	}
+	{
+		const prefix string = ",\"ordered\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int64(int64(in.Ordered))
+	}
+	{
+		const prefix string = ",\"on_hand\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.OnHand))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v InventoryItem) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson6f8bf452EncodeGithubComIamStubborNPetstoreDbModels(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v InventoryItem) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson6f8bf452EncodeGithubComIamStubborNPetstoreDbModels(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *InventoryItem) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson6f8bf452DecodeGithubComIamStubborNPetstoreDbModels(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *InventoryItem) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson6f8bf452DecodeGithubComIamStubborNPetstoreDbModels(l, v)
+}
+func easyjson6f8bf452DecodeGithubComIamStubborNPetstoreDbModels1(in *jlexer.Lexer, out *InventoryFilter) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "GroupBy":
+			if in.IsNull() {
+				in.Skip()
+				out.GroupBy = nil
+			} else {
+				in.Delim('[')
+				if out.GroupBy == nil {
+					if !in.IsDelim(']') {
+						out.GroupBy = make([]string, 0, 4)
+					} else {
+						out.GroupBy = []string{}
+					}
+				} else {
+					out.GroupBy = (out.GroupBy)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v1 string
+					v1 = string(in.String())
+					out.GroupBy = append(out.GroupBy, v1)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "ShipFrom":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.ShipFrom).UnmarshalJSON(data))
+			}
+		case "ShipTo":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.ShipTo).UnmarshalJSON(data))
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson6f8bf452EncodeGithubComIamStubborNPetstoreDbModels1(out *jwriter.Writer, in InventoryFilter) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"GroupBy\":"
+		out.RawString(prefix[1:])
+		if in.GroupBy == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v2, v3 := range in.GroupBy {
+				if v2 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v3))
+			}
+			out.RawByte(']')
+		}
+	}
+	{
+		const prefix string = ",\"ShipFrom\":"
+		out.RawString(prefix)
+		out.Raw((in.ShipFrom).MarshalJSON())
+	}
+	{
+		const prefix string = ",\"ShipTo\":"
+		out.RawString(prefix)
+		out.Raw((in.ShipTo).MarshalJSON())
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v InventoryFilter) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson6f8bf452EncodeGithubComIamStubborNPetstoreDbModels1(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v InventoryFilter) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson6f8bf452EncodeGithubComIamStubborNPetstoreDbModels1(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *InventoryFilter) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson6f8bf452DecodeGithubComIamStubborNPetstoreDbModels1(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *InventoryFilter) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson6f8bf452DecodeGithubComIamStubborNPetstoreDbModels1(l, v)
+}
+func easyjson6f8bf452DecodeGithubComIamStubborNPetstoreDbModels2(in *jlexer.Lexer, out *InventoryChange) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "event_id":
+			out.EventID = int64(in.Int64())
+		case "inventory":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				if !in.IsDelim('}') {
+					out.Inventory = make(map[string]int64)
+				} else {
+					out.Inventory = nil
+				}
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v4 int64
+					v4 = int64(in.Int64())
+					(out.Inventory)[key] = v4
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
+		case "delta":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				if !in.IsDelim('}') {
+					out.Delta = make(map[string]int64)
+				} else {
+					out.Delta = nil
+				}
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v5 int64
+					v5 = int64(in.Int64())
+					(out.Delta)[key] = v5
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson6f8bf452EncodeGithubComIamStubborNPetstoreDbModels2(out *jwriter.Writer, in InventoryChange) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"event_id\":"
+		out.RawString(prefix[1:])
+		out.Int64(int64(in.EventID))
+	}
+	{
+		const prefix string = ",\"inventory\":"
+		out.RawString(prefix)
+		if in.Inventory == nil && (out.Flags&jwriter.NilMapAsEmpty) == 0 {
+			out.RawString(`null`)
+		} else {
+			out.RawByte('{')
+			v6First := true
+			for v6Name, v6Value := range in.Inventory {
+				if v6First {
+					v6First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v6Name))
+				out.RawByte(':')
+				out.Int64(int64(v6Value))
+			}
+			out.RawByte('}')
+		}
+	}
+	{
+		const prefix string = ",\"delta\":"
+		out.RawString(prefix)
+		if in.Delta == nil && (out.Flags&jwriter.NilMapAsEmpty) == 0 {
+			out.RawString(`null`)
+		} else {
+			out.RawByte('{')
+			v7First := true
+			for v7Name, v7Value := range in.Delta {
+				if v7First {
+					v7First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v7Name))
+				out.RawByte(':')
+				out.Int64(int64(v7Value))
+			}
+			out.RawByte('}')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v InventoryChange) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson6f8bf452EncodeGithubComIamStubborNPetstoreDbModels2(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v InventoryChange) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson6f8bf452EncodeGithubComIamStubborNPetstoreDbModels2(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *InventoryChange) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson6f8bf452DecodeGithubComIamStubborNPetstoreDbModels2(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *InventoryChange) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson6f8bf452DecodeGithubComIamStubborNPetstoreDbModels2(l, v)
+}
+func easyjson6f8bf452DecodeGithubComIamStubborNPetstoreDbModels3(in *jlexer.Lexer, out *Inventory) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+		*out = nil
+	} else {
+		in.Delim('[')
+		if *out == nil {
+			if !in.IsDelim(']') {
+				*out = make(Inventory, 0, 8)
+			} else {
+				*out = Inventory{}
+			}
+		} else {
+			*out = (*out)[:0]
+		}
+		for !in.IsDelim(']') {
+			var v8 *InventoryItem
+			if in.IsNull() {
+				in.Skip()
+				v8 = nil
+			} else {
+				if v8 == nil {
+					v8 = new(InventoryItem)
+				}
+				(*v8).UnmarshalEasyJSON(in)
+			}
+			*out = append(*out, v8)
+			in.WantComma()
+		}
+		in.Delim(']')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson6f8bf452EncodeGithubComIamStubborNPetstoreDbModels3(out *jwriter.Writer, in Inventory) {
+	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		out.RawString("null")
+	} else {
+		out.RawByte('[')
+		for v9, v10 := range in {
+			if v9 > 0 {
+				out.RawByte(',')
+			}
+			if v10 == nil {
+				out.RawString("null")
+			} else {
+				(*v10).MarshalEasyJSON(out)
+			}
+		}
+		out.RawByte(']')
+	}
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Inventory) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson6f8bf452EncodeGithubComIamStubborNPetstoreDbModels3(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Inventory) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson6f8bf452EncodeGithubComIamStubborNPetstoreDbModels3(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Inventory) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson6f8bf452DecodeGithubComIamStubborNPetstoreDbModels3(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Inventory) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson6f8bf452DecodeGithubComIamStubborNPetstoreDbModels3(l, v)
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestInventoryFilter_Validate(t *testing.T) {
+	day := time.Date(2019, 9, 6, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		groupBy     string
+		from        time.Time
+		to          time.Time
+		wantGroupBy []string
+		wantErr     bool
+	}{
+		{name: "default", groupBy: "", wantGroupBy: []string{InventoryGroupStatus}},
+		{name: "column order", groupBy: "tag,status,category",
+			wantGroupBy: []string{InventoryGroupCategory, InventoryGroupStatus, InventoryGroupTag}},
+		{name: "repeated group", groupBy: "tag,tag", wantGroupBy: []string{InventoryGroupTag}},
+		{name: "single day", groupBy: "category", from: day, to: day,
+			wantGroupBy: []string{InventoryGroupCategory}},
+		{name: "unknown group", groupBy: "color", wantErr: true},
+		{name: "empty group", groupBy: "status,", wantErr: true},
+		{name: "empty range", groupBy: "status", from: day, to: day.AddDate(0, 0, -1), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := InventoryFilter{GroupBy: ParseInventoryGroups(tt.groupBy), ShipFrom: tt.from, ShipTo: tt.to}
+
+			err := f.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if err == nil && !reflect.DeepEqual(f.GroupBy, tt.wantGroupBy) {
+				t.Errorf("Validate() group by = %v, want %v", f.GroupBy, tt.wantGroupBy)
+			}
+		})
+	}
+}
+
+func TestInventoryDelta(t *testing.T) {
+	tests := []struct {
+		name   string
+		before map[string]int64
+		after  map[string]int64
+		want   map[string]int64
+	}{
+		{name: "unchanged", before: map[string]int64{"available": 3}, after: map[string]int64{"available": 3}},
+		{name: "reserved", before: map[string]int64{"available": 3, "pending": 1},
+			after: map[string]int64{"available": 2, "pending": 2},
+			want:  map[string]int64{"available": -1, "pending": 1}},
+		{name: "new status", before: map[string]int64{"available": 3},
+			after: map[string]int64{"available": 3, "sold": 1}, want: map[string]int64{"sold": 1}},
+		{name: "status gone", before: map[string]int64{"available": 3, "sold": 1},
+			after: map[string]int64{"available": 3}, want: map[string]int64{"sold": -1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := InventoryDelta(tt.before, tt.after); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("InventoryDelta() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+//go:generate easyjson -all invoice.go
+
+package models
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	invoiceDateLayout     = "2006-01-02"
+	invoiceShipDateLayout = "2006-01-02 15:04:05"
+)
+
+// Invoice lists the items of the orders shipped from From to To and the
+// refunds made then, the totals are rounded to cents. Total is the price
+// with the discounts and the refunds taken off. PeriodFrom and PeriodTo
+// are the bounds of the period, PeriodTo isn't included, From and To are
+// the first and the last day of it.
+// easyjson:json
+type Invoice struct {
+	From          string         `json:"from"`
+	To            string         `json:"to"`
+	PeriodFrom    time.Time      `json:"-"`
+	PeriodTo      time.Time      `json:"-"`
+	Items         []*InvoiceItem `json:"items"`
+	TotalPrice    float64        `json:"total_price"`
+	TotalDiscount float64        `json:"total_discount"`
+	TotalRefund   float64        `json:"total_refund"`
+	Total         float64        `json:"total"`
+}
+
+// NewInvoice sums the invoice items, the RFC3339 ship dates of the items
+// are reformatted for reading.
+func NewInvoice(from, to time.Time, items []*InvoiceItem) (*Invoice, error) {
+	invoice := &Invoice{
+		From:       from.UTC().Format(invoiceDateLayout),
+		To:         to.UTC().Add(-time.Nanosecond).Format(invoiceDateLayout),
+		PeriodFrom: from.UTC(),
+		PeriodTo:   to.UTC(),
+		Items:      items,
+	}
+
+	for _, item := range items {
+		shipDate, err := time.Parse(time.RFC3339, item.ShipDate)
+		if err != nil {
+			return nil, errors.Wrap(err, "can't parse time")
+		}
+		item.ShipDate = shipDate.Format(invoiceShipDateLayout)
+
+		invoice.TotalDiscount += item.Discount
+		if item.Refund {
+			// the price of refund lines is the refunded amount with the minus sign
+			invoice.TotalRefund -= item.Price
+			continue
+		}
+		invoice.TotalPrice += float64(item.Quantity) * item.Price
+	}
+
+	invoice.TotalPrice = RoundPrice(invoice.TotalPrice)
+	invoice.TotalDiscount = RoundPrice(invoice.TotalDiscount)
+	invoice.TotalRefund = RoundPrice(invoice.TotalRefund)
+	invoice.Total = RoundPrice(invoice.TotalPrice - invoice.TotalDiscount - invoice.TotalRefund)
+
+	return invoice, nil
+}
+
+// InvoiceRequest asks for the invoice of the orders shipped from From
+// to To, dates or RFC3339 times. A date To includes its day, an RFC3339
+// time To isn't included. The file is sent back in the format,
+// or stored with the generated invoices when Store is set.
+// easyjson:json
+type InvoiceRequest struct {
+	From   string `json:"from" validate:"nonzero"`
+	To     string `json:"to" validate:"nonzero"`
+	Format string `json:"format"`
+	Store  bool   `json:"store"`
+}
+
+// easyjson:json
+type InvoiceFileList []*InvoiceFile
+
+// InvoiceFile is a generated invoice file in the registry, every file
+// gets the next invoice number. From and To are the bounds of its period,
+// To isn't included. ObjectKey is the file in the invoices bucket and
+// Checksum is the SHA-256 of its content in hex.
+// easyjson:json
+type InvoiceFile struct {
+	Number        int64     `json:"number" db:"number"`
+	From          time.Time `json:"from" db:"period_from"`
+	To            time.Time `json:"to" db:"period_to"`
+	Format        string    `json:"format" db:"format"`
+	ObjectKey     string    `json:"object_key" db:"object_key"`
+	Checksum      string    `json:"checksum" db:"checksum"`
+	Size          int64     `json:"size" db:"size"`
+	TotalPrice    float64   `json:"total_price" db:"total_price"`
+	TotalDiscount float64   `json:"total_discount" db:"total_discount"`
+	TotalRefund   float64   `json:"total_refund" db:"total_refund"`
+	Total         float64   `json:"total" db:"total"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,485 @@
+// Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
+
+package models
+
+import (
+	json "encoding/json"
+	easyjson "github.com/mailru/easyjson"
+	jlexer "github.com/mailru/easyjson/jlexer"
+	jwriter "github.com/mailru/easyjson/jwriter"
+)
+
+// suppress unused package warning
+var (
+	_ *json.RawMessage
+	_ *jlexer.Lexer
+	_ *jwriter.Writer
+	_ easyjson.Marshaler
+)
+
+func easyjsonBf33241fDecodeGithubComIamStubborNPetstoreDbModels(in *jlexer.Lexer, out *InvoiceRequest) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "from":
+			out.From = string(in.String())
+		case "to":
+			out.To = string(in.String())
+		case "format":
+			out.Format = string(in.String())
+		case "store":
+			out.Store = bool(in.Bool())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonBf33241fEncodeGithubComIamStubborNPetstoreDbModels(out *jwriter.Writer, in InvoiceRequest) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"from\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.From))
+	}
+	{
+		const prefix string = ",\"to\":"
+		out.RawString(prefix)
+		out.String(string(in.To))
+	}
+	{
+		const prefix string = ",\"format\":"
+		out.RawString(prefix)
+		out.String(string(in.Format))
+	}
+	{
+		const prefix string = ",\"store\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.Store))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v InvoiceRequest) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonBf33241fEncodeGithubComIamStubborNPetstoreDbModels(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v InvoiceRequest) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonBf33241fEncodeGithubComIamStubborNPetstoreDbModels(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *InvoiceRequest) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonBf33241fDecodeGithubComIamStubborNPetstoreDbModels(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *InvoiceRequest) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonBf33241fDecodeGithubComIamStubborNPetstoreDbModels(l, v)
+}
+func easyjsonBf33241fDecodeGithubComIamStubborNPetstoreDbModels1(in *jlexer.Lexer, out *InvoiceFileList) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+		*out = nil
+	} else {
+		in.Delim('[')
+		if *out == nil {
+			if !in.IsDelim(']') {
+				*out = make(InvoiceFileList, 0, 8)
+			} else {
+				*out = InvoiceFileList{}
+			}
+		} else {
+			*out = (*out)[:0]
+		}
+		for !in.IsDelim(']') {
+			var v1 *InvoiceFile
+			if in.IsNull() {
+				in.Skip()
+				v1 = nil
+			} else {
+				if v1 == nil {
+					v1 = new(InvoiceFile)
+				}
+				(*v1).UnmarshalEasyJSON(in)
+			}
+			*out = append(*out, v1)
+			in.WantComma()
+		}
+		in.Delim(']')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonBf33241fEncodeGithubComIamStubborNPetstoreDbModels1(out *jwriter.Writer, in InvoiceFileList) {
+	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		out.RawString("null")
+	} else {
+		out.RawByte('[')
+		for v2, v3 := range in {
+			if v2 > 0 {
+				out.RawByte(',')
+			}
+			if v3 == nil {
+				out.RawString("null")
+			} else {
+				(*v3).MarshalEasyJSON(out)
+			}
+		}
+		out.RawByte(']')
+	}
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v InvoiceFileList) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonBf33241fEncodeGithubComIamStubborNPetstoreDbModels1(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v InvoiceFileList) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonBf33241fEncodeGithubComIamStubborNPetstoreDbModels1(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *InvoiceFileList) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonBf33241fDecodeGithubComIamStubborNPetstoreDbModels1(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *InvoiceFileList) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonBf33241fDecodeGithubComIamStubborNPetstoreDbModels1(l, v)
+}
+func easyjsonBf33241fDecodeGithubComIamStubborNPetstoreDbModels2(in *jlexer.Lexer, out *InvoiceFile) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "number":
+			out.Number = int64(in.Int64())
+		case "from":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.From).UnmarshalJSON(data))
+			}
+		case "to":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.To).UnmarshalJSON(data))
+			}
+		case "format":
+			out.Format = string(in.String())
+		case "object_key":
+			out.ObjectKey = string(in.String())
+		case "checksum":
+			out.Checksum = string(in.String())
+		case "size":
+			out.Size = int64(in.Int64())
+		case "total_price":
+			out.TotalPrice = float64(in.Float64())
+		case "total_discount":
+			out.TotalDiscount = float64(in.Float64())
+		case "total_refund":
+			out.TotalRefund = float64(in.Float64())
+		case "total":
+			out.Total = float64(in.Float64())
+		case "created_at":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.CreatedAt).UnmarshalJSON(data))
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonBf33241fEncodeGithubComIamStubborNPetstoreDbModels2(out *jwriter.Writer, in InvoiceFile) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"number\":"
+		out.RawString(prefix[1:])
+		out.Int64(int64(in.Number))
+	}
+	{
+		const prefix string = ",\"from\":"
+		out.RawString(prefix)
+		out.Raw((in.From).MarshalJSON())
+	}
+	{
+		const prefix string = ",\"to\":"
+		out.RawString(prefix)
+		out.Raw((in.To).MarshalJSON())
+	}
+	{
+		const prefix string = ",\"format\":"
+		out.RawString(prefix)
+		out.String(string(in.Format))
+	}
+	{
+		const prefix string = ",\"object_key\":"
+		out.RawString(prefix)
+		out.String(string(in.ObjectKey))
+	}
+	{
+		const prefix string = ",\"checksum\":"
+		out.RawString(prefix)
+		out.String(string(in.Checksum))
+	}
+	{
+		const prefix string = ",\"size\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.Size))
+	}
+	{
+		const prefix string = ",\"total_price\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.TotalPrice))
+	}
+	{
+		const prefix string = ",\"total_discount\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.TotalDiscount))
+	}
+	{
+		const prefix string = ",\"total_refund\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.TotalRefund))
+	}
+	{
+		const prefix string = ",\"total\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.Total))
+	}
+	{
+		const prefix string = ",\"created_at\":"
+		out.RawString(prefix)
+		out.Raw((in.CreatedAt).MarshalJSON())
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v InvoiceFile) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonBf33241fEncodeGithubComIamStubborNPetstoreDbModels2(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v InvoiceFile) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonBf33241fEncodeGithubComIamStubborNPetstoreDbModels2(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *InvoiceFile) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonBf33241fDecodeGithubComIamStubborNPetstoreDbModels2(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *InvoiceFile) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonBf33241fDecodeGithubComIamStubborNPetstoreDbModels2(l, v)
+}
+func easyjsonBf33241fDecodeGithubComIamStubborNPetstoreDbModels3(in *jlexer.Lexer, out *Invoice) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "from":
+			out.From = string(in.String())
+		case "to":
+			out.To = string(in.String())
+		case "items":
+			if in.IsNull() {
+				in.Skip()
+				out.Items = nil
+			} else {
+				in.Delim('[')
+				if out.Items == nil {
+					if !in.IsDelim(']') {
+						out.Items = make([]*InvoiceItem, 0, 8)
+					} else {
+						out.Items = []*InvoiceItem{}
+					}
+				} else {
+					out.Items = (out.Items)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v4 *InvoiceItem
+					if in.IsNull() {
+						in.Skip()
+						v4 = nil
+					} else {
+						if v4 == nil {
+							v4 = new(InvoiceItem)
+						}
+						(*v4).UnmarshalEasyJSON(in)
+					}
+					out.Items = append(out.Items, v4)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "total_price":
+			out.TotalPrice = float64(in.Float64())
+		case "total_discount":
+			out.TotalDiscount = float64(in.Float64())
+		case "total_refund":
+			out.TotalRefund = float64(in.Float64())
+		case "total":
+			out.Total = float64(in.Float64())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonBf33241fEncodeGithubComIamStubborNPetstoreDbModels3(out *jwriter.Writer, in Invoice) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"from\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.From))
+	}
+	{
+		const prefix string = ",\"to\":"
+		out.RawString(prefix)
+		out.String(string(in.To))
+	}
+	{
+		const prefix string = ",\"items\":"
+		out.RawString(prefix)
+		if in.Items == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v5, v6 := range in.Items {
+				if v5 > 0 {
+					out.RawByte(',')
+				}
+				if v6 == nil {
+					out.RawString("null")
+				} else {
+					(*v6).MarshalEasyJSON(out)
+				}
+			}
+			out.RawByte(']')
+		}
+	}
+	{
+		const prefix string = ",\"total_price\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.TotalPrice))
+	}
+	{
+		const prefix string = ",\"total_discount\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.TotalDiscount))
+	}
+	{
+		const prefix string = ",\"total_refund\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.TotalRefund))
+	}
+	{
+		const prefix string = ",\"total\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.Total))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Invoice) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonBf33241fEncodeGithubComIamStubborNPetstoreDbModels3(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Invoice) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonBf33241fEncodeGithubComIamStubborNPetstoreDbModels3(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Invoice) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonBf33241fDecodeGithubComIamStubborNPetstoreDbModels3(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Invoice) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonBf33241fDecodeGithubComIamStubborNPetstoreDbModels3(l, v)
+}
@@ -2,13 +2,18 @@
 
 package models
 
+// InvoiceItem is a line of an ordered pet, or of a refund
+// with a negative price dated by the time it's made.
 // easyjson:json
 type InvoiceItem struct {
 	ID       int64   `json:"id," db:"id"`
+	Line     int64   `json:"line" db:"line"`
 	User     string  `json:"user_name" db:"user_name"`
 	Pet      string  `json:"pet" db:"pet"`
 	Category string  `json:"category" db:"category"`
 	ShipDate string  `json:"ship_date" db:"ship_date"`
 	Quantity int32   `json:"quantity" db:"quantity"`
 	Price    float64 `json:"price" db:"price"`
+	Discount float64 `json:"discount" db:"discount"`
+	Refund   bool    `json:"refund,omitempty" db:"refund"`
 }
@@ -38,6 +38,8 @@ func easyjsonD1ea2e5DecodeGithubComIamStubborNPetstoreDbModels(in *jlexer.Lexer,
 		switch key {
 		case "id":
 			out.ID = int64(in.Int64())
+		case "line":
+			out.Line = int64(in.Int64())
 		case "user_name":
 			out.User = string(in.String())
 		case "pet":
@@ -50,6 +52,10 @@ func easyjsonD1ea2e5DecodeGithubComIamStubborNPetstoreDbModels(in *jlexer.Lexer,
 			out.Quantity = int32(in.Int32())
 		case "price":
 			out.Price = float64(in.Float64())
+		case "discount":
+			out.Discount = float64(in.Float64())
+		case "refund":
+			out.Refund = bool(in.Bool())
 		default:
 			in.SkipRecursive()
 		}
@@ -69,6 +75,11 @@ func easyjsonD1ea2e5EncodeGithubComIamStubborNPetstoreDbModels(out *jwriter.Writ
 		out.RawString(prefix[1:])
 		out.Int64(int64(in.ID))
 	}
+	{
+		const prefix string = ",\"line\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.Line))
+	}
 	{
 		const prefix string = ",\"user_name\":"
 		out.RawString(prefix)
@@ -99,6 +110,16 @@ func easyjsonD1ea2e5EncodeGithubComIamStubborNPetstoreDbModels(out *jwriter.Writ
 		out.RawString(prefix)
 		out.Float64(float64(in.Price))
 	}
+	{
+		const prefix string = ",\"discount\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.Discount))
+	}
+	if in.Refund {
+		const prefix string = ",\"refund\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.Refund))
+	}
 	out.RawByte('}')
 }
 
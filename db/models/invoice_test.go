@@ -0,0 +1,52 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewInvoice(t *testing.T) {
+	from := time.Date(2019, 9, 5, 12, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+
+	tests := []struct {
+		name         string
+		items        []*InvoiceItem
+		wantTotal    float64
+		wantRefund   float64
+		wantShipDate string
+		wantErr      bool
+	}{
+		{name: "empty", items: nil},
+		{name: "items and refund", items: []*InvoiceItem{
+			{ID: 7, Line: 1, ShipDate: "2019-09-05T15:30:00Z", Quantity: 3, Price: 10.1, Discount: 0.3},
+			{ID: 7, Line: 2, ShipDate: "2019-09-06T09:00:00Z", Quantity: 1, Price: -5, Refund: true},
+		}, wantTotal: 25, wantRefund: 5, wantShipDate: "2019-09-05 15:30:00"},
+		{name: "bad ship date", items: []*InvoiceItem{{ID: 7, Line: 1, ShipDate: "2019-09-05"}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			invoice, err := NewInvoice(from, to, tt.items)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewInvoice() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+
+			if invoice.From != "2019-09-05" || invoice.To != "2019-09-06" {
+				t.Errorf("NewInvoice() period = %s - %s", invoice.From, invoice.To)
+			}
+
+			if invoice.Total != tt.wantTotal || invoice.TotalRefund != tt.wantRefund {
+				t.Errorf("NewInvoice() total = %v, refund = %v, want %v, %v",
+					invoice.Total, invoice.TotalRefund, tt.wantTotal, tt.wantRefund)
+			}
+
+			if len(tt.items) > 0 && invoice.Items[0].ShipDate != tt.wantShipDate {
+				t.Errorf("NewInvoice() ship date = %s, want %s", invoice.Items[0].ShipDate, tt.wantShipDate)
+			}
+		})
+	}
+}
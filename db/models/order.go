@@ -2,13 +2,99 @@
 
 package models
 
+import "github.com/pkg/errors"
+
+// easyjson:json
+type OrderList []*Order
+
+// Order is a purchase of one or several pets, PetID and Quantity
+// describe the order of a single pet and Quantity is the total otherwise.
 // easyjson:json
 type Order struct {
-	ID       int64  `json:"id,omitempty," db:"id" validate:"nonzero"`
-	PetID    int64  `json:"pet_id" db:"pet_id" validate:"nonzero"`
-	UserID   int64  `json:"user_id" db:"user_id" validate:"nonzero"`
-	Quantity int32  `json:"quantity" db:"quantity" validate:"nonzero"`
-	ShipDate string `json:"ship_date" db:"ship_date"`
-	Status   string `json:"status" db:"order_status" validate:"nonzero"`
-	Complete bool   `json:"complete" db:"complete"`
+	ID       int64         `json:"id,omitempty," db:"id" validate:"nonzero"`
+	PetID    int64         `json:"pet_id,omitempty" db:"pet_id"`
+	UserID   int64         `json:"user_id" db:"user_id" validate:"nonzero"`
+	Quantity int32         `json:"quantity" db:"quantity"`
+	Items    OrderItemList `json:"items" db:"-" validate:"nonzero"`
+	Coupons  []string      `json:"coupons,omitempty" db:"-"`
+	Gross    float64       `json:"gross" db:"gross"`
+	Discount float64       `json:"discount" db:"discount"`
+	ShipDate string        `json:"ship_date" db:"ship_date"`
+	Status   string        `json:"status" db:"order_status" validate:"nonzero"`
+	Complete bool          `json:"complete" db:"complete"`
+}
+
+// easyjson:json
+type OrderItemList []*OrderItem
+
+// easyjson:json
+type OrderItem struct {
+	ID         int64   `json:"id,omitempty" db:"id"`
+	OrderID    int64   `json:"-" db:"order_id"`
+	PetID      int64   `json:"pet_id" db:"pet_id" validate:"nonzero"`
+	PetName    string  `json:"pet_name,omitempty" db:"pet_name"`
+	CategoryID int64   `json:"-" db:"category_id"`
+	Quantity   int32   `json:"quantity" db:"quantity" validate:"min=1"`
+	Price      float64 `json:"price,omitempty" db:"price"`
+	Discount   float64 `json:"discount,omitempty" db:"discount"`
+}
+
+// NormalizeItems turns the single pet order into its only item,
+// merges items of the same pet and repeated coupon codes
+// and sums up the order quantity.
+func (o *Order) NormalizeItems() error {
+	if len(o.Items) == 0 && o.PetID != 0 {
+		o.Items = OrderItemList{{PetID: o.PetID, Quantity: o.Quantity}}
+	}
+
+	if len(o.Items) == 0 {
+		return errors.New("order has no items")
+	}
+
+	items := make(OrderItemList, 0, len(o.Items))
+	byPet := make(map[int64]*OrderItem, len(o.Items))
+	for _, item := range o.Items {
+		if merged, ok := byPet[item.PetID]; ok {
+			merged.Quantity += item.Quantity
+			continue
+		}
+
+		byPet[item.PetID] = item
+		items = append(items, item)
+	}
+
+	o.SetItems(items)
+
+	var codes []string
+	isAdded := make(map[string]bool, len(o.Coupons))
+	for _, code := range o.Coupons {
+		if !isAdded[code] {
+			isAdded[code] = true
+			codes = append(codes, code)
+		}
+	}
+	o.Coupons = codes
+
+	return nil
+}
+
+// SetItems attaches the items to the order and fills the
+// single pet fields from them.
+func (o *Order) SetItems(items OrderItemList) {
+	o.Items = items
+	o.PetID = 0
+	o.Quantity = 0
+
+	for _, item := range items {
+		o.Quantity += item.Quantity
+	}
+
+	if len(items) == 1 {
+		o.PetID = items[0].PetID
+	}
+}
+
+// Total is the price the customer pays for the order.
+func (o *Order) Total() float64 {
+	return RoundPrice(o.Gross - o.Discount)
 }
@@ -17,7 +17,270 @@ var (
 	_ easyjson.Marshaler
 )
 
-func easyjson120d1ca2DecodeGithubComIamStubborNPetstoreDbModels(in *jlexer.Lexer, out *Order) {
+func easyjson120d1ca2DecodeGithubComIamStubborNPetstoreDbModels(in *jlexer.Lexer, out *OrderList) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+		*out = nil
+	} else {
+		in.Delim('[')
+		if *out == nil {
+			if !in.IsDelim(']') {
+				*out = make(OrderList, 0, 8)
+			} else {
+				*out = OrderList{}
+			}
+		} else {
+			*out = (*out)[:0]
+		}
+		for !in.IsDelim(']') {
+			var v1 *Order
+			if in.IsNull() {
+				in.Skip()
+				v1 = nil
+			} else {
+				if v1 == nil {
+					v1 = new(Order)
+				}
+				(*v1).UnmarshalEasyJSON(in)
+			}
+			*out = append(*out, v1)
+			in.WantComma()
+		}
+		in.Delim(']')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson120d1ca2EncodeGithubComIamStubborNPetstoreDbModels(out *jwriter.Writer, in OrderList) {
+	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		out.RawString("null")
+	} else {
+		out.RawByte('[')
+		for v2, v3 := range in {
+			if v2 > 0 {
+				out.RawByte(',')
+			}
+			if v3 == nil {
+				out.RawString("null")
+			} else {
+				(*v3).MarshalEasyJSON(out)
+			}
+		}
+		out.RawByte(']')
+	}
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v OrderList) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson120d1ca2EncodeGithubComIamStubborNPetstoreDbModels(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v OrderList) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson120d1ca2EncodeGithubComIamStubborNPetstoreDbModels(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *OrderList) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson120d1ca2DecodeGithubComIamStubborNPetstoreDbModels(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *OrderList) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson120d1ca2DecodeGithubComIamStubborNPetstoreDbModels(l, v)
+}
+func easyjson120d1ca2DecodeGithubComIamStubborNPetstoreDbModels1(in *jlexer.Lexer, out *OrderItemList) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+		*out = nil
+	} else {
+		in.Delim('[')
+		if *out == nil {
+			if !in.IsDelim(']') {
+				*out = make(OrderItemList, 0, 8)
+			} else {
+				*out = OrderItemList{}
+			}
+		} else {
+			*out = (*out)[:0]
+		}
+		for !in.IsDelim(']') {
+			var v4 *OrderItem
+			if in.IsNull() {
+				in.Skip()
+				v4 = nil
+			} else {
+				if v4 == nil {
+					v4 = new(OrderItem)
+				}
+				(*v4).UnmarshalEasyJSON(in)
+			}
+			*out = append(*out, v4)
+			in.WantComma()
+		}
+		in.Delim(']')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson120d1ca2EncodeGithubComIamStubborNPetstoreDbModels1(out *jwriter.Writer, in OrderItemList) {
+	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		out.RawString("null")
+	} else {
+		out.RawByte('[')
+		for v5, v6 := range in {
+			if v5 > 0 {
+				out.RawByte(',')
+			}
+			if v6 == nil {
+				out.RawString("null")
+			} else {
+				(*v6).MarshalEasyJSON(out)
+			}
+		}
+		out.RawByte(']')
+	}
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v OrderItemList) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson120d1ca2EncodeGithubComIamStubborNPetstoreDbModels1(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v OrderItemList) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson120d1ca2EncodeGithubComIamStubborNPetstoreDbModels1(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *OrderItemList) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson120d1ca2DecodeGithubComIamStubborNPetstoreDbModels1(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *OrderItemList) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson120d1ca2DecodeGithubComIamStubborNPetstoreDbModels1(l, v)
+}
+func easyjson120d1ca2DecodeGithubComIamStubborNPetstoreDbModels2(in *jlexer.Lexer, out *OrderItem) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "id":
+			out.ID = int64(in.Int64())
+		case "pet_id":
+			out.PetID = int64(in.Int64())
+		case "pet_name":
+			out.PetName = string(in.String())
+		case "quantity":
+			out.Quantity = int32(in.Int32())
+		case "price":
+			out.Price = float64(in.Float64())
+		case "discount":
+			out.Discount = float64(in.Float64())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson120d1ca2EncodeGithubComIamStubborNPetstoreDbModels2(out *jwriter.Writer, in OrderItem) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	if in.ID != 0 {
+		const prefix string = ",\"id\":"
+		first = false
+		out.RawString(prefix[1:])
+		out.Int64(int64(in.ID))
+	}
+	{
+		const prefix string = ",\"pet_id\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int64(int64(in.PetID))
+	}
+	if in.PetName != "" {
+		const prefix string = ",\"pet_name\":"
+		out.RawString(prefix)
+		out.String(string(in.PetName))
+	}
+	{
+		const prefix string = ",\"quantity\":"
+		out.RawString(prefix)
+		out.Int32(int32(in.Quantity))
+	}
+	if in.Price != 0 {
+		const prefix string = ",\"price\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.Price))
+	}
+	if in.Discount != 0 {
+		const prefix string = ",\"discount\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.Discount))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v OrderItem) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson120d1ca2EncodeGithubComIamStubborNPetstoreDbModels2(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v OrderItem) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson120d1ca2EncodeGithubComIamStubborNPetstoreDbModels2(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *OrderItem) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson120d1ca2DecodeGithubComIamStubborNPetstoreDbModels2(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *OrderItem) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson120d1ca2DecodeGithubComIamStubborNPetstoreDbModels2(l, v)
+}
+func easyjson120d1ca2DecodeGithubComIamStubborNPetstoreDbModels3(in *jlexer.Lexer, out *Order) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -44,6 +307,35 @@ func easyjson120d1ca2DecodeGithubComIamStubborNPetstoreDbModels(in *jlexer.Lexer
 			out.UserID = int64(in.Int64())
 		case "quantity":
 			out.Quantity = int32(in.Int32())
+		case "items":
+			(out.Items).UnmarshalEasyJSON(in)
+		case "coupons":
+			if in.IsNull() {
+				in.Skip()
+				out.Coupons = nil
+			} else {
+				in.Delim('[')
+				if out.Coupons == nil {
+					if !in.IsDelim(']') {
+						out.Coupons = make([]string, 0, 4)
+					} else {
+						out.Coupons = []string{}
+					}
+				} else {
+					out.Coupons = (out.Coupons)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v7 string
+					v7 = string(in.String())
+					out.Coupons = append(out.Coupons, v7)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "gross":
+			out.Gross = float64(in.Float64())
+		case "discount":
+			out.Discount = float64(in.Float64())
 		case "ship_date":
 			out.ShipDate = string(in.String())
 		case "status":
@@ -60,7 +352,7 @@ func easyjson120d1ca2DecodeGithubComIamStubborNPetstoreDbModels(in *jlexer.Lexer
 		in.Consumed()
 	}
 }
-func easyjson120d1ca2EncodeGithubComIamStubborNPetstoreDbModels(out *jwriter.Writer, in Order) {
+func easyjson120d1ca2EncodeGithubComIamStubborNPetstoreDbModels3(out *jwriter.Writer, in Order) {
 	out.RawByte('{')
 	first := true
 	_ = first
@@ -70,7 +362,7 @@ func easyjson120d1ca2EncodeGithubComIamStubborNPetstoreDbModels(out *jwriter.Wri
 		out.RawString(prefix[1:])
 		out.Int64(int64(in.ID))
 	}
-	{
+	if in.PetID != 0 {
 		const prefix string = ",\"pet_id\":"
 		if first {
 			first = false
@@ -82,7 +374,12 @@ func easyjson120d1ca2EncodeGithubComIamStubborNPetstoreDbModels(out *jwriter.Wri
 	}
 	{
 		const prefix string = ",\"user_id\":"
-		out.RawString(prefix)
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
 		out.Int64(int64(in.UserID))
 	}
 	{
@@ -90,6 +387,35 @@ func easyjson120d1ca2EncodeGithubComIamStubborNPetstoreDbModels(out *jwriter.Wri
 		out.RawString(prefix)
 		out.Int32(int32(in.Quantity))
 	}
+	{
+		const prefix string = ",\"items\":"
+		out.RawString(prefix)
+		(in.Items).MarshalEasyJSON(out)
+	}
+	if len(in.Coupons) != 0 {
+		const prefix string = ",\"coupons\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('[')
+			for v8, v9 := range in.Coupons {
+				if v8 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v9))
+			}
+			out.RawByte(']')
+		}
+	}
+	{
+		const prefix string = ",\"gross\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.Gross))
+	}
+	{
+		const prefix string = ",\"discount\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.Discount))
+	}
 	{
 		const prefix string = ",\"ship_date\":"
 		out.RawString(prefix)
@@ -111,23 +437,23 @@ func easyjson120d1ca2EncodeGithubComIamStubborNPetstoreDbModels(out *jwriter.Wri
 // MarshalJSON supports json.Marshaler interface
 func (v Order) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjson120d1ca2EncodeGithubComIamStubborNPetstoreDbModels(&w, v)
+	easyjson120d1ca2EncodeGithubComIamStubborNPetstoreDbModels3(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
 func (v Order) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjson120d1ca2EncodeGithubComIamStubborNPetstoreDbModels(w, v)
+	easyjson120d1ca2EncodeGithubComIamStubborNPetstoreDbModels3(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
 func (v *Order) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjson120d1ca2DecodeGithubComIamStubborNPetstoreDbModels(&r, v)
+	easyjson120d1ca2DecodeGithubComIamStubborNPetstoreDbModels3(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
 func (v *Order) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjson120d1ca2DecodeGithubComIamStubborNPetstoreDbModels(l, v)
+	easyjson120d1ca2DecodeGithubComIamStubborNPetstoreDbModels3(l, v)
 }
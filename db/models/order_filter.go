@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// OrderFilter describes one page of an orders listing, zero fields
+// don't filter and ship dates bound the range inclusively.
+type OrderFilter struct {
+	UserID   int64
+	PetID    int64
+	Status   string
+	Complete *bool
+	ShipFrom time.Time
+	ShipTo   time.Time
+	Limit    int
+	After    string
+}
+
+func (f *OrderFilter) Validate() error {
+	if _, ok := orderStatusTransitions[f.Status]; f.Status != "" && !ok {
+		return errors.Errorf("unknown order status %s", f.Status)
+	}
+
+	if !f.ShipFrom.IsZero() && !f.ShipTo.IsZero() && f.ShipTo.Before(f.ShipFrom) {
+		return errors.New("ship date range is empty")
+	}
+
+	if f.Limit == 0 {
+		f.Limit = DefaultPageLimit
+	}
+
+	if f.Limit < 0 || f.Limit > MaxPageLimit {
+		return errors.Errorf("limit must be between 1 and %d", MaxPageLimit)
+	}
+
+	if _, err := DecodeCursor(f.After); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// CursorOf returns the cursor pointing to order, orders are listed by id.
+func (f *OrderFilter) CursorOf(order *Order) Cursor {
+	return Cursor{ID: order.ID}
+}
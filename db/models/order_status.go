@@ -0,0 +1,40 @@
+package models
+
+import "github.com/pkg/errors"
+
+const (
+	OrderStatusPlaced    = "placed"
+	OrderStatusApproved  = "approved"
+	OrderStatusDelivered = "delivered"
+	OrderStatusCancelled = "cancelled"
+)
+
+// ActiveOrderStatuses are the statuses of orders holding their pets,
+// a held pet is pending and moves only with its order.
+var ActiveOrderStatuses = []string{OrderStatusPlaced, OrderStatusApproved}
+
+// orderStatusTransitions lists the statuses an order may move to from each status.
+var orderStatusTransitions = map[string][]string{
+	OrderStatusPlaced:    {OrderStatusApproved, OrderStatusCancelled},
+	OrderStatusApproved:  {OrderStatusDelivered, OrderStatusCancelled},
+	OrderStatusDelivered: {},
+	OrderStatusCancelled: {},
+}
+
+// CheckOrderStatusTransition returns an error wrapped around ErrInvalidTransition
+// when an order can't move from one status to another. Unlike pets, an order
+// can't move to the status it already has, so repeated requests are reported.
+func CheckOrderStatusTransition(from, to string) error {
+	allowed, ok := orderStatusTransitions[from]
+	if !ok {
+		return errors.Wrapf(ErrInvalidTransition, "unknown order status %s", from)
+	}
+
+	for _, status := range allowed {
+		if status == to {
+			return nil
+		}
+	}
+
+	return errors.Wrapf(ErrInvalidTransition, "order status can't change from %s to %s", from, to)
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestCheckOrderStatusTransition(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    string
+		to      string
+		wantErr bool
+	}{
+		{name: "approve", from: OrderStatusPlaced, to: OrderStatusApproved},
+		{name: "cancel placed", from: OrderStatusPlaced, to: OrderStatusCancelled},
+		{name: "deliver", from: OrderStatusApproved, to: OrderStatusDelivered},
+		{name: "cancel approved", from: OrderStatusApproved, to: OrderStatusCancelled},
+		{name: "deliver without approve", from: OrderStatusPlaced, to: OrderStatusDelivered, wantErr: true},
+		{name: "approve twice", from: OrderStatusApproved, to: OrderStatusApproved, wantErr: true},
+		{name: "cancel delivered", from: OrderStatusDelivered, to: OrderStatusCancelled, wantErr: true},
+		{name: "unknown status", from: "lost", to: OrderStatusCancelled, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckOrderStatusTransition(tt.from, tt.to)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckOrderStatusTransition() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if err != nil && errors.Cause(err) != ErrInvalidTransition {
+				t.Errorf("CheckOrderStatusTransition() error = %v, want ErrInvalidTransition", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestOrder_NormalizeItems(t *testing.T) {
+	tests := []struct {
+		name    string
+		order   Order
+		want    Order
+		wantErr bool
+	}{
+		{
+			name:  "single pet",
+			order: Order{PetID: 2, Quantity: 3},
+			want:  Order{PetID: 2, Quantity: 3, Items: OrderItemList{{PetID: 2, Quantity: 3}}},
+		},
+		{
+			name:  "several pets",
+			order: Order{Items: OrderItemList{{PetID: 2, Quantity: 3}, {PetID: 1, Quantity: 1}}},
+			want:  Order{Quantity: 4, Items: OrderItemList{{PetID: 2, Quantity: 3}, {PetID: 1, Quantity: 1}}},
+		},
+		{
+			name:  "same pet twice",
+			order: Order{Items: OrderItemList{{PetID: 2, Quantity: 3}, {PetID: 2, Quantity: 1}}},
+			want:  Order{PetID: 2, Quantity: 4, Items: OrderItemList{{PetID: 2, Quantity: 4}}},
+		},
+		{
+			name:  "items win over single pet",
+			order: Order{PetID: 5, Quantity: 9, Items: OrderItemList{{PetID: 2, Quantity: 3}}},
+			want:  Order{PetID: 2, Quantity: 3, Items: OrderItemList{{PetID: 2, Quantity: 3}}},
+		},
+		{
+			name:    "no items",
+			order:   Order{},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.order.NormalizeItems()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NormalizeItems() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !tt.wantErr && !reflect.DeepEqual(tt.order, tt.want) {
+				t.Errorf("NormalizeItems() got = %v, want %v", tt.order, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+//go:generate easyjson -all payment.go
+
+package models
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	PaymentStatusPending    = "pending"
+	PaymentStatusAuthorized = "authorized"
+	PaymentStatusCaptured   = "captured"
+	PaymentStatusDeclined   = "declined"
+	PaymentStatusFailed     = "failed"
+	PaymentStatusRefunded   = "refunded"
+)
+
+// PaymentProviderManual marks payments made outside the store,
+// their refunds are recorded without calling a payment provider.
+const PaymentProviderManual = "manual"
+
+// paymentStatusTransitions lists the statuses a payment may move to from each status.
+var paymentStatusTransitions = map[string][]string{
+	PaymentStatusPending:    {PaymentStatusAuthorized, PaymentStatusDeclined, PaymentStatusFailed},
+	PaymentStatusAuthorized: {PaymentStatusCaptured, PaymentStatusFailed},
+	PaymentStatusCaptured:   {PaymentStatusRefunded},
+	PaymentStatusDeclined:   {},
+	PaymentStatusFailed:     {},
+	PaymentStatusRefunded:   {},
+}
+
+// easyjson:json
+type PaymentList []*Payment
+
+// Payment is a charge of the order total through a payment provider,
+// an order has at most one pending, authorized or captured payment.
+// easyjson:json
+type Payment struct {
+	ID        int64     `json:"id" db:"id"`
+	OrderID   int64     `json:"order_id" db:"order_id"`
+	Provider  string    `json:"provider" db:"provider"`
+	Reference string    `json:"reference,omitempty" db:"reference"`
+	Amount    float64   `json:"amount" db:"amount"`
+	Status    string    `json:"status" db:"status"`
+	Reason    string    `json:"reason,omitempty" db:"reason"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// PaymentRequest is the body of an order payment, the token stands
+// for the card at the payment provider. An authorized payment
+// is captured at once when Capture is set.
+// easyjson:json
+type PaymentRequest struct {
+	Token   string `json:"token" validate:"nonzero"`
+	Capture bool   `json:"capture"`
+}
+
+// CheckPaymentStatusTransition returns an error wrapped around ErrInvalidTransition
+// when a payment can't move from one status to another.
+func CheckPaymentStatusTransition(from, to string) error {
+	allowed, ok := paymentStatusTransitions[from]
+	if !ok {
+		return errors.Wrapf(ErrInvalidTransition, "unknown payment status %s", from)
+	}
+
+	for _, status := range allowed {
+		if status == to {
+			return nil
+		}
+	}
+
+	return errors.Wrapf(ErrInvalidTransition, "payment status can't change from %s to %s", from, to)
+}
+
+// Last returns the latest payment with the status, nil when there is none.
+func (l PaymentList) Last(status string) *Payment {
+	for idx := len(l) - 1; idx >= 0; idx-- {
+		if l[idx].Status == status {
+			return l[idx]
+		}
+	}
+
+	return nil
+}
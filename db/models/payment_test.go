@@ -0,0 +1,37 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestCheckPaymentStatusTransition(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    string
+		to      string
+		wantErr bool
+	}{
+		{name: "authorize", from: PaymentStatusPending, to: PaymentStatusAuthorized},
+		{name: "decline", from: PaymentStatusPending, to: PaymentStatusDeclined},
+		{name: "capture", from: PaymentStatusAuthorized, to: PaymentStatusCaptured},
+		{name: "refund", from: PaymentStatusCaptured, to: PaymentStatusRefunded},
+		{name: "capture pending", from: PaymentStatusPending, to: PaymentStatusCaptured, wantErr: true},
+		{name: "capture declined", from: PaymentStatusDeclined, to: PaymentStatusCaptured, wantErr: true},
+		{name: "refund twice", from: PaymentStatusRefunded, to: PaymentStatusRefunded, wantErr: true},
+		{name: "unknown status", from: "lost", to: PaymentStatusCaptured, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckPaymentStatusTransition(tt.from, tt.to)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckPaymentStatusTransition() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if err != nil && errors.Cause(err) != ErrInvalidTransition {
+				t.Errorf("CheckPaymentStatusTransition() error = %v, want ErrInvalidTransition", err)
+			}
+		})
+	}
+}
@@ -13,4 +13,5 @@ type Pet struct {
 	PhotoURLs []string `json:"photo_urls,omitempty" db:"photo_urls,omitempty"`
 	Tags      []Tag    `json:"tags,omitempty" db:"tags,omitempty"`
 	Status    string   `json:"status" db:"pet_status_id" validate:"nonzero"`
+	Quantity  int32    `json:"quantity" db:"quantity" validate:"min=0"`
 }
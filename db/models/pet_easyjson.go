@@ -160,7 +160,7 @@ func easyjson14a1085DecodeGithubComIamStubborNPetstoreDbModels1(in *jlexer.Lexer
 				}
 				for !in.IsDelim(']') {
 					var v5 Tag
-					easyjson14a1085DecodeGithubComIamStubborNPetstoreDbModels2(in, &v5)
+					(v5).UnmarshalEasyJSON(in)
 					out.Tags = append(out.Tags, v5)
 					in.WantComma()
 				}
@@ -168,6 +168,8 @@ func easyjson14a1085DecodeGithubComIamStubborNPetstoreDbModels1(in *jlexer.Lexer
 			}
 		case "status":
 			out.Status = string(in.String())
+		case "quantity":
+			out.Quantity = int32(in.Int32())
 		default:
 			in.SkipRecursive()
 		}
@@ -226,7 +228,7 @@ func easyjson14a1085EncodeGithubComIamStubborNPetstoreDbModels1(out *jwriter.Wri
 				if v8 > 0 {
 					out.RawByte(',')
 				}
-				easyjson14a1085EncodeGithubComIamStubborNPetstoreDbModels2(out, v9)
+				(v9).MarshalEasyJSON(out)
 			}
 			out.RawByte(']')
 		}
@@ -236,6 +238,11 @@ func easyjson14a1085EncodeGithubComIamStubborNPetstoreDbModels1(out *jwriter.Wri
 		out.RawString(prefix)
 		out.String(string(in.Status))
 	}
+	{
+		const prefix string = ",\"quantity\":"
+		out.RawString(prefix)
+		out.Int32(int32(in.Quantity))
+	}
 	out.RawByte('}')
 }
 
@@ -262,52 +269,3 @@ func (v *Pet) UnmarshalJSON(data []byte) error {
 func (v *Pet) UnmarshalEasyJSON(l *jlexer.Lexer) {
 	easyjson14a1085DecodeGithubComIamStubborNPetstoreDbModels1(l, v)
 }
-func easyjson14a1085DecodeGithubComIamStubborNPetstoreDbModels2(in *jlexer.Lexer, out *Tag) {
-	isTopLevel := in.IsStart()
-	if in.IsNull() {
-		if isTopLevel {
-			in.Consumed()
-		}
-		in.Skip()
-		return
-	}
-	in.Delim('{')
-	for !in.IsDelim('}') {
-		key := in.UnsafeString()
-		in.WantColon()
-		if in.IsNull() {
-			in.Skip()
-			in.WantComma()
-			continue
-		}
-		switch key {
-		case "id":
-			out.ID = int64(in.Int64())
-		case "name":
-			out.Name = string(in.String())
-		default:
-			in.SkipRecursive()
-		}
-		in.WantComma()
-	}
-	in.Delim('}')
-	if isTopLevel {
-		in.Consumed()
-	}
-}
-func easyjson14a1085EncodeGithubComIamStubborNPetstoreDbModels2(out *jwriter.Writer, in Tag) {
-	out.RawByte('{')
-	first := true
-	_ = first
-	{
-		const prefix string = ",\"id\":"
-		out.RawString(prefix[1:])
-		out.Int64(int64(in.ID))
-	}
-	{
-		const prefix string = ",\"name\":"
-		out.RawString(prefix)
-		out.String(string(in.Name))
-	}
-	out.RawByte('}')
-}
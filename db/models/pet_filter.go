@@ -0,0 +1,63 @@
+package models
+
+import (
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	PetSortByID    = "id"
+	PetSortByName  = "name"
+	PetSortByPrice = "price"
+
+	DefaultPageLimit = 100
+	MaxPageLimit     = 1000
+)
+
+// PetFilter describes one page of a pets listing.
+type PetFilter struct {
+	Status     []string
+	Category   string
+	NamePrefix string
+	Tag        string
+	SortBy     string
+	Limit      int
+	After      string
+}
+
+func (f *PetFilter) Validate() error {
+	switch f.SortBy {
+	case "":
+		f.SortBy = PetSortByID
+	case PetSortByID, PetSortByName, PetSortByPrice:
+	default:
+		return errors.Errorf("can't sort pets by %s", f.SortBy)
+	}
+
+	if f.Limit == 0 {
+		f.Limit = DefaultPageLimit
+	}
+
+	if f.Limit < 0 || f.Limit > MaxPageLimit {
+		return errors.Errorf("limit must be between 1 and %d", MaxPageLimit)
+	}
+
+	if _, err := DecodeCursor(f.After); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// CursorOf returns the cursor pointing to pet in the filter sort order.
+func (f *PetFilter) CursorOf(pet *Pet) Cursor {
+	switch f.SortBy {
+	case PetSortByName:
+		return Cursor{Key: pet.Name, ID: pet.ID}
+	case PetSortByPrice:
+		return Cursor{Key: strconv.FormatFloat(pet.Category.Price, 'f', -1, 64), ID: pet.ID}
+	default:
+		return Cursor{ID: pet.ID}
+	}
+}
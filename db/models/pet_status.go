@@ -0,0 +1,63 @@
+//go:generate easyjson -all pet_status.go
+
+package models
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	PetStatusAvailable = "available"
+	PetStatusPending   = "pending"
+	PetStatusSold      = "sold"
+)
+
+// petStatusTransitions lists the statuses a pet may move to from each status,
+// a pending pet an active order holds moves only with its order.
+var petStatusTransitions = map[string][]string{
+	PetStatusAvailable: {PetStatusPending},
+	PetStatusPending:   {PetStatusAvailable, PetStatusSold},
+	PetStatusSold:      {},
+}
+
+// CheckPetStatusTransition returns an error wrapped around ErrInvalidTransition
+// when a pet can't move from one status to another, keeping the status is allowed.
+func CheckPetStatusTransition(from, to string) error {
+	allowed, ok := petStatusTransitions[from]
+	if !ok {
+		return errors.Wrapf(ErrInvalidTransition, "unknown pet status %s", from)
+	}
+
+	if _, ok := petStatusTransitions[to]; !ok {
+		return errors.Wrapf(ErrInvalidTransition, "unknown pet status %s", to)
+	}
+
+	if from == to {
+		return nil
+	}
+
+	for _, status := range allowed {
+		if status == to {
+			return nil
+		}
+	}
+
+	return errors.Wrapf(ErrInvalidTransition, "pet status can't change from %s to %s", from, to)
+}
+
+// easyjson:json
+type PetStatusHistory []*PetStatusChange
+
+// PetStatusChange is one recorded pet status transition,
+// FromStatus is empty for the status a pet was added with.
+// easyjson:json
+type PetStatusChange struct {
+	ID         int64     `json:"id" db:"id"`
+	PetID      int64     `json:"pet_id" db:"pet_id"`
+	FromStatus string    `json:"from_status,omitempty" db:"from_status"`
+	ToStatus   string    `json:"to_status" db:"to_status"`
+	UserID     int64     `json:"user_id" db:"user_id"`
+	ChangedAt  time.Time `json:"changed_at" db:"changed_at"`
+}
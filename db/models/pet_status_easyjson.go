@@ -0,0 +1,200 @@
+// Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
+
+package models
+
+import (
+	json "encoding/json"
+	easyjson "github.com/mailru/easyjson"
+	jlexer "github.com/mailru/easyjson/jlexer"
+	jwriter "github.com/mailru/easyjson/jwriter"
+)
+
+// suppress unused package warning
+var (
+	_ *json.RawMessage
+	_ *jlexer.Lexer
+	_ *jwriter.Writer
+	_ easyjson.Marshaler
+)
+
+func easyjson2f1e465cDecodeGithubComIamStubborNPetstoreDbModels(in *jlexer.Lexer, out *PetStatusHistory) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+		*out = nil
+	} else {
+		in.Delim('[')
+		if *out == nil {
+			if !in.IsDelim(']') {
+				*out = make(PetStatusHistory, 0, 8)
+			} else {
+				*out = PetStatusHistory{}
+			}
+		} else {
+			*out = (*out)[:0]
+		}
+		for !in.IsDelim(']') {
+			var v1 *PetStatusChange
+			if in.IsNull() {
+				in.Skip()
+				v1 = nil
+			} else {
+				if v1 == nil {
+					v1 = new(PetStatusChange)
+				}
+				(*v1).UnmarshalEasyJSON(in)
+			}
+			*out = append(*out, v1)
+			in.WantComma()
+		}
+		in.Delim(']')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson2f1e465cEncodeGithubComIamStubborNPetstoreDbModels(out *jwriter.Writer, in PetStatusHistory) {
+	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		out.RawString("null")
+	} else {
+		out.RawByte('[')
+		for v2, v3 := range in {
+			if v2 > 0 {
+				out.RawByte(',')
+			}
+			if v3 == nil {
+				out.RawString("null")
+			} else {
+				(*v3).MarshalEasyJSON(out)
+			}
+		}
+		out.RawByte(']')
+	}
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v PetStatusHistory) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson2f1e465cEncodeGithubComIamStubborNPetstoreDbModels(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v PetStatusHistory) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson2f1e465cEncodeGithubComIamStubborNPetstoreDbModels(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *PetStatusHistory) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson2f1e465cDecodeGithubComIamStubborNPetstoreDbModels(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *PetStatusHistory) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson2f1e465cDecodeGithubComIamStubborNPetstoreDbModels(l, v)
+}
+func easyjson2f1e465cDecodeGithubComIamStubborNPetstoreDbModels1(in *jlexer.Lexer, out *PetStatusChange) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "id":
+			out.ID = int64(in.Int64())
+		case "pet_id":
+			out.PetID = int64(in.Int64())
+		case "from_status":
+			out.FromStatus = string(in.String())
+		case "to_status":
+			out.ToStatus = string(in.String())
+		case "user_id":
+			out.UserID = int64(in.Int64())
+		case "changed_at":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.ChangedAt).UnmarshalJSON(data))
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson2f1e465cEncodeGithubComIamStubborNPetstoreDbModels1(out *jwriter.Writer, in PetStatusChange) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"id\":"
+		out.RawString(prefix[1:])
+		out.Int64(int64(in.ID))
+	}
+	{
+		const prefix string = ",\"pet_id\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.PetID))
+	}
+	if in.FromStatus != "" {
+		const prefix string = ",\"from_status\":"
+		out.RawString(prefix)
+		out.String(string(in.FromStatus))
+	}
+	{
+		const prefix string = ",\"to_status\":"
+		out.RawString(prefix)
+		out.String(string(in.ToStatus))
+	}
+	{
+		const prefix string = ",\"user_id\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.UserID))
+	}
+	{
+		const prefix string = ",\"changed_at\":"
+		out.RawString(prefix)
+		out.Raw((in.ChangedAt).MarshalJSON())
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v PetStatusChange) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson2f1e465cEncodeGithubComIamStubborNPetstoreDbModels1(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v PetStatusChange) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson2f1e465cEncodeGithubComIamStubborNPetstoreDbModels1(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *PetStatusChange) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson2f1e465cDecodeGithubComIamStubborNPetstoreDbModels1(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *PetStatusChange) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson2f1e465cDecodeGithubComIamStubborNPetstoreDbModels1(l, v)
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestCheckPetStatusTransition(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    string
+		to      string
+		wantErr bool
+	}{
+		{name: "reserve", from: PetStatusAvailable, to: PetStatusPending},
+		{name: "release", from: PetStatusPending, to: PetStatusAvailable},
+		{name: "sell", from: PetStatusPending, to: PetStatusSold},
+		{name: "keep", from: PetStatusSold, to: PetStatusSold},
+		{name: "sell without reserve", from: PetStatusAvailable, to: PetStatusSold, wantErr: true},
+		{name: "resell", from: PetStatusSold, to: PetStatusAvailable, wantErr: true},
+		{name: "unknown status", from: PetStatusAvailable, to: "lost", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckPetStatusTransition(tt.from, tt.to)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckPetStatusTransition() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if err != nil && errors.Cause(err) != ErrInvalidTransition {
+				t.Errorf("CheckPetStatusTransition() error = %v, want ErrInvalidTransition", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+//go:generate easyjson -all refund.go
+
+package models
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	RefundStatusPending   = "pending"
+	RefundStatusCompleted = "completed"
+	RefundStatusFailed    = "failed"
+)
+
+// RefundLease is the time a reserved or claimed refund is left to the
+// one making it before the refund worker may try it again.
+const RefundLease = time.Minute
+
+// easyjson:json
+type RefundList []*Refund
+
+// Refund returns a part of the captured payment of the order, the ledger
+// of refunds is kept with the order, so past invoices stay reproducible.
+// An amount of 0 in a refund request asks for everything left.
+// A refund is reserved in the ledger as pending before the payment
+// provider is called and is completed or failed by its answer,
+// Provider and Reference are the ones of its payment.
+// easyjson:json
+type Refund struct {
+	ID            int64      `json:"id" db:"id"`
+	OrderID       int64      `json:"order_id" db:"order_id"`
+	PaymentID     int64      `json:"payment_id" db:"payment_id"`
+	Amount        float64    `json:"amount" db:"amount" validate:"min=0"`
+	Reason        string     `json:"reason" db:"reason" validate:"nonzero,max=255"`
+	UserID        int64      `json:"user_id" db:"user_id"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	Status        string     `json:"status" db:"status"`
+	Attempts      int        `json:"attempts" db:"attempts"`
+	LastError     string     `json:"last_error,omitempty" db:"last_error"`
+	NextAttemptAt time.Time  `json:"-" db:"next_attempt_at"`
+	CompletedAt   *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+	Provider      string     `json:"-" db:"provider"`
+	Reference     string     `json:"-" db:"reference"`
+}
+
+// Refunded sums up the pending and completed refunds of the payment,
+// failed refunds returned nothing.
+func (l RefundList) Refunded(paymentID int64) float64 {
+	var refunded float64
+	for _, refund := range l {
+		if refund.PaymentID == paymentID && refund.Status != RefundStatusFailed {
+			refunded += refund.Amount
+		}
+	}
+
+	return RoundPrice(refunded)
+}
+
+// Key identifies the refund at the payment provider,
+// so a repeated refund is made once.
+func (r *Refund) Key() string {
+	return "refund_" + strconv.FormatInt(r.ID, 10)
+}
+
+// Complete marks the refund returned by the payment provider.
+func (r *Refund) Complete(now time.Time) {
+	r.Attempts++
+	r.Status = RefundStatusCompleted
+	r.LastError = ""
+	r.CompletedAt = &now
+}
+
+// Fail counts the failed attempt, a refund that may be retried stays
+// pending until the next attempt, otherwise it fails and its amount
+// can be refunded again.
+func (r *Refund) Fail(err error, retry bool, now time.Time) {
+	r.Attempts++
+	r.LastError = err.Error()
+	if !retry {
+		r.Status = RefundStatusFailed
+		return
+	}
+
+	r.NextAttemptAt = now.Add(retryDelay(r.Attempts))
+}
+
+// CheckRefund returns an error wrapped around ErrConflict when the amount
+// can't be refunded from the payment, refunded is the sum of its refunds.
+func CheckRefund(payment *Payment, refunded, amount float64) error {
+	if payment.Status != PaymentStatusCaptured {
+		return errors.Wrapf(ErrConflict, "payment № %d is %s", payment.ID, payment.Status)
+	}
+
+	left := RoundPrice(payment.Amount - refunded)
+	if amount <= 0 || amount > left {
+		return errors.Wrapf(ErrConflict, "can't refund %.2f, %.2f is left of payment № %d",
+			amount, left, payment.ID)
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestCheckRefund(t *testing.T) {
+	captured := &Payment{ID: 1, Amount: 134.98, Status: PaymentStatusCaptured}
+
+	tests := []struct {
+		name     string
+		payment  *Payment
+		refunded float64
+		amount   float64
+		wantErr  bool
+	}{
+		{name: "full refund", payment: captured, amount: 134.98},
+		{name: "partial refund", payment: captured, amount: 34.99},
+		{name: "the rest", payment: captured, refunded: 100, amount: 34.98},
+		{name: "over the rest", payment: captured, refunded: 100, amount: 35, wantErr: true},
+		{name: "nothing left", payment: captured, refunded: 134.98, amount: 0.01, wantErr: true},
+		{name: "zero amount", payment: captured, wantErr: true},
+		{name: "authorized payment", payment: &Payment{ID: 2, Amount: 10, Status: PaymentStatusAuthorized},
+			amount: 10, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckRefund(tt.payment, tt.refunded, tt.amount)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckRefund() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if err != nil && errors.Cause(err) != ErrConflict {
+				t.Errorf("CheckRefund() error = %v, want ErrConflict", err)
+			}
+		})
+	}
+}
+
+func TestRefundList_Refunded(t *testing.T) {
+	refunds := RefundList{
+		{PaymentID: 1, Amount: 10.10, Status: RefundStatusCompleted},
+		{PaymentID: 2, Amount: 99, Status: RefundStatusCompleted},
+		{PaymentID: 1, Amount: 20.20, Status: RefundStatusPending},
+		{PaymentID: 1, Amount: 5, Status: RefundStatusFailed},
+	}
+
+	if got := refunds.Refunded(1); got != 30.30 {
+		t.Errorf("Refunded() got = %v, want 30.30", got)
+	}
+}
+
+func TestRefund_Fail(t *testing.T) {
+	now := time.Date(2019, 9, 7, 12, 0, 0, 0, time.UTC)
+	refund := &Refund{ID: 7, Status: RefundStatusPending}
+
+	refund.Fail(errors.New("gateway is down"), true, now)
+	refund.Fail(errors.New("gateway is down"), true, now)
+	if refund.Status != RefundStatusPending || refund.Attempts != 2 ||
+		!refund.NextAttemptAt.Equal(now.Add(2*time.Second)) {
+		t.Errorf("Fail() with retry got = %+v, want pending for 2s", refund)
+	}
+
+	refund.Fail(errors.New("payment declined"), false, now)
+	if refund.Status != RefundStatusFailed || refund.LastError != "payment declined" {
+		t.Errorf("Fail() got = %+v, want failed", refund)
+	}
+
+	if got := refund.Key(); got != "refund_7" {
+		t.Errorf("Key() got = %v, want refund_7", got)
+	}
+}
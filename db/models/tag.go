@@ -2,6 +2,9 @@
 
 package models
 
+// easyjson:json
+type TagList []*Tag
+
 // easyjson:json
 type Tag struct {
 	ID   int64  `json:"id" db:"id"`
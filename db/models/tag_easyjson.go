@@ -17,7 +17,85 @@ var (
 	_ easyjson.Marshaler
 )
 
-func easyjson13673cd6DecodeGithubComIamStubborNPetstoreDbModels(in *jlexer.Lexer, out *Tag) {
+func easyjson13673cd6DecodeGithubComIamStubborNPetstoreDbModels(in *jlexer.Lexer, out *TagList) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+		*out = nil
+	} else {
+		in.Delim('[')
+		if *out == nil {
+			if !in.IsDelim(']') {
+				*out = make(TagList, 0, 8)
+			} else {
+				*out = TagList{}
+			}
+		} else {
+			*out = (*out)[:0]
+		}
+		for !in.IsDelim(']') {
+			var v1 *Tag
+			if in.IsNull() {
+				in.Skip()
+				v1 = nil
+			} else {
+				if v1 == nil {
+					v1 = new(Tag)
+				}
+				(*v1).UnmarshalEasyJSON(in)
+			}
+			*out = append(*out, v1)
+			in.WantComma()
+		}
+		in.Delim(']')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson13673cd6EncodeGithubComIamStubborNPetstoreDbModels(out *jwriter.Writer, in TagList) {
+	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		out.RawString("null")
+	} else {
+		out.RawByte('[')
+		for v2, v3 := range in {
+			if v2 > 0 {
+				out.RawByte(',')
+			}
+			if v3 == nil {
+				out.RawString("null")
+			} else {
+				(*v3).MarshalEasyJSON(out)
+			}
+		}
+		out.RawByte(']')
+	}
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v TagList) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson13673cd6EncodeGithubComIamStubborNPetstoreDbModels(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v TagList) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson13673cd6EncodeGithubComIamStubborNPetstoreDbModels(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *TagList) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson13673cd6DecodeGithubComIamStubborNPetstoreDbModels(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *TagList) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson13673cd6DecodeGithubComIamStubborNPetstoreDbModels(l, v)
+}
+func easyjson13673cd6DecodeGithubComIamStubborNPetstoreDbModels1(in *jlexer.Lexer, out *Tag) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -50,7 +128,7 @@ func easyjson13673cd6DecodeGithubComIamStubborNPetstoreDbModels(in *jlexer.Lexer
 		in.Consumed()
 	}
 }
-func easyjson13673cd6EncodeGithubComIamStubborNPetstoreDbModels(out *jwriter.Writer, in Tag) {
+func easyjson13673cd6EncodeGithubComIamStubborNPetstoreDbModels1(out *jwriter.Writer, in Tag) {
 	out.RawByte('{')
 	first := true
 	_ = first
@@ -70,23 +148,23 @@ func easyjson13673cd6EncodeGithubComIamStubborNPetstoreDbModels(out *jwriter.Wri
 // MarshalJSON supports json.Marshaler interface
 func (v Tag) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjson13673cd6EncodeGithubComIamStubborNPetstoreDbModels(&w, v)
+	easyjson13673cd6EncodeGithubComIamStubborNPetstoreDbModels1(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
 func (v Tag) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjson13673cd6EncodeGithubComIamStubborNPetstoreDbModels(w, v)
+	easyjson13673cd6EncodeGithubComIamStubborNPetstoreDbModels1(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
 func (v *Tag) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjson13673cd6DecodeGithubComIamStubborNPetstoreDbModels(&r, v)
+	easyjson13673cd6DecodeGithubComIamStubborNPetstoreDbModels1(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
 func (v *Tag) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjson13673cd6DecodeGithubComIamStubborNPetstoreDbModels(l, v)
+	easyjson13673cd6DecodeGithubComIamStubborNPetstoreDbModels1(l, v)
 }
@@ -16,3 +16,17 @@ type User struct {
 	Phone      string `json:"phone" db:"phone" validate:"regexp=^\\+[0-9]{9\\,15}$"`
 	UserStatus int64  `json:"user_status_id" db:"user_status_id" validate:"nonzero"`
 }
+
+// UserStatusAdmin and UserStatusCustomer are the ids of the admin
+// and customer rows in user_status. Users sign up as customers,
+// only an admin changes the status of a user.
+const (
+	UserStatusAdmin    int64 = 1
+	UserStatusCustomer int64 = 5
+)
+
+// UserStatusChange is the status an admin grants to a user.
+// easyjson:json
+type UserStatusChange struct {
+	UserStatus int64 `json:"user_status_id" validate:"nonzero"`
+}
@@ -17,7 +17,73 @@ var (
 	_ easyjson.Marshaler
 )
 
-func easyjson9e1087fdDecodeGithubComIamStubborNPetstoreDbModels(in *jlexer.Lexer, out *UserList) {
+func easyjson9e1087fdDecodeGithubComIamStubborNPetstoreDbModels(in *jlexer.Lexer, out *UserStatusChange) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "user_status_id":
+			out.UserStatus = int64(in.Int64())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson9e1087fdEncodeGithubComIamStubborNPetstoreDbModels(out *jwriter.Writer, in UserStatusChange) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"user_status_id\":"
+		out.RawString(prefix[1:])
+		out.Int64(int64(in.UserStatus))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v UserStatusChange) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson9e1087fdEncodeGithubComIamStubborNPetstoreDbModels(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v UserStatusChange) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson9e1087fdEncodeGithubComIamStubborNPetstoreDbModels(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *UserStatusChange) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson9e1087fdDecodeGithubComIamStubborNPetstoreDbModels(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *UserStatusChange) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson9e1087fdDecodeGithubComIamStubborNPetstoreDbModels(l, v)
+}
+func easyjson9e1087fdDecodeGithubComIamStubborNPetstoreDbModels1(in *jlexer.Lexer, out *UserList) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		in.Skip()
@@ -53,7 +119,7 @@ func easyjson9e1087fdDecodeGithubComIamStubborNPetstoreDbModels(in *jlexer.Lexer
 		in.Consumed()
 	}
 }
-func easyjson9e1087fdEncodeGithubComIamStubborNPetstoreDbModels(out *jwriter.Writer, in UserList) {
+func easyjson9e1087fdEncodeGithubComIamStubborNPetstoreDbModels1(out *jwriter.Writer, in UserList) {
 	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
 		out.RawString("null")
 	} else {
@@ -75,27 +141,27 @@ func easyjson9e1087fdEncodeGithubComIamStubborNPetstoreDbModels(out *jwriter.Wri
 // MarshalJSON supports json.Marshaler interface
 func (v UserList) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjson9e1087fdEncodeGithubComIamStubborNPetstoreDbModels(&w, v)
+	easyjson9e1087fdEncodeGithubComIamStubborNPetstoreDbModels1(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
 func (v UserList) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjson9e1087fdEncodeGithubComIamStubborNPetstoreDbModels(w, v)
+	easyjson9e1087fdEncodeGithubComIamStubborNPetstoreDbModels1(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
 func (v *UserList) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjson9e1087fdDecodeGithubComIamStubborNPetstoreDbModels(&r, v)
+	easyjson9e1087fdDecodeGithubComIamStubborNPetstoreDbModels1(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
 func (v *UserList) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjson9e1087fdDecodeGithubComIamStubborNPetstoreDbModels(l, v)
+	easyjson9e1087fdDecodeGithubComIamStubborNPetstoreDbModels1(l, v)
 }
-func easyjson9e1087fdDecodeGithubComIamStubborNPetstoreDbModels1(in *jlexer.Lexer, out *User) {
+func easyjson9e1087fdDecodeGithubComIamStubborNPetstoreDbModels2(in *jlexer.Lexer, out *User) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -140,7 +206,7 @@ func easyjson9e1087fdDecodeGithubComIamStubborNPetstoreDbModels1(in *jlexer.Lexe
 		in.Consumed()
 	}
 }
-func easyjson9e1087fdEncodeGithubComIamStubborNPetstoreDbModels1(out *jwriter.Writer, in User) {
+func easyjson9e1087fdEncodeGithubComIamStubborNPetstoreDbModels2(out *jwriter.Writer, in User) {
 	out.RawByte('{')
 	first := true
 	_ = first
@@ -196,23 +262,23 @@ func easyjson9e1087fdEncodeGithubComIamStubborNPetstoreDbModels1(out *jwriter.Wr
 // MarshalJSON supports json.Marshaler interface
 func (v User) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjson9e1087fdEncodeGithubComIamStubborNPetstoreDbModels1(&w, v)
+	easyjson9e1087fdEncodeGithubComIamStubborNPetstoreDbModels2(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
 func (v User) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjson9e1087fdEncodeGithubComIamStubborNPetstoreDbModels1(w, v)
+	easyjson9e1087fdEncodeGithubComIamStubborNPetstoreDbModels2(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
 func (v *User) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjson9e1087fdDecodeGithubComIamStubborNPetstoreDbModels1(&r, v)
+	easyjson9e1087fdDecodeGithubComIamStubborNPetstoreDbModels2(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
 func (v *User) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjson9e1087fdDecodeGithubComIamStubborNPetstoreDbModels1(l, v)
+	easyjson9e1087fdDecodeGithubComIamStubborNPetstoreDbModels2(l, v)
 }
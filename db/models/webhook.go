@@ -0,0 +1,139 @@
+//go:generate easyjson -all webhook.go
+
+package models
+
+import (
+	"encoding/json"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	WebhookDeliveryFailed    = "failed"
+
+	minWebhookSecretLength = 16
+)
+
+// WebhookLease is the time claimed deliveries are left to the worker
+// delivering them, a delivery it doesn't save in time is delivered again.
+const WebhookLease = 10 * time.Minute
+
+// easyjson:json
+type WebhookList []*Webhook
+
+// Webhook subscribes the URL to the events, every delivery is signed
+// with the secret. The secret is only accepted, it's never shown back.
+// A webhook is disabled after too many failed deliveries in a row,
+// Failures counts them and is reset by a successful delivery.
+// easyjson:json
+type Webhook struct {
+	ID         int64      `json:"id" db:"id"`
+	URL        string     `json:"url" db:"url" validate:"nonzero,max=2000"`
+	Events     []string   `json:"events" db:"events"`
+	Secret     string     `json:"secret,omitempty" db:"secret"`
+	Active     bool       `json:"active" db:"active"`
+	Failures   int        `json:"failures" db:"failures"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	DisabledAt *time.Time `json:"disabled_at,omitempty" db:"disabled_at"`
+}
+
+// Validate checks the webhook values the validator tags can't check.
+func (w *Webhook) Validate() error {
+	u, err := url.Parse(w.URL)
+	if err != nil {
+		return errors.Wrapf(err, "can't parse webhook url %s", w.URL)
+	}
+
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.Errorf("webhook url %s must be absolute http or https url", w.URL)
+	}
+
+	if len(w.Events) == 0 {
+		return errors.New("webhook must subscribe to events")
+	}
+
+	for _, eventType := range w.Events {
+		if !IsEventType(eventType) {
+			return errors.Errorf("unknown event %s", eventType)
+		}
+	}
+
+	if len(w.Secret) < minWebhookSecretLength {
+		return errors.Errorf("webhook secret must be at least %d characters", minWebhookSecretLength)
+	}
+
+	return nil
+}
+
+// easyjson:json
+type WebhookDeliveryList []*WebhookDelivery
+
+// WebhookDelivery is the delivery of an outbox event to a webhook.
+// A pending delivery is retried with a growing delay, it becomes
+// failed when it runs out of attempts. The event and the webhook
+// fields that aren't shown are read to deliver the event.
+// easyjson:json
+type WebhookDelivery struct {
+	ID             int64           `json:"id" db:"id"`
+	WebhookID      int64           `json:"webhook_id" db:"webhook_id"`
+	EventID        int64           `json:"event_id" db:"event_id"`
+	Event          string          `json:"event" db:"event"`
+	Status         string          `json:"status" db:"status"`
+	Attempts       int             `json:"attempts" db:"attempts"`
+	ResponseCode   int             `json:"response_code,omitempty" db:"response_code"`
+	LastError      string          `json:"last_error,omitempty" db:"last_error"`
+	CreatedAt      time.Time       `json:"created_at" db:"created_at"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at" db:"next_attempt_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty" db:"delivered_at"`
+	Aggregate      string          `json:"-" db:"aggregate"`
+	Key            string          `json:"-" db:"aggregate_key"`
+	Payload        json.RawMessage `json:"-" db:"payload"`
+	EventCreatedAt time.Time       `json:"-" db:"event_created_at"`
+	URL            string          `json:"-" db:"url"`
+	Secret         string          `json:"-" db:"secret"`
+}
+
+// WebhookPolicy limits the deliveries, a delivery is tried MaxAttempts
+// times and a webhook is disabled after MaxFailures failed attempts
+// in a row. BatchSize deliveries are made at a time.
+type WebhookPolicy struct {
+	BatchSize   int
+	MaxAttempts int
+	MaxFailures int
+}
+
+// Body is the delivered event, it's the same as the outbox event.
+func (d *WebhookDelivery) Body() *Event {
+	return &Event{
+		ID:        d.EventID,
+		Aggregate: d.Aggregate,
+		Key:       d.Key,
+		Type:      d.Event,
+		Payload:   d.Payload,
+		CreatedAt: d.EventCreatedAt,
+	}
+}
+
+// Succeed marks the delivery delivered.
+func (d *WebhookDelivery) Succeed(now time.Time) {
+	d.Attempts++
+	d.Status = WebhookDeliveryDelivered
+	d.LastError = ""
+	d.DeliveredAt = &now
+}
+
+// Fail counts the failed attempt and schedules the next one,
+// the delivery fails for good after maxAttempts.
+func (d *WebhookDelivery) Fail(err error, now time.Time, maxAttempts int) {
+	d.Attempts++
+	d.LastError = err.Error()
+	d.NextAttemptAt = now.Add(retryDelay(d.Attempts))
+
+	if d.Attempts >= maxAttempts {
+		d.Status = WebhookDeliveryFailed
+	}
+}
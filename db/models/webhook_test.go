@@ -0,0 +1,61 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWebhook_Validate(t *testing.T) {
+	const secret = "partner-secret-0001"
+
+	tests := []struct {
+		name    string
+		webhook Webhook
+		wantErr bool
+	}{
+		{name: "valid", webhook: Webhook{URL: "https://partner.example.com/hooks",
+			Events: []string{EventOrderCreated, EventPetStatusChanged}, Secret: secret}},
+		{name: "http url", webhook: Webhook{URL: "http://10.0.0.1:8080/hooks",
+			Events: []string{EventOrderDelivered}, Secret: secret}},
+		{name: "ftp url", webhook: Webhook{URL: "ftp://partner.example.com",
+			Events: []string{EventOrderCreated}, Secret: secret}, wantErr: true},
+		{name: "relative url", webhook: Webhook{URL: "/hooks",
+			Events: []string{EventOrderCreated}, Secret: secret}, wantErr: true},
+		{name: "no events", webhook: Webhook{URL: "https://partner.example.com/hooks",
+			Secret: secret}, wantErr: true},
+		{name: "unknown event", webhook: Webhook{URL: "https://partner.example.com/hooks",
+			Events: []string{"order.lost"}, Secret: secret}, wantErr: true},
+		{name: "short secret", webhook: Webhook{URL: "https://partner.example.com/hooks",
+			Events: []string{EventOrderCreated}, Secret: "secret"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.webhook.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWebhookDelivery_FailAndSucceed(t *testing.T) {
+	now := time.Date(2020, 3, 1, 12, 0, 0, 0, time.UTC)
+	d := &WebhookDelivery{Status: WebhookDeliveryPending}
+
+	d.Fail(errors.New("webhook answered 500"), now, 3)
+	d.Fail(errors.New("webhook answered 500"), now, 3)
+	if d.Status != WebhookDeliveryPending || d.Attempts != 2 || !d.NextAttemptAt.Equal(now.Add(2*time.Second)) {
+		t.Errorf("Fail() status = %s, attempts = %d, next attempt = %v", d.Status, d.Attempts, d.NextAttemptAt)
+	}
+
+	d.Succeed(now)
+	if d.Status != WebhookDeliveryDelivered || d.Attempts != 3 || d.LastError != "" || d.DeliveredAt == nil {
+		t.Errorf("Succeed() status = %s, attempts = %d, last error = %s", d.Status, d.Attempts, d.LastError)
+	}
+
+	failing := &WebhookDelivery{Status: WebhookDeliveryPending, Attempts: 2}
+	failing.Fail(errors.New("timeout"), now, 3)
+	if failing.Status != WebhookDeliveryFailed {
+		t.Errorf("Fail() of last attempt status = %s, want %s", failing.Status, WebhookDeliveryFailed)
+	}
+}
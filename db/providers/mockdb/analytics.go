@@ -0,0 +1,195 @@
+package mockdb
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/IamStubborN/petstore/db/models"
+)
+
+// testUserNames names the customers of the test orders.
+var testUserNames = map[int64]string{
+	1: testUserName,
+	2: "customer",
+}
+
+// GetSales sums the sold test orders like the postgres query does,
+// sales are sorted by period and category.
+func (d *Database) GetSales(ctx context.Context, filter models.AnalyticsFilter) (models.CategorySalesList, error) {
+	type salesKey struct {
+		period     string
+		categoryID int64
+	}
+
+	categories := testCategoryNames()
+	refunded := d.refundedAmounts()
+	sales := models.CategorySalesList{}
+	byKey := make(map[salesKey]*models.CategorySales)
+	for _, order := range soldOrders(filter) {
+		shipDate, _ := time.Parse("2006-01-02T15:04:05", order.ShipDate)
+		period := filter.PeriodStart(shipDate).Format("2006-01-02")
+
+		for _, item := range order.Items {
+			key := salesKey{period: period, categoryID: item.CategoryID}
+			if _, ok := byKey[key]; !ok {
+				byKey[key] = &models.CategorySales{
+					Period:     period,
+					CategoryID: item.CategoryID,
+					Category:   categories[item.CategoryID],
+				}
+				sales = append(sales, byKey[key])
+			}
+
+			addItemSales(byKey[key], item, keptShare(order, refunded))
+		}
+	}
+
+	sort.Slice(sales, func(i, j int) bool {
+		if sales[i].Period != sales[j].Period {
+			return sales[i].Period < sales[j].Period
+		}
+		return sales[i].CategoryID < sales[j].CategoryID
+	})
+
+	return sales, nil
+}
+
+// GetTopCategories ranks the categories of the sold test orders by revenue.
+func (d *Database) GetTopCategories(ctx context.Context, filter models.AnalyticsFilter) (models.CategorySalesList, error) {
+	categories := testCategoryNames()
+	refunded := d.refundedAmounts()
+	sales := models.CategorySalesList{}
+	byCategory := make(map[int64]*models.CategorySales)
+	for _, order := range soldOrders(filter) {
+		for _, item := range order.Items {
+			if _, ok := byCategory[item.CategoryID]; !ok {
+				byCategory[item.CategoryID] = &models.CategorySales{
+					CategoryID: item.CategoryID,
+					Category:   categories[item.CategoryID],
+				}
+				sales = append(sales, byCategory[item.CategoryID])
+			}
+
+			addItemSales(byCategory[item.CategoryID], item, keptShare(order, refunded))
+		}
+	}
+
+	sort.Slice(sales, func(i, j int) bool {
+		if sales[i].Revenue != sales[j].Revenue {
+			return sales[i].Revenue > sales[j].Revenue
+		}
+		if sales[i].Units != sales[j].Units {
+			return sales[i].Units > sales[j].Units
+		}
+		return sales[i].CategoryID < sales[j].CategoryID
+	})
+
+	if len(sales) > filter.Limit {
+		sales = sales[:filter.Limit]
+	}
+
+	return sales, nil
+}
+
+func (d *Database) GetOrderValue(ctx context.Context, filter models.AnalyticsFilter) (*models.OrderValue, error) {
+	var value models.OrderValue
+	refunded := d.refundedAmounts()
+	for _, order := range soldOrders(filter) {
+		value.Orders++
+		value.Revenue += order.Total() - refunded[order.ID]
+	}
+
+	value.SetAverage()
+
+	return &value, nil
+}
+
+// GetUserValues ranks the customers of the sold test orders by revenue.
+func (d *Database) GetUserValues(ctx context.Context, filter models.AnalyticsFilter) (models.UserValueList, error) {
+	refunded := d.refundedAmounts()
+	values := models.UserValueList{}
+	byUser := make(map[int64]*models.UserValue)
+	for _, order := range soldOrders(filter) {
+		if _, ok := byUser[order.UserID]; !ok {
+			byUser[order.UserID] = &models.UserValue{
+				UserID:   order.UserID,
+				UserName: testUserNames[order.UserID],
+			}
+			values = append(values, byUser[order.UserID])
+		}
+
+		value := byUser[order.UserID]
+		value.Orders++
+		value.Revenue = models.RoundPrice(value.Revenue + order.Total() - refunded[order.ID])
+	}
+
+	sort.Slice(values, func(i, j int) bool {
+		if values[i].Revenue != values[j].Revenue {
+			return values[i].Revenue > values[j].Revenue
+		}
+		return values[i].UserID < values[j].UserID
+	})
+
+	if len(values) > filter.Limit {
+		values = values[:filter.Limit]
+	}
+
+	return values, nil
+}
+
+// soldOrders returns the test orders counted in sales
+// that are shipped in the range of the filter.
+func soldOrders(filter models.AnalyticsFilter) models.OrderList {
+	orders := models.OrderList{}
+	for _, order := range testOrders() {
+		shipDate, err := time.Parse("2006-01-02T15:04:05", order.ShipDate)
+		if err != nil || !models.IsSold(order.Status) {
+			continue
+		}
+
+		if !shipDate.Before(filter.From) && !shipDate.After(filter.To) {
+			orders = append(orders, order)
+		}
+	}
+
+	return orders
+}
+
+// refundedAmounts sums the completed refunds per order.
+func (d *Database) refundedAmounts() map[int64]float64 {
+	d.refunds.Lock()
+	defer d.refunds.Unlock()
+
+	refunded := make(map[int64]float64)
+	for _, refund := range d.refunds.list {
+		if refund.Status == models.RefundStatusCompleted {
+			refunded[refund.OrderID] += refund.Amount
+		}
+	}
+
+	return refunded
+}
+
+// keptShare is the part of the order total that isn't refunded.
+func keptShare(order *models.Order, refunded map[int64]float64) float64 {
+	if order.Total() == 0 {
+		return 1
+	}
+
+	return 1 - refunded[order.ID]/order.Total()
+}
+
+func addItemSales(sales *models.CategorySales, item *models.OrderItem, share float64) {
+	sales.Units += int64(item.Quantity)
+	sales.Revenue = models.RoundPrice(sales.Revenue + (item.Price*float64(item.Quantity)-item.Discount)*share)
+}
+
+func testCategoryNames() map[int64]string {
+	names := make(map[int64]string)
+	for _, pet := range testPets() {
+		names[pet.Category.ID] = pet.Category.Name
+	}
+
+	return names
+}
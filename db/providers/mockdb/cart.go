@@ -0,0 +1,100 @@
+package mockdb
+
+import (
+	"context"
+	"sync"
+
+	"github.com/IamStubborN/petstore/db/models"
+	"github.com/pkg/errors"
+)
+
+type carts struct {
+	sync.Mutex
+	items map[int64]models.CartItemList
+}
+
+func (d *Database) GetCart(ctx context.Context, userID int64) (*models.Cart, error) {
+	d.carts.Lock()
+	defer d.carts.Unlock()
+
+	return d.cartOf(userID), nil
+}
+
+func (d *Database) AddCartItem(ctx context.Context, userID int64, item *models.CartItem) (*models.Cart, error) {
+	d.carts.Lock()
+	defer d.carts.Unlock()
+
+	d.stock.Lock()
+	pet, ok := d.stock.pets[item.PetID]
+	d.stock.Unlock()
+	if !ok {
+		return nil, errors.Wrapf(models.ErrNotFound, "pet № %d doesn't exist", item.PetID)
+	}
+
+	for _, added := range d.carts.items[userID] {
+		if added.PetID == item.PetID {
+			added.Quantity += item.Quantity
+			return d.cartOf(userID), nil
+		}
+	}
+
+	d.carts.items[userID] = append(d.carts.items[userID], &models.CartItem{
+		PetID:    pet.ID,
+		PetName:  pet.Name,
+		Price:    pet.Category.Price,
+		Quantity: item.Quantity,
+	})
+
+	return d.cartOf(userID), nil
+}
+
+func (d *Database) RemoveCartItem(ctx context.Context, userID, petID int64) error {
+	d.carts.Lock()
+	defer d.carts.Unlock()
+
+	items := d.carts.items[userID]
+	for idx, item := range items {
+		if item.PetID == petID {
+			d.carts.items[userID] = append(items[:idx], items[idx+1:]...)
+			return nil
+		}
+	}
+
+	return errors.Wrapf(models.ErrNotFound, "pet № %d isn't in the cart", petID)
+}
+
+func (d *Database) CheckoutCart(ctx context.Context, userID int64, coupons []string) (*models.Order, error) {
+	d.carts.Lock()
+	defer d.carts.Unlock()
+
+	cart := d.cartOf(userID)
+	if len(cart.Items) == 0 {
+		return nil, errors.Wrapf(models.ErrConflict, "cart of user № %d is empty", userID)
+	}
+
+	order := &models.Order{
+		ID:      1,
+		UserID:  userID,
+		Status:  models.OrderStatusPlaced,
+		Items:   cart.OrderItems(),
+		Coupons: coupons,
+	}
+	if err := d.placeOrder(order); err != nil {
+		return nil, err
+	}
+
+	delete(d.carts.items, userID)
+
+	return order, nil
+}
+
+// cartOf copies the user cart, carts must be locked.
+func (d *Database) cartOf(userID int64) *models.Cart {
+	cart := models.Cart{UserID: userID, Items: models.CartItemList{}}
+	for _, item := range d.carts.items[userID] {
+		added := *item
+		cart.Items = append(cart.Items, &added)
+	}
+
+	return &cart
+}
@@ -0,0 +1,54 @@
+package mockdb
+
+import (
+	"context"
+
+	"github.com/IamStubborN/petstore/db/models"
+	"github.com/pkg/errors"
+)
+
+const testCategoryName = "Parrot"
+
+func (d *Database) CreateCategory(ctx context.Context, category *models.Category) (*models.Category, error) {
+	if category.Name != testCategoryName {
+		return nil, errors.Wrapf(models.ErrConflict, "category %s already exists", category.Name)
+	}
+
+	category.ID = 6
+
+	return category, nil
+}
+
+func (d *Database) GetCategories(ctx context.Context) (models.CategoryList, error) {
+	return models.CategoryList{
+		{ID: 1, Name: "Cat", Price: 35.00},
+		{ID: 2, Name: "Dog", Price: 49.99},
+	}, nil
+}
+
+func (d *Database) GetCategoryByID(ctx context.Context, id int64) (*models.Category, error) {
+	if id != 1 {
+		return nil, errors.Wrapf(models.ErrNotFound, "category № %d doesn't exist", id)
+	}
+
+	return &models.Category{ID: 1, Name: "Cat", Price: 35.00}, nil
+}
+
+func (d *Database) UpdateCategory(ctx context.Context, category *models.Category) error {
+	if category.ID != 1 {
+		return errors.Wrapf(models.ErrNotFound, "category № %d doesn't exist", category.ID)
+	}
+
+	return nil
+}
+
+func (d *Database) DeleteCategory(ctx context.Context, id int64) error {
+	switch id {
+	case 1, 2:
+		return errors.Wrapf(models.ErrConflict, "category № %d is used by pets", id)
+	case 6:
+		return nil
+	default:
+		return errors.Wrapf(models.ErrNotFound, "category № %d doesn't exist", id)
+	}
+}
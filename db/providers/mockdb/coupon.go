@@ -0,0 +1,126 @@
+package mockdb
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/IamStubborN/petstore/db/models"
+	"github.com/pkg/errors"
+)
+
+type couponUse struct {
+	couponID int64
+	userID   int64
+}
+
+type coupons struct {
+	sync.Mutex
+	list models.CouponList
+	uses []couponUse
+}
+
+func (d *Database) CreateCoupon(ctx context.Context, coupon *models.Coupon) (*models.Coupon, error) {
+	d.coupons.Lock()
+	defer d.coupons.Unlock()
+
+	var lastID int64
+	for _, stored := range d.coupons.list {
+		if stored.Code == coupon.Code {
+			return nil, errors.Wrapf(models.ErrConflict, "coupon %s already exists", coupon.Code)
+		}
+		lastID = stored.ID
+	}
+
+	if coupon.ValidFrom.IsZero() {
+		coupon.ValidFrom = time.Now().UTC()
+	}
+
+	created := *coupon
+	created.ID = lastID + 1
+	d.coupons.list = append(d.coupons.list, &created)
+
+	return &created, nil
+}
+
+func (d *Database) GetCoupons(ctx context.Context) (models.CouponList, error) {
+	d.coupons.Lock()
+	defer d.coupons.Unlock()
+
+	coupons := make(models.CouponList, 0, len(d.coupons.list))
+	for _, coupon := range d.coupons.list {
+		stored := *coupon
+		coupons = append(coupons, &stored)
+	}
+
+	return coupons, nil
+}
+
+func (d *Database) DeleteCoupon(ctx context.Context, id int64) error {
+	d.coupons.Lock()
+	defer d.coupons.Unlock()
+
+	for idx, coupon := range d.coupons.list {
+		if coupon.ID == id {
+			d.coupons.list = append(d.coupons.list[:idx], d.coupons.list[idx+1:]...)
+			return nil
+		}
+	}
+
+	return errors.Wrapf(models.ErrNotFound, "coupon № %d doesn't exist", id)
+}
+
+// find returns the coupons with the codes when they can be used
+// together by the user now, coupons must be locked.
+func (c *coupons) find(codes []string, userID int64) ([]*models.Coupon, error) {
+	var found []*models.Coupon
+	for _, code := range codes {
+		var coupon *models.Coupon
+		for _, stored := range c.list {
+			if stored.Code == code {
+				coupon = stored
+			}
+		}
+
+		if coupon == nil {
+			return nil, errors.Wrapf(models.ErrNotFound, "coupon %s doesn't exist", code)
+		}
+
+		found = append(found, coupon)
+	}
+
+	if err := models.CheckCoupons(found, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+
+	for _, coupon := range found {
+		var uses, userUses int32
+		for _, use := range c.uses {
+			if use.couponID == coupon.ID {
+				uses++
+				if use.userID == userID {
+					userUses++
+				}
+			}
+		}
+
+		if err := models.CheckCouponUses(coupon, uses, userUses); err != nil {
+			return nil, err
+		}
+	}
+
+	return found, nil
+}
+
+func testCoupons() models.CouponList {
+	since := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	expired := time.Date(2019, 12, 31, 0, 0, 0, 0, time.UTC)
+
+	return models.CouponList{
+		{ID: 1, Code: "SPRING10", Kind: models.CouponKindPercent, Value: 10, ValidFrom: since, Stackable: true},
+		{ID: 2, Code: "DOGS5", Kind: models.CouponKindFixed, Value: 5, CategoryID: 2, ValidFrom: since,
+			MaxUsesPerUser: 1, Stackable: true},
+		{ID: 3, Code: "SOLO20", Kind: models.CouponKindPercent, Value: 20, ValidFrom: since},
+		{ID: 4, Code: "WINTER50", Kind: models.CouponKindPercent, Value: 50, ValidFrom: since, ValidTo: &expired},
+	}
+}
@@ -1,12 +1,59 @@
 package mockdb
 
-import "github.com/IamStubborN/petstore/config"
+import (
+	"sync"
 
+	"github.com/IamStubborN/petstore/config"
+	"github.com/IamStubborN/petstore/db/models"
+	"github.com/IamStubborN/petstore/eventbus"
+)
+
+// Database answers with fixed test data, only order placement
+// changes the pets stock, so reservations can be tested, the last
+// status change of the test orders is remembered for their captures,
+// carts, coupons, payments, refunds, idempotency keys, webhooks, invoice
+// files and the outbox events of changes are kept in memory, the events are
+// streamed on an in-process bus.
 type Database struct {
+	stock           *stock
+	orders          *orders
+	carts           *carts
+	coupons         *coupons
+	payments        *payments
+	refunds         *refunds
+	idempotencyKeys *idempotencyKeys
+	outbox          *outbox
+	webhooks        *webhooks
+	invoiceFiles    *invoiceFiles
+}
+
+type stock struct {
+	sync.Mutex
+	pets map[int64]*models.Pet
 }
 
 func (d Database) InitDatabase(cfg config.DB) *Database {
-	return &Database{}
+	pets := make(map[int64]*models.Pet)
+	for _, pet := range testPets() {
+		pets[pet.ID] = pet
+	}
+
+	webhooks := &webhooks{}
+
+	database := &Database{
+		stock:           &stock{pets: pets},
+		orders:          &orders{statuses: make(map[int64]string)},
+		carts:           &carts{items: make(map[int64]models.CartItemList)},
+		coupons:         &coupons{list: testCoupons()},
+		payments:        &payments{},
+		refunds:         &refunds{},
+		idempotencyKeys: &idempotencyKeys{keys: make(map[idempotencyKeyID]models.IdempotencyKey)},
+		webhooks:        webhooks,
+		invoiceFiles:    &invoiceFiles{},
+	}
+	database.outbox = &outbox{webhooks: webhooks, bus: eventbus.NewBus(database.GetInventories)}
+
+	return database
 }
 
 func (d *Database) Close() error {
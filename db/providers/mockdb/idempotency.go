@@ -0,0 +1,53 @@
+package mockdb
+
+import (
+	"context"
+	"sync"
+
+	"github.com/IamStubborN/petstore/db/models"
+)
+
+type idempotencyKeyID struct {
+	userID int64
+	key    string
+}
+
+type idempotencyKeys struct {
+	sync.Mutex
+	keys map[idempotencyKeyID]models.IdempotencyKey
+}
+
+func (d *Database) ReserveIdempotencyKey(ctx context.Context, key *models.IdempotencyKey) (*models.IdempotencyKey, bool, error) {
+	d.idempotencyKeys.Lock()
+	defer d.idempotencyKeys.Unlock()
+
+	id := idempotencyKeyID{userID: key.UserID, key: key.Key}
+	if stored, ok := d.idempotencyKeys.keys[id]; ok {
+		return &stored, false, nil
+	}
+
+	d.idempotencyKeys.keys[id] = *key
+
+	return key, true, nil
+}
+
+func (d *Database) SaveIdempotentResponse(ctx context.Context, key *models.IdempotencyKey) error {
+	d.idempotencyKeys.Lock()
+	defer d.idempotencyKeys.Unlock()
+
+	d.idempotencyKeys.keys[idempotencyKeyID{userID: key.UserID, key: key.Key}] = *key
+
+	return nil
+}
+
+func (d *Database) ReleaseIdempotencyKey(ctx context.Context, userID int64, key string) error {
+	d.idempotencyKeys.Lock()
+	defer d.idempotencyKeys.Unlock()
+
+	id := idempotencyKeyID{userID: userID, key: key}
+	if stored, ok := d.idempotencyKeys.keys[id]; ok && !stored.IsCompleted() {
+		delete(d.idempotencyKeys.keys, id)
+	}
+
+	return nil
+}
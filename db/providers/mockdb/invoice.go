@@ -0,0 +1,101 @@
+package mockdb
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/IamStubborN/petstore/db/models"
+	"github.com/pkg/errors"
+)
+
+type invoiceFiles struct {
+	sync.Mutex
+	seq      int64
+	list     models.InvoiceFileList
+	periodTo time.Time
+}
+
+func (d *Database) AddInvoiceFile(ctx context.Context, file *models.InvoiceFile) (*models.InvoiceFile, error) {
+	d.invoiceFiles.Lock()
+	defer d.invoiceFiles.Unlock()
+
+	for _, stored := range d.invoiceFiles.list {
+		if stored.ObjectKey == file.ObjectKey {
+			return nil, errors.Wrapf(models.ErrConflict, "invoice file %s is recorded already", file.ObjectKey)
+		}
+	}
+
+	d.invoiceFiles.seq++
+	file.Number = d.invoiceFiles.seq
+	file.CreatedAt = time.Now().UTC()
+
+	stored := *file
+	d.invoiceFiles.list = append(d.invoiceFiles.list, &stored)
+
+	return file, nil
+}
+
+func (d *Database) GetInvoiceFiles(ctx context.Context, limit int) (models.InvoiceFileList, error) {
+	d.invoiceFiles.Lock()
+	defer d.invoiceFiles.Unlock()
+
+	files := models.InvoiceFileList{}
+	for i := len(d.invoiceFiles.list) - 1; i >= 0 && len(files) < limit; i-- {
+		stored := *d.invoiceFiles.list[i]
+		files = append(files, &stored)
+	}
+
+	return files, nil
+}
+
+func (d *Database) GetInvoiceFile(ctx context.Context, number int64) (*models.InvoiceFile, error) {
+	d.invoiceFiles.Lock()
+	defer d.invoiceFiles.Unlock()
+
+	for _, file := range d.invoiceFiles.list {
+		if file.Number == number {
+			stored := *file
+			return &stored, nil
+		}
+	}
+
+	return nil, errors.Wrapf(models.ErrNotFound, "invoice № %d doesn't exist", number)
+}
+
+func (d *Database) GetPeriodInvoiceFiles(ctx context.Context, from, to time.Time) (models.InvoiceFileList, error) {
+	d.invoiceFiles.Lock()
+	defer d.invoiceFiles.Unlock()
+
+	files := models.InvoiceFileList{}
+	for _, file := range d.invoiceFiles.list {
+		if file.From.Equal(from) && file.To.Equal(to) {
+			stored := *file
+			files = append(files, &stored)
+		}
+	}
+
+	return files, nil
+}
+
+func (d *Database) GetInvoicePeriod(ctx context.Context) (time.Time, error) {
+	d.invoiceFiles.Lock()
+	defer d.invoiceFiles.Unlock()
+
+	if d.invoiceFiles.periodTo.IsZero() {
+		return time.Time{}, errors.Wrap(models.ErrNotFound, "no invoice period is completed")
+	}
+
+	return d.invoiceFiles.periodTo, nil
+}
+
+func (d *Database) CompleteInvoicePeriod(ctx context.Context, to time.Time) error {
+	d.invoiceFiles.Lock()
+	defer d.invoiceFiles.Unlock()
+
+	if to.After(d.invoiceFiles.periodTo) {
+		d.invoiceFiles.periodTo = to.UTC()
+	}
+
+	return nil
+}
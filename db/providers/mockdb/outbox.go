@@ -0,0 +1,148 @@
+package mockdb
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/IamStubborN/petstore/db/models"
+	"github.com/IamStubborN/petstore/eventbus"
+	"github.com/mailru/easyjson"
+	"go.uber.org/zap"
+)
+
+// outbox keeps the events of the test data changes until they are published,
+// events are queued for the subscribed webhooks and streamed on the bus
+// when they are added. The history keeps all the events for the streams
+// resumed after an event.
+type outbox struct {
+	sync.Mutex
+	seq      int64
+	events   models.EventList
+	history  models.EventList
+	webhooks *webhooks
+	bus      *eventbus.Bus
+}
+
+// PublishEvents claims up to limit due events and hands them to publish
+// without holding the outbox, published events are dropped and failed
+// events wait for their next attempt.
+func (d *Database) PublishEvents(ctx context.Context, limit int,
+	publish func(context.Context, *models.Event) error) (int, error) {
+	events := d.outbox.claim(limit)
+
+	published := make(map[int64]bool)
+	var count int
+	for _, event := range events {
+		if ctx.Err() != nil {
+			break
+		}
+		count++
+
+		if err := publish(ctx, event); err != nil {
+			d.outbox.Lock()
+			event.Fail(err, time.Now().UTC())
+			d.outbox.Unlock()
+			continue
+		}
+		published[event.ID] = true
+	}
+
+	d.outbox.save(events[count:], published)
+
+	return count, nil
+}
+
+// StreamEvents streams the events added from now on,
+// the events after the id after are replayed first.
+func (d *Database) StreamEvents(ctx context.Context, after int64) (<-chan *models.Event, error) {
+	return eventbus.Stream(ctx, d.outbox.bus, after, d.outbox.replay), nil
+}
+
+// add records the event of a change, the change is made already,
+// so an event that can't be marshaled is only logged.
+func (o *outbox) add(aggregate, key, eventType string, payload easyjson.Marshaler) {
+	event, err := models.NewEvent(aggregate, key, eventType, payload)
+	if err != nil {
+		zap.L().Error("can't add event to outbox", zap.Error(err))
+		return
+	}
+
+	o.Lock()
+	defer o.Unlock()
+
+	o.seq++
+	event.ID = o.seq
+	event.CreatedAt = time.Now().UTC()
+	event.NextAttemptAt = event.CreatedAt
+	o.events = append(o.events, event)
+	o.history = append(o.history, event)
+
+	o.webhooks.fanOut(event)
+	o.bus.Publish(context.Background(), event)
+}
+
+// claim leases up to limit due events for EventLease.
+func (o *outbox) claim(limit int) models.EventList {
+	o.Lock()
+	defer o.Unlock()
+
+	now := time.Now().UTC()
+	events := models.EventList{}
+	for _, event := range o.events {
+		if len(events) == limit {
+			break
+		}
+		if event.NextAttemptAt.After(now) {
+			continue
+		}
+
+		event.NextAttemptAt = now.Add(models.EventLease)
+		events = append(events, event)
+	}
+
+	return events
+}
+
+// save drops the published events and releases the unpublished ones.
+func (o *outbox) save(unpublished models.EventList, published map[int64]bool) {
+	o.Lock()
+	defer o.Unlock()
+
+	now := time.Now().UTC()
+	for _, event := range unpublished {
+		event.NextAttemptAt = now
+	}
+
+	pending := models.EventList{}
+	for _, event := range o.events {
+		if !published[event.ID] {
+			pending = append(pending, event)
+		}
+	}
+
+	o.events = pending
+}
+
+// replay returns up to limit events of the history after the id after.
+func (o *outbox) replay(ctx context.Context, after int64, limit int) (models.EventList, error) {
+	o.Lock()
+	defer o.Unlock()
+
+	events := models.EventList{}
+	for _, event := range o.history {
+		if len(events) == limit {
+			break
+		}
+		if event.ID > after {
+			events = append(events, event)
+		}
+	}
+
+	return events, nil
+}
+
+func keyOf(id int64) string {
+	return strconv.FormatInt(id, 10)
+}
@@ -0,0 +1,107 @@
+package mockdb
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/IamStubborN/petstore/db/models"
+	"github.com/pkg/errors"
+)
+
+type payments struct {
+	sync.Mutex
+	list models.PaymentList
+}
+
+func (d *Database) CreatePayment(ctx context.Context, payment *models.Payment) (*models.Payment, error) {
+	d.payments.Lock()
+	defer d.payments.Unlock()
+
+	if !isTestOrder(payment.OrderID) {
+		return nil, errors.Wrapf(models.ErrNotFound, "order № %d doesn't exist", payment.OrderID)
+	}
+
+	for _, stored := range d.payments.list {
+		if stored.OrderID == payment.OrderID && isPaymentActive(stored.Status) {
+			return nil, errors.Wrapf(models.ErrConflict, "order № %d is already paid", payment.OrderID)
+		}
+	}
+
+	created := *payment
+	created.ID = int64(len(d.payments.list) + 1)
+	created.CreatedAt = time.Now().UTC()
+	created.UpdatedAt = created.CreatedAt
+	d.payments.list = append(d.payments.list, &created)
+
+	*payment = created
+
+	return payment, nil
+}
+
+func (d *Database) GetPayments(ctx context.Context, orderID int64) (models.PaymentList, error) {
+	d.payments.Lock()
+	defer d.payments.Unlock()
+
+	payments := models.PaymentList{}
+	for _, payment := range d.payments.list {
+		if payment.OrderID == orderID {
+			stored := *payment
+			payments = append(payments, &stored)
+		}
+	}
+
+	return payments, nil
+}
+
+func (d *Database) UpdatePayment(ctx context.Context, payment *models.Payment) error {
+	d.payments.Lock()
+	defer d.payments.Unlock()
+
+	for _, stored := range d.payments.list {
+		if stored.ID != payment.ID {
+			continue
+		}
+
+		if err := models.CheckPaymentStatusTransition(stored.Status, payment.Status); err != nil {
+			return err
+		}
+
+		stored.Status = payment.Status
+		stored.Reference = payment.Reference
+		stored.Reason = payment.Reason
+		stored.UpdatedAt = time.Now().UTC()
+		payment.UpdatedAt = stored.UpdatedAt
+
+		orderStatus := d.orders.status(stored.OrderID)
+		if payment.Status != models.PaymentStatusCaptured || orderStatus == models.OrderStatusPlaced {
+			return nil
+		}
+
+		d.refunds.reserve(stored, "order is "+orderStatus+" before the capture")
+
+		return errors.Wrapf(models.ErrConflict, "order № %d is %s, its payment is refunded",
+			stored.OrderID, orderStatus)
+	}
+
+	return errors.Wrapf(models.ErrNotFound, "payment № %d doesn't exist", payment.ID)
+}
+
+func (p *payments) isCaptured(orderID int64) bool {
+	p.Lock()
+	defer p.Unlock()
+
+	for _, payment := range p.list {
+		if payment.OrderID == orderID && payment.Status == models.PaymentStatusCaptured {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isPaymentActive(status string) bool {
+	return status == models.PaymentStatusPending ||
+		status == models.PaymentStatusAuthorized ||
+		status == models.PaymentStatusCaptured
+}
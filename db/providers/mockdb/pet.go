@@ -2,76 +2,149 @@ package mockdb
 
 import (
 	"context"
-	"errors"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/IamStubborN/petstore/db/models"
+	"github.com/pkg/errors"
 )
 
 const testPetName = "TestPet"
 
-func (d *Database) AddPetToStore(ctx context.Context, pet *models.Pet) (*models.Pet, error) {
-	var err error
+func (d *Database) AddPetToStore(ctx context.Context, pet *models.Pet, userID int64) (*models.Pet, error) {
 	if pet.Name != testPetName {
-		err = errors.New("invalid pet")
+		return pet, errors.New("invalid pet")
 	}
 
-	return pet, err
+	d.outbox.add(models.AggregatePet, keyOf(pet.ID), models.EventPetCreated, pet)
+
+	return pet, nil
 }
 
-func (d *Database) UpdatePetInStoreByBody(ctx context.Context, pet *models.Pet) (*models.Pet, error) {
+func (d *Database) UpdatePetInStoreByBody(ctx context.Context, pet *models.Pet, userID int64) (*models.Pet, error) {
 	if pet.ID != 1 {
 		return nil, errors.New("bad input")
 	}
 
+	if err := d.checkPetStatus(pet.ID, pet.Status); err != nil {
+		return nil, err
+	}
+
+	d.changePetStatus(pet.ID, pet.Status, userID)
+	d.outbox.add(models.AggregatePet, keyOf(pet.ID), models.EventPetUpdated, pet)
+
 	return pet, nil
 }
 
-func (d *Database) UpdatePetInStoreByForm(ctx context.Context, id int64, name string, status string) error {
-	var err error
-	if id != 1 || name != testPetName || status != "pending" {
-		err = errors.New("bad input")
+func (d *Database) UpdatePetInStoreByForm(ctx context.Context, id int64, name string, status string, userID int64) error {
+	if id != 1 || name != testPetName {
+		return errors.New("bad input")
 	}
 
-	return err
+	if err := d.checkPetStatus(id, status); err != nil {
+		return err
+	}
+
+	d.changePetStatus(id, status, userID)
+	d.outbox.add(models.AggregatePet, keyOf(id), models.EventPetUpdated,
+		&models.PetPayload{ID: id, Name: name, Status: status})
+
+	return nil
 }
 
-func (d *Database) FindPetsByStatus(ctx context.Context, status []string) (*models.PetList, error) {
-	if strings.Contains(strings.Join(status, " "), "available") {
-		return &models.PetList{
-			{
-				ID: 1,
-				Category: models.Category{
-					ID:   1,
-					Name: "Cat",
-				},
-				Name:      "Soo",
-				PhotoURLs: []string{"1", "2", "3"},
-				Tags: []models.Tag{
-					{ID: 1, Name: "small"},
-					{ID: 4, Name: "best"},
-					{ID: 5, Name: "cool"},
-				},
-				Status: "available",
-			},
-			{
-				ID: 2,
-				Category: models.Category{
-					ID:   2,
-					Name: "Dog",
-				},
-				Name:      "Sylar",
-				PhotoURLs: []string{"1", "2", "3"},
-				Tags: []models.Tag{
-					{ID: 1, Name: "small"},
-					{ID: 2, Name: "average"},
-					{ID: 3, Name: "large"},
-				},
-				Status: "available",
-			},
-		}, nil
+// checkPetStatus checks the status change of the test pet like the postgres
+// provider does, pets of the stock are pending only while an order holds them.
+func (d *Database) checkPetStatus(id int64, status string) error {
+	d.stock.Lock()
+	defer d.stock.Unlock()
+
+	current := models.PetStatusAvailable
+	if pet, ok := d.stock.pets[id]; ok {
+		current = pet.Status
+	}
+
+	if err := models.CheckPetStatusTransition(current, status); err != nil {
+		return err
+	}
+
+	if current == models.PetStatusPending && status != models.PetStatusPending {
+		return errors.Wrapf(models.ErrConflict, "pet № %d is held by an order", id)
+	}
+
+	return nil
+}
+
+// changePetStatus records the status change of the test pet,
+// test pets are available, so only other statuses change it.
+func (d *Database) changePetStatus(id int64, status string, userID int64) {
+	if status == models.PetStatusAvailable {
+		return
+	}
+
+	d.outbox.add(models.AggregatePet, keyOf(id), models.EventPetStatusChanged, &models.PetStatusChange{
+		PetID:      id,
+		FromStatus: models.PetStatusAvailable,
+		ToStatus:   status,
+		UserID:     userID,
+		ChangedAt:  time.Now().UTC(),
+	})
+}
+
+func (d *Database) FindPetsByStatus(ctx context.Context, filter models.PetFilter) (*models.PetList, string, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, "", err
+	}
+
+	after, err := models.DecodeCursor(filter.After)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var matched models.PetList
+	for _, pet := range testPets() {
+		if isPetMatched(pet, filter) {
+			matched = append(matched, pet)
+		}
+	}
+
+	sort.Slice(matched, func(i, j int) bool {
+		return isCursorBefore(filter.SortBy, filter.CursorOf(matched[i]), filter.CursorOf(matched[j]))
+	})
+
+	pets := models.PetList{}
+	for _, pet := range matched {
+		if after.ID == 0 || isCursorBefore(filter.SortBy, after, filter.CursorOf(pet)) {
+			pets = append(pets, pet)
+		}
+	}
+
+	var next string
+	if len(pets) > filter.Limit {
+		pets = pets[:filter.Limit]
+		next = filter.CursorOf(pets[len(pets)-1]).Encode()
+	}
+
+	return &pets, next, nil
+}
+
+func (d *Database) FindPetsByTags(ctx context.Context, tags []string, matchAll bool) (*models.PetList, error) {
+	pets := models.PetList{}
+	for _, pet := range testPets() {
+		var matches int
+		for _, req := range tags {
+			if hasTag(pet, req) {
+				matches++
+			}
+		}
+
+		if (matchAll && matches == len(tags)) || (!matchAll && matches > 0) {
+			pets = append(pets, pet)
+		}
 	}
-	return nil, errors.New("can't find status")
+
+	return &pets, nil
 }
 
 func (d *Database) GetPetByID(ctx context.Context, id int64) (*models.Pet, error) {
@@ -96,19 +169,118 @@ func (d *Database) GetPetByID(ctx context.Context, id int64) (*models.Pet, error
 }
 
 func (d *Database) DeletePetByID(ctx context.Context, id int64) error {
-	var err error
 	if id != 1 {
-		err = errors.New("invalid pet id")
+		return errors.New("invalid pet id")
 	}
 
-	return err
+	d.outbox.add(models.AggregatePet, keyOf(id), models.EventPetDeleted, &models.PetPayload{ID: id})
+
+	return nil
 }
 
-func (d *Database) UpdatePetPhotosByID(ctx context.Context, id int64, imagesURL []string) error {
-	var err error
+func (d *Database) AddPetPhotosByID(ctx context.Context, id int64, imagesURL []string) error {
 	if id != 1 && imagesURL != nil {
-		err = errors.New("invalid pet id or imagesURLs")
+		return errors.New("invalid pet id or imagesURLs")
+	}
+
+	d.outbox.add(models.AggregatePet, keyOf(id), models.EventPetUpdated,
+		&models.PetPayload{ID: id, PhotoURLs: imagesURL})
+
+	return nil
+}
+
+func (d *Database) GetPetStatusHistory(ctx context.Context, id int64) (models.PetStatusHistory, error) {
+	if id != 1 {
+		return nil, errors.Wrapf(models.ErrNotFound, "pet № %d doesn't exist", id)
+	}
+
+	changedAt := time.Date(2019, 8, 17, 20, 30, 0, 0, time.UTC)
+
+	return models.PetStatusHistory{
+		{ID: 1, PetID: 1, ToStatus: models.PetStatusAvailable, UserID: 1, ChangedAt: changedAt},
+		{ID: 2, PetID: 1, FromStatus: models.PetStatusAvailable, ToStatus: models.PetStatusPending,
+			UserID: 2, ChangedAt: changedAt.Add(time.Hour)},
+		{ID: 3, PetID: 1, FromStatus: models.PetStatusPending, ToStatus: models.PetStatusAvailable,
+			UserID: 1, ChangedAt: changedAt.Add(2 * time.Hour)},
+	}, nil
+}
+
+func isPetMatched(pet *models.Pet, filter models.PetFilter) bool {
+	var isStatusMatched bool
+	for _, status := range filter.Status {
+		if pet.Status == status {
+			isStatusMatched = true
+		}
 	}
 
-	return err
+	return isStatusMatched &&
+		(filter.Category == "" || pet.Category.Name == filter.Category) &&
+		strings.HasPrefix(pet.Name, filter.NamePrefix) &&
+		(filter.Tag == "" || hasTag(pet, filter.Tag))
+}
+
+func isCursorBefore(sortBy string, a, b models.Cursor) bool {
+	switch sortBy {
+	case models.PetSortByName:
+		if a.Key != b.Key {
+			return a.Key < b.Key
+		}
+	case models.PetSortByPrice:
+		aPrice, _ := strconv.ParseFloat(a.Key, 64)
+		bPrice, _ := strconv.ParseFloat(b.Key, 64)
+		if aPrice != bPrice {
+			return aPrice < bPrice
+		}
+	}
+
+	return a.ID < b.ID
+}
+
+func hasTag(pet *models.Pet, tag string) bool {
+	for _, t := range pet.Tags {
+		if t.Name == tag || strconv.FormatInt(t.ID, 10) == tag {
+			return true
+		}
+	}
+
+	return false
+}
+
+func testPets() models.PetList {
+	return models.PetList{
+		{
+			ID: 1,
+			Category: models.Category{
+				ID:    1,
+				Name:  "Cat",
+				Price: 35.00,
+			},
+			Name:      "Soo",
+			PhotoURLs: []string{"1", "2", "3"},
+			Tags: []models.Tag{
+				{ID: 1, Name: "small"},
+				{ID: 4, Name: "best"},
+				{ID: 5, Name: "cool"},
+			},
+			Status:   "available",
+			Quantity: 1,
+		},
+		{
+			ID: 2,
+			Category: models.Category{
+				ID:    2,
+				Name:  "Dog",
+				Price: 49.99,
+			},
+			Name:      "Sylar",
+			PhotoURLs: []string{"1", "2", "3"},
+			Tags: []models.Tag{
+				{ID: 1, Name: "small"},
+				{ID: 2, Name: "average"},
+				{ID: 3, Name: "large"},
+			},
+			Status:   "available",
+			Quantity: 12,
+		},
+	}
 }
@@ -0,0 +1,156 @@
+package mockdb
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/IamStubborN/petstore/db/models"
+	"github.com/pkg/errors"
+)
+
+type refunds struct {
+	sync.Mutex
+	list models.RefundList
+}
+
+func (d *Database) ReserveRefund(ctx context.Context, refund *models.Refund) (*models.Refund, error) {
+	d.payments.Lock()
+	defer d.payments.Unlock()
+	d.refunds.Lock()
+	defer d.refunds.Unlock()
+
+	var payment *models.Payment
+	for _, stored := range d.payments.list {
+		if stored.ID == refund.PaymentID && stored.OrderID == refund.OrderID {
+			payment = stored
+		}
+	}
+
+	if payment == nil {
+		return nil, errors.Wrapf(models.ErrNotFound, "payment № %d of order № %d doesn't exist",
+			refund.PaymentID, refund.OrderID)
+	}
+
+	refunded := d.refunds.list.Refunded(payment.ID)
+	if err := models.CheckRefund(payment, refunded, refund.Amount); err != nil {
+		return nil, err
+	}
+
+	created := *refund
+	created.ID = int64(len(d.refunds.list) + 1)
+	created.CreatedAt = time.Now().UTC()
+	created.Status = models.RefundStatusPending
+	created.Attempts = 0
+	created.LastError = ""
+	created.NextAttemptAt = created.CreatedAt.Add(models.RefundLease)
+	created.CompletedAt = nil
+	created.Provider = payment.Provider
+	created.Reference = payment.Reference
+	d.refunds.list = append(d.refunds.list, &created)
+
+	*refund = created
+
+	return refund, nil
+}
+
+func (d *Database) SaveRefund(ctx context.Context, refund *models.Refund) error {
+	d.payments.Lock()
+	defer d.payments.Unlock()
+	d.refunds.Lock()
+	defer d.refunds.Unlock()
+
+	var stored *models.Refund
+	for _, r := range d.refunds.list {
+		if r.ID == refund.ID {
+			stored = r
+		}
+	}
+
+	if stored == nil || stored.Status != models.RefundStatusPending {
+		return errors.Wrapf(models.ErrConflict, "refund № %d isn't pending", refund.ID)
+	}
+
+	stored.Status = refund.Status
+	stored.Attempts = refund.Attempts
+	stored.LastError = refund.LastError
+	stored.NextAttemptAt = refund.NextAttemptAt
+	stored.CompletedAt = refund.CompletedAt
+
+	if stored.Status != models.RefundStatusCompleted {
+		return nil
+	}
+
+	var completed float64
+	for _, r := range d.refunds.list {
+		if r.PaymentID == stored.PaymentID && r.Status == models.RefundStatusCompleted {
+			completed += r.Amount
+		}
+	}
+
+	for _, payment := range d.payments.list {
+		if payment.ID == stored.PaymentID && payment.Status == models.PaymentStatusCaptured &&
+			models.RoundPrice(completed) >= payment.Amount {
+			payment.Status = models.PaymentStatusRefunded
+			payment.UpdatedAt = time.Now().UTC()
+		}
+	}
+
+	return nil
+}
+
+func (d *Database) ClaimRefunds(ctx context.Context, limit int) (models.RefundList, error) {
+	d.refunds.Lock()
+	defer d.refunds.Unlock()
+
+	now := time.Now().UTC()
+	refunds := models.RefundList{}
+	for _, refund := range d.refunds.list {
+		if len(refunds) == limit {
+			break
+		}
+
+		if refund.Status == models.RefundStatusPending && !refund.NextAttemptAt.After(now) {
+			refund.NextAttemptAt = now.Add(models.RefundLease)
+			claimed := *refund
+			refunds = append(refunds, &claimed)
+		}
+	}
+
+	return refunds, nil
+}
+
+func (d *Database) GetRefunds(ctx context.Context, orderID int64) (models.RefundList, error) {
+	d.refunds.Lock()
+	defer d.refunds.Unlock()
+
+	refunds := models.RefundList{}
+	for _, refund := range d.refunds.list {
+		if refund.OrderID == orderID {
+			stored := *refund
+			refunds = append(refunds, &stored)
+		}
+	}
+
+	return refunds, nil
+}
+
+// reserve returns the whole captured payment, the refund worker sends it soon.
+func (r *refunds) reserve(payment *models.Payment, reason string) {
+	r.Lock()
+	defer r.Unlock()
+
+	created := &models.Refund{
+		ID:        int64(len(r.list) + 1),
+		OrderID:   payment.OrderID,
+		PaymentID: payment.ID,
+		Amount:    payment.Amount,
+		Reason:    reason,
+		CreatedAt: time.Now().UTC(),
+		Status:    models.RefundStatusPending,
+		Provider:  payment.Provider,
+		Reference: payment.Reference,
+	}
+	created.NextAttemptAt = created.CreatedAt
+	r.list = append(r.list, created)
+}
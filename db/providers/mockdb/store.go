@@ -2,11 +2,19 @@ package mockdb
 
 import (
 	"context"
-	"errors"
+	"sort"
+	"sync"
+	"time"
 
 	"github.com/IamStubborN/petstore/db/models"
+	"github.com/pkg/errors"
 )
 
+type orders struct {
+	sync.Mutex
+	statuses map[int64]string
+}
+
 func (d *Database) GetInventories(ctx context.Context) (map[string]int64, error) {
 	return map[string]int64{
 		"available": 353,
@@ -15,62 +23,391 @@ func (d *Database) GetInventories(ctx context.Context) (map[string]int64, error)
 	}, nil
 }
 
+// GetInventory breaks the test orders and the pets in stock down
+// like the postgres query does.
+func (d *Database) GetInventory(ctx context.Context, filter models.InventoryFilter) (models.Inventory, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	ordered := make(map[int64]int64)
+	orderFilter := models.OrderFilter{ShipFrom: filter.ShipFrom, ShipTo: filter.ShipTo}
+	for _, order := range testOrders() {
+		if order.Status == models.OrderStatusCancelled || !isOrderMatched(order, orderFilter) {
+			continue
+		}
+
+		for _, item := range order.Items {
+			ordered[item.PetID] += int64(item.Quantity)
+		}
+	}
+
+	d.stock.Lock()
+	defer d.stock.Unlock()
+
+	inventory := models.Inventory{}
+	byKey := make(map[models.InventoryItem]*models.InventoryItem)
+	for _, pet := range d.stock.pets {
+		for _, key := range inventoryKeys(pet, filter) {
+			if _, ok := byKey[key]; !ok {
+				item := key
+				byKey[key] = &item
+				inventory = append(inventory, &item)
+			}
+
+			byKey[key].Ordered += ordered[pet.ID]
+			byKey[key].OnHand += int64(pet.Quantity)
+		}
+	}
+
+	sort.Slice(inventory, func(i, j int) bool {
+		a, b := inventory[i], inventory[j]
+		if a.Category != b.Category {
+			return a.Category < b.Category
+		}
+		if a.Status != b.Status {
+			return a.Status < b.Status
+		}
+		return a.Tag < b.Tag
+	})
+
+	return inventory, nil
+}
+
+// inventoryKeys returns the inventory lines the pet is counted in,
+// one per tag when the inventory is grouped by tag.
+func inventoryKeys(pet *models.Pet, filter models.InventoryFilter) []models.InventoryItem {
+	var key models.InventoryItem
+	if filter.IsGroupedBy(models.InventoryGroupCategory) {
+		key.Category = pet.Category.Name
+	}
+	if filter.IsGroupedBy(models.InventoryGroupStatus) {
+		key.Status = pet.Status
+	}
+
+	if !filter.IsGroupedBy(models.InventoryGroupTag) || len(pet.Tags) == 0 {
+		return []models.InventoryItem{key}
+	}
+
+	keys := make([]models.InventoryItem, 0, len(pet.Tags))
+	for _, tag := range pet.Tags {
+		key.Tag = tag.Name
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
 func (d *Database) CreateOrder(ctx context.Context, order *models.Order) (*models.Order, error) {
 	if order.ID != 1 {
 		return nil, errors.New("bad order input")
 	}
 
+	if err := d.placeOrder(order); err != nil {
+		return nil, err
+	}
+
 	return order, nil
 }
 
+// placeOrder reserves the order items and prices them with the order coupons.
+func (d *Database) placeOrder(order *models.Order) error {
+	if err := order.NormalizeItems(); err != nil {
+		return err
+	}
+
+	d.coupons.Lock()
+	defer d.coupons.Unlock()
+
+	coupons, err := d.coupons.find(order.Coupons, order.UserID)
+	if err != nil {
+		return err
+	}
+
+	d.stock.Lock()
+	defer d.stock.Unlock()
+
+	for _, item := range order.Items {
+		if pet, ok := d.stock.pets[item.PetID]; ok {
+			item.CategoryID = pet.Category.ID
+			item.Price = pet.Category.Price
+		}
+	}
+
+	if err = order.ApplyCoupons(coupons); err != nil {
+		return err
+	}
+
+	if err = d.stock.reserve(order.Items); err != nil {
+		return err
+	}
+
+	for _, coupon := range coupons {
+		d.coupons.uses = append(d.coupons.uses, couponUse{couponID: coupon.ID, userID: order.UserID})
+	}
+
+	d.outbox.add(models.AggregateOrder, keyOf(order.ID), models.EventOrderCreated, order)
+
+	return nil
+}
+
+// reserve takes the quantity of all the items from the stock,
+// nothing is taken when any item can't be reserved.
+func (s *stock) reserve(items models.OrderItemList) error {
+	for _, item := range items {
+		pet, ok := s.pets[item.PetID]
+		if !ok {
+			return errors.Wrapf(models.ErrNotFound, "pet № %d doesn't exist", item.PetID)
+		}
+
+		if pet.Status != models.PetStatusAvailable {
+			return errors.Wrapf(models.ErrConflict, "pet № %d is %s", pet.ID, pet.Status)
+		}
+
+		if pet.Quantity < item.Quantity {
+			return errors.Wrapf(models.ErrConflict, "pet № %d has only %d left", pet.ID, pet.Quantity)
+		}
+	}
+
+	for _, item := range items {
+		pet := s.pets[item.PetID]
+		pet.Quantity -= item.Quantity
+		pet.Status = models.PetStatusPending
+	}
+
+	return nil
+}
+
 func (d *Database) FindOrderByID(ctx context.Context, orderID int64) (*models.Order, error) {
 	if orderID != 1 {
 		return nil, errors.New("bad orderID")
 	}
 
-	return &models.Order{
-		ID:       1,
-		PetID:    1,
-		UserID:   1,
-		Quantity: 12,
-		ShipDate: "2019-09-05T15:35:12",
-		Status:   "placed",
-		Complete: false,
-	}, nil
+	return testOrder(), nil
 }
 
-func (d *Database) DeleteOrderByID(ctx context.Context, orderID int64) error {
-	var err error
-	if orderID != 1 {
-		err = errors.New("bad orderID")
+func (d *Database) FindOrders(ctx context.Context, filter models.OrderFilter) (*models.OrderList, string, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, "", err
+	}
+
+	after, err := models.DecodeCursor(filter.After)
+	if err != nil {
+		return nil, "", err
+	}
+
+	orders := models.OrderList{}
+	for _, order := range testOrders() {
+		if order.ID > after.ID && isOrderMatched(order, filter) {
+			orders = append(orders, order)
+		}
+	}
+
+	var next string
+	if len(orders) > filter.Limit {
+		orders = orders[:filter.Limit]
+		next = filter.CursorOf(orders[len(orders)-1]).Encode()
+	}
+
+	return &orders, next, nil
+}
+
+// DeleteOrderByID cancels the placed test order, the order isn't hidden.
+func (d *Database) DeleteOrderByID(ctx context.Context, orderID, userID int64) (*models.Order, error) {
+	order, err := d.changeOrderStatus(orderID, models.OrderStatusCancelled)
+	if err != nil {
+		return nil, err
+	}
+
+	d.outbox.add(models.AggregateOrder, keyOf(orderID), models.EventOrderDeleted, order)
+
+	return order, nil
+}
+
+func (d *Database) ApproveOrder(ctx context.Context, orderID, userID int64) (*models.Order, error) {
+	if isTestOrder(orderID) && !d.payments.isCaptured(orderID) {
+		return nil, errors.Wrapf(models.ErrConflict, "order № %d isn't paid", orderID)
 	}
 
-	return err
+	return d.changeOrderStatus(orderID, models.OrderStatusApproved)
+}
+
+func (d *Database) DeliverOrder(ctx context.Context, orderID, userID int64) (*models.Order, error) {
+	return d.changeOrderStatus(orderID, models.OrderStatusDelivered)
 }
 
+func (d *Database) CancelOrder(ctx context.Context, orderID, userID int64) (*models.Order, error) {
+	return d.changeOrderStatus(orderID, models.OrderStatusCancelled)
+}
+
+// CreateInvoiceByDates returns the test invoice lines shipped
+// from the RFC3339 time from up to, but not including, to.
 func (d *Database) CreateInvoiceByDates(ctx context.Context, from, to string) ([]*models.InvoiceItem, error) {
-	if from != "2019-09-07" || to != "2019-09-08" {
-		return nil, errors.New("bad dates inputs")
+	fromTime, err := time.Parse(time.RFC3339, from)
+	if err != nil {
+		return nil, errors.Wrap(err, "bad dates inputs")
+	}
+
+	toTime, err := time.Parse(time.RFC3339, to)
+	if err != nil {
+		return nil, errors.Wrap(err, "bad dates inputs")
 	}
 
+	var invoices []*models.InvoiceItem
+	for _, item := range testInvoiceItems() {
+		shipDate, err := time.Parse(time.RFC3339, item.ShipDate)
+		if err == nil && !shipDate.Before(fromTime) && shipDate.Before(toTime) {
+			invoices = append(invoices, item)
+		}
+	}
+
+	if len(invoices) == 0 {
+		return nil, errors.Wrap(models.ErrNotFound, "no data with this dates")
+	}
+
+	return invoices, nil
+}
+
+func testInvoiceItems() []*models.InvoiceItem {
 	return []*models.InvoiceItem{
 		{
 			ID:       1,
+			Line:     1,
 			User:     "Jack The Ripper",
 			Pet:      "John Snow",
 			Category: "Cat",
-			ShipDate: "2019-09-07T15:35:04",
+			ShipDate: "2019-09-07T15:35:04Z",
 			Quantity: 15,
 			Price:    35.00,
 		},
 		{
 			ID:       2,
+			Line:     1,
 			User:     "Ginger",
 			Pet:      "Phil Heat",
 			Category: "Dog",
-			ShipDate: "2019-09-08T15:35:04",
+			ShipDate: "2019-09-08T15:35:04Z",
 			Quantity: 34,
 			Price:    49.99,
 		},
-	}, nil
+	}
+}
+
+func (d *Database) changeOrderStatus(orderID int64, status string) (*models.Order, error) {
+	if orderID != 1 {
+		return nil, errors.Wrapf(models.ErrNotFound, "order № %d doesn't exist", orderID)
+	}
+
+	order := testOrder()
+	if err := models.CheckOrderStatusTransition(order.Status, status); err != nil {
+		return nil, err
+	}
+
+	order.Status = status
+	order.Complete = status == models.OrderStatusDelivered
+	d.orders.set(orderID, status)
+
+	d.outbox.add(models.AggregateOrder, keyOf(orderID), models.OrderStatusEvent(status), order)
+
+	return order, nil
+}
+
+func isOrderMatched(order *models.Order, filter models.OrderFilter) bool {
+	shipDate, err := time.Parse("2006-01-02T15:04:05", order.ShipDate)
+	if err != nil {
+		return false
+	}
+
+	return (filter.UserID == 0 || order.UserID == filter.UserID) &&
+		(filter.PetID == 0 || hasOrderPet(order, filter.PetID)) &&
+		(filter.Status == "" || order.Status == filter.Status) &&
+		(filter.Complete == nil || order.Complete == *filter.Complete) &&
+		(filter.ShipFrom.IsZero() || !shipDate.Before(filter.ShipFrom)) &&
+		(filter.ShipTo.IsZero() || !shipDate.After(filter.ShipTo))
+}
+
+func hasOrderPet(order *models.Order, petID int64) bool {
+	for _, item := range order.Items {
+		if item.PetID == petID {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isTestOrder(orderID int64) bool {
+	for _, order := range testOrders() {
+		if order.ID == orderID {
+			return true
+		}
+	}
+
+	return false
+}
+
+func testOrder() *models.Order {
+	return testOrders()[0]
+}
+
+func testOrders() models.OrderList {
+	orders := models.OrderList{
+		{
+			ID:       1,
+			UserID:   1,
+			ShipDate: "2019-09-05T15:35:12",
+			Status:   "placed",
+			Complete: false,
+		},
+		{
+			ID:       2,
+			UserID:   2,
+			ShipDate: "2019-09-06T10:12:40",
+			Status:   "approved",
+			Complete: false,
+		},
+		{
+			ID:       3,
+			UserID:   1,
+			ShipDate: "2019-09-07T15:35:04",
+			Status:   "delivered",
+			Complete: true,
+		},
+	}
+
+	orders[0].Gross = 420.00
+	orders[1].Gross = 49.99
+	orders[2].Gross = 134.98
+
+	orders[0].SetItems(models.OrderItemList{
+		{ID: 1, OrderID: 1, PetID: 1, PetName: "Soo", CategoryID: 1, Quantity: 12, Price: 35.00},
+	})
+	orders[1].SetItems(models.OrderItemList{
+		{ID: 2, OrderID: 2, PetID: 2, PetName: "Sylar", CategoryID: 2, Quantity: 1, Price: 49.99},
+	})
+	orders[2].SetItems(models.OrderItemList{
+		{ID: 3, OrderID: 3, PetID: 1, PetName: "Soo", CategoryID: 1, Quantity: 1, Price: 35.00},
+		{ID: 4, OrderID: 3, PetID: 2, PetName: "Sylar", CategoryID: 2, Quantity: 2, Price: 49.99},
+	})
+
+	return orders
+}
+
+func (o *orders) set(orderID int64, status string) {
+	o.Lock()
+	defer o.Unlock()
+
+	o.statuses[orderID] = status
+}
+
+// status is the last status the test order is moved to, placed by default.
+func (o *orders) status(orderID int64) string {
+	o.Lock()
+	defer o.Unlock()
+
+	if status, ok := o.statuses[orderID]; ok {
+		return status
+	}
+
+	return models.OrderStatusPlaced
 }
@@ -0,0 +1,49 @@
+package mockdb
+
+import (
+	"context"
+
+	"github.com/IamStubborN/petstore/db/models"
+	"github.com/pkg/errors"
+)
+
+const testTagName = "fluffy"
+
+func (d *Database) CreateTag(ctx context.Context, tag *models.Tag) (*models.Tag, error) {
+	if tag.Name != testTagName {
+		return nil, errors.Wrapf(models.ErrConflict, "tag %s already exists", tag.Name)
+	}
+
+	tag.ID = 6
+
+	return tag, nil
+}
+
+func (d *Database) GetTags(ctx context.Context) (models.TagList, error) {
+	return models.TagList{
+		{ID: 1, Name: "small"},
+		{ID: 2, Name: "average"},
+		{ID: 3, Name: "large"},
+		{ID: 4, Name: "best"},
+		{ID: 5, Name: "cool"},
+	}, nil
+}
+
+func (d *Database) RenameTag(ctx context.Context, id int64, name string) error {
+	if id != 6 {
+		return errors.Wrapf(models.ErrNotFound, "tag № %d doesn't exist", id)
+	}
+
+	return nil
+}
+
+func (d *Database) DeleteTag(ctx context.Context, id int64) error {
+	switch {
+	case id >= 1 && id <= 5:
+		return errors.Wrapf(models.ErrConflict, "tag № %d is used by pets", id)
+	case id == 6:
+		return nil
+	default:
+		return errors.Wrapf(models.ErrNotFound, "tag № %d doesn't exist", id)
+	}
+}
@@ -2,18 +2,24 @@ package mockdb
 
 import (
 	"context"
-	"errors"
 
 	"github.com/IamStubborN/petstore/db/models"
+	"github.com/pkg/errors"
 )
 
 const testUserName = "admin"
 
 func (d *Database) CreateUser(ctx context.Context, user *models.User) error {
+	d.outbox.add(models.AggregateUser, user.Username, models.EventUserCreated, models.NewUserPayload(user))
+
 	return nil
 }
 
 func (d *Database) CreateUsersFromList(ctx context.Context, list *models.UserList) error {
+	for _, user := range *list {
+		d.outbox.add(models.AggregateUser, user.Username, models.EventUserCreated, models.NewUserPayload(user))
+	}
+
 	return nil
 }
 
@@ -39,19 +45,34 @@ func (d *Database) GetUserByName(ctx context.Context, username string) (*models.
 }
 
 func (d *Database) UpdateUser(ctx context.Context, user *models.User) error {
-	var err error
 	if user.Username != testUserName {
-		err = errors.New("invalid user")
+		return errors.New("invalid user")
+	}
+
+	d.outbox.add(models.AggregateUser, user.Username, models.EventUserUpdated, models.NewUserPayload(user))
+
+	return nil
+}
+
+func (d *Database) SetUserStatus(ctx context.Context, username string, status int64) (*models.User, error) {
+	user, err := d.GetUserByName(ctx, username)
+	if err != nil {
+		return nil, errors.Wrapf(models.ErrNotFound, "user %s doesn't exist", username)
 	}
 
-	return err
+	user.Password = ""
+	user.UserStatus = status
+	d.outbox.add(models.AggregateUser, user.Username, models.EventUserUpdated, models.NewUserPayload(user))
+
+	return user, nil
 }
 
 func (d *Database) DeleteUser(ctx context.Context, username string) error {
-	var err error
 	if username != testUserName {
-		err = errors.New("invalid user")
+		return errors.New("invalid user")
 	}
 
-	return err
+	d.outbox.add(models.AggregateUser, username, models.EventUserDeleted, &models.UserPayload{Username: username})
+
+	return nil
 }
@@ -0,0 +1,274 @@
+package mockdb
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/IamStubborN/petstore/db/models"
+	"github.com/pkg/errors"
+)
+
+type webhooks struct {
+	sync.Mutex
+	seq         int64
+	deliverySeq int64
+	list        models.WebhookList
+	deliveries  models.WebhookDeliveryList
+}
+
+func (d *Database) CreateWebhook(ctx context.Context, webhook *models.Webhook) (*models.Webhook, error) {
+	d.webhooks.Lock()
+	defer d.webhooks.Unlock()
+
+	d.webhooks.seq++
+	created := *webhook
+	created.ID = d.webhooks.seq
+	created.Active = true
+	created.Failures = 0
+	created.CreatedAt = time.Now().UTC()
+	d.webhooks.list = append(d.webhooks.list, &created)
+
+	*webhook = created
+	webhook.Secret = ""
+
+	return webhook, nil
+}
+
+func (d *Database) GetWebhooks(ctx context.Context) (models.WebhookList, error) {
+	d.webhooks.Lock()
+	defer d.webhooks.Unlock()
+
+	webhooks := models.WebhookList{}
+	for _, webhook := range d.webhooks.list {
+		stored := *webhook
+		stored.Secret = ""
+		webhooks = append(webhooks, &stored)
+	}
+
+	return webhooks, nil
+}
+
+func (d *Database) DeleteWebhook(ctx context.Context, id int64) error {
+	d.webhooks.Lock()
+	defer d.webhooks.Unlock()
+
+	if d.webhooks.find(id) == nil {
+		return errors.Wrapf(models.ErrNotFound, "webhook № %d doesn't exist", id)
+	}
+
+	webhooks := models.WebhookList{}
+	for _, webhook := range d.webhooks.list {
+		if webhook.ID != id {
+			webhooks = append(webhooks, webhook)
+		}
+	}
+
+	deliveries := models.WebhookDeliveryList{}
+	for _, delivery := range d.webhooks.deliveries {
+		if delivery.WebhookID != id {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+
+	d.webhooks.list = webhooks
+	d.webhooks.deliveries = deliveries
+
+	return nil
+}
+
+func (d *Database) EnableWebhook(ctx context.Context, id int64) (*models.Webhook, error) {
+	d.webhooks.Lock()
+	defer d.webhooks.Unlock()
+
+	webhook := d.webhooks.find(id)
+	if webhook == nil {
+		return nil, errors.Wrapf(models.ErrNotFound, "webhook № %d doesn't exist", id)
+	}
+
+	webhook.Active = true
+	webhook.Failures = 0
+	webhook.DisabledAt = nil
+
+	enabled := *webhook
+	enabled.Secret = ""
+
+	return &enabled, nil
+}
+
+func (d *Database) GetWebhookDeliveries(ctx context.Context, webhookID int64, limit int) (models.WebhookDeliveryList, error) {
+	d.webhooks.Lock()
+	defer d.webhooks.Unlock()
+
+	if d.webhooks.find(webhookID) == nil {
+		return nil, errors.Wrapf(models.ErrNotFound, "webhook № %d doesn't exist", webhookID)
+	}
+
+	deliveries := models.WebhookDeliveryList{}
+	for i := len(d.webhooks.deliveries) - 1; i >= 0 && len(deliveries) < limit; i-- {
+		if delivery := d.webhooks.deliveries[i]; delivery.WebhookID == webhookID {
+			stored := *delivery
+			deliveries = append(deliveries, &stored)
+		}
+	}
+
+	return deliveries, nil
+}
+
+// DeliverWebhooks claims the due deliveries of active webhooks, hands
+// copies of them to deliver without holding the webhooks and counts
+// the failures like the postgres provider does.
+func (d *Database) DeliverWebhooks(ctx context.Context, policy models.WebhookPolicy,
+	deliver func(context.Context, *models.WebhookDelivery) error) (int, error) {
+	claimed := d.webhooks.claim(policy.BatchSize)
+
+	var count int
+	for i, delivery := range claimed {
+		if ctx.Err() != nil {
+			d.webhooks.release(claimed[i:])
+			break
+		}
+
+		if !d.webhooks.isActive(delivery.WebhookID) {
+			d.webhooks.release(claimed[i : i+1])
+			continue
+		}
+		count++
+
+		sent := *delivery
+		d.webhooks.save(delivery, &sent, policy, deliver(ctx, &sent))
+	}
+
+	return count, nil
+}
+
+// claim leases up to limit due deliveries of active webhooks for WebhookLease.
+func (w *webhooks) claim(limit int) models.WebhookDeliveryList {
+	w.Lock()
+	defer w.Unlock()
+
+	now := time.Now().UTC()
+	due := models.WebhookDeliveryList{}
+	for _, delivery := range w.deliveries {
+		webhook := w.find(delivery.WebhookID)
+		if delivery.Status == models.WebhookDeliveryPending && webhook != nil &&
+			webhook.Active && !delivery.NextAttemptAt.After(now) {
+			due = append(due, delivery)
+		}
+	}
+
+	sort.SliceStable(due, func(i, j int) bool {
+		return due[i].NextAttemptAt.Before(due[j].NextAttemptAt)
+	})
+
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	for _, delivery := range due {
+		webhook := w.find(delivery.WebhookID)
+		delivery.URL = webhook.URL
+		delivery.Secret = webhook.Secret
+		delivery.NextAttemptAt = now.Add(models.WebhookLease)
+	}
+
+	return due
+}
+
+// isActive tells whether the webhook is still delivered to.
+func (w *webhooks) isActive(id int64) bool {
+	w.Lock()
+	defer w.Unlock()
+
+	webhook := w.find(id)
+
+	return webhook != nil && webhook.Active
+}
+
+// save copies the sent delivery back with its outcome and counts it
+// for its webhook, the deliveries of a deleted webhook are gone already.
+func (w *webhooks) save(delivery, sent *models.WebhookDelivery, policy models.WebhookPolicy, err error) {
+	w.Lock()
+	defer w.Unlock()
+
+	now := time.Now().UTC()
+	webhook := w.find(delivery.WebhookID)
+	if webhook == nil {
+		return
+	}
+
+	if err != nil {
+		sent.Fail(err, now, policy.MaxAttempts)
+
+		webhook.Failures++
+		if webhook.Failures >= policy.MaxFailures {
+			webhook.Active = false
+			webhook.DisabledAt = &now
+		}
+	} else {
+		sent.Succeed(now)
+		webhook.Failures = 0
+	}
+
+	*delivery = *sent
+}
+
+// release ends the lease of the deliveries left undelivered.
+func (w *webhooks) release(deliveries models.WebhookDeliveryList) {
+	w.Lock()
+	defer w.Unlock()
+
+	now := time.Now().UTC()
+	for _, delivery := range deliveries {
+		delivery.NextAttemptAt = now
+	}
+}
+
+// fanOut queues the event for the active webhooks subscribed to it.
+func (w *webhooks) fanOut(event *models.Event) {
+	w.Lock()
+	defer w.Unlock()
+
+	for _, webhook := range w.list {
+		if !webhook.Active || !isSubscribed(webhook, event.Type) {
+			continue
+		}
+
+		w.deliverySeq++
+		w.deliveries = append(w.deliveries, &models.WebhookDelivery{
+			ID:             w.deliverySeq,
+			WebhookID:      webhook.ID,
+			EventID:        event.ID,
+			Event:          event.Type,
+			Status:         models.WebhookDeliveryPending,
+			CreatedAt:      event.CreatedAt,
+			NextAttemptAt:  event.CreatedAt,
+			Aggregate:      event.Aggregate,
+			Key:            event.Key,
+			Payload:        event.Payload,
+			EventCreatedAt: event.CreatedAt,
+		})
+	}
+}
+
+// find returns the stored webhook, webhooks must be locked.
+func (w *webhooks) find(id int64) *models.Webhook {
+	for _, webhook := range w.list {
+		if webhook.ID == id {
+			return webhook
+		}
+	}
+
+	return nil
+}
+
+func isSubscribed(webhook *models.Webhook, eventType string) bool {
+	for _, subscribed := range webhook.Events {
+		if subscribed == eventType {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,59 @@
+package psql
+
+import (
+	"context"
+
+	"github.com/IamStubborN/petstore/db/models"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+)
+
+// GetSales sums the units and revenue of the sold pets
+// per category and period.
+func (d *Database) GetSales(ctx context.Context, filter models.AnalyticsFilter) (models.CategorySalesList, error) {
+	sales := models.CategorySalesList{}
+	err := d.pool.SelectContext(ctx, &sales, qm[analyticsSalesQ],
+		filter.From, filter.To, filter.Period, pq.Array(models.SalesStatuses))
+	if err != nil {
+		return nil, errors.Wrap(err, "can't get sales")
+	}
+
+	return sales, nil
+}
+
+// GetTopCategories ranks the categories by revenue.
+func (d *Database) GetTopCategories(ctx context.Context, filter models.AnalyticsFilter) (models.CategorySalesList, error) {
+	sales := models.CategorySalesList{}
+	err := d.pool.SelectContext(ctx, &sales, qm[analyticsTopCategoriesQ],
+		filter.From, filter.To, pq.Array(models.SalesStatuses), filter.Limit)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't get top categories")
+	}
+
+	return sales, nil
+}
+
+func (d *Database) GetOrderValue(ctx context.Context, filter models.AnalyticsFilter) (*models.OrderValue, error) {
+	var value models.OrderValue
+	err := d.pool.GetContext(ctx, &value, qm[analyticsOrderValueQ],
+		filter.From, filter.To, pq.Array(models.SalesStatuses))
+	if err != nil {
+		return nil, errors.Wrap(err, "can't get order value")
+	}
+
+	value.SetAverage()
+
+	return &value, nil
+}
+
+// GetUserValues ranks the customers by the revenue of their orders.
+func (d *Database) GetUserValues(ctx context.Context, filter models.AnalyticsFilter) (models.UserValueList, error) {
+	values := models.UserValueList{}
+	err := d.pool.SelectContext(ctx, &values, qm[analyticsUserValuesQ],
+		filter.From, filter.To, pq.Array(models.SalesStatuses), filter.Limit)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't get user values")
+	}
+
+	return values, nil
+}
@@ -0,0 +1,214 @@
+package psql
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/IamStubborN/petstore/db/models"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+func TestDatabase_GetSales(t *testing.T) {
+	pool, mock := generateMockedDB()
+	defer checkError(pool.Close)
+
+	filter := models.AnalyticsFilter{
+		From:   time.Date(2019, 9, 1, 0, 0, 0, 0, time.UTC),
+		To:     time.Date(2019, 9, 30, 0, 0, 0, 0, time.UTC),
+		Period: models.AnalyticsPeriodWeek,
+	}
+
+	type fields struct {
+		pool *sqlx.DB
+	}
+	type args struct {
+		ctx    context.Context
+		filter models.AnalyticsFilter
+		mockFn func()
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    models.CategorySalesList
+		wantErr bool
+	}{
+		{
+			name:   "Success",
+			fields: fields{pool: pool},
+			args: args{
+				ctx:    context.Background(),
+				filter: filter,
+				mockFn: func() {
+					mock.ExpectQuery(`with refunded as (.+) select to_char\(date_trunc(.+) from "order" o (.+) left join refunded r (.+) group by 1, 2, 3`).
+						WithArgs(filter.From, filter.To, "week", pq.Array(models.SalesStatuses)).
+						WillReturnRows(sqlmock.NewRows([]string{"period", "category_id", "category", "units", "revenue"}).
+							AddRow("2019-09-02", 1, "Cat", 1, 35.00).
+							AddRow("2019-09-02", 2, "Dog", 3, 149.97))
+				},
+			},
+			want: models.CategorySalesList{
+				{Period: "2019-09-02", CategoryID: 1, Category: "Cat", Units: 1, Revenue: 35.00},
+				{Period: "2019-09-02", CategoryID: 2, Category: "Dog", Units: 3, Revenue: 149.97},
+			},
+			wantErr: false,
+		},
+		{
+			name:   "Failure",
+			fields: fields{pool: pool},
+			args: args{
+				ctx:    context.Background(),
+				filter: filter,
+				mockFn: func() {
+					mock.ExpectQuery(`select to_char\(date_trunc(.+) from "order" o`).
+						WillReturnError(errors.New("connection lost"))
+				},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Database{
+				pool: tt.fields.pool,
+			}
+
+			tt.args.mockFn()
+
+			got, err := d.GetSales(tt.args.ctx, tt.args.filter)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetSales() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetSales() got = %v, want %v", got, tt.want)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func TestDatabase_GetOrderValue(t *testing.T) {
+	pool, mock := generateMockedDB()
+	defer checkError(pool.Close)
+
+	filter := models.AnalyticsFilter{
+		From: time.Date(2019, 9, 1, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2019, 9, 30, 0, 0, 0, 0, time.UTC),
+	}
+
+	type fields struct {
+		pool *sqlx.DB
+	}
+	type args struct {
+		ctx    context.Context
+		filter models.AnalyticsFilter
+		mockFn func()
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    *models.OrderValue
+		wantErr bool
+	}{
+		{
+			name:   "Success",
+			fields: fields{pool: pool},
+			args: args{
+				ctx:    context.Background(),
+				filter: filter,
+				mockFn: func() {
+					mock.ExpectQuery(`select count\(o.id\) as orders(.+) from "order" o (.+) left join refunded r`).
+						WithArgs(filter.From, filter.To, pq.Array(models.SalesStatuses)).
+						WillReturnRows(sqlmock.NewRows([]string{"orders", "revenue"}).AddRow(3, 100.00))
+				},
+			},
+			want:    &models.OrderValue{Orders: 3, Revenue: 100, Average: 33.33},
+			wantErr: false,
+		},
+		{
+			name:   "Success without orders",
+			fields: fields{pool: pool},
+			args: args{
+				ctx:    context.Background(),
+				filter: filter,
+				mockFn: func() {
+					mock.ExpectQuery(`select count\(o.id\) as orders(.+) from "order" o`).
+						WithArgs(filter.From, filter.To, pq.Array(models.SalesStatuses)).
+						WillReturnRows(sqlmock.NewRows([]string{"orders", "revenue"}).AddRow(0, 0))
+				},
+			},
+			want:    &models.OrderValue{},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Database{
+				pool: tt.fields.pool,
+			}
+
+			tt.args.mockFn()
+
+			got, err := d.GetOrderValue(tt.args.ctx, tt.args.filter)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetOrderValue() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetOrderValue() got = %v, want %v", got, tt.want)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func TestDatabase_GetUserValues(t *testing.T) {
+	pool, mock := generateMockedDB()
+	defer checkError(pool.Close)
+
+	filter := models.AnalyticsFilter{
+		From:  time.Date(2019, 9, 1, 0, 0, 0, 0, time.UTC),
+		To:    time.Date(2019, 9, 30, 0, 0, 0, 0, time.UTC),
+		Limit: 5,
+	}
+
+	mock.ExpectQuery(`select o.user_id, u.user_name(.+) left join refunded r (.+) group by o.user_id, u.user_name (.+) limit`).
+		WithArgs(filter.From, filter.To, pq.Array(models.SalesStatuses), 5).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "user_name", "orders", "revenue"}).
+			AddRow(1, "admin", 2, 554.98).
+			AddRow(2, "customer", 1, 49.99))
+
+	d := &Database{pool: pool}
+	got, err := d.GetUserValues(context.Background(), filter)
+	if err != nil {
+		t.Fatalf("GetUserValues() error = %v", err)
+	}
+
+	want := models.UserValueList{
+		{UserID: 1, UserName: "admin", Orders: 2, Revenue: 554.98},
+		{UserID: 2, UserName: "customer", Orders: 1, Revenue: 49.99},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GetUserValues() got = %v, want %v", got, want)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,94 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/IamStubborN/petstore/db/models"
+	"github.com/pkg/errors"
+)
+
+func (d *Database) GetCart(ctx context.Context, userID int64) (*models.Cart, error) {
+	cart := models.Cart{UserID: userID, Items: models.CartItemList{}}
+	if err := d.pool.SelectContext(ctx, &cart.Items, qm[cartGetQ], userID); err != nil {
+		return nil, errors.Wrap(err, "can't get data from cart_item")
+	}
+
+	return &cart, nil
+}
+
+// AddCartItem puts the pet to the cart, the quantity of a pet
+// which is already in the cart is increased.
+func (d *Database) AddCartItem(ctx context.Context, userID int64, item *models.CartItem) (*models.Cart, error) {
+	_, err := d.pool.ExecContext(ctx, qm[cartAddItemQ], userID, item.PetID, item.Quantity)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return nil, errors.Wrapf(models.ErrNotFound, "pet № %d doesn't exist", item.PetID)
+		}
+		return nil, errors.Wrap(err, "can't insert into cart_item")
+	}
+
+	return d.GetCart(ctx, userID)
+}
+
+func (d *Database) RemoveCartItem(ctx context.Context, userID, petID int64) error {
+	res, err := d.pool.ExecContext(ctx, qm[cartRemoveItemQ], userID, petID)
+	if err != nil {
+		return errors.Wrap(err, "can't delete from cart_item")
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return errors.Wrapf(models.ErrNotFound, "pet № %d isn't in the cart", petID)
+	}
+
+	return nil
+}
+
+// CheckoutCart places one order of all the cart items with the coupons and
+// empties the cart in one transaction, so the cart is kept when any pet
+// can't be reserved or any coupon can't be used.
+func (d *Database) CheckoutCart(ctx context.Context, userID int64, coupons []string) (*models.Order, error) {
+	tx, err := d.pool.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "can't start transaction")
+	}
+
+	defer func() {
+		if err != nil {
+			checkError(tx.Rollback)
+			return
+		}
+		checkError(tx.Commit)
+	}()
+
+	cart := models.Cart{UserID: userID}
+	if err = tx.SelectContext(ctx, &cart.Items, qm[cartLockQ], userID); err != nil {
+		return nil, errors.Wrap(err, "can't lock cart_item")
+	}
+
+	if len(cart.Items) == 0 {
+		err = errors.Wrapf(models.ErrConflict, "cart of user № %d is empty", userID)
+		return nil, err
+	}
+
+	order := &models.Order{
+		UserID:  userID,
+		Status:  models.OrderStatusPlaced,
+		Items:   cart.OrderItems(),
+		Coupons: coupons,
+	}
+	if err = createOrder(ctx, tx, order); err != nil {
+		return nil, err
+	}
+
+	if _, err = tx.ExecContext(ctx, qm[cartClearQ], userID); err != nil {
+		return nil, errors.Wrap(err, "can't delete from cart_item")
+	}
+
+	return order, nil
+}
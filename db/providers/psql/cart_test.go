@@ -0,0 +1,285 @@
+package psql
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/IamStubborN/petstore/db/models"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	pkgerrors "github.com/pkg/errors"
+)
+
+func TestDatabase_AddCartItem(t *testing.T) {
+	pool, mock := generateMockedDB()
+	defer checkError(pool.Close)
+
+	type fields struct {
+		pool *sqlx.DB
+	}
+	type args struct {
+		ctx    context.Context
+		userID int64
+		item   *models.CartItem
+		mockFn func()
+	}
+	tests := []struct {
+		name      string
+		fields    fields
+		args      args
+		want      *models.Cart
+		wantCause error
+		wantErr   bool
+	}{
+		{
+			name:   "Success 1",
+			fields: fields{pool: pool},
+			args: args{
+				ctx:    context.Background(),
+				userID: 7,
+				item:   &models.CartItem{PetID: 2, Quantity: 1},
+				mockFn: func() {
+					mock.ExpectExec(`insert into cart_item (.+) on conflict`).
+						WithArgs(7, 2, 1).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectQuery(`select (.+) from cart_item c (.+) where c.user_id=(.+)`).
+						WithArgs(7).
+						WillReturnRows(sqlmock.NewRows([]string{"pet_id", "pet_name", "price", "quantity"}).
+							AddRow(2, "Sylar", 49.99, 3))
+				},
+			},
+			want: &models.Cart{UserID: 7, Items: models.CartItemList{
+				{PetID: 2, PetName: "Sylar", Price: 49.99, Quantity: 3},
+			}},
+			wantErr: false,
+		},
+		{
+			name:   "Failure unknown pet",
+			fields: fields{pool: pool},
+			args: args{
+				ctx:    context.Background(),
+				userID: 7,
+				item:   &models.CartItem{PetID: 99, Quantity: 1},
+				mockFn: func() {
+					mock.ExpectExec(`insert into cart_item (.+) on conflict`).
+						WithArgs(7, 99, 1).
+						WillReturnError(&pq.Error{Code: "23503"})
+				},
+			},
+			want:      nil,
+			wantCause: models.ErrNotFound,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Database{
+				pool: tt.fields.pool,
+			}
+
+			tt.args.mockFn()
+
+			got, err := d.AddCartItem(tt.args.ctx, tt.args.userID, tt.args.item)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("AddCartItem() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.wantCause != nil && pkgerrors.Cause(err) != tt.wantCause {
+				t.Errorf("AddCartItem() error = %v, wantCause %v", err, tt.wantCause)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("AddCartItem() got = %v, want %v", got, tt.want)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func TestDatabase_CheckoutCart(t *testing.T) {
+	pool, mock := generateMockedDB()
+	defer checkError(pool.Close)
+
+	cartColumns := []string{"pet_id", "quantity"}
+	expectReserve := func(petID int64, quantity int32) {
+		mock.ExpectQuery(`select ps.name, p.quantity from pet p (.+) for update`).
+			WithArgs(petID).
+			WillReturnRows(sqlmock.NewRows([]string{"name", "quantity"}).AddRow("available", 12))
+		mock.ExpectExec(`update pet set quantity=quantity`).
+			WithArgs(petID, quantity).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(`select ps.name from pet p (.+) for update`).
+			WithArgs(petID).
+			WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("available"))
+		mock.ExpectExec(`update pet set pet_status_id`).
+			WithArgs(petID, "pending").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`insert into pet_status_history`).
+			WithArgs(petID, "available", "pending", 7).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		expectEvent(mock, "pet", strconv.FormatInt(petID, 10), "pet.status_changed")
+	}
+
+	type fields struct {
+		pool *sqlx.DB
+	}
+	type args struct {
+		ctx     context.Context
+		userID  int64
+		coupons []string
+		mockFn  func()
+	}
+	tests := []struct {
+		name      string
+		fields    fields
+		args      args
+		want      *models.Order
+		wantCause error
+		wantErr   bool
+	}{
+		{
+			name:   "Success 1",
+			fields: fields{pool: pool},
+			args: args{
+				ctx:    context.Background(),
+				userID: 7,
+				mockFn: func() {
+					mock.ExpectBegin()
+					mock.ExpectQuery(`select pet_id, quantity from cart_item (.+) for update`).
+						WithArgs(7).
+						WillReturnRows(sqlmock.NewRows(cartColumns).AddRow(1, 1).AddRow(2, 3))
+					mock.ExpectQuery(`select (.+) from order_status where name=(.+)`).
+						WithArgs("placed").
+						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+					expectReserve(1, 1)
+					expectReserve(2, 3)
+					mock.ExpectQuery(`select id, category_id, price from pet_info where id = any`).
+						WithArgs(pq.Array([]int64{1, 2})).
+						WillReturnRows(sqlmock.NewRows([]string{"id", "category_id", "price"}).
+							AddRow(1, 1, 35.00).AddRow(2, 2, 49.99))
+					mock.ExpectPrepare(`insert into "order" (.+) values (.+)`).ExpectQuery().
+						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(30))
+					mock.ExpectQuery(`insert into order_item (.+) returning id`).
+						WithArgs(30, 1, 1, 35.00, 0.0).
+						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(50))
+					mock.ExpectQuery(`insert into order_item (.+) returning id`).
+						WithArgs(30, 2, 3, 49.99, 0.0).
+						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(51))
+					expectEvent(mock, "order", "30", "order.created")
+					mock.ExpectExec(`delete from cart_item where user_id=(.+)`).
+						WithArgs(7).
+						WillReturnResult(sqlmock.NewResult(0, 2))
+					mock.ExpectCommit()
+				},
+			},
+			want: &models.Order{
+				ID:       30,
+				UserID:   7,
+				Quantity: 4,
+				Items: models.OrderItemList{
+					{ID: 50, OrderID: 30, PetID: 1, CategoryID: 1, Quantity: 1, Price: 35.00},
+					{ID: 51, OrderID: 30, PetID: 2, CategoryID: 2, Quantity: 3, Price: 49.99},
+				},
+				Gross:    184.97,
+				ShipDate: time.Now().UTC().Format(time.RFC3339),
+				Status:   "placed",
+			},
+			wantErr: false,
+		},
+		{
+			name:   "Failure empty cart",
+			fields: fields{pool: pool},
+			args: args{
+				ctx:    context.Background(),
+				userID: 7,
+				mockFn: func() {
+					mock.ExpectBegin()
+					mock.ExpectQuery(`select pet_id, quantity from cart_item (.+) for update`).
+						WithArgs(7).
+						WillReturnRows(sqlmock.NewRows(cartColumns))
+					mock.ExpectRollback()
+				},
+			},
+			want:      nil,
+			wantCause: models.ErrConflict,
+			wantErr:   true,
+		},
+		{
+			name:   "Failure pet is reserved",
+			fields: fields{pool: pool},
+			args: args{
+				ctx:    context.Background(),
+				userID: 7,
+				mockFn: func() {
+					mock.ExpectBegin()
+					mock.ExpectQuery(`select pet_id, quantity from cart_item (.+) for update`).
+						WithArgs(7).
+						WillReturnRows(sqlmock.NewRows(cartColumns).AddRow(1, 1))
+					mock.ExpectQuery(`select (.+) from order_status where name=(.+)`).
+						WithArgs("placed").
+						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+					mock.ExpectQuery(`select ps.name, p.quantity from pet p (.+) for update`).
+						WithArgs(1).
+						WillReturnRows(sqlmock.NewRows([]string{"name", "quantity"}).AddRow("pending", 1))
+					mock.ExpectRollback()
+				},
+			},
+			want:      nil,
+			wantCause: models.ErrConflict,
+			wantErr:   true,
+		},
+		{
+			name:   "Failure 1",
+			fields: fields{pool: pool},
+			args: args{
+				ctx:    context.Background(),
+				userID: 7,
+				mockFn: func() {
+					mock.ExpectBegin()
+					mock.ExpectQuery(`select pet_id, quantity from cart_item (.+) for update`).
+						WithArgs(7).
+						WillReturnError(errors.New("can't lock cart"))
+					mock.ExpectRollback()
+				},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Database{
+				pool: tt.fields.pool,
+			}
+
+			tt.args.mockFn()
+
+			got, err := d.CheckoutCart(tt.args.ctx, tt.args.userID, tt.args.coupons)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckoutCart() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.wantCause != nil && pkgerrors.Cause(err) != tt.wantCause {
+				t.Errorf("CheckoutCart() error = %v, wantCause %v", err, tt.wantCause)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CheckoutCart() got = %v, want %v", got, tt.want)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
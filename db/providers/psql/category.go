@@ -0,0 +1,113 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/IamStubborN/petstore/db/models"
+	"github.com/pkg/errors"
+)
+
+func (d *Database) CreateCategory(ctx context.Context, category *models.Category) (*models.Category, error) {
+	rows, err := d.pool.NamedQueryContext(ctx, qm[categoryCreateQ], category)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, errors.Wrapf(models.ErrConflict, "category %s already exists", category.Name)
+		}
+		return nil, errors.Wrap(err, "can't insert into category")
+	}
+	defer checkError(rows.Close)
+
+	for rows.Next() {
+		if err := rows.Scan(&category.ID); err != nil {
+			return nil, errors.Wrap(err, "can't scan id from category")
+		}
+	}
+
+	return category, nil
+}
+
+func (d *Database) GetCategories(ctx context.Context) (models.CategoryList, error) {
+	categories := models.CategoryList{}
+	if err := d.pool.SelectContext(ctx, &categories, qm[categoryGetAllQ]); err != nil {
+		return nil, errors.Wrap(err, "can't get data from category")
+	}
+
+	return categories, nil
+}
+
+func (d *Database) GetCategoryByID(ctx context.Context, id int64) (*models.Category, error) {
+	var category models.Category
+	err := d.pool.GetContext(ctx, &category, qm[categoryGetByIDQ], id)
+	if err == sql.ErrNoRows {
+		return nil, errors.Wrapf(models.ErrNotFound, "category № %d doesn't exist", id)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "can't get data from category")
+	}
+
+	return &category, nil
+}
+
+func (d *Database) UpdateCategory(ctx context.Context, category *models.Category) error {
+	res, err := d.pool.NamedExecContext(ctx, qm[categoryUpdateQ], category)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return errors.Wrapf(models.ErrConflict, "category %s already exists", category.Name)
+		}
+		return errors.Wrap(err, "can't update category")
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return errors.Wrapf(models.ErrNotFound, "category № %d doesn't exist", category.ID)
+	}
+
+	return nil
+}
+
+// DeleteCategory locks the category row first, so no pet can be
+// added to the category between the usage check and the delete.
+func (d *Database) DeleteCategory(ctx context.Context, id int64) error {
+	tx, err := d.pool.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return errors.Wrap(err, "can't start transaction")
+	}
+
+	defer func() {
+		if err != nil {
+			checkError(tx.Rollback)
+			return
+		}
+		checkError(tx.Commit)
+	}()
+
+	var categoryID int64
+	err = tx.GetContext(ctx, &categoryID, qm[categoryLockByIDQ], id)
+	if err == sql.ErrNoRows {
+		return errors.Wrapf(models.ErrNotFound, "category № %d doesn't exist", id)
+	}
+	if err != nil {
+		return errors.Wrap(err, "can't lock category")
+	}
+
+	var isUsed bool
+	if err = tx.GetContext(ctx, &isUsed, qm[categoryIsUsedQ], id); err != nil {
+		return errors.Wrap(err, "can't check category usage")
+	}
+
+	if isUsed {
+		err = errors.Wrapf(models.ErrConflict, "category № %d is used by pets", id)
+		return err
+	}
+
+	if _, err = tx.ExecContext(ctx, qm[categoryDeleteQ], id); err != nil {
+		return errors.Wrap(err, "can't delete from category")
+	}
+
+	return nil
+}
@@ -0,0 +1,428 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/IamStubborN/petstore/db/models"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	pkgerrors "github.com/pkg/errors"
+)
+
+func TestDatabase_CreateCategory(t *testing.T) {
+	pool, mock := generateMockedDB()
+	defer checkError(pool.Close)
+
+	type fields struct {
+		pool *sqlx.DB
+	}
+	type args struct {
+		ctx      context.Context
+		category *models.Category
+		mockFn   func()
+	}
+	tests := []struct {
+		name      string
+		fields    fields
+		args      args
+		want      *models.Category
+		wantCause error
+		wantErr   bool
+	}{
+		{
+			name:   "Success 1",
+			fields: fields{pool: pool},
+			args: args{
+				ctx:      context.Background(),
+				category: &models.Category{Name: "Parrot", Price: 15.50},
+				mockFn: func() {
+					mock.ExpectQuery(`insert into category (.+) returning id`).
+						WithArgs("Parrot", 15.50).
+						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(6))
+				},
+			},
+			want:    &models.Category{ID: 6, Name: "Parrot", Price: 15.50},
+			wantErr: false,
+		},
+		{
+			name:   "Failure duplicate",
+			fields: fields{pool: pool},
+			args: args{
+				ctx:      context.Background(),
+				category: &models.Category{Name: "Cat", Price: 35.00},
+				mockFn: func() {
+					mock.ExpectQuery(`insert into category (.+) returning id`).
+						WithArgs("Cat", 35.00).
+						WillReturnError(&pq.Error{Code: "23505"})
+				},
+			},
+			want:      nil,
+			wantCause: models.ErrConflict,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Database{
+				pool: tt.fields.pool,
+			}
+
+			tt.args.mockFn()
+
+			got, err := d.CreateCategory(tt.args.ctx, tt.args.category)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CreateCategory() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.wantCause != nil && pkgerrors.Cause(err) != tt.wantCause {
+				t.Errorf("CreateCategory() error = %v, wantCause %v", err, tt.wantCause)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CreateCategory() got = %v, want %v", got, tt.want)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func TestDatabase_GetCategories(t *testing.T) {
+	pool, mock := generateMockedDB()
+	defer checkError(pool.Close)
+
+	type fields struct {
+		pool *sqlx.DB
+	}
+	type args struct {
+		ctx    context.Context
+		mockFn func()
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    models.CategoryList
+		wantErr bool
+	}{
+		{
+			name:   "Success 1",
+			fields: fields{pool: pool},
+			args: args{
+				ctx: context.Background(),
+				mockFn: func() {
+					mock.ExpectQuery(`select id, name, price from category`).
+						WillReturnRows(sqlmock.NewRows([]string{"id", "name", "price"}).
+							AddRow(1, "Cat", 35.00).AddRow(2, "Dog", 49.99))
+				},
+			},
+			want: models.CategoryList{
+				{ID: 1, Name: "Cat", Price: 35.00},
+				{ID: 2, Name: "Dog", Price: 49.99},
+			},
+			wantErr: false,
+		},
+		{
+			name:   "Failure 1",
+			fields: fields{pool: pool},
+			args: args{
+				ctx: context.Background(),
+				mockFn: func() {
+					mock.ExpectQuery(`select id, name, price from category`).
+						WillReturnError(errors.New("can't get data from category"))
+				},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Database{
+				pool: tt.fields.pool,
+			}
+
+			tt.args.mockFn()
+
+			got, err := d.GetCategories(tt.args.ctx)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetCategories() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetCategories() got = %v, want %v", got, tt.want)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func TestDatabase_GetCategoryByID(t *testing.T) {
+	pool, mock := generateMockedDB()
+	defer checkError(pool.Close)
+
+	type fields struct {
+		pool *sqlx.DB
+	}
+	type args struct {
+		ctx    context.Context
+		id     int64
+		mockFn func()
+	}
+	tests := []struct {
+		name      string
+		fields    fields
+		args      args
+		want      *models.Category
+		wantCause error
+		wantErr   bool
+	}{
+		{
+			name:   "Success 1",
+			fields: fields{pool: pool},
+			args: args{
+				ctx: context.Background(),
+				id:  1,
+				mockFn: func() {
+					mock.ExpectQuery(`select id, name, price from category where id=.`).
+						WithArgs(1).
+						WillReturnRows(sqlmock.NewRows([]string{"id", "name", "price"}).
+							AddRow(1, "Cat", 35.00))
+				},
+			},
+			want:    &models.Category{ID: 1, Name: "Cat", Price: 35.00},
+			wantErr: false,
+		},
+		{
+			name:   "Failure not found",
+			fields: fields{pool: pool},
+			args: args{
+				ctx: context.Background(),
+				id:  99,
+				mockFn: func() {
+					mock.ExpectQuery(`select id, name, price from category where id=.`).
+						WithArgs(99).
+						WillReturnError(sql.ErrNoRows)
+				},
+			},
+			want:      nil,
+			wantCause: models.ErrNotFound,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Database{
+				pool: tt.fields.pool,
+			}
+
+			tt.args.mockFn()
+
+			got, err := d.GetCategoryByID(tt.args.ctx, tt.args.id)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetCategoryByID() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.wantCause != nil && pkgerrors.Cause(err) != tt.wantCause {
+				t.Errorf("GetCategoryByID() error = %v, wantCause %v", err, tt.wantCause)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetCategoryByID() got = %v, want %v", got, tt.want)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func TestDatabase_UpdateCategory(t *testing.T) {
+	pool, mock := generateMockedDB()
+	defer checkError(pool.Close)
+
+	type fields struct {
+		pool *sqlx.DB
+	}
+	type args struct {
+		ctx      context.Context
+		category *models.Category
+		mockFn   func()
+	}
+	tests := []struct {
+		name      string
+		fields    fields
+		args      args
+		wantCause error
+		wantErr   bool
+	}{
+		{
+			name:   "Success 1",
+			fields: fields{pool: pool},
+			args: args{
+				ctx:      context.Background(),
+				category: &models.Category{ID: 2, Name: "Dog", Price: 59.99},
+				mockFn: func() {
+					mock.ExpectExec(`update category set`).
+						WithArgs("Dog", 59.99, 2).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:   "Failure not found",
+			fields: fields{pool: pool},
+			args: args{
+				ctx:      context.Background(),
+				category: &models.Category{ID: 99, Name: "Dog", Price: 59.99},
+				mockFn: func() {
+					mock.ExpectExec(`update category set`).
+						WithArgs("Dog", 59.99, 99).
+						WillReturnResult(sqlmock.NewResult(0, 0))
+				},
+			},
+			wantCause: models.ErrNotFound,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Database{
+				pool: tt.fields.pool,
+			}
+
+			tt.args.mockFn()
+
+			err := d.UpdateCategory(tt.args.ctx, tt.args.category)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("UpdateCategory() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantCause != nil && pkgerrors.Cause(err) != tt.wantCause {
+				t.Errorf("UpdateCategory() error = %v, wantCause %v", err, tt.wantCause)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func TestDatabase_DeleteCategory(t *testing.T) {
+	pool, mock := generateMockedDB()
+	defer checkError(pool.Close)
+
+	type fields struct {
+		pool *sqlx.DB
+	}
+	type args struct {
+		ctx    context.Context
+		id     int64
+		mockFn func()
+	}
+	tests := []struct {
+		name      string
+		fields    fields
+		args      args
+		wantCause error
+		wantErr   bool
+	}{
+		{
+			name:   "Success 1",
+			fields: fields{pool: pool},
+			args: args{
+				ctx: context.Background(),
+				id:  6,
+				mockFn: func() {
+					mock.ExpectBegin()
+					mock.ExpectQuery(`select id from category where id=(.+) for update`).
+						WithArgs(6).
+						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(6))
+					mock.ExpectQuery(`select exists`).
+						WithArgs(6).
+						WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+					mock.ExpectExec(`delete from category`).
+						WithArgs(6).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectCommit()
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:   "Failure used by pets",
+			fields: fields{pool: pool},
+			args: args{
+				ctx: context.Background(),
+				id:  1,
+				mockFn: func() {
+					mock.ExpectBegin()
+					mock.ExpectQuery(`select id from category where id=(.+) for update`).
+						WithArgs(1).
+						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+					mock.ExpectQuery(`select exists`).
+						WithArgs(1).
+						WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+					mock.ExpectRollback()
+				},
+			},
+			wantCause: models.ErrConflict,
+			wantErr:   true,
+		},
+		{
+			name:   "Failure not found",
+			fields: fields{pool: pool},
+			args: args{
+				ctx: context.Background(),
+				id:  99,
+				mockFn: func() {
+					mock.ExpectBegin()
+					mock.ExpectQuery(`select id from category where id=(.+) for update`).
+						WithArgs(99).
+						WillReturnError(sql.ErrNoRows)
+					mock.ExpectRollback()
+				},
+			},
+			wantCause: models.ErrNotFound,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Database{
+				pool: tt.fields.pool,
+			}
+
+			tt.args.mockFn()
+
+			err := d.DeleteCategory(tt.args.ctx, tt.args.id)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DeleteCategory() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantCause != nil && pkgerrors.Cause(err) != tt.wantCause {
+				t.Errorf("DeleteCategory() error = %v, wantCause %v", err, tt.wantCause)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
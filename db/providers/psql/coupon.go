@@ -0,0 +1,107 @@
+package psql
+
+import (
+	"context"
+	"time"
+
+	"github.com/IamStubborN/petstore/db/models"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+)
+
+func (d *Database) CreateCoupon(ctx context.Context, coupon *models.Coupon) (*models.Coupon, error) {
+	if coupon.ValidFrom.IsZero() {
+		coupon.ValidFrom = time.Now().UTC()
+	}
+
+	stmt, err := d.pool.PrepareNamedContext(ctx, qm[couponCreateQ])
+	if err != nil {
+		return nil, err
+	}
+	defer checkError(stmt.Close)
+
+	if err = stmt.GetContext(ctx, &coupon.ID, coupon); err != nil {
+		switch {
+		case isUniqueViolation(err):
+			return nil, errors.Wrapf(models.ErrConflict, "coupon %s already exists", coupon.Code)
+		case isForeignKeyViolation(err):
+			return nil, errors.Wrapf(models.ErrNotFound, "category № %d doesn't exist", coupon.CategoryID)
+		}
+		return nil, errors.Wrap(err, "can't insert into coupon")
+	}
+
+	return coupon, nil
+}
+
+func (d *Database) GetCoupons(ctx context.Context) (models.CouponList, error) {
+	coupons := models.CouponList{}
+	if err := d.pool.SelectContext(ctx, &coupons, qm[couponGetAllQ]); err != nil {
+		return nil, errors.Wrap(err, "can't get data from coupon")
+	}
+
+	return coupons, nil
+}
+
+// DeleteCoupon removes the coupon with the history of its uses,
+// orders keep their discounts.
+func (d *Database) DeleteCoupon(ctx context.Context, id int64) error {
+	res, err := d.pool.ExecContext(ctx, qm[couponDeleteQ], id)
+	if err != nil {
+		return errors.Wrap(err, "can't delete from coupon")
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return errors.Wrapf(models.ErrNotFound, "coupon № %d doesn't exist", id)
+	}
+
+	return nil
+}
+
+// lockCoupons returns the coupons with the codes when they can be used
+// together by the user now, the coupon rows stay locked till the order
+// is placed, so usage limits can't be exceeded by concurrent orders.
+func lockCoupons(ctx context.Context, tx *sqlx.Tx, codes []string, userID int64) ([]*models.Coupon, error) {
+	if len(codes) == 0 {
+		return nil, nil
+	}
+
+	var coupons []*models.Coupon
+	if err := tx.SelectContext(ctx, &coupons, qm[couponLockByCodesQ], pq.Array(codes)); err != nil {
+		return nil, errors.Wrap(err, "can't lock coupon")
+	}
+
+	byCode := make(map[string]bool, len(coupons))
+	for _, coupon := range coupons {
+		byCode[coupon.Code] = true
+	}
+
+	for _, code := range codes {
+		if !byCode[code] {
+			return nil, errors.Wrapf(models.ErrNotFound, "coupon %s doesn't exist", code)
+		}
+	}
+
+	if err := models.CheckCoupons(coupons, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+
+	for _, coupon := range coupons {
+		var uses, userUses int32
+		err := tx.QueryRowxContext(ctx, qm[couponUsesQ], coupon.ID, userID).Scan(&uses, &userUses)
+		if err != nil {
+			return nil, errors.Wrap(err, "can't count coupon uses")
+		}
+
+		if err = models.CheckCouponUses(coupon, uses, userUses); err != nil {
+			return nil, err
+		}
+	}
+
+	return coupons, nil
+}
@@ -0,0 +1,205 @@
+package psql
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/IamStubborN/petstore/db/models"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	pkgerrors "github.com/pkg/errors"
+)
+
+func TestDatabase_CreateCoupon(t *testing.T) {
+	pool, mock := generateMockedDB()
+	defer checkError(pool.Close)
+
+	validFrom := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	type fields struct {
+		pool *sqlx.DB
+	}
+	type args struct {
+		ctx    context.Context
+		coupon *models.Coupon
+		mockFn func()
+	}
+	tests := []struct {
+		name      string
+		fields    fields
+		args      args
+		want      *models.Coupon
+		wantCause error
+		wantErr   bool
+	}{
+		{
+			name:   "Success 1",
+			fields: fields{pool: pool},
+			args: args{
+				ctx: context.Background(),
+				coupon: &models.Coupon{Code: "CATS15", Kind: models.CouponKindPercent, Value: 15,
+					CategoryID: 1, ValidFrom: validFrom},
+				mockFn: func() {
+					mock.ExpectPrepare(`insert into coupon (.+) values (.+) returning id`).ExpectQuery().
+						WithArgs("CATS15", "percent", 15.0, 1, validFrom, nil, 0, 0, false).
+						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+				},
+			},
+			want: &models.Coupon{ID: 5, Code: "CATS15", Kind: models.CouponKindPercent, Value: 15,
+				CategoryID: 1, ValidFrom: validFrom},
+			wantErr: false,
+		},
+		{
+			name:   "Failure duplicate",
+			fields: fields{pool: pool},
+			args: args{
+				ctx: context.Background(),
+				coupon: &models.Coupon{Code: "SPRING10", Kind: models.CouponKindPercent, Value: 10,
+					ValidFrom: validFrom},
+				mockFn: func() {
+					mock.ExpectPrepare(`insert into coupon (.+) values (.+) returning id`).ExpectQuery().
+						WithArgs("SPRING10", "percent", 10.0, 0, validFrom, nil, 0, 0, false).
+						WillReturnError(&pq.Error{Code: "23505"})
+				},
+			},
+			want:      nil,
+			wantCause: models.ErrConflict,
+			wantErr:   true,
+		},
+		{
+			name:   "Failure unknown category",
+			fields: fields{pool: pool},
+			args: args{
+				ctx: context.Background(),
+				coupon: &models.Coupon{Code: "HORSES", Kind: models.CouponKindFixed, Value: 5,
+					CategoryID: 99, ValidFrom: validFrom},
+				mockFn: func() {
+					mock.ExpectPrepare(`insert into coupon (.+) values (.+) returning id`).ExpectQuery().
+						WithArgs("HORSES", "fixed", 5.0, 99, validFrom, nil, 0, 0, false).
+						WillReturnError(&pq.Error{Code: "23503"})
+				},
+			},
+			want:      nil,
+			wantCause: models.ErrNotFound,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Database{
+				pool: tt.fields.pool,
+			}
+
+			tt.args.mockFn()
+
+			got, err := d.CreateCoupon(tt.args.ctx, tt.args.coupon)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CreateCoupon() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.wantCause != nil && pkgerrors.Cause(err) != tt.wantCause {
+				t.Errorf("CreateCoupon() error = %v, wantCause %v", err, tt.wantCause)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CreateCoupon() got = %v, want %v", got, tt.want)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func TestDatabase_DeleteCoupon(t *testing.T) {
+	pool, mock := generateMockedDB()
+	defer checkError(pool.Close)
+
+	type fields struct {
+		pool *sqlx.DB
+	}
+	type args struct {
+		ctx    context.Context
+		id     int64
+		mockFn func()
+	}
+	tests := []struct {
+		name      string
+		fields    fields
+		args      args
+		wantCause error
+		wantErr   bool
+	}{
+		{
+			name:   "Success 1",
+			fields: fields{pool: pool},
+			args: args{
+				ctx: context.Background(),
+				id:  1,
+				mockFn: func() {
+					mock.ExpectExec(`delete from coupon`).
+						WithArgs(1).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:   "Failure not found",
+			fields: fields{pool: pool},
+			args: args{
+				ctx: context.Background(),
+				id:  99,
+				mockFn: func() {
+					mock.ExpectExec(`delete from coupon`).
+						WithArgs(99).
+						WillReturnResult(sqlmock.NewResult(0, 0))
+				},
+			},
+			wantCause: models.ErrNotFound,
+			wantErr:   true,
+		},
+		{
+			name:   "Failure",
+			fields: fields{pool: pool},
+			args: args{
+				ctx: context.Background(),
+				id:  1,
+				mockFn: func() {
+					mock.ExpectExec(`delete from coupon`).
+						WithArgs(1).
+						WillReturnError(errors.New("some error"))
+				},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Database{
+				pool: tt.fields.pool,
+			}
+
+			tt.args.mockFn()
+
+			err := d.DeleteCoupon(tt.args.ctx, tt.args.id)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DeleteCoupon() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantCause != nil && pkgerrors.Cause(err) != tt.wantCause {
+				t.Errorf("DeleteCoupon() error = %v, wantCause %v", err, tt.wantCause)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
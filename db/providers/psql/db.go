@@ -8,22 +8,37 @@ import (
 	"github.com/IamStubborN/petstore/config"
 	"github.com/IamStubborN/petstore/templates"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
 	migrate "github.com/rubenv/sql-migrate"
 
 	"go.uber.org/zap"
 )
 
 type Database struct {
-	pool *sqlx.DB
+	pool            *sqlx.DB
+	allowCreateTags bool
+	events          *eventStream
 }
 
 func (d Database) InitDatabase(cfg config.DB) *Database {
-	return &Database{
-		pool: initialSQLConn(cfg),
+	dsn := dataSourceName(cfg)
+	pool := initialSQLConn(dsn, cfg)
+
+	database := &Database{
+		pool:            pool,
+		allowCreateTags: cfg.AllowCreateTags,
 	}
+	database.events = newEventStream(dsn, pool, database.GetInventories)
+
+	return database
 }
 
 func (d *Database) Close() error {
+	if err := d.events.stop(); err != nil {
+		return err
+	}
+
 	if err := d.pool.Close(); err != nil {
 		return err
 	}
@@ -31,10 +46,13 @@ func (d *Database) Close() error {
 	return nil
 }
 
-func initialSQLConn(cfg config.DB) *sqlx.DB {
-	dbInfo := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s connect_timeout=%s",
+func dataSourceName(cfg config.DB) string {
+	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s connect_timeout=%s",
 		cfg.Host, strconv.Itoa(cfg.Port), cfg.User, cfg.Password, cfg.DB, cfg.SSL, cfg.Timeout)
-	db, err := sqlx.Open("postgres", dbInfo)
+}
+
+func initialSQLConn(dsn string, cfg config.DB) *sqlx.DB {
+	db, err := sqlx.Open("postgres", dsn)
 	if err != nil {
 		zap.L().Fatal("can't open connection to database", zap.Error(err))
 	}
@@ -46,6 +64,26 @@ func initialSQLConn(cfg config.DB) *sqlx.DB {
 	return db
 }
 
+// isUniqueViolation reports whether err is a postgres unique_violation error.
+func isUniqueViolation(err error) bool {
+	pqErr, ok := errors.Cause(err).(*pq.Error)
+	return ok && pqErr.Code == "23505"
+}
+
+func isForeignKeyViolation(err error) bool {
+	pqErr, ok := errors.Cause(err).(*pq.Error)
+	return ok && pqErr.Code == "23503"
+}
+
+// nullTime passes the zero time to queries as null.
+func nullTime(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+
+	return t.UTC()
+}
+
 func checkError(f func() error) {
 	if err := f(); err != nil {
 		zap.L().Error("error in defer", zap.Error(err))
@@ -76,22 +114,22 @@ func migrationLogic(db *sqlx.DB, randomDataCount int) {
 	if err != nil {
 		zap.L().Fatal("can't exec db migrations", zap.Error(err))
 	}
-	if !isExist {
-		if randomDataCount > 0 {
-			if err = templates.GenerateRandomSQLData(randomDataCount); err != nil {
-				zap.L().Fatal("can't generate random data for db", zap.Error(err))
-			}
-		}
-
-		migrations := &migrate.FileMigrationSource{
-			Dir: "db/migrations",
+	if !isExist && randomDataCount > 0 {
+		if err = templates.GenerateRandomSQLData(randomDataCount); err != nil {
+			zap.L().Fatal("can't generate random data for db", zap.Error(err))
 		}
+	}
 
-		_, err = migrate.Exec(db.DB, "postgres", migrations, migrate.Up)
-		if err != nil {
-			zap.L().Fatal("can't exec db migrations", zap.Error(err))
-		}
+	// already applied migrations are skipped, so new ones
+	// reach existing databases too
+	migrations := &migrate.FileMigrationSource{
+		Dir: "db/migrations",
+	}
 
-		zap.L().Info("migrations complete")
+	n, err := migrate.Exec(db.DB, "postgres", migrations, migrate.Up)
+	if err != nil {
+		zap.L().Fatal("can't exec db migrations", zap.Error(err))
 	}
+
+	zap.L().Info("migrations complete", zap.Int("applied", n))
 }
@@ -0,0 +1,53 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/IamStubborN/petstore/db/models"
+	"github.com/pkg/errors"
+)
+
+// ReserveIdempotencyKey stores the key for a new request and reports true,
+// when the key is already used the stored one is returned instead.
+// Keys older than a day are reserved again.
+func (d *Database) ReserveIdempotencyKey(ctx context.Context, key *models.IdempotencyKey) (*models.IdempotencyKey, bool, error) {
+	var userID int64
+	err := d.pool.GetContext(ctx, &userID, qm[idempotencyReserveQ], key.UserID, key.Key, key.RequestHash)
+	if err == nil {
+		return key, true, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, false, errors.Wrap(err, "can't insert into idempotency_key")
+	}
+
+	var stored models.IdempotencyKey
+	err = d.pool.GetContext(ctx, &stored, qm[idempotencyGetQ], key.UserID, key.Key)
+	if err == sql.ErrNoRows {
+		return nil, false, errors.Wrapf(models.ErrConflict, "idempotency key %s was just released", key.Key)
+	}
+	if err != nil {
+		return nil, false, errors.Wrap(err, "can't get data from idempotency_key")
+	}
+
+	return &stored, false, nil
+}
+
+func (d *Database) SaveIdempotentResponse(ctx context.Context, key *models.IdempotencyKey) error {
+	_, err := d.pool.ExecContext(ctx, qm[idempotencySaveQ], key.UserID, key.Key, key.StatusCode, key.Body)
+	if err != nil {
+		return errors.Wrap(err, "can't update idempotency_key")
+	}
+
+	return nil
+}
+
+// ReleaseIdempotencyKey forgets a key whose request didn't complete,
+// so the request can be retried with the same key.
+func (d *Database) ReleaseIdempotencyKey(ctx context.Context, userID int64, key string) error {
+	if _, err := d.pool.ExecContext(ctx, qm[idempotencyReleaseQ], userID, key); err != nil {
+		return errors.Wrap(err, "can't delete from idempotency_key")
+	}
+
+	return nil
+}
@@ -0,0 +1,149 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/IamStubborN/petstore/db/models"
+	"github.com/jmoiron/sqlx"
+	pkgerrors "github.com/pkg/errors"
+)
+
+func TestDatabase_ReserveIdempotencyKey(t *testing.T) {
+	pool, mock := generateMockedDB()
+	defer checkError(pool.Close)
+
+	type fields struct {
+		pool *sqlx.DB
+	}
+	type args struct {
+		ctx    context.Context
+		key    *models.IdempotencyKey
+		mockFn func()
+	}
+	tests := []struct {
+		name         string
+		fields       fields
+		args         args
+		want         *models.IdempotencyKey
+		wantReserved bool
+		wantCause    error
+		wantErr      bool
+	}{
+		{
+			name:   "Success new key",
+			fields: fields{pool: pool},
+			args: args{
+				ctx: context.Background(),
+				key: &models.IdempotencyKey{UserID: 1, Key: "order-1", RequestHash: "hash"},
+				mockFn: func() {
+					mock.ExpectQuery(`insert into idempotency_key (.+) returning user_id`).
+						WithArgs(1, "order-1", "hash").
+						WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(1))
+				},
+			},
+			want:         &models.IdempotencyKey{UserID: 1, Key: "order-1", RequestHash: "hash"},
+			wantReserved: true,
+			wantErr:      false,
+		},
+		{
+			name:   "Success used key",
+			fields: fields{pool: pool},
+			args: args{
+				ctx: context.Background(),
+				key: &models.IdempotencyKey{UserID: 1, Key: "order-1", RequestHash: "hash"},
+				mockFn: func() {
+					mock.ExpectQuery(`insert into idempotency_key (.+) returning user_id`).
+						WithArgs(1, "order-1", "hash").
+						WillReturnError(sql.ErrNoRows)
+					mock.ExpectQuery(`select (.+) from idempotency_key where`).
+						WithArgs(1, "order-1").
+						WillReturnRows(sqlmock.NewRows([]string{"user_id", "key", "request_hash", "status_code", "body"}).
+							AddRow(1, "order-1", "hash", 200, []byte(`{"id":1}`)))
+				},
+			},
+			want: &models.IdempotencyKey{
+				UserID: 1, Key: "order-1", RequestHash: "hash", StatusCode: 200, Body: []byte(`{"id":1}`),
+			},
+			wantReserved: false,
+			wantErr:      false,
+		},
+		{
+			name:   "Failure key released",
+			fields: fields{pool: pool},
+			args: args{
+				ctx: context.Background(),
+				key: &models.IdempotencyKey{UserID: 1, Key: "order-1", RequestHash: "hash"},
+				mockFn: func() {
+					mock.ExpectQuery(`insert into idempotency_key (.+) returning user_id`).
+						WithArgs(1, "order-1", "hash").
+						WillReturnError(sql.ErrNoRows)
+					mock.ExpectQuery(`select (.+) from idempotency_key where`).
+						WithArgs(1, "order-1").
+						WillReturnError(sql.ErrNoRows)
+				},
+			},
+			want:      nil,
+			wantCause: models.ErrConflict,
+			wantErr:   true,
+		},
+		{
+			name:   "Failure",
+			fields: fields{pool: pool},
+			args: args{
+				ctx: context.Background(),
+				key: &models.IdempotencyKey{UserID: 1, Key: "order-1", RequestHash: "hash"},
+				mockFn: func() {
+					mock.ExpectQuery(`insert into idempotency_key (.+) returning user_id`).
+						WithArgs(1, "order-1", "hash").
+						WillReturnError(errors.New("some error"))
+				},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Database{
+				pool: tt.fields.pool,
+			}
+
+			tt.args.mockFn()
+
+			got, isReserved, err := d.ReserveIdempotencyKey(tt.args.ctx, tt.args.key)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ReserveIdempotencyKey() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantCause != nil && pkgerrors.Cause(err) != tt.wantCause {
+				t.Errorf("ReserveIdempotencyKey() error cause = %v, want %v", pkgerrors.Cause(err), tt.wantCause)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReserveIdempotencyKey() got = %v, want %v", got, tt.want)
+			}
+			if isReserved != tt.wantReserved {
+				t.Errorf("ReserveIdempotencyKey() isReserved = %v, want %v", isReserved, tt.wantReserved)
+			}
+		})
+	}
+}
+
+func TestDatabase_ReleaseIdempotencyKey(t *testing.T) {
+	pool, mock := generateMockedDB()
+	defer checkError(pool.Close)
+
+	d := &Database{pool: pool}
+
+	mock.ExpectExec(`delete from idempotency_key where (.+) and status_code=0`).
+		WithArgs(1, "order-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := d.ReleaseIdempotencyKey(context.Background(), 1, "order-1"); err != nil {
+		t.Errorf("ReleaseIdempotencyKey() error = %v", err)
+	}
+}
@@ -0,0 +1,84 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/IamStubborN/petstore/db/models"
+	"github.com/pkg/errors"
+)
+
+// AddInvoiceFile records the uploaded invoice file under the next invoice number.
+func (d *Database) AddInvoiceFile(ctx context.Context, file *models.InvoiceFile) (*models.InvoiceFile, error) {
+	err := d.pool.QueryRowxContext(ctx, qm[invoiceCreateQ],
+		file.From.UTC(), file.To.UTC(), file.Format, file.ObjectKey, file.Checksum, file.Size,
+		file.TotalPrice, file.TotalDiscount, file.TotalRefund, file.Total).
+		Scan(&file.Number, &file.CreatedAt)
+	if isUniqueViolation(err) {
+		return nil, errors.Wrapf(models.ErrConflict, "invoice file %s is recorded already", file.ObjectKey)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "can't insert into invoice")
+	}
+
+	return file, nil
+}
+
+// GetInvoiceFiles returns up to limit invoice files, the latest come first.
+func (d *Database) GetInvoiceFiles(ctx context.Context, limit int) (models.InvoiceFileList, error) {
+	files := models.InvoiceFileList{}
+	if err := d.pool.SelectContext(ctx, &files, qm[invoiceGetAllQ], limit); err != nil {
+		return nil, errors.Wrap(err, "can't get invoices")
+	}
+
+	return files, nil
+}
+
+func (d *Database) GetInvoiceFile(ctx context.Context, number int64) (*models.InvoiceFile, error) {
+	var file models.InvoiceFile
+	err := d.pool.GetContext(ctx, &file, qm[invoiceGetByNumberQ], number)
+	if err == sql.ErrNoRows {
+		return nil, errors.Wrapf(models.ErrNotFound, "invoice № %d doesn't exist", number)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't get invoice № %d", number)
+	}
+
+	return &file, nil
+}
+
+// GetPeriodInvoiceFiles returns the invoice files of the period from to.
+func (d *Database) GetPeriodInvoiceFiles(ctx context.Context, from, to time.Time) (models.InvoiceFileList, error) {
+	files := models.InvoiceFileList{}
+	if err := d.pool.SelectContext(ctx, &files, qm[invoiceGetByPeriodQ], from.UTC(), to.UTC()); err != nil {
+		return nil, errors.Wrap(err, "can't get invoices of period")
+	}
+
+	return files, nil
+}
+
+// GetInvoicePeriod returns the end of the last completed invoice period,
+// models.ErrNotFound means no period is completed yet.
+func (d *Database) GetInvoicePeriod(ctx context.Context) (time.Time, error) {
+	var to time.Time
+	err := d.pool.GetContext(ctx, &to, qm[invoicePeriodGetQ])
+	if err == sql.ErrNoRows {
+		return time.Time{}, errors.Wrap(models.ErrNotFound, "no invoice period is completed")
+	}
+	if err != nil {
+		return time.Time{}, errors.Wrap(err, "can't get invoice period")
+	}
+
+	return to.UTC(), nil
+}
+
+// CompleteInvoicePeriod moves the end of the last completed invoice period
+// forward to to, an earlier end leaves it as it is.
+func (d *Database) CompleteInvoicePeriod(ctx context.Context, to time.Time) error {
+	if _, err := d.pool.ExecContext(ctx, qm[invoicePeriodSetQ], to.UTC()); err != nil {
+		return errors.Wrap(err, "can't complete invoice period")
+	}
+
+	return nil
+}
@@ -0,0 +1,350 @@
+package psql
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/IamStubborN/petstore/db/models"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	pkgerrors "github.com/pkg/errors"
+)
+
+func TestDatabase_AddInvoiceFile(t *testing.T) {
+	pool, mock := generateMockedDB()
+	defer checkError(pool.Close)
+
+	from := time.Date(2019, 9, 7, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+	now := time.Date(2019, 9, 8, 12, 0, 0, 0, time.UTC)
+	file := func() *models.InvoiceFile {
+		return &models.InvoiceFile{From: from, To: to, Format: "csv", ObjectKey: "from_2019-09-07_to_2019-09-08/a.csv",
+			Checksum: "ab12", Size: 120, TotalPrice: 60, TotalDiscount: 6, TotalRefund: 10, Total: 44}
+	}
+
+	type fields struct {
+		pool *sqlx.DB
+	}
+	type args struct {
+		ctx    context.Context
+		file   *models.InvoiceFile
+		mockFn func()
+	}
+	tests := []struct {
+		name      string
+		fields    fields
+		args      args
+		want      *models.InvoiceFile
+		wantCause error
+		wantErr   bool
+	}{
+		{
+			name:   "Success 1",
+			fields: fields{pool: pool},
+			args: args{
+				ctx:  context.Background(),
+				file: file(),
+				mockFn: func() {
+					mock.ExpectQuery(`insert into invoice (.+) returning number, created_at`).
+						WithArgs(from, to, "csv", "from_2019-09-07_to_2019-09-08/a.csv", "ab12", 120,
+							60.0, 6.0, 10.0, 44.0).
+						WillReturnRows(sqlmock.NewRows([]string{"number", "created_at"}).AddRow(42, now))
+				},
+			},
+			want: &models.InvoiceFile{Number: 42, From: from, To: to, Format: "csv",
+				ObjectKey: "from_2019-09-07_to_2019-09-08/a.csv", Checksum: "ab12", Size: 120,
+				TotalPrice: 60, TotalDiscount: 6, TotalRefund: 10, Total: 44, CreatedAt: now},
+			wantErr: false,
+		},
+		{
+			name:   "Failure object key is recorded",
+			fields: fields{pool: pool},
+			args: args{
+				ctx:  context.Background(),
+				file: file(),
+				mockFn: func() {
+					mock.ExpectQuery(`insert into invoice (.+) returning number, created_at`).
+						WillReturnError(&pq.Error{Code: "23505"})
+				},
+			},
+			want:      nil,
+			wantCause: models.ErrConflict,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Database{
+				pool: tt.fields.pool,
+			}
+
+			tt.args.mockFn()
+
+			got, err := d.AddInvoiceFile(tt.args.ctx, tt.args.file)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("AddInvoiceFile() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.wantCause != nil && pkgerrors.Cause(err) != tt.wantCause {
+				t.Errorf("AddInvoiceFile() error = %v, wantCause %v", err, tt.wantCause)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("AddInvoiceFile() got = %v, want %v", got, tt.want)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func TestDatabase_GetInvoiceFile(t *testing.T) {
+	pool, mock := generateMockedDB()
+	defer checkError(pool.Close)
+
+	from := time.Date(2019, 9, 7, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+	columns := []string{"number", "period_from", "period_to", "format", "object_key", "checksum", "size",
+		"total_price", "total_discount", "total_refund", "total", "created_at"}
+
+	type fields struct {
+		pool *sqlx.DB
+	}
+	type args struct {
+		ctx    context.Context
+		number int64
+		mockFn func()
+	}
+	tests := []struct {
+		name      string
+		fields    fields
+		args      args
+		want      *models.InvoiceFile
+		wantCause error
+		wantErr   bool
+	}{
+		{
+			name:   "Success 1",
+			fields: fields{pool: pool},
+			args: args{
+				ctx:    context.Background(),
+				number: 42,
+				mockFn: func() {
+					mock.ExpectQuery(`select (.+) from invoice where number=`).
+						WithArgs(42).
+						WillReturnRows(sqlmock.NewRows(columns).
+							AddRow(42, from, to, "pdf", "a.pdf", "ab12", 2048, 60, 6, 10, 44, to))
+				},
+			},
+			want: &models.InvoiceFile{Number: 42, From: from, To: to, Format: "pdf", ObjectKey: "a.pdf",
+				Checksum: "ab12", Size: 2048, TotalPrice: 60, TotalDiscount: 6, TotalRefund: 10, Total: 44,
+				CreatedAt: to},
+			wantErr: false,
+		},
+		{
+			name:   "Failure not found",
+			fields: fields{pool: pool},
+			args: args{
+				ctx:    context.Background(),
+				number: 43,
+				mockFn: func() {
+					mock.ExpectQuery(`select (.+) from invoice where number=`).
+						WithArgs(43).
+						WillReturnRows(sqlmock.NewRows(columns))
+				},
+			},
+			want:      nil,
+			wantCause: models.ErrNotFound,
+			wantErr:   true,
+		},
+		{
+			name:   "Failure 1",
+			fields: fields{pool: pool},
+			args: args{
+				ctx:    context.Background(),
+				number: 44,
+				mockFn: func() {
+					mock.ExpectQuery(`select (.+) from invoice where number=`).
+						WillReturnError(errors.New("connection lost"))
+				},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Database{
+				pool: tt.fields.pool,
+			}
+
+			tt.args.mockFn()
+
+			got, err := d.GetInvoiceFile(tt.args.ctx, tt.args.number)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetInvoiceFile() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.wantCause != nil && pkgerrors.Cause(err) != tt.wantCause {
+				t.Errorf("GetInvoiceFile() error = %v, wantCause %v", err, tt.wantCause)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetInvoiceFile() got = %v, want %v", got, tt.want)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func TestDatabase_GetPeriodInvoiceFiles(t *testing.T) {
+	pool, mock := generateMockedDB()
+	defer checkError(pool.Close)
+
+	from := time.Date(2019, 9, 7, 12, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+	columns := []string{"number", "period_from", "period_to", "format", "object_key", "checksum", "size",
+		"total_price", "total_discount", "total_refund", "total", "created_at"}
+
+	tests := []struct {
+		name    string
+		mockFn  func()
+		want    models.InvoiceFileList
+		wantErr bool
+	}{
+		{
+			name: "Success 1",
+			mockFn: func() {
+				mock.ExpectQuery(`select (.+) from invoice where period_from=(.+) and period_to=`).
+					WithArgs(from, to).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow(42, from, to, "csv", "a.csv", "ab12", 2048, 60, 6, 10, 44, to))
+			},
+			want: models.InvoiceFileList{{Number: 42, From: from, To: to, Format: "csv", ObjectKey: "a.csv",
+				Checksum: "ab12", Size: 2048, TotalPrice: 60, TotalDiscount: 6, TotalRefund: 10, Total: 44,
+				CreatedAt: to}},
+			wantErr: false,
+		},
+		{
+			name: "Failure 1",
+			mockFn: func() {
+				mock.ExpectQuery(`select (.+) from invoice where period_from=(.+) and period_to=`).
+					WillReturnError(errors.New("connection lost"))
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Database{
+				pool: pool,
+			}
+
+			tt.mockFn()
+
+			got, err := d.GetPeriodInvoiceFiles(context.Background(), from, to)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetPeriodInvoiceFiles() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetPeriodInvoiceFiles() got = %v, want %v", got, tt.want)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func TestDatabase_GetInvoicePeriod(t *testing.T) {
+	pool, mock := generateMockedDB()
+	defer checkError(pool.Close)
+
+	to := time.Date(2019, 9, 8, 12, 0, 0, 0, time.UTC)
+
+	type fields struct {
+		pool *sqlx.DB
+	}
+	type args struct {
+		ctx    context.Context
+		mockFn func()
+	}
+	tests := []struct {
+		name      string
+		fields    fields
+		args      args
+		want      time.Time
+		wantCause error
+		wantErr   bool
+	}{
+		{
+			name:   "Success 1",
+			fields: fields{pool: pool},
+			args: args{
+				ctx: context.Background(),
+				mockFn: func() {
+					mock.ExpectQuery(`select period_to from invoice_period`).
+						WillReturnRows(sqlmock.NewRows([]string{"period_to"}).AddRow(to))
+				},
+			},
+			want:    to,
+			wantErr: false,
+		},
+		{
+			name:   "Failure no period is completed",
+			fields: fields{pool: pool},
+			args: args{
+				ctx: context.Background(),
+				mockFn: func() {
+					mock.ExpectQuery(`select period_to from invoice_period`).
+						WillReturnRows(sqlmock.NewRows([]string{"period_to"}))
+				},
+			},
+			want:      time.Time{},
+			wantCause: models.ErrNotFound,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Database{
+				pool: tt.fields.pool,
+			}
+
+			tt.args.mockFn()
+
+			got, err := d.GetInvoicePeriod(tt.args.ctx)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetInvoicePeriod() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.wantCause != nil && pkgerrors.Cause(err) != tt.wantCause {
+				t.Errorf("GetInvoicePeriod() error = %v, wantCause %v", err, tt.wantCause)
+			}
+
+			if !got.Equal(tt.want) {
+				t.Errorf("GetInvoicePeriod() got = %v, want %v", got, tt.want)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"time"
+
+	"github.com/IamStubborN/petstore/db/models"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/mailru/easyjson"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// publishSaveTimeout bounds saving the outcome of a published event or
+// a webhook delivery, it is saved even when the worker is closed,
+// so the event isn't sent again.
+const publishSaveTimeout = 5 * time.Second
+
+// PublishEvents claims up to limit events due for delivery, hands them
+// to publish one by one and saves the outcome. Claimed events are leased
+// for EventLease in a short transaction, so other workers skip them while
+// they are published, failed events are retried later with a growing delay.
+// Events left when ctx is done are released for the next worker.
+// It returns the number of events handed to publish.
+func (d *Database) PublishEvents(ctx context.Context, limit int,
+	publish func(context.Context, *models.Event) error) (int, error) {
+	events, err := d.claimEvents(ctx, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	for _, event := range events {
+		if ctx.Err() != nil {
+			break
+		}
+		count++
+
+		if err = d.saveEvent(event, publish(ctx, event)); err != nil {
+			break
+		}
+	}
+
+	if count < len(events) {
+		d.releaseEvents(events[count:])
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// claimEvents locks the due events and leases them until they are published.
+func (d *Database) claimEvents(ctx context.Context, limit int) (models.EventList, error) {
+	tx, err := d.pool.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "can't start transaction")
+	}
+
+	defer func() {
+		if err != nil {
+			checkError(tx.Rollback)
+			return
+		}
+		checkError(tx.Commit)
+	}()
+
+	events := models.EventList{}
+	if err = tx.SelectContext(ctx, &events, qm[outboxLockDueQ], limit); err != nil {
+		return nil, errors.Wrap(err, "can't lock outbox events")
+	}
+
+	if len(events) == 0 {
+		return events, nil
+	}
+
+	ids := make([]int64, len(events))
+	leased := time.Now().Add(models.EventLease)
+	for i, event := range events {
+		ids[i] = event.ID
+		event.NextAttemptAt = leased
+	}
+
+	if _, err = tx.ExecContext(ctx, qm[outboxLeaseQ], pq.Array(ids), leased); err != nil {
+		return nil, errors.Wrap(err, "can't lease outbox events")
+	}
+
+	return events, nil
+}
+
+// saveEvent marks the event published or schedules its next attempt.
+func (d *Database) saveEvent(event *models.Event, publishErr error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), publishSaveTimeout)
+	defer cancel()
+
+	var err error
+	if publishErr != nil {
+		event.Fail(publishErr, time.Now().UTC())
+		_, err = d.pool.ExecContext(ctx, qm[outboxRetryQ],
+			event.ID, event.Attempts, event.NextAttemptAt, event.LastError)
+	} else {
+		_, err = d.pool.ExecContext(ctx, qm[outboxPublishedQ], event.ID)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "can't save outbox event № %d", event.ID)
+	}
+
+	return nil
+}
+
+// releaseEvents ends the lease of the events left unpublished,
+// an event that can't be released waits for its lease to end.
+func (d *Database) releaseEvents(events models.EventList) {
+	ctx, cancel := context.WithTimeout(context.Background(), publishSaveTimeout)
+	defer cancel()
+
+	ids := make([]int64, len(events))
+	for i, event := range events {
+		ids[i] = event.ID
+	}
+
+	if _, err := d.pool.ExecContext(ctx, qm[outboxReleaseQ], pq.Array(ids)); err != nil {
+		zap.L().Warn("can't release outbox events", zap.Int64s("ids", ids), zap.Error(err))
+	}
+}
+
+// keyOf turns the id of a pet or an order into the key of its events.
+func keyOf(id int64) string {
+	return strconv.FormatInt(id, 10)
+}
+
+// addEvent writes the event to the outbox in the transaction of the change,
+// so the event is published only when the change is committed.
+func addEvent(ctx context.Context, tx *sqlx.Tx, aggregate, key, eventType string, payload easyjson.Marshaler) error {
+	event, err := models.NewEvent(aggregate, key, eventType, payload)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, qm[outboxAddQ], event.Aggregate, event.Key, event.Type, string(event.Payload))
+	if err != nil {
+		return errors.Wrapf(err, "can't add %s event to outbox", eventType)
+	}
+
+	return nil
+}
@@ -0,0 +1,207 @@
+package psql
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/IamStubborN/petstore/db/models"
+	"github.com/jmoiron/sqlx"
+)
+
+// expectEvent expects the event to be written to the outbox.
+func expectEvent(mock sqlmock.Sqlmock, aggregate, key, eventType string) {
+	mock.ExpectExec(`insert into outbox`).
+		WithArgs(aggregate, key, eventType, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+}
+
+func TestDatabase_PublishEvents(t *testing.T) {
+	pool, mock := generateMockedDB()
+	defer checkError(pool.Close)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	now := time.Date(2020, 3, 1, 12, 0, 0, 0, time.UTC)
+	columns := []string{"id", "aggregate", "aggregate_key", "event", "payload", "created_at",
+		"attempts", "next_attempt_at", "last_error"}
+
+	type fields struct {
+		pool *sqlx.DB
+	}
+	type args struct {
+		ctx     context.Context
+		limit   int
+		publish func(context.Context, *models.Event) error
+		mockFn  func()
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    int
+		wantErr bool
+	}{
+		{
+			name:   "Success one is published, one is retried",
+			fields: fields{pool: pool},
+			args: args{
+				ctx:   context.Background(),
+				limit: 10,
+				publish: func(ctx context.Context, event *models.Event) error {
+					if event.ID == 2 {
+						return errors.New("endpoint is down")
+					}
+					return nil
+				},
+				mockFn: func() {
+					mock.ExpectBegin()
+					mock.ExpectQuery(`select (.+) from outbox (.+) for update skip locked`).
+						WithArgs(10).
+						WillReturnRows(sqlmock.NewRows(columns).
+							AddRow(1, "pet", "45", "pet.created", []byte(`{"id":45}`), now, 0, now, "").
+							AddRow(2, "order", "7", "order.created", []byte(`{"id":7}`), now, 2, now, "timeout"))
+					mock.ExpectExec(`update outbox set next_attempt_at=\$2 where id = any\(\$1\)`).
+						WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg()).
+						WillReturnResult(sqlmock.NewResult(0, 2))
+					mock.ExpectCommit()
+					mock.ExpectExec(`update outbox set published_at=now\(\)`).
+						WithArgs(1).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectExec(`update outbox set attempts=(.+), next_attempt_at`).
+						WithArgs(2, 3, sqlmock.AnyArg(), "endpoint is down").
+						WillReturnResult(sqlmock.NewResult(0, 1))
+				},
+			},
+			want:    2,
+			wantErr: false,
+		},
+		{
+			name:   "Success nothing is due",
+			fields: fields{pool: pool},
+			args: args{
+				ctx:     context.Background(),
+				limit:   10,
+				publish: func(ctx context.Context, event *models.Event) error { return nil },
+				mockFn: func() {
+					mock.ExpectBegin()
+					mock.ExpectQuery(`select (.+) from outbox (.+) for update skip locked`).
+						WithArgs(10).
+						WillReturnRows(sqlmock.NewRows(columns))
+					mock.ExpectCommit()
+				},
+			},
+			want:    0,
+			wantErr: false,
+		},
+		{
+			name:   "Success events left are released when closed",
+			fields: fields{pool: pool},
+			args: args{
+				ctx:   ctx,
+				limit: 10,
+				publish: func(ctx context.Context, event *models.Event) error {
+					cancel()
+					return nil
+				},
+				mockFn: func() {
+					mock.ExpectBegin()
+					mock.ExpectQuery(`select (.+) from outbox (.+) for update skip locked`).
+						WithArgs(10).
+						WillReturnRows(sqlmock.NewRows(columns).
+							AddRow(1, "pet", "45", "pet.created", []byte(`{"id":45}`), now, 0, now, "").
+							AddRow(2, "order", "7", "order.created", []byte(`{"id":7}`), now, 0, now, ""))
+					mock.ExpectExec(`update outbox set next_attempt_at=\$2 where id = any\(\$1\)`).
+						WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg()).
+						WillReturnResult(sqlmock.NewResult(0, 2))
+					mock.ExpectCommit()
+					mock.ExpectExec(`update outbox set published_at=now\(\)`).
+						WithArgs(1).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectExec(`update outbox set next_attempt_at=now\(\) where id = any\(\$1\)`).
+						WithArgs(sqlmock.AnyArg()).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+				},
+			},
+			want:    1,
+			wantErr: false,
+		},
+		{
+			name:   "Failure can't lease events",
+			fields: fields{pool: pool},
+			args: args{
+				ctx:     context.Background(),
+				limit:   10,
+				publish: func(ctx context.Context, event *models.Event) error { return nil },
+				mockFn: func() {
+					mock.ExpectBegin()
+					mock.ExpectQuery(`select (.+) from outbox (.+) for update skip locked`).
+						WithArgs(10).
+						WillReturnRows(sqlmock.NewRows(columns).
+							AddRow(1, "pet", "45", "pet.created", []byte(`{"id":45}`), now, 0, now, ""))
+					mock.ExpectExec(`update outbox set next_attempt_at=\$2 where id = any\(\$1\)`).
+						WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg()).
+						WillReturnError(errors.New("connection lost"))
+					mock.ExpectRollback()
+				},
+			},
+			want:    0,
+			wantErr: true,
+		},
+		{
+			name:   "Failure can't save outcome",
+			fields: fields{pool: pool},
+			args: args{
+				ctx:     context.Background(),
+				limit:   10,
+				publish: func(ctx context.Context, event *models.Event) error { return nil },
+				mockFn: func() {
+					mock.ExpectBegin()
+					mock.ExpectQuery(`select (.+) from outbox (.+) for update skip locked`).
+						WithArgs(10).
+						WillReturnRows(sqlmock.NewRows(columns).
+							AddRow(1, "pet", "45", "pet.created", []byte(`{"id":45}`), now, 0, now, "").
+							AddRow(2, "order", "7", "order.created", []byte(`{"id":7}`), now, 0, now, ""))
+					mock.ExpectExec(`update outbox set next_attempt_at=\$2 where id = any\(\$1\)`).
+						WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg()).
+						WillReturnResult(sqlmock.NewResult(0, 2))
+					mock.ExpectCommit()
+					mock.ExpectExec(`update outbox set published_at=now\(\)`).
+						WithArgs(1).
+						WillReturnError(errors.New("connection lost"))
+					mock.ExpectExec(`update outbox set next_attempt_at=now\(\) where id = any\(\$1\)`).
+						WithArgs(sqlmock.AnyArg()).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+				},
+			},
+			want:    0,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Database{
+				pool: tt.fields.pool,
+			}
+
+			tt.args.mockFn()
+
+			got, err := d.PublishEvents(tt.args.ctx, tt.args.limit, tt.args.publish)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("PublishEvents() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if got != tt.want {
+				t.Errorf("PublishEvents() got = %v, want %v", got, tt.want)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
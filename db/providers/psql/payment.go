@@ -0,0 +1,114 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/IamStubborN/petstore/db/models"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+// CreatePayment records a payment of the order before the provider is
+// called, so the order can't be paid twice by concurrent requests.
+func (d *Database) CreatePayment(ctx context.Context, payment *models.Payment) (*models.Payment, error) {
+	err := d.pool.QueryRowxContext(ctx, qm[paymentCreateQ],
+		payment.OrderID, payment.Provider, payment.Amount, payment.Status).
+		Scan(&payment.ID, &payment.CreatedAt, &payment.UpdatedAt)
+	if err != nil {
+		switch {
+		case isUniqueViolation(err):
+			return nil, errors.Wrapf(models.ErrConflict, "order № %d is already paid", payment.OrderID)
+		case isForeignKeyViolation(err):
+			return nil, errors.Wrapf(models.ErrNotFound, "order № %d doesn't exist", payment.OrderID)
+		}
+		return nil, errors.Wrap(err, "can't insert into payment")
+	}
+
+	return payment, nil
+}
+
+func (d *Database) GetPayments(ctx context.Context, orderID int64) (models.PaymentList, error) {
+	payments := models.PaymentList{}
+	if err := d.pool.SelectContext(ctx, &payments, qm[paymentGetByOrderIDQ], orderID); err != nil {
+		return nil, errors.Wrap(err, "can't get data from payment")
+	}
+
+	return payments, nil
+}
+
+// UpdatePayment moves the payment to its new status and saves
+// the provider reference and the reason of a failure.
+func (d *Database) UpdatePayment(ctx context.Context, payment *models.Payment) error {
+	tx, err := d.pool.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return errors.Wrap(err, "can't start transaction")
+	}
+
+	defer func() {
+		if err != nil {
+			checkError(tx.Rollback)
+			return
+		}
+		checkError(tx.Commit)
+	}()
+
+	var status string
+	err = tx.GetContext(ctx, &status, qm[paymentLockByIDQ], payment.ID)
+	if err == sql.ErrNoRows {
+		return errors.Wrapf(models.ErrNotFound, "payment № %d doesn't exist", payment.ID)
+	}
+	if err != nil {
+		return errors.Wrap(err, "can't lock payment")
+	}
+
+	if err = models.CheckPaymentStatusTransition(status, payment.Status); err != nil {
+		return err
+	}
+
+	// the order is locked so it can't be cancelled while its capture is saved
+	orderStatus := models.OrderStatusPlaced
+	if payment.Status == models.PaymentStatusCaptured {
+		err = tx.GetContext(ctx, &orderStatus, qm[storeLockByIDQ], payment.OrderID)
+		if err == sql.ErrNoRows {
+			orderStatus, err = models.OrderStatusCancelled, nil
+		}
+		if err != nil {
+			return errors.Wrap(err, "can't lock order")
+		}
+	}
+
+	err = tx.QueryRowxContext(ctx, qm[paymentUpdateQ], payment.ID, payment.Status, payment.Reference, payment.Reason).
+		Scan(&payment.UpdatedAt)
+	if err != nil {
+		return errors.Wrap(err, "can't update payment")
+	}
+
+	if orderStatus == models.OrderStatusPlaced {
+		return nil
+	}
+
+	if err = reserveCaptureRefund(ctx, tx, payment, orderStatus); err != nil {
+		return err
+	}
+
+	// the capture and its refund are committed, the caller is told of the conflict
+	return errors.Wrapf(models.ErrConflict, "order № %d is %s, its payment is refunded",
+		payment.OrderID, orderStatus)
+}
+
+// reserveCaptureRefund returns the money captured for an order
+// that isn't placed anymore, the refund worker sends it soon.
+func reserveCaptureRefund(ctx context.Context, tx *sqlx.Tx, payment *models.Payment, orderStatus string) error {
+	var refund models.Refund
+	err := tx.QueryRowxContext(ctx, qm[refundCreateQ],
+		payment.OrderID, payment.ID, payment.Amount, "order is "+orderStatus+" before the capture", 0,
+		models.RefundStatusPending, time.Now()).
+		Scan(&refund.ID, &refund.CreatedAt)
+	if err != nil {
+		return errors.Wrap(err, "can't insert into refund")
+	}
+
+	return nil
+}
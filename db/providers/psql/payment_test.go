@@ -0,0 +1,267 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/IamStubborN/petstore/db/models"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	pkgerrors "github.com/pkg/errors"
+)
+
+func TestDatabase_CreatePayment(t *testing.T) {
+	pool, mock := generateMockedDB()
+	defer checkError(pool.Close)
+
+	now := time.Date(2020, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	type fields struct {
+		pool *sqlx.DB
+	}
+	type args struct {
+		ctx     context.Context
+		payment *models.Payment
+		mockFn  func()
+	}
+	tests := []struct {
+		name      string
+		fields    fields
+		args      args
+		want      *models.Payment
+		wantCause error
+		wantErr   bool
+	}{
+		{
+			name:   "Success 1",
+			fields: fields{pool: pool},
+			args: args{
+				ctx:     context.Background(),
+				payment: &models.Payment{OrderID: 1, Provider: "fake", Amount: 134.98, Status: "pending"},
+				mockFn: func() {
+					mock.ExpectQuery(`insert into payment (.+) returning id`).
+						WithArgs(1, "fake", 134.98, "pending").
+						WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).
+							AddRow(3, now, now))
+				},
+			},
+			want: &models.Payment{ID: 3, OrderID: 1, Provider: "fake", Amount: 134.98, Status: "pending",
+				CreatedAt: now, UpdatedAt: now},
+			wantErr: false,
+		},
+		{
+			name:   "Failure already paid",
+			fields: fields{pool: pool},
+			args: args{
+				ctx:     context.Background(),
+				payment: &models.Payment{OrderID: 1, Provider: "fake", Amount: 134.98, Status: "pending"},
+				mockFn: func() {
+					mock.ExpectQuery(`insert into payment (.+) returning id`).
+						WithArgs(1, "fake", 134.98, "pending").
+						WillReturnError(&pq.Error{Code: "23505"})
+				},
+			},
+			want:      nil,
+			wantCause: models.ErrConflict,
+			wantErr:   true,
+		},
+		{
+			name:   "Failure unknown order",
+			fields: fields{pool: pool},
+			args: args{
+				ctx:     context.Background(),
+				payment: &models.Payment{OrderID: 99, Provider: "fake", Amount: 10, Status: "pending"},
+				mockFn: func() {
+					mock.ExpectQuery(`insert into payment (.+) returning id`).
+						WithArgs(99, "fake", 10.0, "pending").
+						WillReturnError(&pq.Error{Code: "23503"})
+				},
+			},
+			want:      nil,
+			wantCause: models.ErrNotFound,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Database{
+				pool: tt.fields.pool,
+			}
+
+			tt.args.mockFn()
+
+			got, err := d.CreatePayment(tt.args.ctx, tt.args.payment)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CreatePayment() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.wantCause != nil && pkgerrors.Cause(err) != tt.wantCause {
+				t.Errorf("CreatePayment() error = %v, wantCause %v", err, tt.wantCause)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CreatePayment() got = %v, want %v", got, tt.want)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func TestDatabase_UpdatePayment(t *testing.T) {
+	pool, mock := generateMockedDB()
+	defer checkError(pool.Close)
+
+	now := time.Date(2020, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	type fields struct {
+		pool *sqlx.DB
+	}
+	type args struct {
+		ctx     context.Context
+		payment *models.Payment
+		mockFn  func()
+	}
+	tests := []struct {
+		name      string
+		fields    fields
+		args      args
+		wantCause error
+		wantErr   bool
+	}{
+		{
+			name:   "Success 1",
+			fields: fields{pool: pool},
+			args: args{
+				ctx:     context.Background(),
+				payment: &models.Payment{ID: 3, Status: "authorized", Reference: "fake_1_1"},
+				mockFn: func() {
+					mock.ExpectBegin()
+					mock.ExpectQuery(`select status from payment where id=(.+) for update`).
+						WithArgs(3).
+						WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("pending"))
+					mock.ExpectQuery(`update payment set status`).
+						WithArgs(3, "authorized", "fake_1_1", "").
+						WillReturnRows(sqlmock.NewRows([]string{"updated_at"}).AddRow(now))
+					mock.ExpectCommit()
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:   "Success capture of placed order",
+			fields: fields{pool: pool},
+			args: args{
+				ctx:     context.Background(),
+				payment: &models.Payment{ID: 3, OrderID: 1, Amount: 420, Status: "captured", Reference: "fake_1_1"},
+				mockFn: func() {
+					mock.ExpectBegin()
+					mock.ExpectQuery(`select status from payment where id=(.+) for update`).
+						WithArgs(3).
+						WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("authorized"))
+					mock.ExpectQuery(`select os.name as order_status from "order" o (.+) for update of o`).
+						WithArgs(1).
+						WillReturnRows(sqlmock.NewRows([]string{"order_status"}).AddRow("placed"))
+					mock.ExpectQuery(`update payment set status`).
+						WithArgs(3, "captured", "fake_1_1", "").
+						WillReturnRows(sqlmock.NewRows([]string{"updated_at"}).AddRow(now))
+					mock.ExpectCommit()
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:   "Conflict capture of cancelled order is refunded",
+			fields: fields{pool: pool},
+			args: args{
+				ctx:     context.Background(),
+				payment: &models.Payment{ID: 3, OrderID: 1, Amount: 420, Status: "captured", Reference: "fake_1_1"},
+				mockFn: func() {
+					mock.ExpectBegin()
+					mock.ExpectQuery(`select status from payment where id=(.+) for update`).
+						WithArgs(3).
+						WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("authorized"))
+					mock.ExpectQuery(`select os.name as order_status from "order" o (.+) for update of o`).
+						WithArgs(1).
+						WillReturnRows(sqlmock.NewRows([]string{"order_status"}).AddRow("cancelled"))
+					mock.ExpectQuery(`update payment set status`).
+						WithArgs(3, "captured", "fake_1_1", "").
+						WillReturnRows(sqlmock.NewRows([]string{"updated_at"}).AddRow(now))
+					mock.ExpectQuery(`insert into refund`).
+						WithArgs(1, 3, 420.0, "order is cancelled before the capture", 0, "pending", sqlmock.AnyArg()).
+						WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(1, now))
+					mock.ExpectCommit()
+				},
+			},
+			wantCause: models.ErrConflict,
+			wantErr:   true,
+		},
+		{
+			name:   "Failure capture declined payment",
+			fields: fields{pool: pool},
+			args: args{
+				ctx:     context.Background(),
+				payment: &models.Payment{ID: 3, Status: "captured", Reference: "fake_1_1"},
+				mockFn: func() {
+					mock.ExpectBegin()
+					mock.ExpectQuery(`select status from payment where id=(.+) for update`).
+						WithArgs(3).
+						WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("declined"))
+					mock.ExpectRollback()
+				},
+			},
+			wantCause: models.ErrInvalidTransition,
+			wantErr:   true,
+		},
+		{
+			name:   "Failure not found",
+			fields: fields{pool: pool},
+			args: args{
+				ctx:     context.Background(),
+				payment: &models.Payment{ID: 99, Status: "captured"},
+				mockFn: func() {
+					mock.ExpectBegin()
+					mock.ExpectQuery(`select status from payment where id=(.+) for update`).
+						WithArgs(99).
+						WillReturnError(sql.ErrNoRows)
+					mock.ExpectRollback()
+				},
+			},
+			wantCause: models.ErrNotFound,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Database{
+				pool: tt.fields.pool,
+			}
+
+			tt.args.mockFn()
+
+			err := d.UpdatePayment(tt.args.ctx, tt.args.payment)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("UpdatePayment() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantCause != nil && pkgerrors.Cause(err) != tt.wantCause {
+				t.Errorf("UpdatePayment() error = %v, wantCause %v", err, tt.wantCause)
+			}
+
+			if !tt.wantErr && !tt.args.payment.UpdatedAt.Equal(now) {
+				t.Errorf("UpdatePayment() updated_at = %v, want %v", tt.args.payment.UpdatedAt, now)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
@@ -3,6 +3,8 @@ package psql
 import (
 	"context"
 	"database/sql"
+	"strconv"
+	"time"
 
 	"github.com/IamStubborN/petstore/db/models"
 	"github.com/jmoiron/sqlx"
@@ -10,7 +12,7 @@ import (
 	"github.com/pkg/errors"
 )
 
-func (d *Database) AddPetToStore(ctx context.Context, pet *models.Pet) (*models.Pet, error) {
+func (d *Database) AddPetToStore(ctx context.Context, pet *models.Pet, userID int64) (*models.Pet, error) {
 	tx, err := d.pool.BeginTxx(ctx, &sql.TxOptions{})
 	if err != nil {
 		return nil, errors.Wrap(err, "can't start transaction")
@@ -29,6 +31,11 @@ func (d *Database) AddPetToStore(ctx context.Context, pet *models.Pet) (*models.
 		return nil, errors.Wrap(err, "can't find pet_status")
 	}
 
+	pet.Tags, err = d.resolveTags(ctx, tx, pet.Tags)
+	if err != nil {
+		return nil, err
+	}
+
 	stmt, err := tx.PrepareNamedContext(ctx, qm[petAddToStoreQ])
 	if err != nil {
 		return nil, err
@@ -40,6 +47,7 @@ func (d *Database) AddPetToStore(ctx context.Context, pet *models.Pet) (*models.
 		"name":          pet.Name,
 		"photo_urls":    pq.Array(pet.PhotoURLs),
 		"pet_status_id": petStatusID,
+		"quantity":      pet.Quantity,
 	}
 
 	var petID int64
@@ -50,6 +58,10 @@ func (d *Database) AddPetToStore(ctx context.Context, pet *models.Pet) (*models.
 
 	pet.ID = petID
 
+	if _, err = tx.ExecContext(ctx, qm[petStatusHistoryAddQ], petID, nil, pet.Status, userID); err != nil {
+		return nil, errors.Wrap(err, "can't insert into pet_status_history")
+	}
+
 	for _, tag := range pet.Tags {
 		argQ = map[string]interface{}{
 			"pet_id": petID,
@@ -62,10 +74,14 @@ func (d *Database) AddPetToStore(ctx context.Context, pet *models.Pet) (*models.
 		}
 	}
 
+	if err = addEvent(ctx, tx, models.AggregatePet, keyOf(petID), models.EventPetCreated, pet); err != nil {
+		return nil, err
+	}
+
 	return pet, nil
 }
 
-func (d *Database) UpdatePetInStoreByBody(ctx context.Context, pet *models.Pet) (*models.Pet, error) {
+func (d *Database) UpdatePetInStoreByBody(ctx context.Context, pet *models.Pet, userID int64) (*models.Pet, error) {
 	tx, err := d.pool.BeginTxx(ctx, &sql.TxOptions{})
 	if err != nil {
 		return nil, errors.Wrap(err, "can't start transaction")
@@ -84,6 +100,15 @@ func (d *Database) UpdatePetInStoreByBody(ctx context.Context, pet *models.Pet)
 		return nil, errors.Wrap(err, "can't find pet_status")
 	}
 
+	pet.Tags, err = d.resolveTags(ctx, tx, pet.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = changePetStatusByHand(ctx, tx, pet.ID, pet.Status, userID); err != nil {
+		return nil, err
+	}
+
 	stmt, err := tx.PrepareNamedContext(ctx, qm[petUpdateWithBodyQ])
 	if err != nil {
 		return nil, err
@@ -96,6 +121,7 @@ func (d *Database) UpdatePetInStoreByBody(ctx context.Context, pet *models.Pet)
 		"name":          pet.Name,
 		"photo_urls":    pq.Array(pet.PhotoURLs),
 		"pet_status_id": petStatusID,
+		"quantity":      pet.Quantity,
 	}
 
 	var petID int64
@@ -122,37 +148,110 @@ func (d *Database) UpdatePetInStoreByBody(ctx context.Context, pet *models.Pet)
 		}
 	}
 
+	if err = addEvent(ctx, tx, models.AggregatePet, keyOf(petID), models.EventPetUpdated, pet); err != nil {
+		return nil, err
+	}
+
 	return pet, nil
 }
 
-func (d *Database) FindPetsByStatus(ctx context.Context, status []string) (*models.PetList, error) {
-	rows, err := d.pool.QueryxContext(ctx, qm[petFindByStatusQ], pq.Array(status))
+func (d *Database) FindPetsByStatus(ctx context.Context, filter models.PetFilter) (*models.PetList, string, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, "", err
+	}
+
+	after, err := models.DecodeCursor(filter.After)
 	if err != nil {
-		return nil, errors.Wrap(err, "can't get data from pet_info")
+		return nil, "", err
 	}
-	defer checkError(rows.Close)
 
-	var pets models.PetList
-	for rows.Next() {
-		var pet models.Pet
-		err = rows.Scan(
-			&pet.ID,
-			&pet.Category.ID,
-			&pet.Category.Name,
-			&pet.Name,
-			pq.Array(&pet.PhotoURLs),
-			&pet.Status,
-		)
-		if err != nil {
-			return nil, err
+	query := qm[petFindByStatusQ]
+	args := []interface{}{pq.Array(filter.Status), filter.Category, filter.NamePrefix, filter.Tag}
+
+	switch filter.SortBy {
+	case models.PetSortByName:
+		query = qm[petFindByStatusSortNameQ]
+		args = append(args, after.Key)
+	case models.PetSortByPrice:
+		query = qm[petFindByStatusSortPriceQ]
+
+		var price float64
+		if after.ID != 0 {
+			if price, err = strconv.ParseFloat(after.Key, 64); err != nil {
+				return nil, "", errors.Wrap(err, "cursor is malformed")
+			}
 		}
+		args = append(args, price)
+	}
 
-		pet.Tags, err = d.getTagsByPetID(ctx, pet.ID)
-		if err != nil {
-			return nil, errors.Wrap(err, "can't get tags by id")
+	// one extra row tells whether the next page exists
+	args = append(args, after.ID, filter.Limit+1)
+
+	rows, err := d.pool.QueryxContext(ctx, query, args...)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "can't get data from pet_info")
+	}
+	defer checkError(rows.Close)
+
+	pets := models.PetList{}
+	if err = d.scanPets(ctx, rows, &pets); err != nil {
+		return nil, "", err
+	}
+
+	var next string
+	if len(pets) > filter.Limit {
+		pets = pets[:filter.Limit]
+		next = filter.CursorOf(pets[len(pets)-1]).Encode()
+	}
+
+	return &pets, next, nil
+}
+
+func (d *Database) FindPetsByTags(ctx context.Context, tags []string, matchAll bool) (*models.PetList, error) {
+	var tagIDs []int64
+	var tagNames []string
+	for _, tag := range tags {
+		if id, err := strconv.ParseInt(tag, 10, 64); err == nil {
+			tagIDs = append(tagIDs, id)
+			continue
 		}
+		tagNames = append(tagNames, tag)
+	}
+
+	rows, err := d.pool.QueryxContext(ctx, qm[tagsGetByIDsOrNamesQ], pq.Array(tagIDs), pq.Array(tagNames))
+	if err != nil {
+		return nil, errors.Wrap(err, "can't get data from tag")
+	}
+	defer checkError(rows.Close)
+
+	var found []models.Tag
+	if err = sqlx.StructScan(rows, &found); err != nil {
+		return nil, errors.Wrap(err, "can't scan data from tag")
+	}
+
+	pets := models.PetList{}
+	if len(found) == 0 || (matchAll && !isAllTagsFound(tags, found)) {
+		return &pets, nil
+	}
 
-		pets = append(pets, &pet)
+	foundIDs := make([]int64, 0, len(found))
+	for _, tag := range found {
+		foundIDs = append(foundIDs, tag.ID)
+	}
+
+	minMatches := 1
+	if matchAll {
+		minMatches = len(foundIDs)
+	}
+
+	petRows, err := d.pool.QueryxContext(ctx, qm[petFindByTagsQ], pq.Array(foundIDs), minMatches)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't get data from pet_info")
+	}
+	defer checkError(petRows.Close)
+
+	if err = d.scanPets(ctx, petRows, &pets); err != nil {
+		return nil, err
 	}
 
 	return &pets, nil
@@ -165,7 +264,7 @@ func (d *Database) GetPetByID(ctx context.Context, id int64) (*models.Pet, error
 	err := row.Scan(
 		&pet.ID, &pet.Category.ID,
 		&pet.Category.Name, &pet.Name,
-		pq.Array(&pet.PhotoURLs), &pet.Status)
+		pq.Array(&pet.PhotoURLs), &pet.Status, &pet.Quantity)
 	if err != nil {
 		return nil, errors.Wrap(err, "can't find by id")
 	}
@@ -178,38 +277,61 @@ func (d *Database) GetPetByID(ctx context.Context, id int64) (*models.Pet, error
 	return &pet, nil
 }
 
-func (d *Database) UpdatePetInStoreByForm(ctx context.Context, petID int64, name, status string) error {
+func (d *Database) UpdatePetInStoreByForm(ctx context.Context, petID int64, name, status string, userID int64) error {
+	tx, err := d.pool.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return errors.Wrap(err, "can't start transaction")
+	}
+
+	defer func() {
+		if err != nil {
+			checkError(tx.Rollback)
+			return
+		}
+		checkError(tx.Commit)
+	}()
+
 	var petStatusID int64
-	err := d.pool.GetContext(ctx, &petStatusID, qm[petGetStatusIDByNameQ], status)
+	err = tx.GetContext(ctx, &petStatusID, qm[petGetStatusIDByNameQ], status)
 	if err != nil {
 		return errors.Wrap(err, "can't find pet_status")
 	}
 
+	if err = changePetStatusByHand(ctx, tx, petID, status, userID); err != nil {
+		return err
+	}
+
 	argQ := map[string]interface{}{
 		"id":            petID,
 		"name":          name,
 		"pet_status_id": petStatusID,
 	}
 
-	res, err := d.pool.NamedExecContext(ctx, qm[petUpdateWithFieldsQ], argQ)
-	if err != nil {
+	if _, err = tx.NamedExecContext(ctx, qm[petUpdateWithFieldsQ], argQ); err != nil {
 		return errors.Wrap(err, "can't update pet")
 	}
 
-	count, err := res.RowsAffected()
-	if err != nil {
-		return err
-	}
+	payload := &models.PetPayload{ID: petID, Name: name, Status: status}
+	err = addEvent(ctx, tx, models.AggregatePet, keyOf(petID), models.EventPetUpdated, payload)
 
-	if count == 0 {
-		return errors.Errorf("pet № %d doesn't exist", petID)
-	}
-
-	return nil
+	return err
 }
 
 func (d *Database) DeletePetByID(ctx context.Context, petID int64) error {
-	res, err := d.pool.ExecContext(ctx, qm[petDeleteByIDQ], petID)
+	tx, err := d.pool.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return errors.Wrap(err, "can't start transaction")
+	}
+
+	defer func() {
+		if err != nil {
+			checkError(tx.Rollback)
+			return
+		}
+		checkError(tx.Commit)
+	}()
+
+	res, err := tx.ExecContext(ctx, qm[petDeleteByIDQ], petID)
 	if err != nil {
 		return errors.Wrap(err, "can't delete from pet")
 	}
@@ -220,19 +342,37 @@ func (d *Database) DeletePetByID(ctx context.Context, petID int64) error {
 	}
 
 	if count == 0 {
-		return errors.Errorf("pet № %d doesn't exist", petID)
+		err = errors.Errorf("pet № %d doesn't exist", petID)
+		return err
 	}
 
-	return nil
+	err = addEvent(ctx, tx, models.AggregatePet, keyOf(petID), models.EventPetDeleted, &models.PetPayload{ID: petID})
+
+	return err
 }
 
-func (d *Database) UpdatePetPhotosByID(ctx context.Context, petID int64, imagesURL []string) error {
+// AddPetPhotosByID appends the photos to the pet,
+// the event of the change lists only the added photos.
+func (d *Database) AddPetPhotosByID(ctx context.Context, petID int64, imagesURL []string) error {
+	tx, err := d.pool.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return errors.Wrap(err, "can't start transaction")
+	}
+
+	defer func() {
+		if err != nil {
+			checkError(tx.Rollback)
+			return
+		}
+		checkError(tx.Commit)
+	}()
+
 	argQ := map[string]interface{}{
 		"id":         petID,
 		"photo_urls": pq.Array(imagesURL),
 	}
 
-	res, err := d.pool.NamedExecContext(ctx, qm[petUpdatePhotosByIDQ], argQ)
+	res, err := tx.NamedExecContext(ctx, qm[petAddPhotosByIDQ], argQ)
 	if err != nil {
 		return errors.Wrap(err, "can't update photo_urls")
 	}
@@ -243,10 +383,110 @@ func (d *Database) UpdatePetPhotosByID(ctx context.Context, petID int64, imagesU
 	}
 
 	if count == 0 {
-		return errors.Errorf("pet № %d doesn't exist", petID)
+		err = errors.Errorf("pet № %d doesn't exist", petID)
+		return err
 	}
 
-	return nil
+	payload := &models.PetPayload{ID: petID, PhotoURLs: imagesURL}
+	err = addEvent(ctx, tx, models.AggregatePet, keyOf(petID), models.EventPetUpdated, payload)
+
+	return err
+}
+
+func (d *Database) GetPetStatusHistory(ctx context.Context, petID int64) (models.PetStatusHistory, error) {
+	var isExist bool
+	if err := d.pool.GetContext(ctx, &isExist, qm[petIsExistQ], petID); err != nil {
+		return nil, errors.Wrap(err, "can't find pet")
+	}
+
+	if !isExist {
+		return nil, errors.Wrapf(models.ErrNotFound, "pet № %d doesn't exist", petID)
+	}
+
+	history := models.PetStatusHistory{}
+	if err := d.pool.SelectContext(ctx, &history, qm[petStatusHistoryGetQ], petID); err != nil {
+		return nil, errors.Wrap(err, "can't get data from pet_status_history")
+	}
+
+	return history, nil
+}
+
+// changePetStatus moves a locked pet to the status when the lifecycle allows it
+// and records the transition, keeping the current status records nothing.
+func changePetStatus(ctx context.Context, tx *sqlx.Tx, petID int64, status string, userID int64) error {
+	current, err := lockPetStatus(ctx, tx, petID)
+	if err != nil {
+		return err
+	}
+
+	return setPetStatus(ctx, tx, petID, current, status, userID)
+}
+
+// changePetStatusByHand moves the pet like changePetStatus, but a pet
+// an active order holds moves only with its order.
+func changePetStatusByHand(ctx context.Context, tx *sqlx.Tx, petID int64, status string, userID int64) error {
+	current, err := lockPetStatus(ctx, tx, petID)
+	if err != nil {
+		return err
+	}
+
+	if current == models.PetStatusPending && status != models.PetStatusPending {
+		var isHeld bool
+		err = tx.GetContext(ctx, &isHeld, qm[petIsHeldQ], petID, pq.Array(models.ActiveOrderStatuses))
+		if err != nil {
+			return errors.Wrap(err, "can't find orders of pet")
+		}
+
+		if isHeld {
+			return errors.Wrapf(models.ErrConflict, "pet № %d is held by an order", petID)
+		}
+	}
+
+	return setPetStatus(ctx, tx, petID, current, status, userID)
+}
+
+// lockPetStatus locks the pet and returns its status.
+func lockPetStatus(ctx context.Context, tx *sqlx.Tx, petID int64) (string, error) {
+	var current string
+	err := tx.GetContext(ctx, &current, qm[petLockStatusByIDQ], petID)
+	if err == sql.ErrNoRows {
+		return "", errors.Wrapf(models.ErrNotFound, "pet № %d doesn't exist", petID)
+	}
+	if err != nil {
+		return "", errors.Wrap(err, "can't lock pet")
+	}
+
+	return current, nil
+}
+
+// setPetStatus moves the locked pet from its current status.
+func setPetStatus(ctx context.Context, tx *sqlx.Tx, petID int64, current, status string, userID int64) error {
+	err := models.CheckPetStatusTransition(current, status)
+	if err != nil {
+		return err
+	}
+
+	if current == status {
+		return nil
+	}
+
+	if _, err = tx.ExecContext(ctx, qm[petSetStatusQ], petID, status); err != nil {
+		return errors.Wrap(err, "can't update pet status")
+	}
+
+	if _, err = tx.ExecContext(ctx, qm[petStatusHistoryAddQ], petID, current, status, userID); err != nil {
+		return errors.Wrap(err, "can't insert into pet_status_history")
+	}
+
+	change := &models.PetStatusChange{
+		PetID:      petID,
+		FromStatus: current,
+		ToStatus:   status,
+		UserID:     userID,
+		ChangedAt:  time.Now().UTC(),
+	}
+
+	return addEvent(ctx, tx, models.AggregatePet, keyOf(petID), models.EventPetStatusChanged, change)
 }
 
 func (d *Database) getTagsByPetID(ctx context.Context, petID int64) ([]models.Tag, error) {
@@ -263,3 +503,89 @@ func (d *Database) getTagsByPetID(ctx context.Context, petID int64) ([]models.Ta
 
 	return tags, nil
 }
+
+// scanPets reads a page of pets and loads tags for the whole page
+// with a single query, so the number of queries doesn't grow with the page.
+func (d *Database) scanPets(ctx context.Context, rows *sqlx.Rows, pets *models.PetList) error {
+	for rows.Next() {
+		var pet models.Pet
+		err := rows.Scan(
+			&pet.ID,
+			&pet.Category.ID,
+			&pet.Category.Name,
+			&pet.Category.Price,
+			&pet.Name,
+			pq.Array(&pet.PhotoURLs),
+			&pet.Status,
+			&pet.Quantity,
+		)
+		if err != nil {
+			return err
+		}
+
+		*pets = append(*pets, &pet)
+	}
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if err := d.fillTags(ctx, *pets); err != nil {
+		return errors.Wrap(err, "can't get tags by pet ids")
+	}
+
+	return nil
+}
+
+func (d *Database) fillTags(ctx context.Context, pets models.PetList) error {
+	if len(pets) == 0 {
+		return nil
+	}
+
+	petsByID := make(map[int64]*models.Pet, len(pets))
+	petIDs := make([]int64, 0, len(pets))
+	for _, pet := range pets {
+		petsByID[pet.ID] = pet
+		petIDs = append(petIDs, pet.ID)
+	}
+
+	rows, err := d.pool.QueryxContext(ctx, qm[tagsGetByPetIDsQ], pq.Array(petIDs))
+	if err != nil {
+		return err
+	}
+	defer checkError(rows.Close)
+
+	for rows.Next() {
+		var petID int64
+		var tag models.Tag
+		if err = rows.Scan(&petID, &tag.ID, &tag.Name); err != nil {
+			return err
+		}
+
+		if pet, ok := petsByID[petID]; ok {
+			pet.Tags = append(pet.Tags, tag)
+		}
+	}
+
+	return rows.Err()
+}
+
+// isAllTagsFound reports whether every requested tag, given by id or name,
+// is present in the found tags.
+func isAllTagsFound(requested []string, found []models.Tag) bool {
+	for _, req := range requested {
+		var isFound bool
+		for _, tag := range found {
+			if tag.Name == req || strconv.FormatInt(tag.ID, 10) == req {
+				isFound = true
+				break
+			}
+		}
+
+		if !isFound {
+			return false
+		}
+	}
+
+	return true
+}
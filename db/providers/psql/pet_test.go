@@ -2,12 +2,16 @@ package psql
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"reflect"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/IamStubborN/petstore/db/models"
 	"github.com/lib/pq"
+	pkgerrors "github.com/pkg/errors"
 
 	"github.com/DATA-DOG/go-sqlmock"
 
@@ -59,15 +63,23 @@ func TestDatabase_AddPetToStore(t *testing.T) {
 					mock.ExpectQuery(`select id from pet_status`).
 						WithArgs("pending").
 						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+					mock.ExpectQuery(`select id, name from tag`).
+						WithArgs(pq.Array([]int64{1, 4, 5}), pq.Array([]string(nil))).
+						WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).
+							AddRow(1, "small").AddRow(4, "best").AddRow(5, "cool"))
 					mock.ExpectPrepare(`insert into pet (.+) values (.+) returning id`).ExpectQuery().
-						WithArgs(1, "Soo", pq.Array([]string{"1", "2", "3"}), 1).
+						WithArgs(1, "Soo", pq.Array([]string{"1", "2", "3"}), 1, 0).
 						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(45))
+					mock.ExpectExec(`insert into pet_status_history`).
+						WithArgs(45, nil, "pending", 0).
+						WillReturnResult(sqlmock.NewResult(1, 1))
 					mock.ExpectExec(`insert into pet_tag`).
 						WithArgs(45, 1).WillReturnResult(sqlmock.NewResult(1, 1))
 					mock.ExpectExec(`insert into pet_tag`).
 						WithArgs(45, 4).WillReturnResult(sqlmock.NewResult(1, 1))
 					mock.ExpectExec(`insert into pet_tag`).
 						WithArgs(45, 5).WillReturnResult(sqlmock.NewResult(1, 1))
+					expectEvent(mock, "pet", "45", "pet.created")
 					mock.ExpectCommit()
 				},
 			},
@@ -130,7 +142,7 @@ func TestDatabase_AddPetToStore(t *testing.T) {
 
 			tt.args.mockFn()
 
-			got, err := d.AddPetToStore(tt.args.ctx, tt.args.pet)
+			got, err := d.AddPetToStore(tt.args.ctx, tt.args.pet, 0)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("AddPetToStore() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -172,9 +184,12 @@ func TestDatabase_DeletePetByID(t *testing.T) {
 				ctx:   context.Background(),
 				petID: 1,
 				mockFn: func() {
+					mock.ExpectBegin()
 					mock.ExpectExec(`delete from pet`).
 						WithArgs(1).
 						WillReturnResult(sqlmock.NewResult(0, 1))
+					expectEvent(mock, "pet", "1", "pet.deleted")
+					mock.ExpectCommit()
 				},
 			},
 			wantErr: false,
@@ -186,9 +201,11 @@ func TestDatabase_DeletePetByID(t *testing.T) {
 				ctx:   context.Background(),
 				petID: 99,
 				mockFn: func() {
+					mock.ExpectBegin()
 					mock.ExpectExec(`delete from pet`).
 						WithArgs(99).
 						WillReturnResult(sqlmock.NewResult(0, 0))
+					mock.ExpectRollback()
 				},
 			},
 			wantErr: true,
@@ -217,53 +234,53 @@ func TestDatabase_FindPetsByStatus(t *testing.T) {
 	pool, mock := generateMockedDB()
 	defer checkError(pool.Close)
 
+	petColumns := []string{"id", "category_id", "category_name", "price",
+		"name", "photo_urls", "pet_status_name", "quantity"}
+
 	type fields struct {
 		pool *sqlx.DB
 	}
 	type args struct {
 		ctx    context.Context
-		status []string
+		filter models.PetFilter
 		mockFn func()
 	}
 	tests := []struct {
-		name    string
-		fields  fields
-		args    args
-		want    *models.PetList
-		wantErr bool
+		name     string
+		fields   fields
+		args     args
+		want     *models.PetList
+		wantNext string
+		wantErr  bool
 	}{
 		{
 			name:   "Success 1",
 			fields: fields{pool: pool},
 			args: args{
 				ctx:    context.Background(),
-				status: []string{"pending", "available"},
+				filter: models.PetFilter{Status: []string{"pending", "available"}},
 				mockFn: func() {
-					mock.ExpectQuery(`select (.+) from pet_info`).
-						WithArgs(pq.Array([]string{"pending", "available"})).
-						WillReturnRows(sqlmock.NewRows(
-							[]string{"id", "category_id", "category_name",
-								"name", "photo_urls", "pet_status_name"}).
-							AddRow(1, 1, "Cat", "Soo",
-								pq.Array([]string{"1", "2", "3"}), "pending").
-							AddRow(2, 2, "Dog", "Sylar",
-								pq.Array([]string{"1", "2", "3"}), "available"))
-					mock.ExpectQuery(`select (.+) from pet_tag`).
-						WithArgs(1).
-						WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).
-							AddRow(1, "small").AddRow(4, "best").AddRow(5, "cool"))
-					mock.ExpectQuery(`select (.+) from pet_tag`).
-						WithArgs(2).
-						WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).
-							AddRow(1, "small").AddRow(2, "average").AddRow(3, "large"))
+					mock.ExpectQuery(`select (.+) from pet_info (.+) order by id`).
+						WithArgs(pq.Array([]string{"pending", "available"}), "", "", "", 0, 101).
+						WillReturnRows(sqlmock.NewRows(petColumns).
+							AddRow(1, 1, "Cat", 35.00, "Soo",
+								pq.Array([]string{"1", "2", "3"}), "pending", 1).
+							AddRow(2, 2, "Dog", 49.99, "Sylar",
+								pq.Array([]string{"1", "2", "3"}), "available", 1))
+					mock.ExpectQuery(`select (.+) from pet_tag (.+) where pet_id = any`).
+						WithArgs(pq.Array([]int64{1, 2})).
+						WillReturnRows(sqlmock.NewRows([]string{"pet_id", "id", "name"}).
+							AddRow(1, 1, "small").AddRow(1, 4, "best").AddRow(1, 5, "cool").
+							AddRow(2, 1, "small").AddRow(2, 2, "average").AddRow(2, 3, "large"))
 				},
 			},
 			want: &models.PetList{
 				{
 					ID: 1,
 					Category: models.Category{
-						ID:   1,
-						Name: "Cat",
+						ID:    1,
+						Name:  "Cat",
+						Price: 35.00,
 					},
 					Name:      "Soo",
 					PhotoURLs: []string{"1", "2", "3"},
@@ -272,13 +289,15 @@ func TestDatabase_FindPetsByStatus(t *testing.T) {
 						{ID: 4, Name: "best"},
 						{ID: 5, Name: "cool"},
 					},
-					Status: "pending",
+					Status:   "pending",
+					Quantity: 1,
 				},
 				{
 					ID: 2,
 					Category: models.Category{
-						ID:   2,
-						Name: "Dog",
+						ID:    2,
+						Name:  "Dog",
+						Price: 49.99,
 					},
 					Name:      "Sylar",
 					PhotoURLs: []string{"1", "2", "3"},
@@ -287,26 +306,80 @@ func TestDatabase_FindPetsByStatus(t *testing.T) {
 						{ID: 2, Name: "average"},
 						{ID: 3, Name: "large"},
 					},
-					Status: "available",
+					Status:   "available",
+					Quantity: 1,
 				},
 			},
 			wantErr: false,
 		},
+		{
+			name:   "Success next page sorted by name",
+			fields: fields{pool: pool},
+			args: args{
+				ctx: context.Background(),
+				filter: models.PetFilter{
+					Status:   []string{"available"},
+					Category: "Dog",
+					SortBy:   models.PetSortByName,
+					Limit:    1,
+					After:    models.Cursor{Key: "Rex", ID: 7}.Encode(),
+				},
+				mockFn: func() {
+					mock.ExpectQuery(`select (.+) from pet_info (.+) order by name, id`).
+						WithArgs(pq.Array([]string{"available"}), "Dog", "", "", "Rex", 7, 2).
+						WillReturnRows(sqlmock.NewRows(petColumns).
+							AddRow(2, 2, "Dog", 49.99, "Sylar",
+								pq.Array([]string{"1"}), "available", 1).
+							AddRow(3, 2, "Dog", 49.99, "Tom",
+								pq.Array([]string{"1"}), "available", 1))
+					mock.ExpectQuery(`select (.+) from pet_tag (.+) where pet_id = any`).
+						WithArgs(pq.Array([]int64{2, 3})).
+						WillReturnRows(sqlmock.NewRows([]string{"pet_id", "id", "name"}))
+				},
+			},
+			want: &models.PetList{
+				{
+					ID: 2,
+					Category: models.Category{
+						ID:    2,
+						Name:  "Dog",
+						Price: 49.99,
+					},
+					Name:      "Sylar",
+					PhotoURLs: []string{"1"},
+					Status:    "available",
+					Quantity:  1,
+				},
+			},
+			wantNext: models.Cursor{Key: "Sylar", ID: 2}.Encode(),
+			wantErr:  false,
+		},
 		{
 			name:   "Failure 1",
 			fields: fields{pool: pool},
 			args: args{
 				ctx:    context.Background(),
-				status: []string{"bad status"},
+				filter: models.PetFilter{Status: []string{"bad status"}},
 				mockFn: func() {
 					mock.ExpectQuery(`select (.+) from pet_info`).
-						WithArgs(pq.Array([]string{"bad status"})).
+						WithArgs(pq.Array([]string{"bad status"}), "", "", "", 0, 101).
 						WillReturnError(errors.New("can't get data from pet_info"))
 				},
 			},
 			want:    nil,
 			wantErr: true,
 		},
+		{
+			name:   "Failure bad sort",
+			fields: fields{pool: pool},
+			args: args{
+				ctx:    context.Background(),
+				filter: models.PetFilter{Status: []string{"available"}, SortBy: "age"},
+				mockFn: func() {},
+			},
+			want:    nil,
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -316,18 +389,140 @@ func TestDatabase_FindPetsByStatus(t *testing.T) {
 
 			tt.args.mockFn()
 
-			got, err := d.FindPetsByStatus(tt.args.ctx, tt.args.status)
+			got, next, err := d.FindPetsByStatus(tt.args.ctx, tt.args.filter)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("FindPetsByStatus() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
 
-			if got != nil {
-				for idx := range *(got) {
-					if !reflect.DeepEqual(*(*(got))[idx], *(*(tt.want))[idx]) {
-						t.Errorf("FindPetsByStatus() got = %v, want %v", *(*(got))[idx], *(*(tt.want))[idx])
-					}
-				}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindPetsByStatus() got = %v, want %v", got, tt.want)
+			}
+
+			if next != tt.wantNext {
+				t.Errorf("FindPetsByStatus() next = %v, want %v", next, tt.wantNext)
+			}
+
+			if err = mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func TestDatabase_FindPetsByTags(t *testing.T) {
+	pool, mock := generateMockedDB()
+	defer checkError(pool.Close)
+
+	type fields struct {
+		pool *sqlx.DB
+	}
+	type args struct {
+		ctx      context.Context
+		tags     []string
+		matchAll bool
+		mockFn   func()
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    *models.PetList
+		wantErr bool
+	}{
+		{
+			name:   "Success any",
+			fields: fields{pool: pool},
+			args: args{
+				ctx:  context.Background(),
+				tags: []string{"small", "4"},
+				mockFn: func() {
+					mock.ExpectQuery(`select id, name from tag`).
+						WithArgs(pq.Array([]int64{4}), pq.Array([]string{"small"})).
+						WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).
+							AddRow(1, "small").AddRow(4, "best"))
+					mock.ExpectQuery(`select (.+) from pet_info`).
+						WithArgs(pq.Array([]int64{1, 4}), 1).
+						WillReturnRows(sqlmock.NewRows(
+							[]string{"id", "category_id", "category_name", "price",
+								"name", "photo_urls", "pet_status_name", "quantity"}).
+							AddRow(1, 1, "Cat", 35.00, "Soo",
+								pq.Array([]string{"1", "2", "3"}), "pending", 1))
+					mock.ExpectQuery(`select (.+) from pet_tag (.+) where pet_id = any`).
+						WithArgs(pq.Array([]int64{1})).
+						WillReturnRows(sqlmock.NewRows([]string{"pet_id", "id", "name"}).
+							AddRow(1, 1, "small").AddRow(1, 4, "best"))
+				},
+			},
+			want: &models.PetList{
+				{
+					ID: 1,
+					Category: models.Category{
+						ID:    1,
+						Name:  "Cat",
+						Price: 35.00,
+					},
+					Name:      "Soo",
+					PhotoURLs: []string{"1", "2", "3"},
+					Tags: []models.Tag{
+						{ID: 1, Name: "small"},
+						{ID: 4, Name: "best"},
+					},
+					Status:   "pending",
+					Quantity: 1,
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:   "Success all with unknown tag",
+			fields: fields{pool: pool},
+			args: args{
+				ctx:      context.Background(),
+				tags:     []string{"small", "unknown"},
+				matchAll: true,
+				mockFn: func() {
+					mock.ExpectQuery(`select id, name from tag`).
+						WithArgs(pq.Array([]int64(nil)), pq.Array([]string{"small", "unknown"})).
+						WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).
+							AddRow(1, "small"))
+				},
+			},
+			want:    &models.PetList{},
+			wantErr: false,
+		},
+		{
+			name:   "Failure 1",
+			fields: fields{pool: pool},
+			args: args{
+				ctx:  context.Background(),
+				tags: []string{"small"},
+				mockFn: func() {
+					mock.ExpectQuery(`select id, name from tag`).
+						WithArgs(pq.Array([]int64(nil)), pq.Array([]string{"small"})).
+						WillReturnError(errors.New("can't get data from tag"))
+				},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Database{
+				pool: tt.fields.pool,
+			}
+
+			tt.args.mockFn()
+
+			got, err := d.FindPetsByTags(tt.args.ctx, tt.args.tags, tt.args.matchAll)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("FindPetsByTags() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindPetsByTags() got = %v, want %v", got, tt.want)
 			}
 
 			if err = mock.ExpectationsWereMet(); err != nil {
@@ -368,9 +563,9 @@ func TestDatabase_GetPetByID(t *testing.T) {
 						WillReturnRows(sqlmock.NewRows([]string{
 							"id", "category_id",
 							"category_name", "name", "photo_urls",
-							"pet_status_name",
+							"pet_status_name", "quantity",
 						}).AddRow(1, 1, "Cat", "Soo",
-							pq.Array([]string{"1", "2", "3"}), "pending"))
+							pq.Array([]string{"1", "2", "3"}), "pending", 1))
 					mock.ExpectQuery(`select (.+) from pet_tag`).
 						WithArgs(1).
 						WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).
@@ -390,7 +585,8 @@ func TestDatabase_GetPetByID(t *testing.T) {
 					{ID: 4, Name: "best"},
 					{ID: 5, Name: "cool"},
 				},
-				Status: "pending",
+				Status:   "pending",
+				Quantity: 1,
 			},
 			wantErr: false,
 		},
@@ -478,8 +674,22 @@ func TestDatabase_UpdatePetInStoreByBody(t *testing.T) {
 					mock.ExpectQuery(`select id from pet_status`).
 						WithArgs("pending").
 						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+					mock.ExpectQuery(`select id, name from tag`).
+						WithArgs(pq.Array([]int64{1, 4, 5}), pq.Array([]string(nil))).
+						WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).
+							AddRow(1, "small").AddRow(4, "best").AddRow(5, "cool"))
+					mock.ExpectQuery(`select ps.name from pet p (.+) for update`).
+						WithArgs(1).
+						WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("available"))
+					mock.ExpectExec(`update pet set pet_status_id`).
+						WithArgs(1, "pending").
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectExec(`insert into pet_status_history`).
+						WithArgs(1, "available", "pending", 0).
+						WillReturnResult(sqlmock.NewResult(1, 1))
+					expectEvent(mock, "pet", "1", "pet.status_changed")
 					mock.ExpectPrepare(`update pet set`).ExpectQuery().
-						WithArgs(1, "Soo", pq.Array([]string{"1", "2", "3"}), 1, 1).
+						WithArgs(1, "Soo", pq.Array([]string{"1", "2", "3"}), 1, 0, 1).
 						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(45))
 					mock.ExpectExec(`delete from pet_tag`).
 						WithArgs(45).WillReturnResult(sqlmock.NewResult(45, 1))
@@ -489,6 +699,7 @@ func TestDatabase_UpdatePetInStoreByBody(t *testing.T) {
 						WithArgs(45, 4).WillReturnResult(sqlmock.NewResult(45, 1))
 					mock.ExpectExec(`insert into pet_tag`).
 						WithArgs(45, 5).WillReturnResult(sqlmock.NewResult(45, 1))
+					expectEvent(mock, "pet", "45", "pet.updated")
 					mock.ExpectCommit()
 				},
 			},
@@ -534,8 +745,15 @@ func TestDatabase_UpdatePetInStoreByBody(t *testing.T) {
 					mock.ExpectQuery(`select id from pet_status`).
 						WithArgs("pending").
 						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+					mock.ExpectQuery(`select id, name from tag`).
+						WithArgs(pq.Array([]int64{1, 4, 5}), pq.Array([]string(nil))).
+						WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).
+							AddRow(1, "small").AddRow(4, "best").AddRow(5, "cool"))
+					mock.ExpectQuery(`select ps.name from pet p (.+) for update`).
+						WithArgs(1).
+						WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("pending"))
 					mock.ExpectPrepare(`update pet set`).ExpectQuery().
-						WithArgs(99, "Soo", pq.Array([]string{"1", "2", "3"}), 1, 1).
+						WithArgs(99, "Soo", pq.Array([]string{"1", "2", "3"}), 1, 0, 1).
 						WillReturnError(errors.New("can't exec add query"))
 					mock.ExpectRollback()
 				},
@@ -553,7 +771,7 @@ func TestDatabase_UpdatePetInStoreByBody(t *testing.T) {
 
 			tt.args.mockFn()
 
-			got, err := d.UpdatePetInStoreByBody(tt.args.ctx, tt.args.pet)
+			got, err := d.UpdatePetInStoreByBody(tt.args.ctx, tt.args.pet, 0)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("UpdatePetInStoreByBody() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -585,10 +803,11 @@ func TestDatabase_UpdatePetInStoreByForm(t *testing.T) {
 		mockFn func()
 	}
 	tests := []struct {
-		name    string
-		fields  fields
-		args    args
-		wantErr bool
+		name      string
+		fields    fields
+		args      args
+		wantCause error
+		wantErr   bool
 	}{
 		{
 			name:   "Success 1",
@@ -599,18 +818,113 @@ func TestDatabase_UpdatePetInStoreByForm(t *testing.T) {
 				name:   "Soo",
 				status: "pending",
 				mockFn: func() {
+					mock.ExpectBegin()
 					mock.ExpectQuery(`select id from pet_status`).
 						WithArgs("pending").
+						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+					mock.ExpectQuery(`select ps.name from pet p (.+) for update`).
+						WithArgs(1).
+						WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("available"))
+					mock.ExpectExec(`update pet set pet_status_id`).
+						WithArgs(1, "pending").
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectExec(`insert into pet_status_history`).
+						WithArgs(1, "available", "pending", 0).
+						WillReturnResult(sqlmock.NewResult(1, 1))
+					expectEvent(mock, "pet", "1", "pet.status_changed")
+					mock.ExpectExec(`update pet set name`).
+						WithArgs("Soo", 2, 1).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					expectEvent(mock, "pet", "1", "pet.updated")
+					mock.ExpectCommit()
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:   "Failure illegal transition",
+			fields: fields{pool: pool},
+			args: args{
+				ctx:    context.Background(),
+				petID:  1,
+				name:   "Soo",
+				status: "available",
+				mockFn: func() {
+					mock.ExpectBegin()
+					mock.ExpectQuery(`select id from pet_status`).
+						WithArgs("available").
 						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
-					mock.ExpectExec(`update pet set`).
+					mock.ExpectQuery(`select ps.name from pet p (.+) for update`).
+						WithArgs(1).
+						WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("sold"))
+					mock.ExpectRollback()
+				},
+			},
+			wantCause: models.ErrInvalidTransition,
+			wantErr:   true,
+		},
+		{
+			name:   "Failure pet is held by an order",
+			fields: fields{pool: pool},
+			args: args{
+				ctx:    context.Background(),
+				petID:  1,
+				name:   "Soo",
+				status: "available",
+				mockFn: func() {
+					mock.ExpectBegin()
+					mock.ExpectQuery(`select id from pet_status`).
+						WithArgs("available").
+						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+					mock.ExpectQuery(`select ps.name from pet p (.+) for update`).
+						WithArgs(1).
+						WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("pending"))
+					mock.ExpectQuery(`select exists\((.+) from order_item oi`).
+						WithArgs(1, pq.Array(models.ActiveOrderStatuses)).
+						WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+					mock.ExpectRollback()
+				},
+			},
+			wantCause: models.ErrConflict,
+			wantErr:   true,
+		},
+		{
+			name:   "Success pending pet without an order is released",
+			fields: fields{pool: pool},
+			args: args{
+				ctx:    context.Background(),
+				petID:  1,
+				name:   "Soo",
+				status: "available",
+				mockFn: func() {
+					mock.ExpectBegin()
+					mock.ExpectQuery(`select id from pet_status`).
+						WithArgs("available").
+						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+					mock.ExpectQuery(`select ps.name from pet p (.+) for update`).
+						WithArgs(1).
+						WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("pending"))
+					mock.ExpectQuery(`select exists\((.+) from order_item oi`).
+						WithArgs(1, pq.Array(models.ActiveOrderStatuses)).
+						WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+					mock.ExpectExec(`update pet set pet_status_id`).
+						WithArgs(1, "available").
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectExec(`insert into pet_status_history`).
+						WithArgs(1, "pending", "available", 0).
+						WillReturnResult(sqlmock.NewResult(1, 1))
+					expectEvent(mock, "pet", "1", "pet.status_changed")
+					mock.ExpectExec(`update pet set name`).
 						WithArgs("Soo", 1, 1).
 						WillReturnResult(sqlmock.NewResult(0, 1))
+					expectEvent(mock, "pet", "1", "pet.updated")
+					mock.ExpectCommit()
 				},
 			},
 			wantErr: false,
 		},
 		{
-			name:   "Failure 1",
+			name:   "Failure not found",
 			fields: fields{pool: pool},
 			args: args{
 				ctx:    context.Background(),
@@ -618,15 +932,18 @@ func TestDatabase_UpdatePetInStoreByForm(t *testing.T) {
 				name:   "Soo",
 				status: "pending",
 				mockFn: func() {
+					mock.ExpectBegin()
 					mock.ExpectQuery(`select id from pet_status`).
 						WithArgs("pending").
-						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
-					mock.ExpectExec(`update pet set`).
-						WithArgs("Soo", 1, 99).
-						WillReturnError(errors.New("can't update pet"))
+						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+					mock.ExpectQuery(`select ps.name from pet p (.+) for update`).
+						WithArgs(99).
+						WillReturnError(sql.ErrNoRows)
+					mock.ExpectRollback()
 				},
 			},
-			wantErr: true,
+			wantCause: models.ErrNotFound,
+			wantErr:   true,
 		},
 	}
 	for _, tt := range tests {
@@ -637,11 +954,107 @@ func TestDatabase_UpdatePetInStoreByForm(t *testing.T) {
 
 			tt.args.mockFn()
 
-			err := d.UpdatePetInStoreByForm(tt.args.ctx, tt.args.petID, tt.args.name, tt.args.status)
+			err := d.UpdatePetInStoreByForm(tt.args.ctx, tt.args.petID, tt.args.name, tt.args.status, 0)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("UpdatePetInStoreByForm() error = %v, wantErr %v", err, tt.wantErr)
 			}
 
+			if tt.wantCause != nil && pkgerrors.Cause(err) != tt.wantCause {
+				t.Errorf("UpdatePetInStoreByForm() error = %v, wantCause %v", err, tt.wantCause)
+			}
+
+			if err = mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func TestDatabase_GetPetStatusHistory(t *testing.T) {
+	pool, mock := generateMockedDB()
+	defer checkError(pool.Close)
+
+	changedAt := time.Date(2019, 8, 17, 20, 30, 0, 0, time.UTC)
+
+	type fields struct {
+		pool *sqlx.DB
+	}
+	type args struct {
+		ctx    context.Context
+		petID  int64
+		mockFn func()
+	}
+	tests := []struct {
+		name      string
+		fields    fields
+		args      args
+		want      models.PetStatusHistory
+		wantCause error
+		wantErr   bool
+	}{
+		{
+			name:   "Success 1",
+			fields: fields{pool: pool},
+			args: args{
+				ctx:   context.Background(),
+				petID: 1,
+				mockFn: func() {
+					mock.ExpectQuery(`select exists`).
+						WithArgs(1).
+						WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+					mock.ExpectQuery(`select (.+) from pet_status_history`).
+						WithArgs(1).
+						WillReturnRows(sqlmock.NewRows([]string{
+							"id", "pet_id", "from_status", "to_status", "user_id", "changed_at"}).
+							AddRow(1, 1, "", "available", 2, changedAt).
+							AddRow(2, 1, "available", "pending", 3, changedAt))
+				},
+			},
+			want: models.PetStatusHistory{
+				{ID: 1, PetID: 1, ToStatus: "available", UserID: 2, ChangedAt: changedAt},
+				{ID: 2, PetID: 1, FromStatus: "available", ToStatus: "pending", UserID: 3, ChangedAt: changedAt},
+			},
+			wantErr: false,
+		},
+		{
+			name:   "Failure not found",
+			fields: fields{pool: pool},
+			args: args{
+				ctx:   context.Background(),
+				petID: 99,
+				mockFn: func() {
+					mock.ExpectQuery(`select exists`).
+						WithArgs(99).
+						WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+				},
+			},
+			want:      nil,
+			wantCause: models.ErrNotFound,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Database{
+				pool: tt.fields.pool,
+			}
+
+			tt.args.mockFn()
+
+			got, err := d.GetPetStatusHistory(tt.args.ctx, tt.args.petID)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetPetStatusHistory() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.wantCause != nil && pkgerrors.Cause(err) != tt.wantCause {
+				t.Errorf("GetPetStatusHistory() error = %v, wantCause %v", err, tt.wantCause)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetPetStatusHistory() got = %v, want %v", got, tt.want)
+			}
+
 			if err = mock.ExpectationsWereMet(); err != nil {
 				t.Errorf("there were unfulfilled expectations: %s", err)
 			}
@@ -649,7 +1062,7 @@ func TestDatabase_UpdatePetInStoreByForm(t *testing.T) {
 	}
 }
 
-func TestDatabase_UpdatePetPhotosByID(t *testing.T) {
+func TestDatabase_AddPetPhotosByID(t *testing.T) {
 	pool, mock := generateMockedDB()
 	defer checkError(pool.Close)
 
@@ -676,9 +1089,12 @@ func TestDatabase_UpdatePetPhotosByID(t *testing.T) {
 				petID:     1,
 				imagesURL: []string{"1", "2", "3"},
 				mockFn: func() {
-					mock.ExpectExec(`update pet set`).
+					mock.ExpectBegin()
+					mock.ExpectExec(`update pet set photo_urls=array_cat`).
 						WithArgs(pq.Array([]string{"1", "2", "3"}), 1).
 						WillReturnResult(sqlmock.NewResult(1, 1))
+					expectEvent(mock, "pet", "1", "pet.updated")
+					mock.ExpectCommit()
 				},
 			},
 			wantErr: false,
@@ -691,9 +1107,11 @@ func TestDatabase_UpdatePetPhotosByID(t *testing.T) {
 				petID:     99,
 				imagesURL: []string{"1", "2", "3"},
 				mockFn: func() {
-					mock.ExpectExec(`update pet set`).
+					mock.ExpectBegin()
+					mock.ExpectExec(`update pet set photo_urls=array_cat`).
 						WithArgs(pq.Array([]string{"1", "2", "3"}), 99).
 						WillReturnError(errors.New("pet № 99 doesn't exist"))
+					mock.ExpectRollback()
 				},
 			},
 			wantErr: true,
@@ -707,8 +1125,8 @@ func TestDatabase_UpdatePetPhotosByID(t *testing.T) {
 
 			tt.args.mockFn()
 
-			if err := d.UpdatePetPhotosByID(tt.args.ctx, tt.args.petID, tt.args.imagesURL); (err != nil) != tt.wantErr {
-				t.Errorf("UpdatePetPhotosByID() error = %v, wantErr %v", err, tt.wantErr)
+			if err := d.AddPetPhotosByID(tt.args.ctx, tt.args.petID, tt.args.imagesURL); (err != nil) != tt.wantErr {
+				t.Errorf("AddPetPhotosByID() error = %v, wantErr %v", err, tt.wantErr)
 			}
 
 			if err := mock.ExpectationsWereMet(); err != nil {
@@ -797,3 +1215,81 @@ func TestDatabase_getTagsByPetID(t *testing.T) {
 		})
 	}
 }
+
+func benchmarkPetList(b *testing.B, petsCount int, mockFn func(sqlmock.Sqlmock, *sqlmock.Rows, *sqlmock.Rows),
+	findFn func(d *Database) error) {
+	var queries int
+	matcher := sqlmock.QueryMatcherFunc(func(expectedSQL, actualSQL string) error {
+		err := sqlmock.QueryMatcherRegexp.Match(expectedSQL, actualSQL)
+		if err == nil {
+			queries++
+		}
+		return err
+	})
+
+	mockDB, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(matcher))
+	if err != nil {
+		b.Fatal(err)
+	}
+	d := &Database{pool: sqlx.NewDb(mockDB, "sqlmock")}
+	defer checkError(d.pool.Close)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		petRows := sqlmock.NewRows([]string{"id", "category_id", "category_name", "price",
+			"name", "photo_urls", "pet_status_name", "quantity"})
+		tagRows := sqlmock.NewRows([]string{"pet_id", "id", "name"})
+		for id := 1; id <= petsCount; id++ {
+			petRows.AddRow(id, 1, "Cat", 35.00, "Soo", pq.Array([]string{"1"}), "available", 1)
+			tagRows.AddRow(id, 1, "small").AddRow(id, 4, "best")
+		}
+		mockFn(mock, petRows, tagRows)
+		b.StartTimer()
+
+		if err := findFn(d); err != nil {
+			b.Fatal(err)
+		}
+
+		if err := mock.ExpectationsWereMet(); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	b.ReportMetric(float64(queries)/float64(b.N), "queries/op")
+}
+
+func BenchmarkDatabase_FindPetsByStatus(b *testing.B) {
+	for _, petsCount := range []int{10, 100, 1000} {
+		b.Run(strconv.Itoa(petsCount), func(b *testing.B) {
+			benchmarkPetList(b, petsCount,
+				func(mock sqlmock.Sqlmock, petRows, tagRows *sqlmock.Rows) {
+					mock.ExpectQuery(`select (.+) from pet_info`).WillReturnRows(petRows)
+					mock.ExpectQuery(`select (.+) from pet_tag`).WillReturnRows(tagRows)
+				},
+				func(d *Database) error {
+					filter := models.PetFilter{Status: []string{"available"}, Limit: models.MaxPageLimit}
+					_, _, err := d.FindPetsByStatus(context.Background(), filter)
+					return err
+				})
+		})
+	}
+}
+
+func BenchmarkDatabase_FindPetsByTags(b *testing.B) {
+	for _, petsCount := range []int{10, 100, 1000} {
+		b.Run(strconv.Itoa(petsCount), func(b *testing.B) {
+			benchmarkPetList(b, petsCount,
+				func(mock sqlmock.Sqlmock, petRows, tagRows *sqlmock.Rows) {
+					mock.ExpectQuery(`select id, name from tag`).
+						WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "small"))
+					mock.ExpectQuery(`select (.+) from pet_info`).WillReturnRows(petRows)
+					mock.ExpectQuery(`select (.+) from pet_tag`).WillReturnRows(tagRows)
+				},
+				func(d *Database) error {
+					_, err := d.FindPetsByTags(context.Background(), []string{"small"}, false)
+					return err
+				})
+		})
+	}
+}
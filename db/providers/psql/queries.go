@@ -3,31 +3,140 @@ package psql
 const (
 	categoryIsExist = iota
 
+	categoryCreateQ
+	categoryGetAllQ
+	categoryGetByIDQ
+	categoryUpdateQ
+	categoryLockByIDQ
+	categoryIsUsedQ
+	categoryDeleteQ
+
 	storeInventoriesQ
+	storeInventoryQ
 	storeGetStatusQ
 	storeCreateQ
 	storeFindByIDQ
+	storeFindQ
 	storeDeleteByIDQ
 	storeCreateInvoiceByDatesQ
+	storeLockByIDQ
+	storeSetStatusQ
+
+	orderItemCreateQ
+	orderItemsGetByOrderIDsQ
+
+	cartGetQ
+	cartAddItemQ
+	cartRemoveItemQ
+	cartLockQ
+	cartClearQ
+
+	couponCreateQ
+	couponGetAllQ
+	couponDeleteQ
+	couponLockByCodesQ
+	couponUsesQ
+	couponUseAddQ
+
+	paymentCreateQ
+	paymentGetByOrderIDQ
+	paymentLockByIDQ
+	paymentUpdateQ
+	paymentIsCapturedQ
+	paymentSetStatusQ
+	paymentLockForRefundQ
+
+	refundCreateQ
+	refundGetByOrderIDQ
+	refundSumQ
+	refundCompletedSumQ
+	refundSaveQ
+	refundLockDueQ
+	refundLeaseQ
+
+	outboxAddQ
+	outboxLockDueQ
+	outboxLeaseQ
+	outboxReleaseQ
+	outboxPublishedQ
+	outboxRetryQ
+	outboxGetByIDQ
+	outboxGetAfterQ
+	outboxLastIDQ
+
+	invoiceCreateQ
+	invoiceGetAllQ
+	invoiceGetByNumberQ
+	invoiceGetByPeriodQ
+	invoicePeriodGetQ
+	invoicePeriodSetQ
+
+	webhookCreateQ
+	webhookGetAllQ
+	webhookDeleteQ
+	webhookEnableQ
+	webhookIsExistQ
+	webhookSucceedQ
+	webhookFailQ
+	webhookDeliveriesQ
+	webhookDeliveryLockDueQ
+	webhookDeliveryLeaseQ
+	webhookDeliveryReleaseQ
+	webhookDeliverySaveQ
+
+	analyticsSalesQ
+	analyticsTopCategoriesQ
+	analyticsOrderValueQ
+	analyticsUserValuesQ
 
 	userCreateQ
 	userGetAllowedMethodsAndPassQ
 	userGetByNameQ
 	userUpdateQ
+	userSetStatusQ
 	userDeleteQ
 
 	petGetStatusIDByNameQ
+	petIsExistQ
 	petAddToStoreQ
 	petUpdateWithBodyQ
 	petFindByStatusQ
+	petFindByStatusSortNameQ
+	petFindByStatusSortPriceQ
+	petFindByTagsQ
 	petGetByIDQ
 	petUpdateWithFieldsQ
 	petDeleteByIDQ
-	petUpdatePhotosByIDQ
+	petAddPhotosByIDQ
+	petLockStatusByIDQ
+	petSetStatusQ
+	petIsHeldQ
+	petLockForOrderQ
+	petTakeQuantityQ
+	petReturnQuantityQ
+	petPricesByIDsQ
+
+	petStatusHistoryAddQ
+	petStatusHistoryGetQ
 
 	tagsInsertQ
 	tagsDeleteQ
 	tagsGetByPetIDQ
+	tagsGetByPetIDsQ
+	tagsGetByIDsOrNamesQ
+	tagsUpsertByNameQ
+
+	tagCreateQ
+	tagGetAllQ
+	tagRenameQ
+	tagLockByIDQ
+	tagIsUsedQ
+	tagDeleteQ
+
+	idempotencyReserveQ
+	idempotencyGetQ
+	idempotencySaveQ
+	idempotencyReleaseQ
 )
 
 // query master
@@ -36,32 +145,237 @@ var qm = map[int]string{
 	SELECT count(*) FROM information_schema.TABLES
 	WHERE (TABLE_SCHEMA = 'public') AND (TABLE_NAME = 'category');`,
 
+	categoryCreateQ: `
+	insert into category (name, price)
+	values (:name, :price) returning id`,
+
+	categoryGetAllQ: `
+	select id, name, price from category
+	order by id`,
+
+	categoryGetByIDQ: `
+	select id, name, price from category
+	where id=$1`,
+
+	categoryUpdateQ: `
+	update category set name=:name, price=:price
+	where id=:id`,
+
+	categoryLockByIDQ: `
+	select id from category
+	where id=$1 for update`,
+
+	categoryIsUsedQ: `
+	select exists(select 1 from pet where category_id=$1)`,
+
+	categoryDeleteQ: `
+	delete from category
+	where id=$1`,
+
 	storeInventoriesQ: `
-	select pet_status, sum(quantity) from order_info group by pet_status`,
+	select pet_status, sum(quantity) from order_item_info group by pet_status`,
+
+	storeInventoryQ: `
+	with ordered as (
+		select oi.pet_id, sum(oi.quantity) as quantity
+		from order_item oi
+		inner join "order" o on oi.order_id = o.id
+		inner join order_status os on o.order_status_id = os.id
+		where os.name <> $1
+		and ($2::timestamp is null or o.ship_date >= $2::timestamp)
+		and ($3::timestamp is null or o.ship_date <= $3::timestamp)
+		group by oi.pet_id
+	), pets as (
+		select p.id, p.quantity, c.name as category, ps.name as status, coalesce(t.name, '') as tag
+		from pet p
+		inner join category c on p.category_id = c.id
+		inner join pet_status ps on p.pet_status_id = ps.id
+		left join pet_tag pt on pt.pet_id = p.id and $6::boolean
+		left join tag t on pt.tag_id = t.id
+	)
+	select case when $4::boolean then p.category else '' end as category,
+	case when $5::boolean then p.status else '' end as status,
+	case when $6::boolean then p.tag else '' end as tag,
+	coalesce(sum(o.quantity), 0) as ordered, sum(p.quantity) as on_hand
+	from pets p
+	left join ordered o on o.pet_id = p.id
+	group by 1, 2, 3
+	order by 1, 2, 3`,
 
 	storeGetStatusQ: `
 	select id from order_status where name=$1`,
 
 	storeCreateQ: `
-	insert into "order" (pet_id, user_id, quantity, ship_date, order_status_id, complete)
-	values (:pet_id, :user_id, :quantity, :ship_date, :order_status, :complete) returning id;`,
+	insert into "order" (user_id, gross, discount, ship_date, order_status_id, complete)
+	values (:user_id, :gross, :discount, :ship_date, :order_status, :complete) returning id;`,
 
 	storeFindByIDQ: `
-	select id, user_id, pet_id, quantity,
+	select id, user_id, pet_id, quantity, gross, discount,
 	ship_date, order_status, complete 
 	from order_info where id=$1`,
 
+	storeFindQ: `
+	select id, user_id, pet_id, quantity, gross, discount,
+	ship_date, order_status, complete
+	from order_info
+	where ($1::bigint = 0 or user_id = $1::bigint)
+	and ($2::bigint = 0 or exists(select 1 from order_item oi
+		where oi.order_id = order_info.id and oi.pet_id = $2::bigint))
+	and ($3::text = '' or order_status = $3::text)
+	and ($4::boolean is null or complete = $4::boolean)
+	and ($5::timestamp is null or ship_date >= $5::timestamp)
+	and ($6::timestamp is null or ship_date <= $6::timestamp)
+	and id > $7
+	order by id limit $8`,
+
 	storeDeleteByIDQ: `
-	delete from "order" 
-	where id=$1 returning id`,
+	update "order" set deleted_at=now()
+	where id=$1`,
 
 	storeCreateInvoiceByDatesQ: `
-	select id, user_name, pet, category, ship_date, quantity, price
-	from invoice_info where ship_date between :from and :to
-	group by category, ship_date, id, user_name, pet, quantity, price
-	order by category, ship_date;
+	select id, line, user_name, pet, category, ship_date, quantity, price, discount, false as refund
+	from invoice_info where ship_date >= :from and ship_date < :to
+	union all
+	select id, line, user_name, pet, category, ship_date, quantity, price, discount, true as refund
+	from invoice_refund_info where ship_date >= :from and ship_date < :to
+	order by ship_date, id, line;
 	`,
 
+	storeLockByIDQ: `
+	select os.name as order_status
+	from "order" o
+	inner join order_status os on o.order_status_id = os.id
+	where o.id=$1 and o.deleted_at is null for update of o`,
+
+	storeSetStatusQ: `
+	update "order" set order_status_id=(select id from order_status where name=$2),
+	complete=$3 where id=$1`,
+
+	orderItemCreateQ: `
+	insert into order_item (order_id, pet_id, quantity, price, discount)
+	values ($1, $2, $3, $4, $5) returning id`,
+
+	orderItemsGetByOrderIDsQ: `
+	select id, order_id, pet_id, pet_name, category_id, quantity, price, discount
+	from order_item_info where order_id = any($1)
+	order by order_id, pet_id`,
+
+	cartGetQ: `
+	select c.pet_id, p.name as pet_name, p.price, c.quantity
+	from cart_item c
+	inner join pet_info p on c.pet_id = p.id
+	where c.user_id=$1 order by c.added_at, c.pet_id`,
+
+	cartAddItemQ: `
+	insert into cart_item (user_id, pet_id, quantity)
+	values ($1, $2, $3)
+	on conflict (user_id, pet_id) do update
+	set quantity=cart_item.quantity+excluded.quantity`,
+
+	cartRemoveItemQ: `
+	delete from cart_item
+	where user_id=$1 and pet_id=$2`,
+
+	cartLockQ: `
+	select pet_id, quantity from cart_item
+	where user_id=$1 order by pet_id for update`,
+
+	cartClearQ: `
+	delete from cart_item
+	where user_id=$1`,
+
+	couponCreateQ: `
+	insert into coupon (code, kind, value, category_id, valid_from,
+	valid_to, max_uses, max_uses_per_user, stackable)
+	values (:code, :kind, :value, nullif(:category_id, 0), :valid_from,
+	:valid_to, :max_uses, :max_uses_per_user, :stackable) returning id`,
+
+	couponGetAllQ: `
+	select id, code, kind, value, coalesce(category_id, 0) as category_id, valid_from,
+	valid_to, max_uses, max_uses_per_user, stackable
+	from coupon order by id`,
+
+	couponDeleteQ: `
+	delete from coupon
+	where id=$1`,
+
+	couponLockByCodesQ: `
+	select id, code, kind, value, coalesce(category_id, 0) as category_id, valid_from,
+	valid_to, max_uses, max_uses_per_user, stackable
+	from coupon where code = any($1) order by id for update`,
+
+	couponUsesQ: `
+	select count(*), count(*) filter (where cu.user_id=$2)
+	from coupon_use cu
+	inner join "order" o on cu.order_id = o.id
+	inner join order_status os on o.order_status_id = os.id
+	where cu.coupon_id=$1 and os.name <> 'cancelled'`,
+
+	couponUseAddQ: `
+	insert into coupon_use (coupon_id, order_id, user_id)
+	values ($1, $2, $3)`,
+
+	paymentCreateQ: `
+	insert into payment (order_id, provider, amount, status)
+	values ($1, $2, $3, $4) returning id, created_at, updated_at`,
+
+	paymentGetByOrderIDQ: `
+	select id, order_id, provider, reference, amount, status, reason, created_at, updated_at
+	from payment where order_id=$1 order by id`,
+
+	paymentLockByIDQ: `
+	select status from payment
+	where id=$1 for update`,
+
+	paymentLockForRefundQ: `
+	select id, order_id, provider, reference, amount, status, reason, created_at, updated_at
+	from payment where id=$1 for update`,
+
+	paymentUpdateQ: `
+	update payment set status=$2, reference=$3, reason=$4, updated_at=now()
+	where id=$1 returning updated_at`,
+
+	paymentIsCapturedQ: `
+	select exists(select 1 from payment where order_id=$1 and status='captured')`,
+
+	paymentSetStatusQ: `
+	update payment set status=$2, updated_at=now()
+	where id=$1`,
+
+	refundCreateQ: `
+	insert into refund (order_id, payment_id, amount, reason, user_id, status, next_attempt_at)
+	values ($1, $2, $3, $4, $5, $6, $7) returning id, created_at`,
+
+	refundGetByOrderIDQ: `
+	select id, order_id, payment_id, amount, reason, user_id, created_at,
+	status, attempts, last_error, next_attempt_at, completed_at
+	from refund where order_id=$1 order by id`,
+
+	refundSumQ: `
+	select coalesce(sum(amount), 0) from refund
+	where payment_id=$1 and status <> 'failed'`,
+
+	refundCompletedSumQ: `
+	select coalesce(sum(amount), 0) from refund
+	where payment_id=$1 and status = 'completed'`,
+
+	refundSaveQ: `
+	update refund set status=$2, attempts=$3, last_error=$4, next_attempt_at=$5, completed_at=$6
+	where id=$1 and status = 'pending'`,
+
+	refundLockDueQ: `
+	select r.id, r.order_id, r.payment_id, r.amount, r.reason, r.user_id, r.created_at,
+	r.status, r.attempts, r.last_error, r.next_attempt_at, r.completed_at,
+	p.provider, p.reference
+	from refund r
+	inner join payment p on r.payment_id = p.id
+	where r.status = 'pending' and r.next_attempt_at <= now()
+	order by r.next_attempt_at, r.id limit $1 for update of r skip locked`,
+
+	refundLeaseQ: `
+	update refund set next_attempt_at=$2
+	where id = any($1)`,
+
 	userCreateQ: `
 	insert into "user" (user_name, first_name, 
 	last_name, email, password, phone, user_status_id) 
@@ -79,33 +393,83 @@ var qm = map[int]string{
 
 	userUpdateQ: `update "user" set 
 	user_name = :user_name, first_name = :first_name, last_name = :last_name, 
-	email = :email, password = :password, phone = :phone, user_status_id = :user_status_id 
+	email = :email, password = :password, phone = :phone
 	where user_name = :user_name`,
 
+	userSetStatusQ: `
+	update "user" set user_status_id = $2 where user_name = $1
+	returning id, user_name, first_name, last_name, email, phone, user_status_id`,
+
 	userDeleteQ: `delete from "user" where user_name=$1`,
 
 	petGetStatusIDByNameQ: `
 	select id from pet_status where name = $1`,
 
+	petIsExistQ: `
+	select exists(select 1 from pet where id=$1)`,
+
 	petAddToStoreQ: `
 	insert into pet 
-    (category_id, name, photo_urls, pet_status_id)
- 	values (:category_id, :name, :photo_urls, :pet_status_id) returning id;`,
+    (category_id, name, photo_urls, pet_status_id, quantity)
+ 	values (:category_id, :name, :photo_urls, :pet_status_id,
+ 	coalesce(nullif(:quantity, 0), 1)) returning id;`,
 
 	petUpdateWithBodyQ: `
 	update pet set category_id = :category_id, name = :name, 
-	photo_urls = :photo_urls, pet_status_id = :pet_status_id
+	photo_urls = :photo_urls, pet_status_id = :pet_status_id,
+	quantity = coalesce(nullif(:quantity, 0), quantity)
 	where id = :id returning id;`,
 
 	petFindByStatusQ: `
-	select id, category_id, category_name, name,
-	photo_urls, pet_status_name from pet_info
-	where pet_status_name=any($1)`,
+	select id, category_id, category_name, price, name,
+	photo_urls, pet_status_name, quantity from pet_info p
+	where pet_status_name=any($1)
+	and ($2::text = '' or category_name = $2::text)
+	and ($3::text = '' or left(name, length($3::text)) = $3::text)
+	and ($4::text = '' or exists(select 1 from pet_tag pt
+		inner join tag t on pt.tag_id = t.id
+		where pt.pet_id = p.id and t.name = $4::text))
+	and id > $5
+	order by id limit $6`,
+
+	petFindByStatusSortNameQ: `
+	select id, category_id, category_name, price, name,
+	photo_urls, pet_status_name, quantity from pet_info p
+	where pet_status_name=any($1)
+	and ($2::text = '' or category_name = $2::text)
+	and ($3::text = '' or left(name, length($3::text)) = $3::text)
+	and ($4::text = '' or exists(select 1 from pet_tag pt
+		inner join tag t on pt.tag_id = t.id
+		where pt.pet_id = p.id and t.name = $4::text))
+	and ($6 = 0 or (name, id) > ($5::text, $6))
+	order by name, id limit $7`,
+
+	petFindByStatusSortPriceQ: `
+	select id, category_id, category_name, price, name,
+	photo_urls, pet_status_name, quantity from pet_info p
+	where pet_status_name=any($1)
+	and ($2::text = '' or category_name = $2::text)
+	and ($3::text = '' or left(name, length($3::text)) = $3::text)
+	and ($4::text = '' or exists(select 1 from pet_tag pt
+		inner join tag t on pt.tag_id = t.id
+		where pt.pet_id = p.id and t.name = $4::text))
+	and ($6 = 0 or (price, id) > ($5::numeric, $6))
+	order by price, id limit $7`,
+
+	petFindByTagsQ: `
+	select p.id, p.category_id, p.category_name, p.price, p.name,
+	p.photo_urls, p.pet_status_name, p.quantity from pet_info p
+	inner join pet_tag pt on pt.pet_id = p.id
+	where pt.tag_id = any($1)
+	group by p.id, p.category_id, p.category_name, p.price,
+	p.name, p.photo_urls, p.pet_status_name, p.quantity
+	having count(distinct pt.tag_id) >= $2
+	order by p.id`,
 
 	petGetByIDQ: `
 	select id, category_id, 
 	category_name, name, photo_urls,
-	pet_status_name from pet_info where id=$1`,
+	pet_status_name, quantity from pet_info where id=$1`,
 
 	petUpdateWithFieldsQ: `
 	update pet set name=:name, pet_status_id=:pet_status_id 
@@ -115,10 +479,57 @@ var qm = map[int]string{
 	delete from pet 
 	where id=$1`,
 
-	petUpdatePhotosByIDQ: `
-	update pet set photo_urls=:photo_urls 
+	petAddPhotosByIDQ: `
+	update pet set photo_urls=array_cat(photo_urls, :photo_urls)
 	where id=:id`,
 
+	petLockStatusByIDQ: `
+	select ps.name from pet p
+	inner join pet_status ps on p.pet_status_id = ps.id
+	where p.id=$1 for update of p`,
+
+	petSetStatusQ: `
+	update pet set pet_status_id=(select id from pet_status where name=$2)
+	where id=$1`,
+
+	petIsHeldQ: `
+	select exists(
+		select 1 from order_item oi
+		inner join "order" o on oi.order_id = o.id
+		inner join order_status os on o.order_status_id = os.id
+		where oi.pet_id=$1 and os.name = any($2))`,
+
+	petLockForOrderQ: `
+	select ps.name, p.quantity from pet p
+	inner join pet_status ps on p.pet_status_id = ps.id
+	where p.id=$1 for update of p`,
+
+	petTakeQuantityQ: `
+	update pet set quantity=quantity-$2
+	where id=$1`,
+
+	petReturnQuantityQ: `
+	update pet set quantity=quantity+$2
+	where id=$1`,
+
+	petPricesByIDsQ: `
+	select id, category_id, price from pet_info
+	where id = any($1)`,
+
+	petStatusHistoryAddQ: `
+	insert into pet_status_history (pet_id, from_status_id, to_status_id, user_id)
+	values ($1, (select id from pet_status where name=$2),
+	(select id from pet_status where name=$3), $4)`,
+
+	petStatusHistoryGetQ: `
+	select h.id, h.pet_id, coalesce(fs.name, '') as from_status,
+	ts.name as to_status, h.user_id, h.changed_at
+	from pet_status_history h
+	left join pet_status fs on h.from_status_id = fs.id
+	inner join pet_status ts on h.to_status_id = ts.id
+	where h.pet_id=$1
+	order by h.changed_at, h.id`,
+
 	tagsInsertQ: `
 	insert into pet_tag 
 	VALUES (:pet_id, :tag_id)`,
@@ -131,4 +542,258 @@ var qm = map[int]string{
 	select t.id, t.name from pet_tag 
 	inner join tag t on pet_tag.tag_id = t.id 
 	where pet_id=$1`,
+
+	tagsGetByPetIDsQ: `
+	select pet_id, t.id, t.name from pet_tag
+	inner join tag t on pet_tag.tag_id = t.id
+	where pet_id = any($1)
+	order by pet_id, t.id`,
+
+	tagsGetByIDsOrNamesQ: `
+	select id, name from tag
+	where id = any($1) or name = any($2)`,
+
+	tagsUpsertByNameQ: `
+	insert into tag (name) values ($1)
+	on conflict (name) do update set name = excluded.name
+	returning id`,
+
+	tagCreateQ: `
+	insert into tag (name)
+	values ($1) returning id`,
+
+	tagGetAllQ: `
+	select id, name from tag
+	order by id`,
+
+	tagRenameQ: `
+	update tag set name=$2
+	where id=$1`,
+
+	tagLockByIDQ: `
+	select id from tag
+	where id=$1 for update`,
+
+	tagIsUsedQ: `
+	select exists(select 1 from pet_tag where tag_id=$1)`,
+
+	tagDeleteQ: `
+	delete from tag
+	where id=$1`,
+
+	outboxAddQ: `
+	with added as (
+		insert into outbox (aggregate, aggregate_key, event, payload)
+		values ($1, $2, $3, $4) returning id
+	)
+	insert into webhook_delivery (webhook_id, event_id)
+	select w.id, added.id from webhook w, added
+	where w.active and $3 = any(w.events)`,
+
+	outboxLockDueQ: `
+	select id, aggregate, aggregate_key, event, payload, created_at,
+	attempts, next_attempt_at, last_error
+	from outbox where published_at is null and next_attempt_at <= now()
+	order by next_attempt_at, id limit $1 for update skip locked`,
+
+	outboxLeaseQ: `
+	update outbox set next_attempt_at=$2
+	where id = any($1)`,
+
+	outboxReleaseQ: `
+	update outbox set next_attempt_at=now()
+	where id = any($1) and published_at is null`,
+
+	outboxPublishedQ: `
+	update outbox set published_at=now(), attempts=attempts + 1, last_error=''
+	where id=$1`,
+
+	outboxRetryQ: `
+	update outbox set attempts=$2, next_attempt_at=$3, last_error=$4
+	where id=$1`,
+
+	outboxGetByIDQ: `
+	select id, aggregate, aggregate_key, event, payload, created_at
+	from outbox where id=$1`,
+
+	outboxGetAfterQ: `
+	select id, aggregate, aggregate_key, event, payload, created_at
+	from outbox where id > $1
+	order by id limit $2`,
+
+	outboxLastIDQ: `
+	select coalesce(max(id), 0) from outbox`,
+
+	invoiceCreateQ: `
+	insert into invoice (period_from, period_to, format, object_key, checksum, size,
+	total_price, total_discount, total_refund, total)
+	values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	returning number, created_at`,
+
+	invoiceGetAllQ: `
+	select number, period_from, period_to, format, object_key, checksum, size,
+	total_price, total_discount, total_refund, total, created_at
+	from invoice order by number desc limit $1`,
+
+	invoiceGetByNumberQ: `
+	select number, period_from, period_to, format, object_key, checksum, size,
+	total_price, total_discount, total_refund, total, created_at
+	from invoice where number=$1`,
+
+	invoiceGetByPeriodQ: `
+	select number, period_from, period_to, format, object_key, checksum, size,
+	total_price, total_discount, total_refund, total, created_at
+	from invoice where period_from=$1 and period_to=$2 order by number`,
+
+	invoicePeriodGetQ: `
+	select period_to from invoice_period where id`,
+
+	invoicePeriodSetQ: `
+	insert into invoice_period (period_to) values ($1)
+	on conflict (id) do update set period_to = excluded.period_to, updated_at = now()
+	where invoice_period.period_to < excluded.period_to`,
+
+	webhookCreateQ: `
+	insert into webhook (url, events, secret)
+	values ($1, $2, $3)
+	returning id, active, failures, created_at`,
+
+	webhookGetAllQ: `
+	select id, url, events, active, failures, created_at, disabled_at
+	from webhook order by id`,
+
+	webhookDeleteQ: `
+	delete from webhook
+	where id=$1`,
+
+	webhookEnableQ: `
+	update webhook set active=true, failures=0, disabled_at=null
+	where id=$1
+	returning id, url, events, active, failures, created_at, disabled_at`,
+
+	webhookIsExistQ: `
+	select exists(select 1 from webhook where id=$1)`,
+
+	webhookSucceedQ: `
+	update webhook set failures=0
+	where id=$1`,
+
+	webhookFailQ: `
+	update webhook set failures=failures + 1, active=failures + 1 < $2,
+	disabled_at=case when failures + 1 < $2 then disabled_at else now() end
+	where id=$1
+	returning active`,
+
+	webhookDeliveriesQ: `
+	select d.id, d.webhook_id, d.event_id, o.event, d.status, d.attempts,
+	d.response_code, d.last_error, d.created_at, d.next_attempt_at, d.delivered_at
+	from webhook_delivery d
+	inner join outbox o on d.event_id = o.id
+	where d.webhook_id=$1
+	order by d.id desc limit $2`,
+
+	webhookDeliveryLockDueQ: `
+	select d.id, d.webhook_id, d.event_id, o.event, d.status, d.attempts,
+	d.response_code, d.last_error, d.created_at, d.next_attempt_at, d.delivered_at,
+	o.aggregate, o.aggregate_key, o.payload, o.created_at as event_created_at,
+	w.url, w.secret
+	from webhook_delivery d
+	inner join webhook w on d.webhook_id = w.id
+	inner join outbox o on d.event_id = o.id
+	where d.status='pending' and w.active and d.next_attempt_at <= now()
+	order by d.next_attempt_at, d.id limit $1 for update of d skip locked`,
+
+	webhookDeliveryLeaseQ: `
+	update webhook_delivery set next_attempt_at=$2
+	where id = any($1)`,
+
+	webhookDeliveryReleaseQ: `
+	update webhook_delivery set next_attempt_at=now()
+	where id = any($1) and status='pending'`,
+
+	webhookDeliverySaveQ: `
+	update webhook_delivery set status=$2, attempts=$3, response_code=$4,
+	last_error=$5, next_attempt_at=$6, delivered_at=$7
+	where id=$1`,
+
+	analyticsSalesQ: `
+	with refunded as (
+		select order_id, sum(amount) as amount from refund
+		where status = 'completed' group by order_id
+	)
+	select to_char(date_trunc($3, o.ship_date), 'YYYY-MM-DD') as period,
+	c.id as category_id, c.name as category, sum(oi.quantity) as units,
+	round(sum((oi.price * oi.quantity - oi.discount) * (1 - coalesce(r.amount / nullif(o.gross - o.discount, 0), 0))), 2) as revenue
+	from "order" o
+	inner join order_status os on o.order_status_id = os.id
+	inner join order_item oi on oi.order_id = o.id
+	inner join pet p on oi.pet_id = p.id
+	inner join category c on p.category_id = c.id
+	left join refunded r on r.order_id = o.id
+	where o.ship_date between $1 and $2 and os.name = any($4)
+	group by 1, 2, 3
+	order by 1, 2`,
+
+	analyticsTopCategoriesQ: `
+	with refunded as (
+		select order_id, sum(amount) as amount from refund
+		where status = 'completed' group by order_id
+	)
+	select c.id as category_id, c.name as category, sum(oi.quantity) as units,
+	round(sum((oi.price * oi.quantity - oi.discount) * (1 - coalesce(r.amount / nullif(o.gross - o.discount, 0), 0))), 2) as revenue
+	from "order" o
+	inner join order_status os on o.order_status_id = os.id
+	inner join order_item oi on oi.order_id = o.id
+	inner join pet p on oi.pet_id = p.id
+	inner join category c on p.category_id = c.id
+	left join refunded r on r.order_id = o.id
+	where o.ship_date between $1 and $2 and os.name = any($3)
+	group by c.id, c.name
+	order by revenue desc, units desc, c.id limit $4`,
+
+	analyticsOrderValueQ: `
+	with refunded as (
+		select order_id, sum(amount) as amount from refund
+		where status = 'completed' group by order_id
+	)
+	select count(o.id) as orders, coalesce(sum(o.gross - o.discount - coalesce(r.amount, 0)), 0) as revenue
+	from "order" o
+	inner join order_status os on o.order_status_id = os.id
+	left join refunded r on r.order_id = o.id
+	where o.ship_date between $1 and $2 and os.name = any($3)`,
+
+	analyticsUserValuesQ: `
+	with refunded as (
+		select order_id, sum(amount) as amount from refund
+		where status = 'completed' group by order_id
+	)
+	select o.user_id, u.user_name, count(o.id) as orders,
+	sum(o.gross - o.discount - coalesce(r.amount, 0)) as revenue
+	from "order" o
+	inner join order_status os on o.order_status_id = os.id
+	inner join "user" u on o.user_id = u.id
+	left join refunded r on r.order_id = o.id
+	where o.ship_date between $1 and $2 and os.name = any($3)
+	group by o.user_id, u.user_name
+	order by revenue desc, o.user_id limit $4`,
+
+	idempotencyReserveQ: `
+	insert into idempotency_key (user_id, key, request_hash)
+	values ($1, $2, $3)
+	on conflict (user_id, key) do update
+	set request_hash=excluded.request_hash, status_code=0, body=null, created_at=now()
+	where idempotency_key.created_at < now() - interval '24 hours'
+	returning user_id`,
+
+	idempotencyGetQ: `
+	select user_id, key, request_hash, status_code, body
+	from idempotency_key where user_id=$1 and key=$2`,
+
+	idempotencySaveQ: `
+	update idempotency_key set status_code=$3, body=$4
+	where user_id=$1 and key=$2`,
+
+	idempotencyReleaseQ: `
+	delete from idempotency_key
+	where user_id=$1 and key=$2 and status_code=0`,
 }
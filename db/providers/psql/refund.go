@@ -0,0 +1,177 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/IamStubborN/petstore/db/models"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+)
+
+// ReserveRefund adds the refund to the ledger of its payment as pending,
+// the payment is locked, so concurrent refunds can't return more than
+// was captured. The refund is left to the caller for RefundLease.
+func (d *Database) ReserveRefund(ctx context.Context, refund *models.Refund) (*models.Refund, error) {
+	tx, err := d.pool.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "can't start transaction")
+	}
+
+	defer func() {
+		if err != nil {
+			checkError(tx.Rollback)
+			return
+		}
+		checkError(tx.Commit)
+	}()
+
+	var payment models.Payment
+	err = tx.GetContext(ctx, &payment, qm[paymentLockForRefundQ], refund.PaymentID)
+	if err == sql.ErrNoRows || (err == nil && payment.OrderID != refund.OrderID) {
+		err = errors.Wrapf(models.ErrNotFound, "payment № %d of order № %d doesn't exist",
+			refund.PaymentID, refund.OrderID)
+		return nil, err
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "can't lock payment")
+	}
+
+	var refunded float64
+	if err = tx.GetContext(ctx, &refunded, qm[refundSumQ], payment.ID); err != nil {
+		return nil, errors.Wrap(err, "can't sum refunds")
+	}
+
+	if err = models.CheckRefund(&payment, refunded, refund.Amount); err != nil {
+		return nil, err
+	}
+
+	refund.Status = models.RefundStatusPending
+	refund.Attempts = 0
+	refund.LastError = ""
+	refund.NextAttemptAt = time.Now().Add(models.RefundLease)
+	refund.CompletedAt = nil
+	refund.Provider = payment.Provider
+	refund.Reference = payment.Reference
+
+	err = tx.QueryRowxContext(ctx, qm[refundCreateQ],
+		refund.OrderID, refund.PaymentID, refund.Amount, refund.Reason, refund.UserID,
+		refund.Status, refund.NextAttemptAt).
+		Scan(&refund.ID, &refund.CreatedAt)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't insert into refund")
+	}
+
+	return refund, nil
+}
+
+// SaveRefund saves the attempt of the pending refund, a payment
+// whose completed refunds return all of it moves to refunded.
+func (d *Database) SaveRefund(ctx context.Context, refund *models.Refund) error {
+	tx, err := d.pool.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return errors.Wrap(err, "can't start transaction")
+	}
+
+	defer func() {
+		if err != nil {
+			checkError(tx.Rollback)
+			return
+		}
+		checkError(tx.Commit)
+	}()
+
+	var payment models.Payment
+	err = tx.GetContext(ctx, &payment, qm[paymentLockForRefundQ], refund.PaymentID)
+	if err == sql.ErrNoRows {
+		err = errors.Wrapf(models.ErrNotFound, "payment № %d doesn't exist", refund.PaymentID)
+		return err
+	}
+	if err != nil {
+		return errors.Wrap(err, "can't lock payment")
+	}
+
+	res, err := tx.ExecContext(ctx, qm[refundSaveQ], refund.ID, refund.Status,
+		refund.Attempts, refund.LastError, refund.NextAttemptAt, refund.CompletedAt)
+	if err != nil {
+		return errors.Wrap(err, "can't update refund")
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "can't get rows affected")
+	}
+
+	if count == 0 {
+		err = errors.Wrapf(models.ErrConflict, "refund № %d isn't pending", refund.ID)
+		return err
+	}
+
+	if refund.Status != models.RefundStatusCompleted || payment.Status != models.PaymentStatusCaptured {
+		return nil
+	}
+
+	var completed float64
+	if err = tx.GetContext(ctx, &completed, qm[refundCompletedSumQ], payment.ID); err != nil {
+		return errors.Wrap(err, "can't sum refunds")
+	}
+
+	if models.RoundPrice(completed) < payment.Amount {
+		return nil
+	}
+
+	if _, err = tx.ExecContext(ctx, qm[paymentSetStatusQ], payment.ID, models.PaymentStatusRefunded); err != nil {
+		return errors.Wrap(err, "can't update payment status")
+	}
+
+	return nil
+}
+
+// ClaimRefunds returns up to limit pending refunds due to be retried,
+// they are leased for RefundLease, so other workers skip them.
+func (d *Database) ClaimRefunds(ctx context.Context, limit int) (models.RefundList, error) {
+	tx, err := d.pool.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "can't start transaction")
+	}
+
+	defer func() {
+		if err != nil {
+			checkError(tx.Rollback)
+			return
+		}
+		checkError(tx.Commit)
+	}()
+
+	refunds := models.RefundList{}
+	if err = tx.SelectContext(ctx, &refunds, qm[refundLockDueQ], limit); err != nil {
+		return nil, errors.Wrap(err, "can't lock due refunds")
+	}
+
+	if len(refunds) == 0 {
+		return refunds, nil
+	}
+
+	ids := make([]int64, len(refunds))
+	leased := time.Now().Add(models.RefundLease)
+	for i, refund := range refunds {
+		ids[i] = refund.ID
+		refund.NextAttemptAt = leased
+	}
+
+	if _, err = tx.ExecContext(ctx, qm[refundLeaseQ], pq.Array(ids), leased); err != nil {
+		return nil, errors.Wrap(err, "can't lease refunds")
+	}
+
+	return refunds, nil
+}
+
+func (d *Database) GetRefunds(ctx context.Context, orderID int64) (models.RefundList, error) {
+	refunds := models.RefundList{}
+	if err := d.pool.SelectContext(ctx, &refunds, qm[refundGetByOrderIDQ], orderID); err != nil {
+		return nil, errors.Wrap(err, "can't get data from refund")
+	}
+
+	return refunds, nil
+}
@@ -0,0 +1,321 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/IamStubborN/petstore/db/models"
+	"github.com/jmoiron/sqlx"
+	pkgerrors "github.com/pkg/errors"
+)
+
+func TestDatabase_ReserveRefund(t *testing.T) {
+	pool, mock := generateMockedDB()
+	defer checkError(pool.Close)
+
+	now := time.Date(2020, 3, 1, 12, 0, 0, 0, time.UTC)
+	paymentColumns := []string{"id", "order_id", "provider", "reference", "amount",
+		"status", "reason", "created_at", "updated_at"}
+
+	expectPayment := func(orderID int64, status string, refunded float64) {
+		mock.ExpectQuery(`select (.+) from payment where id=(.+) for update`).
+			WithArgs(3).
+			WillReturnRows(sqlmock.NewRows(paymentColumns).
+				AddRow(3, orderID, "fake", "fake_1_1", 134.98, status, "", now, now))
+		if orderID != 1 || status != "captured" {
+			return
+		}
+		mock.ExpectQuery(`select coalesce\(sum\(amount\), 0\) from refund`).
+			WithArgs(3).
+			WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(refunded))
+	}
+	expectInsert := func(amount float64) {
+		mock.ExpectQuery(`insert into refund (.+) returning id, created_at`).
+			WithArgs(1, 3, amount, "damaged cage", 2, "pending", sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(8, now))
+	}
+
+	type fields struct {
+		pool *sqlx.DB
+	}
+	type args struct {
+		ctx    context.Context
+		refund *models.Refund
+		mockFn func()
+	}
+	tests := []struct {
+		name      string
+		fields    fields
+		args      args
+		want      *models.Refund
+		wantCause error
+		wantErr   bool
+	}{
+		{
+			name:   "Success partial refund",
+			fields: fields{pool: pool},
+			args: args{
+				ctx:    context.Background(),
+				refund: &models.Refund{OrderID: 1, PaymentID: 3, Amount: 34.98, Reason: "damaged cage", UserID: 2},
+				mockFn: func() {
+					mock.ExpectBegin()
+					expectPayment(1, "captured", 0)
+					expectInsert(34.98)
+					mock.ExpectCommit()
+				},
+			},
+			want: &models.Refund{ID: 8, OrderID: 1, PaymentID: 3, Amount: 34.98, Reason: "damaged cage",
+				UserID: 2, CreatedAt: now, Status: "pending", Provider: "fake", Reference: "fake_1_1"},
+			wantErr: false,
+		},
+		{
+			name:   "Success the rest is reserved",
+			fields: fields{pool: pool},
+			args: args{
+				ctx:    context.Background(),
+				refund: &models.Refund{OrderID: 1, PaymentID: 3, Amount: 100, Reason: "damaged cage", UserID: 2},
+				mockFn: func() {
+					mock.ExpectBegin()
+					expectPayment(1, "captured", 34.98)
+					expectInsert(100)
+					mock.ExpectCommit()
+				},
+			},
+			want: &models.Refund{ID: 8, OrderID: 1, PaymentID: 3, Amount: 100, Reason: "damaged cage",
+				UserID: 2, CreatedAt: now, Status: "pending", Provider: "fake", Reference: "fake_1_1"},
+			wantErr: false,
+		},
+		{
+			name:   "Failure over the captured amount",
+			fields: fields{pool: pool},
+			args: args{
+				ctx:    context.Background(),
+				refund: &models.Refund{OrderID: 1, PaymentID: 3, Amount: 100.01, Reason: "damaged cage", UserID: 2},
+				mockFn: func() {
+					mock.ExpectBegin()
+					expectPayment(1, "captured", 34.98)
+					mock.ExpectRollback()
+				},
+			},
+			want:      nil,
+			wantCause: models.ErrConflict,
+			wantErr:   true,
+		},
+		{
+			name:   "Failure payment of another order",
+			fields: fields{pool: pool},
+			args: args{
+				ctx:    context.Background(),
+				refund: &models.Refund{OrderID: 1, PaymentID: 3, Amount: 10, Reason: "damaged cage", UserID: 2},
+				mockFn: func() {
+					mock.ExpectBegin()
+					expectPayment(2, "captured", 0)
+					mock.ExpectRollback()
+				},
+			},
+			want:      nil,
+			wantCause: models.ErrNotFound,
+			wantErr:   true,
+		},
+		{
+			name:   "Failure payment not found",
+			fields: fields{pool: pool},
+			args: args{
+				ctx:    context.Background(),
+				refund: &models.Refund{OrderID: 1, PaymentID: 3, Amount: 10, Reason: "damaged cage", UserID: 2},
+				mockFn: func() {
+					mock.ExpectBegin()
+					mock.ExpectQuery(`select (.+) from payment where id=(.+) for update`).
+						WithArgs(3).
+						WillReturnError(sql.ErrNoRows)
+					mock.ExpectRollback()
+				},
+			},
+			want:      nil,
+			wantCause: models.ErrNotFound,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Database{
+				pool: tt.fields.pool,
+			}
+
+			tt.args.mockFn()
+
+			got, err := d.ReserveRefund(tt.args.ctx, tt.args.refund)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ReserveRefund() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.wantCause != nil && pkgerrors.Cause(err) != tt.wantCause {
+				t.Errorf("ReserveRefund() error = %v, wantCause %v", err, tt.wantCause)
+			}
+
+			if got != nil {
+				if !got.NextAttemptAt.After(time.Now()) {
+					t.Errorf("ReserveRefund() next attempt = %v, want the lease", got.NextAttemptAt)
+				}
+				got.NextAttemptAt = time.Time{}
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReserveRefund() got = %v, want %v", got, tt.want)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func TestDatabase_SaveRefund(t *testing.T) {
+	pool, mock := generateMockedDB()
+	defer checkError(pool.Close)
+
+	now := time.Date(2020, 3, 1, 12, 0, 0, 0, time.UTC)
+	paymentColumns := []string{"id", "order_id", "provider", "reference", "amount",
+		"status", "reason", "created_at", "updated_at"}
+
+	expectPayment := func() {
+		mock.ExpectQuery(`select (.+) from payment where id=(.+) for update`).
+			WithArgs(3).
+			WillReturnRows(sqlmock.NewRows(paymentColumns).
+				AddRow(3, 1, "fake", "fake_1_1", 134.98, "captured", "", now, now))
+	}
+	expectSave := func(refund *models.Refund, count int64) {
+		mock.ExpectExec(`update refund set status=(.+) where id=(.+) and status = 'pending'`).
+			WithArgs(8, refund.Status, refund.Attempts, refund.LastError, refund.NextAttemptAt, refund.CompletedAt).
+			WillReturnResult(sqlmock.NewResult(0, count))
+	}
+	expectCompleted := func(completed float64) {
+		mock.ExpectQuery(`select coalesce\(sum\(amount\), 0\) from refund`).
+			WithArgs(3).
+			WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(completed))
+	}
+
+	completed := &models.Refund{ID: 8, OrderID: 1, PaymentID: 3, Amount: 100,
+		Status: models.RefundStatusCompleted, Attempts: 1, CompletedAt: &now}
+	retried := &models.Refund{ID: 8, OrderID: 1, PaymentID: 3, Amount: 100,
+		Status: models.RefundStatusPending, Attempts: 1, LastError: "gateway is down", NextAttemptAt: now}
+
+	tests := []struct {
+		name      string
+		refund    *models.Refund
+		mockFn    func()
+		wantCause error
+		wantErr   bool
+	}{
+		{
+			name:   "Success partial refund completed",
+			refund: completed,
+			mockFn: func() {
+				mock.ExpectBegin()
+				expectPayment()
+				expectSave(completed, 1)
+				expectCompleted(100)
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:   "Success the rest completed",
+			refund: completed,
+			mockFn: func() {
+				mock.ExpectBegin()
+				expectPayment()
+				expectSave(completed, 1)
+				expectCompleted(134.98)
+				mock.ExpectExec(`update payment set status`).
+					WithArgs(3, "refunded").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:   "Success retry later",
+			refund: retried,
+			mockFn: func() {
+				mock.ExpectBegin()
+				expectPayment()
+				expectSave(retried, 1)
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:   "Failure refund isn't pending",
+			refund: completed,
+			mockFn: func() {
+				mock.ExpectBegin()
+				expectPayment()
+				expectSave(completed, 0)
+				mock.ExpectRollback()
+			},
+			wantCause: models.ErrConflict,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Database{
+				pool: pool,
+			}
+
+			tt.mockFn()
+
+			err := d.SaveRefund(context.Background(), tt.refund)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SaveRefund() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.wantCause != nil && pkgerrors.Cause(err) != tt.wantCause {
+				t.Errorf("SaveRefund() error = %v, wantCause %v", err, tt.wantCause)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func TestDatabase_ClaimRefunds(t *testing.T) {
+	pool, mock := generateMockedDB()
+	defer checkError(pool.Close)
+
+	now := time.Date(2020, 3, 1, 12, 0, 0, 0, time.UTC)
+	columns := []string{"id", "order_id", "payment_id", "amount", "reason", "user_id", "created_at",
+		"status", "attempts", "last_error", "next_attempt_at", "completed_at", "provider", "reference"}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`select (.+) from refund r (.+) for update of r skip locked`).
+		WithArgs(10).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(8, 1, 3, 100, "order cancelled", 2, now, "pending", 1, "gateway is down", now, nil,
+				"fake", "fake_1_1"))
+	mock.ExpectExec(`update refund set next_attempt_at=(.+) where id = any`).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	d := &Database{pool: pool}
+	got, err := d.ClaimRefunds(context.Background(), 10)
+	if err != nil {
+		t.Fatalf("ClaimRefunds() error = %v", err)
+	}
+
+	if len(got) != 1 || got[0].ID != 8 || got[0].Reference != "fake_1_1" || !got[0].NextAttemptAt.After(now) {
+		t.Errorf("ClaimRefunds() got = %v, want leased refund № 8", got)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -2,18 +2,20 @@ package psql
 
 import (
 	"context"
-	"strconv"
+	"database/sql"
+	"sort"
 	"time"
 
 	"github.com/IamStubborN/petstore/db/models"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/pkg/errors"
 )
 
 func (d *Database) GetInventories(ctx context.Context) (map[string]int64, error) {
 	rows, err := d.pool.QueryContext(ctx, qm[storeInventoriesQ])
 	if err != nil {
-		return nil, errors.Wrap(err, "can't get data from order_info")
+		return nil, errors.Wrap(err, "can't get data from order_item_info")
 	}
 	defer checkError(rows.Close)
 
@@ -23,7 +25,7 @@ func (d *Database) GetInventories(ctx context.Context) (map[string]int64, error)
 		var quantity int64
 
 		if err = rows.Scan(&orderStatus, &quantity); err != nil {
-			return nil, errors.Wrap(err, "can't scan data from order_info")
+			return nil, errors.Wrap(err, "can't scan data from order_item_info")
 		}
 
 		result[orderStatus] = quantity
@@ -36,30 +38,47 @@ func (d *Database) GetInventories(ctx context.Context) (map[string]int64, error)
 	return result, nil
 }
 
-func (d *Database) CreateOrder(ctx context.Context, order *models.Order) (*models.Order, error) {
-	orderStatusID, err := d.getOrderStatusID(ctx, order.Status)
+// GetInventory breaks the ordered and stocked pets down by the groups
+// of the filter, pets without tags count under the empty tag. The pet tags
+// are joined only when the inventory is grouped by tag, so pets with several
+// tags aren't counted several times otherwise.
+func (d *Database) GetInventory(ctx context.Context, filter models.InventoryFilter) (models.Inventory, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	inventory := models.Inventory{}
+	err := d.pool.SelectContext(ctx, &inventory, qm[storeInventoryQ],
+		models.OrderStatusCancelled, nullTime(filter.ShipFrom), nullTime(filter.ShipTo),
+		filter.IsGroupedBy(models.InventoryGroupCategory),
+		filter.IsGroupedBy(models.InventoryGroupStatus),
+		filter.IsGroupedBy(models.InventoryGroupTag))
 	if err != nil {
-		return nil, errors.Wrap(err, "can't find order_status from db")
+		return nil, errors.Wrap(err, "can't get inventory")
 	}
-	orderStatusName := order.Status
-	order.Status = strconv.FormatInt(orderStatusID, 10)
 
-	shipDate := time.Now().UTC()
-	order.ShipDate = shipDate.Format(time.RFC3339)
+	return inventory, nil
+}
 
-	rows, err := d.pool.NamedQueryContext(ctx, qm[storeCreateQ], order)
+// CreateOrder places the order and reserves the ordered pets in one transaction,
+// so two customers can't order the same pet.
+func (d *Database) CreateOrder(ctx context.Context, order *models.Order) (*models.Order, error) {
+	tx, err := d.pool.BeginTxx(ctx, &sql.TxOptions{})
 	if err != nil {
-		return nil, errors.Wrap(err, "can't insert into order table")
+		return nil, errors.Wrap(err, "can't start transaction")
 	}
-	defer checkError(rows.Close)
 
-	for rows.Next() {
-		if err := rows.Scan(&order.ID); err != nil {
-			return nil, errors.Wrap(err, "can't scan order_id from order table")
+	defer func() {
+		if err != nil {
+			checkError(tx.Rollback)
+			return
 		}
-	}
+		checkError(tx.Commit)
+	}()
 
-	order.Status = orderStatusName
+	if err = createOrder(ctx, tx, order); err != nil {
+		return nil, err
+	}
 
 	return order, nil
 }
@@ -71,27 +90,365 @@ func (d *Database) FindOrderByID(ctx context.Context, orderID int64) (*models.Or
 		return nil, errors.Wrap(err, "can't scan data from db")
 	}
 
+	if err = attachOrderItems(ctx, d.pool, &order); err != nil {
+		return nil, err
+	}
+
 	return &order, nil
 }
 
-func (d *Database) DeleteOrderByID(ctx context.Context, orderID int64) error {
-	res, err := d.pool.ExecContext(ctx, qm[storeDeleteByIDQ], orderID)
+func (d *Database) FindOrders(ctx context.Context, filter models.OrderFilter) (*models.OrderList, string, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, "", err
+	}
+
+	after, err := models.DecodeCursor(filter.After)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var complete interface{}
+	if filter.Complete != nil {
+		complete = *filter.Complete
+	}
+
+	// one extra row tells whether the next page exists
+	orders := models.OrderList{}
+	err = d.pool.SelectContext(ctx, &orders, qm[storeFindQ],
+		filter.UserID, filter.PetID, filter.Status, complete,
+		nullTime(filter.ShipFrom), nullTime(filter.ShipTo), after.ID, filter.Limit+1)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "can't get data from order_info")
+	}
+
+	var next string
+	if len(orders) > filter.Limit {
+		orders = orders[:filter.Limit]
+		next = filter.CursorOf(orders[len(orders)-1]).Encode()
+	}
+
+	if err = attachOrderItems(ctx, d.pool, orders...); err != nil {
+		return nil, "", err
+	}
+
+	return &orders, next, nil
+}
+
+// DeleteOrderByID hides the order from the store, an order that isn't
+// delivered or cancelled yet is cancelled first. Deleted orders stay
+// in invoices and keep their payments and refunds.
+func (d *Database) DeleteOrderByID(ctx context.Context, orderID, userID int64) (*models.Order, error) {
+	tx, err := d.pool.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "can't start transaction")
+	}
+
+	defer func() {
+		if err != nil {
+			checkError(tx.Rollback)
+			return
+		}
+		checkError(tx.Commit)
+	}()
+
+	order, err := updateOrderStatus(ctx, tx, orderID, models.OrderStatusCancelled, userID)
+	if errors.Cause(err) == models.ErrInvalidTransition {
+		order = &models.Order{}
+		if err = tx.GetContext(ctx, order, qm[storeFindByIDQ], orderID); err != nil {
+			return nil, errors.Wrap(err, "can't scan data from db")
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = tx.ExecContext(ctx, qm[storeDeleteByIDQ], orderID); err != nil {
+		return nil, errors.Wrap(err, "can't delete order")
+	}
+
+	if err = addEvent(ctx, tx, models.AggregateOrder, keyOf(orderID), models.EventOrderDeleted, order); err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// ApproveOrder accepts the order once its payment is captured.
+func (d *Database) ApproveOrder(ctx context.Context, orderID, userID int64) (*models.Order, error) {
+	return d.changeOrderStatus(ctx, orderID, models.OrderStatusApproved, userID)
+}
+
+// DeliverOrder completes the order and marks the reserved pet sold.
+func (d *Database) DeliverOrder(ctx context.Context, orderID, userID int64) (*models.Order, error) {
+	return d.changeOrderStatus(ctx, orderID, models.OrderStatusDelivered, userID)
+}
+
+// CancelOrder returns the ordered quantity and releases the pet back to available.
+func (d *Database) CancelOrder(ctx context.Context, orderID, userID int64) (*models.Order, error) {
+	return d.changeOrderStatus(ctx, orderID, models.OrderStatusCancelled, userID)
+}
+
+func (d *Database) changeOrderStatus(ctx context.Context, orderID int64, status string, userID int64) (*models.Order, error) {
+	tx, err := d.pool.BeginTxx(ctx, &sql.TxOptions{})
 	if err != nil {
-		return errors.Wrap(err, "can't find order")
+		return nil, errors.Wrap(err, "can't start transaction")
 	}
 
-	count, err := res.RowsAffected()
+	defer func() {
+		if err != nil {
+			checkError(tx.Rollback)
+			return
+		}
+		checkError(tx.Commit)
+	}()
+
+	order, err := updateOrderStatus(ctx, tx, orderID, status, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// updateOrderStatus locks the order and moves it with its pets to the status.
+func updateOrderStatus(ctx context.Context, tx *sqlx.Tx, orderID int64, status string, userID int64) (*models.Order, error) {
+	var locked models.Order
+	err := tx.GetContext(ctx, &locked, qm[storeLockByIDQ], orderID)
+	if err == sql.ErrNoRows {
+		return nil, errors.Wrapf(models.ErrNotFound, "order № %d doesn't exist", orderID)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "can't lock order")
+	}
+
+	if err = models.CheckOrderStatusTransition(locked.Status, status); err != nil {
+		return nil, err
+	}
+
+	if status == models.OrderStatusApproved {
+		var isPaid bool
+		if err = tx.GetContext(ctx, &isPaid, qm[paymentIsCapturedQ], orderID); err != nil {
+			return nil, errors.Wrap(err, "can't check order payment")
+		}
+
+		if !isPaid {
+			return nil, errors.Wrapf(models.ErrConflict, "order № %d isn't paid", orderID)
+		}
+	}
+
+	locked.ID = orderID
+	if err = attachOrderItems(ctx, tx, &locked); err != nil {
+		return nil, err
+	}
+
+	isComplete := status == models.OrderStatusDelivered
+	if _, err = tx.ExecContext(ctx, qm[storeSetStatusQ], orderID, status, isComplete); err != nil {
+		return nil, errors.Wrap(err, "can't update order status")
+	}
+
+	// items come sorted by pet id, so pets are locked in the same order everywhere
+	for _, item := range locked.Items {
+		switch status {
+		case models.OrderStatusDelivered:
+			err = changePetStatus(ctx, tx, item.PetID, models.PetStatusSold, userID)
+		case models.OrderStatusCancelled:
+			if _, err = tx.ExecContext(ctx, qm[petReturnQuantityQ], item.PetID, item.Quantity); err != nil {
+				return nil, errors.Wrap(err, "can't update pet quantity")
+			}
+			err = changePetStatus(ctx, tx, item.PetID, models.PetStatusAvailable, userID)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var order models.Order
+	if err = tx.GetContext(ctx, &order, qm[storeFindByIDQ], orderID); err != nil {
+		return nil, errors.Wrap(err, "can't scan data from db")
+	}
+
+	order.SetItems(locked.Items)
+
+	if err = addEvent(ctx, tx, models.AggregateOrder, keyOf(orderID), models.OrderStatusEvent(status), &order); err != nil {
+		return nil, err
+	}
+
+	return &order, nil
+}
+
+// createOrder reserves the order items, prices them with the order coupons
+// and inserts the order with them, an order of a single pet becomes an order
+// of one item. Coupons are locked first and items are sorted by pet id,
+// so concurrent orders lock rows in the same order and can't deadlock.
+func createOrder(ctx context.Context, tx *sqlx.Tx, order *models.Order) error {
+	if err := order.NormalizeItems(); err != nil {
+		return err
+	}
+
+	var orderStatusID int64
+	if err := tx.GetContext(ctx, &orderStatusID, qm[storeGetStatusQ], order.Status); err != nil {
+		return errors.Wrap(err, "can't find order_status from db")
+	}
+
+	coupons, err := lockCoupons(ctx, tx, order.Coupons, order.UserID)
 	if err != nil {
 		return err
 	}
 
-	if count == 0 {
-		return errors.Errorf("order № %d doesn't exist", orderID)
+	sort.Slice(order.Items, func(i, j int) bool {
+		return order.Items[i].PetID < order.Items[j].PetID
+	})
+
+	for _, item := range order.Items {
+		if err = reservePet(ctx, tx, item.PetID, item.Quantity, order.UserID); err != nil {
+			return err
+		}
+	}
+
+	if err = priceOrderItems(ctx, tx, order.Items); err != nil {
+		return err
+	}
+
+	if err = order.ApplyCoupons(coupons); err != nil {
+		return err
+	}
+
+	order.ShipDate = time.Now().UTC().Format(time.RFC3339)
+
+	stmt, err := tx.PrepareNamedContext(ctx, qm[storeCreateQ])
+	if err != nil {
+		return err
+	}
+	defer checkError(stmt.Close)
+
+	argQ := map[string]interface{}{
+		"user_id":      order.UserID,
+		"gross":        order.Gross,
+		"discount":     order.Discount,
+		"ship_date":    order.ShipDate,
+		"order_status": orderStatusID,
+		"complete":     order.Complete,
+	}
+
+	if err = stmt.QueryRowxContext(ctx, argQ).Scan(&order.ID); err != nil {
+		return errors.Wrap(err, "can't insert into order table")
+	}
+
+	for _, item := range order.Items {
+		item.OrderID = order.ID
+		err = tx.GetContext(ctx, &item.ID, qm[orderItemCreateQ],
+			order.ID, item.PetID, item.Quantity, item.Price, item.Discount)
+		if err != nil {
+			return errors.Wrap(err, "can't insert into order_item table")
+		}
+	}
+
+	for _, coupon := range coupons {
+		if _, err = tx.ExecContext(ctx, qm[couponUseAddQ], coupon.ID, order.ID, order.UserID); err != nil {
+			return errors.Wrap(err, "can't insert into coupon_use table")
+		}
+	}
+
+	order.SetItems(order.Items)
+
+	return addEvent(ctx, tx, models.AggregateOrder, keyOf(order.ID), models.EventOrderCreated, order)
+}
+
+// priceOrderItems sets the current price and category of the items pets.
+func priceOrderItems(ctx context.Context, tx *sqlx.Tx, items models.OrderItemList) error {
+	petIDs := make([]int64, 0, len(items))
+	for _, item := range items {
+		petIDs = append(petIDs, item.PetID)
+	}
+
+	rows, err := tx.QueryxContext(ctx, qm[petPricesByIDsQ], pq.Array(petIDs))
+	if err != nil {
+		return errors.Wrap(err, "can't get data from pet_info")
+	}
+	defer checkError(rows.Close)
+
+	prices := make(map[int64]*models.OrderItem, len(items))
+	for rows.Next() {
+		var price models.OrderItem
+		if err = rows.Scan(&price.PetID, &price.CategoryID, &price.Price); err != nil {
+			return errors.Wrap(err, "can't scan data from pet_info")
+		}
+		prices[price.PetID] = &price
+	}
+
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		price, ok := prices[item.PetID]
+		if !ok {
+			return errors.Wrapf(models.ErrNotFound, "pet № %d doesn't exist", item.PetID)
+		}
+
+		item.CategoryID = price.CategoryID
+		item.Price = price.Price
+	}
+
+	return nil
+}
+
+// attachOrderItems loads the items of all the orders with one query.
+func attachOrderItems(ctx context.Context, q sqlx.QueryerContext, orders ...*models.Order) error {
+	if len(orders) == 0 {
+		return nil
+	}
+
+	orderIDs := make([]int64, 0, len(orders))
+	for _, order := range orders {
+		orderIDs = append(orderIDs, order.ID)
+	}
+
+	var items models.OrderItemList
+	if err := sqlx.SelectContext(ctx, q, &items, qm[orderItemsGetByOrderIDsQ], pq.Array(orderIDs)); err != nil {
+		return errors.Wrap(err, "can't get data from order_item_info")
+	}
+
+	byOrderID := make(map[int64]models.OrderItemList, len(orders))
+	for _, item := range items {
+		byOrderID[item.OrderID] = append(byOrderID[item.OrderID], item)
+	}
+
+	for _, order := range orders {
+		order.SetItems(byOrderID[order.ID])
 	}
 
 	return nil
 }
 
+// reservePet takes the ordered quantity from a locked available pet
+// and moves the pet to pending.
+func reservePet(ctx context.Context, tx *sqlx.Tx, petID int64, quantity int32, userID int64) error {
+	var status string
+	var stock int32
+	err := tx.QueryRowxContext(ctx, qm[petLockForOrderQ], petID).Scan(&status, &stock)
+	if err == sql.ErrNoRows {
+		return errors.Wrapf(models.ErrNotFound, "pet № %d doesn't exist", petID)
+	}
+	if err != nil {
+		return errors.Wrap(err, "can't lock pet")
+	}
+
+	if status != models.PetStatusAvailable {
+		return errors.Wrapf(models.ErrConflict, "pet № %d is %s", petID, status)
+	}
+
+	if stock < quantity {
+		return errors.Wrapf(models.ErrConflict, "pet № %d has only %d left", petID, stock)
+	}
+
+	if _, err = tx.ExecContext(ctx, qm[petTakeQuantityQ], petID, quantity); err != nil {
+		return errors.Wrap(err, "can't update pet quantity")
+	}
+
+	return changePetStatus(ctx, tx, petID, models.PetStatusPending, userID)
+}
+
 func (d *Database) getOrderStatusID(ctx context.Context, orderStatusName string) (int64, error) {
 	var orderStatusID int64
 	err := d.pool.GetContext(ctx, &orderStatusID, qm[storeGetStatusQ], orderStatusName)
@@ -120,7 +477,7 @@ func (d *Database) CreateInvoiceByDates(ctx context.Context, from, to string) ([
 	}
 
 	if len(invoices) == 0 {
-		return nil, errors.New("no data with this dates")
+		return nil, errors.Wrap(models.ErrNotFound, "no data with this dates")
 	}
 
 	return invoices, nil
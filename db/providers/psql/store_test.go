@@ -2,15 +2,19 @@ package psql
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"log"
 	"reflect"
+	"strconv"
 	"testing"
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/IamStubborN/petstore/db/models"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	pkgerrors "github.com/pkg/errors"
 )
 
 func generateMockedDB() (*sqlx.DB, sqlmock.Sqlmock) {
@@ -53,20 +57,24 @@ func TestDatabase_CreateInvoiceByDates(t *testing.T) {
 				from: "2019-09-07",
 				to:   "2019-09-08",
 				mockFn: func() {
-					mock.ExpectQuery(`select (.+) from invoice_info where ship_date between . and .`).
-						WithArgs("2019-09-07", "2019-09-08").
+					mock.ExpectQuery(`select (.+) from invoice_info where ship_date >= . and ship_date < . `+
+						`union all select (.+) from invoice_refund_info where ship_date >= . and ship_date < .`).
+						WithArgs("2019-09-07", "2019-09-08", "2019-09-07", "2019-09-08").
 						WillReturnRows(sqlmock.NewRows([]string{
-							"id", "user_name", "pet", "category",
-							"ship_date", "quantity", "price"}).
-							AddRow(1, "Jack The Ripper", "John Snow",
-								"Cat", "2019-08-07T15:35:04", 15, 35.00).
-							AddRow(2, "Ginger", "Phil Heat",
-								"Dog", "2019-09-08T15:35:04", 34, 49.99))
+							"id", "line", "user_name", "pet", "category",
+							"ship_date", "quantity", "price", "discount", "refund"}).
+							AddRow(1, 1, "Jack The Ripper", "John Snow",
+								"Cat", "2019-08-07T15:35:04", 15, 35.00, 0, false).
+							AddRow(2, 1, "Ginger", "Phil Heat",
+								"Dog", "2019-09-08T15:35:04", 34, 49.99, 0, false).
+							AddRow(1, 1, "Jack The Ripper", "Refund",
+								"order cancelled", "2019-09-08T16:00:00", 1, -525.00, 0, true))
 				},
 			},
 			want: []*models.InvoiceItem{
 				{
 					ID:       1,
+					Line:     1,
 					User:     "Jack The Ripper",
 					Pet:      "John Snow",
 					Category: "Cat",
@@ -76,6 +84,7 @@ func TestDatabase_CreateInvoiceByDates(t *testing.T) {
 				},
 				{
 					ID:       2,
+					Line:     1,
 					User:     "Ginger",
 					Pet:      "Phil Heat",
 					Category: "Dog",
@@ -83,6 +92,17 @@ func TestDatabase_CreateInvoiceByDates(t *testing.T) {
 					Quantity: 34,
 					Price:    49.99,
 				},
+				{
+					ID:       1,
+					Line:     1,
+					User:     "Jack The Ripper",
+					Pet:      "Refund",
+					Category: "order cancelled",
+					ShipDate: "2019-09-08T16:00:00",
+					Quantity: 1,
+					Price:    -525.00,
+					Refund:   true,
+				},
 			},
 		},
 		{
@@ -93,8 +113,8 @@ func TestDatabase_CreateInvoiceByDates(t *testing.T) {
 				from: "2019-09-12",
 				to:   "2019-09-09",
 				mockFn: func() {
-					mock.ExpectQuery(`select (.+) from invoice_info where ship_date between . and .`).
-						WithArgs("2019-09-12", "2019-09-09").
+					mock.ExpectQuery(`select (.+) from invoice_info where ship_date >= . and ship_date < .`).
+						WithArgs("2019-09-12", "2019-09-09", "2019-09-12", "2019-09-09").
 						WillReturnError(errors.New("can't get data for invoice"))
 				},
 			},
@@ -117,7 +137,7 @@ func TestDatabase_CreateInvoiceByDates(t *testing.T) {
 			}
 
 			for idx := range got {
-				if !reflect.DeepEqual(got[idx].ID, tt.want[idx].ID) {
+				if got[idx].ID != tt.want[idx].ID || got[idx].Refund != tt.want[idx].Refund {
 					t.Errorf("CreateInvoiceByDates() got = %v, want %v", got[idx], tt.want[idx])
 				}
 			}
@@ -133,6 +153,39 @@ func TestDatabase_CreateOrder(t *testing.T) {
 	pool, mock := generateMockedDB()
 	defer checkError(pool.Close)
 
+	expectReserve := func(petID int64, quantity int32) {
+		mock.ExpectQuery(`select ps.name, p.quantity from pet p (.+) for update`).
+			WithArgs(petID).
+			WillReturnRows(sqlmock.NewRows([]string{"name", "quantity"}).AddRow("available", 12))
+		mock.ExpectExec(`update pet set quantity=quantity`).
+			WithArgs(petID, quantity).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(`select ps.name from pet p (.+) for update`).
+			WithArgs(petID).
+			WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("available"))
+		mock.ExpectExec(`update pet set pet_status_id`).
+			WithArgs(petID, "pending").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`insert into pet_status_history`).
+			WithArgs(petID, "available", "pending", 1).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		expectEvent(mock, "pet", strconv.FormatInt(petID, 10), "pet.status_changed")
+	}
+	expectPrices := func(petIDs []int64, rows *sqlmock.Rows) {
+		mock.ExpectQuery(`select id, category_id, price from pet_info where id = any`).
+			WithArgs(pq.Array(petIDs)).
+			WillReturnRows(rows)
+	}
+	priceColumns := []string{"id", "category_id", "price"}
+	expectItem := func(orderID, petID int64, quantity int32, price, discount float64, itemID int64) {
+		mock.ExpectQuery(`insert into order_item (.+) returning id`).
+			WithArgs(orderID, petID, quantity, price, discount).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(itemID))
+	}
+	couponColumns := []string{"id", "code", "kind", "value", "category_id", "valid_from",
+		"valid_to", "max_uses", "max_uses_per_user", "stackable"}
+	since := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+
 	type fields struct {
 		pool *sqlx.DB
 	}
@@ -142,11 +195,12 @@ func TestDatabase_CreateOrder(t *testing.T) {
 		mockFn func()
 	}
 	tests := []struct {
-		name    string
-		fields  fields
-		args    args
-		want    *models.Order
-		wantErr bool
+		name      string
+		fields    fields
+		args      args
+		want      *models.Order
+		wantCause error
+		wantErr   bool
 	}{
 		{
 			name:   "Success 1",
@@ -163,10 +217,16 @@ func TestDatabase_CreateOrder(t *testing.T) {
 					Complete: false,
 				},
 				mockFn: func() {
+					mock.ExpectBegin()
 					mock.ExpectQuery(`select (.+) from order_status where name=(.+)`).
 						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
-					mock.ExpectQuery(`insert into "order" (.+) values (.+)`).
+					expectReserve(1, 12)
+					expectPrices([]int64{1}, sqlmock.NewRows(priceColumns).AddRow(1, 1, 35.00))
+					mock.ExpectPrepare(`insert into "order" (.+) values (.+)`).ExpectQuery().
 						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(23))
+					expectItem(23, 1, 12, 35.00, 0, 40)
+					expectEvent(mock, "order", "23", "order.created")
+					mock.ExpectCommit()
 				},
 			},
 			want: &models.Order{
@@ -174,12 +234,190 @@ func TestDatabase_CreateOrder(t *testing.T) {
 				PetID:    1,
 				UserID:   1,
 				Quantity: 12,
+				Items: models.OrderItemList{
+					{ID: 40, OrderID: 23, PetID: 1, CategoryID: 1, Quantity: 12, Price: 35.00},
+				},
+				Gross:    420.00,
 				ShipDate: time.Now().UTC().Format(time.RFC3339),
 				Status:   "placed",
 				Complete: false,
 			},
 			wantErr: false,
 		},
+		{
+			name:   "Success several pets",
+			fields: fields{pool: pool},
+			args: args{
+				ctx: context.Background(),
+				order: &models.Order{
+					UserID: 1,
+					Items: models.OrderItemList{
+						{PetID: 3, Quantity: 2},
+						{PetID: 1, Quantity: 1},
+						{PetID: 3, Quantity: 1},
+					},
+					Status: "placed",
+				},
+				mockFn: func() {
+					mock.ExpectBegin()
+					mock.ExpectQuery(`select (.+) from order_status where name=(.+)`).
+						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+					expectReserve(1, 1)
+					expectReserve(3, 3)
+					expectPrices([]int64{1, 3}, sqlmock.NewRows(priceColumns).
+						AddRow(1, 1, 35.00).AddRow(3, 2, 49.99))
+					mock.ExpectPrepare(`insert into "order" (.+) values (.+)`).ExpectQuery().
+						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(24))
+					expectItem(24, 1, 1, 35.00, 0, 41)
+					expectItem(24, 3, 3, 49.99, 0, 42)
+					expectEvent(mock, "order", "24", "order.created")
+					mock.ExpectCommit()
+				},
+			},
+			want: &models.Order{
+				ID:       24,
+				UserID:   1,
+				Quantity: 4,
+				Items: models.OrderItemList{
+					{ID: 41, OrderID: 24, PetID: 1, CategoryID: 1, Quantity: 1, Price: 35.00},
+					{ID: 42, OrderID: 24, PetID: 3, CategoryID: 2, Quantity: 3, Price: 49.99},
+				},
+				Gross:    184.97,
+				ShipDate: time.Now().UTC().Format(time.RFC3339),
+				Status:   "placed",
+			},
+			wantErr: false,
+		},
+		{
+			name:   "Success with coupons",
+			fields: fields{pool: pool},
+			args: args{
+				ctx: context.Background(),
+				order: &models.Order{
+					UserID: 1,
+					Items: models.OrderItemList{
+						{PetID: 1, Quantity: 1},
+						{PetID: 3, Quantity: 2},
+					},
+					Coupons: []string{"SPRING10", "DOGS5", "SPRING10"},
+					Status:  "placed",
+				},
+				mockFn: func() {
+					mock.ExpectBegin()
+					mock.ExpectQuery(`select (.+) from order_status where name=(.+)`).
+						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+					mock.ExpectQuery(`select (.+) from coupon where code = any(.+) for update`).
+						WithArgs(pq.Array([]string{"SPRING10", "DOGS5"})).
+						WillReturnRows(sqlmock.NewRows(couponColumns).
+							AddRow(1, "SPRING10", "percent", 10, 0, since, nil, 0, 0, true).
+							AddRow(2, "DOGS5", "fixed", 5, 2, since, nil, 0, 1, true))
+					mock.ExpectQuery(`select count(.+) from coupon_use`).
+						WithArgs(1, 1).
+						WillReturnRows(sqlmock.NewRows([]string{"count", "count"}).AddRow(7, 0))
+					mock.ExpectQuery(`select count(.+) from coupon_use`).
+						WithArgs(2, 1).
+						WillReturnRows(sqlmock.NewRows([]string{"count", "count"}).AddRow(3, 0))
+					expectReserve(1, 1)
+					expectReserve(3, 2)
+					expectPrices([]int64{1, 3}, sqlmock.NewRows(priceColumns).
+						AddRow(1, 1, 35.00).AddRow(3, 2, 49.99))
+					mock.ExpectPrepare(`insert into "order" (.+) values (.+)`).ExpectQuery().
+						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(25))
+					expectItem(25, 1, 1, 35.00, 3.50, 43)
+					expectItem(25, 3, 2, 49.99, 15.00, 44)
+					mock.ExpectExec(`insert into coupon_use`).
+						WithArgs(1, 25, 1).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectExec(`insert into coupon_use`).
+						WithArgs(2, 25, 1).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					expectEvent(mock, "order", "25", "order.created")
+					mock.ExpectCommit()
+				},
+			},
+			want: &models.Order{
+				ID:       25,
+				UserID:   1,
+				Quantity: 3,
+				Items: models.OrderItemList{
+					{ID: 43, OrderID: 25, PetID: 1, CategoryID: 1, Quantity: 1, Price: 35.00, Discount: 3.50},
+					{ID: 44, OrderID: 25, PetID: 3, CategoryID: 2, Quantity: 2, Price: 49.99, Discount: 15.00},
+				},
+				Coupons:  []string{"SPRING10", "DOGS5"},
+				Gross:    134.98,
+				Discount: 18.50,
+				ShipDate: time.Now().UTC().Format(time.RFC3339),
+				Status:   "placed",
+			},
+			wantErr: false,
+		},
+		{
+			name:   "Failure coupon is used",
+			fields: fields{pool: pool},
+			args: args{
+				ctx: context.Background(),
+				order: &models.Order{
+					UserID:   1,
+					PetID:    3,
+					Quantity: 1,
+					Coupons:  []string{"DOGS5"},
+					Status:   "placed",
+				},
+				mockFn: func() {
+					mock.ExpectBegin()
+					mock.ExpectQuery(`select (.+) from order_status where name=(.+)`).
+						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+					mock.ExpectQuery(`select (.+) from coupon where code = any(.+) for update`).
+						WithArgs(pq.Array([]string{"DOGS5"})).
+						WillReturnRows(sqlmock.NewRows(couponColumns).
+							AddRow(2, "DOGS5", "fixed", 5, 2, since, nil, 0, 1, true))
+					mock.ExpectQuery(`select count(.+) from coupon_use`).
+						WithArgs(2, 1).
+						WillReturnRows(sqlmock.NewRows([]string{"count", "count"}).AddRow(3, 1))
+					mock.ExpectRollback()
+				},
+			},
+			wantCause: models.ErrConflict,
+			wantErr:   true,
+		},
+		{
+			name:   "Failure unknown coupon",
+			fields: fields{pool: pool},
+			args: args{
+				ctx: context.Background(),
+				order: &models.Order{
+					UserID:   1,
+					PetID:    3,
+					Quantity: 1,
+					Coupons:  []string{"FREE"},
+					Status:   "placed",
+				},
+				mockFn: func() {
+					mock.ExpectBegin()
+					mock.ExpectQuery(`select (.+) from order_status where name=(.+)`).
+						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+					mock.ExpectQuery(`select (.+) from coupon where code = any(.+) for update`).
+						WithArgs(pq.Array([]string{"FREE"})).
+						WillReturnRows(sqlmock.NewRows(couponColumns))
+					mock.ExpectRollback()
+				},
+			},
+			wantCause: models.ErrNotFound,
+			wantErr:   true,
+		},
+		{
+			name:   "Failure no items",
+			fields: fields{pool: pool},
+			args: args{
+				ctx:   context.Background(),
+				order: &models.Order{UserID: 1, Status: "placed"},
+				mockFn: func() {
+					mock.ExpectBegin()
+					mock.ExpectRollback()
+				},
+			},
+			wantErr: true,
+		},
 		{
 			name:   "Failure 1",
 			fields: fields{pool: pool},
@@ -195,9 +433,11 @@ func TestDatabase_CreateOrder(t *testing.T) {
 					Complete: false,
 				},
 				mockFn: func() {
+					mock.ExpectBegin()
 					mock.ExpectQuery(`select (.+) from order_status where name=(.+)`).
 						WithArgs("BAD_STATUS").
 						WillReturnError(errors.New("can't get order status id by name"))
+					mock.ExpectRollback()
 				},
 			},
 			wantErr: true,
@@ -217,14 +457,66 @@ func TestDatabase_CreateOrder(t *testing.T) {
 					Complete: false,
 				},
 				mockFn: func() {
+					mock.ExpectBegin()
 					mock.ExpectQuery(`select (.+) from order_status where name=(.+)`).
 						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
-					mock.ExpectQuery(`insert into "order" (.+) values (.+)`).
+					expectReserve(99, 12)
+					expectPrices([]int64{99}, sqlmock.NewRows(priceColumns).AddRow(99, 1, 35.00))
+					mock.ExpectPrepare(`insert into "order" (.+) values (.+)`).ExpectQuery().
 						WillReturnError(errors.New("can't get order, invalid pet_id"))
+					mock.ExpectRollback()
 				},
 			},
 			wantErr: true,
 		},
+		{
+			name:   "Failure pet is reserved",
+			fields: fields{pool: pool},
+			args: args{
+				ctx: context.Background(),
+				order: &models.Order{
+					PetID:    2,
+					UserID:   1,
+					Quantity: 1,
+					Status:   "placed",
+				},
+				mockFn: func() {
+					mock.ExpectBegin()
+					mock.ExpectQuery(`select (.+) from order_status where name=(.+)`).
+						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+					mock.ExpectQuery(`select ps.name, p.quantity from pet p (.+) for update`).
+						WithArgs(2).
+						WillReturnRows(sqlmock.NewRows([]string{"name", "quantity"}).AddRow("pending", 3))
+					mock.ExpectRollback()
+				},
+			},
+			wantCause: models.ErrConflict,
+			wantErr:   true,
+		},
+		{
+			name:   "Failure not enough quantity",
+			fields: fields{pool: pool},
+			args: args{
+				ctx: context.Background(),
+				order: &models.Order{
+					PetID:    2,
+					UserID:   1,
+					Quantity: 5,
+					Status:   "placed",
+				},
+				mockFn: func() {
+					mock.ExpectBegin()
+					mock.ExpectQuery(`select (.+) from order_status where name=(.+)`).
+						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+					mock.ExpectQuery(`select ps.name, p.quantity from pet p (.+) for update`).
+						WithArgs(2).
+						WillReturnRows(sqlmock.NewRows([]string{"name", "quantity"}).AddRow("available", 3))
+					mock.ExpectRollback()
+				},
+			},
+			wantCause: models.ErrConflict,
+			wantErr:   true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -240,6 +532,10 @@ func TestDatabase_CreateOrder(t *testing.T) {
 				t.Errorf("CreateOrder() error = %v, wantErr %v", err, tt.wantErr)
 			}
 
+			if tt.wantCause != nil && pkgerrors.Cause(err) != tt.wantCause {
+				t.Errorf("CreateOrder() error = %v, wantCause %v", err, tt.wantCause)
+			}
+
 			if got != nil && !reflect.DeepEqual(*(got), *(tt.want)) {
 				t.Errorf("CreateOrder() got = %v, want %v", got, tt.want)
 			}
@@ -251,10 +547,360 @@ func TestDatabase_CreateOrder(t *testing.T) {
 	}
 }
 
+func TestDatabase_FindOrders(t *testing.T) {
+	pool, mock := generateMockedDB()
+	defer checkError(pool.Close)
+
+	orderColumns := []string{"id", "user_id", "pet_id", "quantity",
+		"ship_date", "order_status", "complete"}
+	itemColumns := []string{"id", "order_id", "pet_id", "pet_name", "quantity"}
+	isComplete := true
+	shipFrom := time.Date(2019, 9, 1, 0, 0, 0, 0, time.UTC)
+
+	type fields struct {
+		pool *sqlx.DB
+	}
+	type args struct {
+		ctx    context.Context
+		filter models.OrderFilter
+		mockFn func()
+	}
+	tests := []struct {
+		name     string
+		fields   fields
+		args     args
+		want     *models.OrderList
+		wantNext string
+		wantErr  bool
+	}{
+		{
+			name:   "Success 1",
+			fields: fields{pool: pool},
+			args: args{
+				ctx:    context.Background(),
+				filter: models.OrderFilter{UserID: 1, Status: "delivered", Complete: &isComplete, ShipFrom: shipFrom, Limit: 1},
+				mockFn: func() {
+					mock.ExpectQuery(`select (.+) from order_info (.+) order by id`).
+						WithArgs(1, 0, "delivered", true, shipFrom, nil, 0, 2).
+						WillReturnRows(sqlmock.NewRows(orderColumns).
+							AddRow(3, 1, 2, 1, "2019-09-07T15:35:04", "delivered", true).
+							AddRow(5, 1, 1, 1, "2019-09-08T15:35:04", "delivered", true))
+					mock.ExpectQuery(`select (.+) from order_item_info where order_id = any`).
+						WithArgs(pq.Array([]int64{3})).
+						WillReturnRows(sqlmock.NewRows(itemColumns).
+							AddRow(7, 3, 2, "Rex", 1))
+				},
+			},
+			want: &models.OrderList{
+				{ID: 3, UserID: 1, PetID: 2, Quantity: 1, ShipDate: "2019-09-07T15:35:04",
+					Items:  models.OrderItemList{{ID: 7, OrderID: 3, PetID: 2, PetName: "Rex", Quantity: 1}},
+					Status: "delivered", Complete: true},
+			},
+			wantNext: models.Cursor{ID: 3}.Encode(),
+			wantErr:  false,
+		},
+		{
+			name:   "Failure bad status",
+			fields: fields{pool: pool},
+			args: args{
+				ctx:    context.Background(),
+				filter: models.OrderFilter{Status: "lost"},
+				mockFn: func() {},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:   "Failure 1",
+			fields: fields{pool: pool},
+			args: args{
+				ctx:    context.Background(),
+				filter: models.OrderFilter{},
+				mockFn: func() {
+					mock.ExpectQuery(`select (.+) from order_info`).
+						WillReturnError(errors.New("can't get data from order_info"))
+				},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Database{
+				pool: tt.fields.pool,
+			}
+
+			tt.args.mockFn()
+
+			got, next, err := d.FindOrders(tt.args.ctx, tt.args.filter)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("FindOrders() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindOrders() got = %v, want %v", got, tt.want)
+			}
+
+			if next != tt.wantNext {
+				t.Errorf("FindOrders() next = %v, want %v", next, tt.wantNext)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func TestDatabase_changeOrderStatus(t *testing.T) {
+	pool, mock := generateMockedDB()
+	defer checkError(pool.Close)
+
+	orderColumns := []string{"id", "user_id", "pet_id", "quantity",
+		"ship_date", "order_status", "complete"}
+
+	expectLock := func(orderStatus string) {
+		mock.ExpectQuery(`select (.+) from "order" o (.+) for update`).
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"order_status"}).AddRow(orderStatus))
+	}
+	expectItems := func() {
+		mock.ExpectQuery(`select (.+) from order_item_info where order_id = any`).
+			WithArgs(pq.Array([]int64{1})).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "order_id", "pet_id", "pet_name", "quantity"}).
+				AddRow(7, 1, 2, "Rex", 3))
+	}
+	items := models.OrderItemList{{ID: 7, OrderID: 1, PetID: 2, PetName: "Rex", Quantity: 3}}
+	expectPaid := func(isPaid bool) {
+		mock.ExpectQuery(`select exists(.+) from payment`).
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(isPaid))
+	}
+	expectPetStatus := func(from, to string) {
+		mock.ExpectQuery(`select ps.name from pet p (.+) for update`).
+			WithArgs(2).
+			WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow(from))
+		mock.ExpectExec(`update pet set pet_status_id`).
+			WithArgs(2, to).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`insert into pet_status_history`).
+			WithArgs(2, from, to, 5).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		expectEvent(mock, "pet", "2", "pet.status_changed")
+	}
+
+	type fields struct {
+		pool *sqlx.DB
+	}
+	type args struct {
+		ctx     context.Context
+		orderID int64
+		status  string
+		mockFn  func()
+	}
+	tests := []struct {
+		name      string
+		fields    fields
+		args      args
+		want      *models.Order
+		wantCause error
+		wantErr   bool
+	}{
+		{
+			name:   "Success approve",
+			fields: fields{pool: pool},
+			args: args{
+				ctx:     context.Background(),
+				orderID: 1,
+				status:  "approved",
+				mockFn: func() {
+					mock.ExpectBegin()
+					expectLock("placed")
+					expectPaid(true)
+					expectItems()
+					mock.ExpectExec(`update "order" set order_status_id`).
+						WithArgs(1, "approved", false).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectQuery(`select (.+) from order_info where id=(.+)`).
+						WithArgs(1).
+						WillReturnRows(sqlmock.NewRows(orderColumns).
+							AddRow(1, 4, 2, 3, "2019-09-05T15:35:12", "approved", false))
+					expectEvent(mock, "order", "1", "order.approved")
+					mock.ExpectCommit()
+				},
+			},
+			want: &models.Order{ID: 1, UserID: 4, PetID: 2, Quantity: 3, Items: items,
+				ShipDate: "2019-09-05T15:35:12", Status: "approved"},
+			wantErr: false,
+		},
+		{
+			name:   "Success deliver",
+			fields: fields{pool: pool},
+			args: args{
+				ctx:     context.Background(),
+				orderID: 1,
+				status:  "delivered",
+				mockFn: func() {
+					mock.ExpectBegin()
+					expectLock("approved")
+					expectItems()
+					mock.ExpectExec(`update "order" set order_status_id`).
+						WithArgs(1, "delivered", true).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					expectPetStatus("pending", "sold")
+					mock.ExpectQuery(`select (.+) from order_info where id=(.+)`).
+						WithArgs(1).
+						WillReturnRows(sqlmock.NewRows(orderColumns).
+							AddRow(1, 4, 2, 3, "2019-09-05T15:35:12", "delivered", true))
+					expectEvent(mock, "order", "1", "order.delivered")
+					mock.ExpectCommit()
+				},
+			},
+			want: &models.Order{ID: 1, UserID: 4, PetID: 2, Quantity: 3, Items: items,
+				ShipDate: "2019-09-05T15:35:12", Status: "delivered", Complete: true},
+			wantErr: false,
+		},
+		{
+			name:   "Success cancel",
+			fields: fields{pool: pool},
+			args: args{
+				ctx:     context.Background(),
+				orderID: 1,
+				status:  "cancelled",
+				mockFn: func() {
+					mock.ExpectBegin()
+					expectLock("approved")
+					expectItems()
+					mock.ExpectExec(`update "order" set order_status_id`).
+						WithArgs(1, "cancelled", false).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectExec(`update pet set quantity=quantity\+`).
+						WithArgs(2, 3).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					expectPetStatus("pending", "available")
+					mock.ExpectQuery(`select (.+) from order_info where id=(.+)`).
+						WithArgs(1).
+						WillReturnRows(sqlmock.NewRows(orderColumns).
+							AddRow(1, 4, 2, 3, "2019-09-05T15:35:12", "cancelled", false))
+					expectEvent(mock, "order", "1", "order.cancelled")
+					mock.ExpectCommit()
+				},
+			},
+			want: &models.Order{ID: 1, UserID: 4, PetID: 2, Quantity: 3, Items: items,
+				ShipDate: "2019-09-05T15:35:12", Status: "cancelled"},
+			wantErr: false,
+		},
+		{
+			name:   "Failure approve unpaid order",
+			fields: fields{pool: pool},
+			args: args{
+				ctx:     context.Background(),
+				orderID: 1,
+				status:  "approved",
+				mockFn: func() {
+					mock.ExpectBegin()
+					expectLock("placed")
+					expectPaid(false)
+					mock.ExpectRollback()
+				},
+			},
+			want:      nil,
+			wantCause: models.ErrConflict,
+			wantErr:   true,
+		},
+		{
+			name:   "Failure deliver placed order",
+			fields: fields{pool: pool},
+			args: args{
+				ctx:     context.Background(),
+				orderID: 1,
+				status:  "delivered",
+				mockFn: func() {
+					mock.ExpectBegin()
+					expectLock("placed")
+					mock.ExpectRollback()
+				},
+			},
+			want:      nil,
+			wantCause: models.ErrInvalidTransition,
+			wantErr:   true,
+		},
+		{
+			name:   "Failure not found",
+			fields: fields{pool: pool},
+			args: args{
+				ctx:     context.Background(),
+				orderID: 99,
+				status:  "approved",
+				mockFn: func() {
+					mock.ExpectBegin()
+					mock.ExpectQuery(`select (.+) from "order" o (.+) for update`).
+						WithArgs(99).
+						WillReturnError(sql.ErrNoRows)
+					mock.ExpectRollback()
+				},
+			},
+			want:      nil,
+			wantCause: models.ErrNotFound,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Database{
+				pool: tt.fields.pool,
+			}
+
+			tt.args.mockFn()
+
+			got, err := d.changeOrderStatus(tt.args.ctx, tt.args.orderID, tt.args.status, 5)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("changeOrderStatus() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.wantCause != nil && pkgerrors.Cause(err) != tt.wantCause {
+				t.Errorf("changeOrderStatus() error = %v, wantCause %v", err, tt.wantCause)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("changeOrderStatus() got = %v, want %v", got, tt.want)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
 func TestDatabase_DeleteOrderByID(t *testing.T) {
 	pool, mock := generateMockedDB()
 	defer checkError(pool.Close)
 
+	orderColumns := []string{"id", "user_id", "pet_id", "quantity",
+		"ship_date", "order_status", "complete"}
+
+	expectLock := func(orderID int64, orderStatus string) {
+		mock.ExpectQuery(`select (.+) from "order" o (.+)deleted_at is null for update`).
+			WithArgs(orderID).
+			WillReturnRows(sqlmock.NewRows([]string{"order_status"}).AddRow(orderStatus))
+	}
+	expectFind := func(orderStatus string, isComplete bool) {
+		mock.ExpectQuery(`select (.+) from order_info where id=(.+)`).
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows(orderColumns).
+				AddRow(1, 4, 2, 3, "2019-09-05T15:35:12", orderStatus, isComplete))
+	}
+	expectDelete := func() {
+		mock.ExpectExec(`update "order" set deleted_at=now()`).
+			WithArgs(1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		expectEvent(mock, "order", "1", "order.deleted")
+	}
+
 	type fields struct {
 		pool *sqlx.DB
 	}
@@ -264,38 +910,88 @@ func TestDatabase_DeleteOrderByID(t *testing.T) {
 		mockFn  func()
 	}
 	tests := []struct {
-		name    string
-		fields  fields
-		args    args
-		wantErr bool
+		name      string
+		fields    fields
+		args      args
+		want      *models.Order
+		wantCause error
+		wantErr   bool
 	}{
 		{
-			name:   "Success 1",
+			name:   "Success delivered order",
+			fields: fields{pool: pool},
+			args: args{
+				ctx:     context.Background(),
+				orderID: 1,
+				mockFn: func() {
+					mock.ExpectBegin()
+					expectLock(1, "delivered")
+					expectFind("delivered", true)
+					expectDelete()
+					mock.ExpectCommit()
+				},
+			},
+			want: &models.Order{ID: 1, UserID: 4, PetID: 2, Quantity: 3,
+				ShipDate: "2019-09-05T15:35:12", Status: "delivered", Complete: true},
+			wantErr: false,
+		},
+		{
+			name:   "Success placed order is cancelled",
 			fields: fields{pool: pool},
 			args: args{
 				ctx:     context.Background(),
 				orderID: 1,
 				mockFn: func() {
-					mock.ExpectExec(`delete from "order" where id=.`).
+					mock.ExpectBegin()
+					expectLock(1, "placed")
+					mock.ExpectQuery(`select (.+) from order_item_info where order_id = any`).
+						WithArgs(pq.Array([]int64{1})).
+						WillReturnRows(sqlmock.NewRows([]string{"id", "order_id", "pet_id", "pet_name", "quantity"}).
+							AddRow(7, 1, 2, "Rex", 3))
+					mock.ExpectExec(`update "order" set order_status_id`).
+						WithArgs(1, "cancelled", false).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectExec(`update pet set quantity=quantity\+`).
+						WithArgs(2, 3).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectQuery(`select ps.name from pet p (.+) for update`).
+						WithArgs(2).
+						WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("pending"))
+					mock.ExpectExec(`update pet set pet_status_id`).
+						WithArgs(2, "available").
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectExec(`insert into pet_status_history`).
+						WithArgs(2, "pending", "available", 5).
 						WillReturnResult(sqlmock.NewResult(1, 1))
-
+					expectEvent(mock, "pet", "2", "pet.status_changed")
+					expectFind("cancelled", false)
+					expectEvent(mock, "order", "1", "order.cancelled")
+					expectDelete()
+					mock.ExpectCommit()
 				},
 			},
+			want: &models.Order{ID: 1, UserID: 4, PetID: 2, Quantity: 3,
+				Items:    models.OrderItemList{{ID: 7, OrderID: 1, PetID: 2, PetName: "Rex", Quantity: 3}},
+				ShipDate: "2019-09-05T15:35:12", Status: "cancelled"},
 			wantErr: false,
 		},
 		{
-			name:   "Failure 1",
+			name:   "Failure not found",
 			fields: fields{pool: pool},
 			args: args{
 				ctx:     context.Background(),
 				orderID: 99,
 				mockFn: func() {
-					mock.ExpectExec(`delete from "order" where id=.`).
+					mock.ExpectBegin()
+					mock.ExpectQuery(`select (.+) from "order" o (.+) for update`).
 						WithArgs(99).
-						WillReturnError(errors.New("can't find order by id"))
+						WillReturnError(sql.ErrNoRows)
+					mock.ExpectRollback()
 				},
 			},
-			wantErr: true,
+			want:      nil,
+			wantCause: models.ErrNotFound,
+			wantErr:   true,
 		},
 	}
 	for _, tt := range tests {
@@ -306,8 +1002,18 @@ func TestDatabase_DeleteOrderByID(t *testing.T) {
 
 			tt.args.mockFn()
 
-			if err := d.DeleteOrderByID(tt.args.ctx, tt.args.orderID); (err != nil) != tt.wantErr {
+			got, err := d.DeleteOrderByID(tt.args.ctx, tt.args.orderID, 5)
+			if (err != nil) != tt.wantErr {
 				t.Errorf("DeleteOrderByID() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.wantCause != nil && pkgerrors.Cause(err) != tt.wantCause {
+				t.Errorf("DeleteOrderByID() error = %v, wantCause %v", err, tt.wantCause)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DeleteOrderByID() got = %v, want %v", got, tt.want)
 			}
 
 			if err := mock.ExpectationsWereMet(); err != nil {
@@ -348,6 +1054,10 @@ func TestDatabase_FindOrderByID(t *testing.T) {
 							"id", "user_id", "pet_id", "quantity",
 							"ship_date", "order_status", "complete"}).
 							AddRow(1, 1, 1, 12, "2019-09-05T15:35:12", "placed", false))
+					mock.ExpectQuery(`select (.+) from order_item_info where order_id = any`).
+						WithArgs(pq.Array([]int64{1})).
+						WillReturnRows(sqlmock.NewRows([]string{"id", "order_id", "pet_id", "pet_name", "quantity"}).
+							AddRow(7, 1, 1, "Rex", 12))
 				},
 			},
 			want: &models.Order{
@@ -355,6 +1065,7 @@ func TestDatabase_FindOrderByID(t *testing.T) {
 				PetID:    1,
 				UserID:   1,
 				Quantity: 12,
+				Items:    models.OrderItemList{{ID: 7, OrderID: 1, PetID: 1, PetName: "Rex", Quantity: 12}},
 				ShipDate: "2019-09-05T15:35:12",
 				Status:   "placed",
 				Complete: false,
@@ -425,7 +1136,7 @@ func TestDatabase_GetInventories(t *testing.T) {
 			args: args{
 				ctx: context.Background(),
 				mockFn: func() {
-					mock.ExpectQuery("select (.+) from order_info").
+					mock.ExpectQuery("select (.+) from order_item_info").
 						WillReturnRows(sqlmock.NewRows([]string{"pet_status", "sum"}).
 							AddRow("sold", 516).
 							AddRow("pending", 663).
@@ -465,6 +1176,85 @@ func TestDatabase_GetInventories(t *testing.T) {
 	}
 }
 
+func TestDatabase_GetInventory(t *testing.T) {
+	pool, mock := generateMockedDB()
+	defer checkError(pool.Close)
+
+	from := time.Date(2019, 9, 1, 0, 0, 0, 0, time.UTC)
+	columns := []string{"category", "status", "tag", "ordered", "on_hand"}
+
+	type fields struct {
+		pool *sqlx.DB
+	}
+	type args struct {
+		ctx    context.Context
+		filter models.InventoryFilter
+		mockFn func()
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    models.Inventory
+		wantErr bool
+	}{
+		{
+			name:   "Success by category and tag",
+			fields: fields{pool: pool},
+			args: args{
+				ctx:    context.Background(),
+				filter: models.InventoryFilter{GroupBy: []string{"tag", "category"}, ShipFrom: from},
+				mockFn: func() {
+					mock.ExpectQuery(`with ordered as (.+) group by 1, 2, 3`).
+						WithArgs("cancelled", from, nil, true, false, true).
+						WillReturnRows(sqlmock.NewRows(columns).
+							AddRow("Cat", "", "small", 13, 1).
+							AddRow("Dog", "", "", 3, 12))
+				},
+			},
+			want: models.Inventory{
+				{Category: "Cat", Tag: "small", Ordered: 13, OnHand: 1},
+				{Category: "Dog", Ordered: 3, OnHand: 12},
+			},
+			wantErr: false,
+		},
+		{
+			name:   "Failure unknown group",
+			fields: fields{pool: pool},
+			args: args{
+				ctx:    context.Background(),
+				filter: models.InventoryFilter{GroupBy: []string{"color"}},
+				mockFn: func() {},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Database{
+				pool: tt.fields.pool,
+			}
+
+			tt.args.mockFn()
+
+			got, err := d.GetInventory(tt.args.ctx, tt.args.filter)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetInventory() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetInventory() got = %v, want %v", got, tt.want)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
 func TestDatabase_getOrderStatusID(t *testing.T) {
 	pool, mock := generateMockedDB()
 	defer checkError(pool.Close)
@@ -0,0 +1,191 @@
+package psql
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/IamStubborN/petstore/db/models"
+	"github.com/IamStubborN/petstore/eventbus"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+const (
+	// outboxChannel is notified of the outbox event ids by the outbox_notify trigger.
+	outboxChannel = "outbox_event"
+
+	listenerMinReconnect = time.Second
+	listenerMaxReconnect = time.Minute
+	listenerPingInterval = time.Minute
+	listenerFetchTimeout = 10 * time.Second
+	listenerCatchUpPage  = 100
+)
+
+// eventStream listens to the outbox notifications and publishes the notified
+// events on the bus. The listener connects when the first stream is opened,
+// so the workers that don't stream don't hold the connection.
+type eventStream struct {
+	sync.Mutex
+	dsn      string
+	pool     *sqlx.DB
+	bus      *eventbus.Bus
+	listener *pq.Listener
+	done     chan struct{}
+	lastID   int64
+}
+
+func newEventStream(dsn string, pool *sqlx.DB, inventories eventbus.Inventories) *eventStream {
+	return &eventStream{
+		dsn:  dsn,
+		pool: pool,
+		bus:  eventbus.NewBus(inventories),
+	}
+}
+
+// StreamEvents streams the outbox events as they are committed,
+// the events after the id after are replayed first.
+func (d *Database) StreamEvents(ctx context.Context, after int64) (<-chan *models.Event, error) {
+	if err := d.events.start(ctx); err != nil {
+		return nil, err
+	}
+
+	return eventbus.Stream(ctx, d.events.bus, after, d.replayEvents), nil
+}
+
+// replayEvents returns up to limit outbox events after the id after.
+func (d *Database) replayEvents(ctx context.Context, after int64, limit int) (models.EventList, error) {
+	events := models.EventList{}
+	if err := d.pool.SelectContext(ctx, &events, qm[outboxGetAfterQ], after, limit); err != nil {
+		return nil, errors.Wrapf(err, "can't get outbox events after № %d", after)
+	}
+
+	return events, nil
+}
+
+func (s *eventStream) start(ctx context.Context) error {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.listener != nil {
+		return nil
+	}
+
+	listener := pq.NewListener(s.dsn, listenerMinReconnect, listenerMaxReconnect, logListenerEvent)
+	if err := listener.Listen(outboxChannel); err != nil {
+		checkError(listener.Close)
+		return errors.Wrap(err, "can't listen to outbox events")
+	}
+
+	if err := s.pool.GetContext(ctx, &s.lastID, qm[outboxLastIDQ]); err != nil {
+		checkError(listener.Close)
+		return errors.Wrap(err, "can't get last outbox event id")
+	}
+
+	s.listener = listener
+	s.done = make(chan struct{})
+	go s.run(listener, s.done)
+
+	return nil
+}
+
+func (s *eventStream) stop() error {
+	s.Lock()
+	defer s.Unlock()
+
+	s.bus.Close()
+
+	if s.listener == nil {
+		return nil
+	}
+
+	close(s.done)
+	err := s.listener.Close()
+	s.listener = nil
+	if err != nil {
+		return errors.Wrap(err, "can't close outbox listener")
+	}
+
+	return nil
+}
+
+func (s *eventStream) run(listener *pq.Listener, done chan struct{}) {
+	ping := time.NewTicker(listenerPingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case notification, ok := <-listener.Notify:
+			if !ok {
+				return
+			}
+			s.publish(notification)
+		case <-ping.C:
+			go checkError(listener.Ping)
+		}
+	}
+}
+
+func (s *eventStream) publish(notification *pq.Notification) {
+	ctx, cancel := context.WithTimeout(context.Background(), listenerFetchTimeout)
+	defer cancel()
+
+	events, err := s.fetch(ctx, notification)
+	if err != nil {
+		zap.L().Error("can't fetch notified outbox events", zap.Error(err))
+		return
+	}
+
+	s.bus.Publish(ctx, events...)
+}
+
+// fetch reads the notified event. The listener sends a nil notification
+// after it reconnects, the events committed since the last notified one
+// are read then.
+func (s *eventStream) fetch(ctx context.Context, notification *pq.Notification) (models.EventList, error) {
+	if notification != nil {
+		id, err := strconv.ParseInt(notification.Extra, 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "can't parse notified outbox event id [%s]", notification.Extra)
+		}
+
+		var event models.Event
+		if err = s.pool.GetContext(ctx, &event, qm[outboxGetByIDQ], id); err != nil {
+			return nil, errors.Wrapf(err, "can't get outbox event № %d", id)
+		}
+
+		if id > s.lastID {
+			s.lastID = id
+		}
+
+		return models.EventList{&event}, nil
+	}
+
+	events := models.EventList{}
+	for {
+		page := models.EventList{}
+		if err := s.pool.SelectContext(ctx, &page, qm[outboxGetAfterQ], s.lastID, listenerCatchUpPage); err != nil {
+			return nil, errors.Wrapf(err, "can't get outbox events after № %d", s.lastID)
+		}
+
+		events = append(events, page...)
+		if len(page) > 0 {
+			s.lastID = page[len(page)-1].ID
+		}
+
+		if len(page) < listenerCatchUpPage {
+			return events, nil
+		}
+	}
+}
+
+func logListenerEvent(event pq.ListenerEventType, err error) {
+	if err != nil {
+		zap.L().Error("outbox listener error", zap.Error(err))
+	}
+}
@@ -0,0 +1,177 @@
+package psql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/IamStubborN/petstore/db/models"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+func TestDatabase_replayEvents(t *testing.T) {
+	pool, mock := generateMockedDB()
+	defer checkError(pool.Close)
+
+	now := time.Date(2020, 3, 1, 12, 0, 0, 0, time.UTC)
+	columns := []string{"id", "aggregate", "aggregate_key", "event", "payload", "created_at"}
+
+	type fields struct {
+		pool *sqlx.DB
+	}
+	type args struct {
+		ctx    context.Context
+		after  int64
+		mockFn func()
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    models.EventList
+		wantErr bool
+	}{
+		{
+			name:   "Success 1",
+			fields: fields{pool: pool},
+			args: args{
+				ctx:   context.Background(),
+				after: 40,
+				mockFn: func() {
+					mock.ExpectQuery(`select (.+) from outbox where id > (.+) order by id limit`).
+						WithArgs(40, 10).
+						WillReturnRows(sqlmock.NewRows(columns).
+							AddRow(41, "order", "7", "order.created", []byte(`{"id":7}`), now))
+				},
+			},
+			want: models.EventList{{ID: 41, Aggregate: "order", Key: "7", Type: "order.created",
+				Payload: json.RawMessage(`{"id":7}`), CreatedAt: now}},
+			wantErr: false,
+		},
+		{
+			name:   "Failure 1",
+			fields: fields{pool: pool},
+			args: args{
+				ctx:   context.Background(),
+				after: 40,
+				mockFn: func() {
+					mock.ExpectQuery(`select (.+) from outbox where id > (.+) order by id limit`).
+						WillReturnError(errors.New("connection lost"))
+				},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Database{
+				pool: tt.fields.pool,
+			}
+
+			tt.args.mockFn()
+
+			got, err := d.replayEvents(tt.args.ctx, tt.args.after, 10)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("replayEvents() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("replayEvents() got = %v, want %v", got, tt.want)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func TestEventStream_fetch(t *testing.T) {
+	pool, mock := generateMockedDB()
+	defer checkError(pool.Close)
+
+	now := time.Date(2020, 3, 1, 12, 0, 0, 0, time.UTC)
+	columns := []string{"id", "aggregate", "aggregate_key", "event", "payload", "created_at"}
+
+	tests := []struct {
+		name         string
+		notification *pq.Notification
+		mockFn       func()
+		wantIDs      []int64
+		wantLastID   int64
+		wantErr      bool
+	}{
+		{
+			name:         "Success notified event",
+			notification: &pq.Notification{Channel: outboxChannel, Extra: "42"},
+			mockFn: func() {
+				mock.ExpectQuery(`select (.+) from outbox where id=`).
+					WithArgs(42).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow(42, "pet", "1", "pet.status_changed", []byte(`{"pet_id":1}`), now))
+			},
+			wantIDs:    []int64{42},
+			wantLastID: 42,
+			wantErr:    false,
+		},
+		{
+			name:         "Success catch up after reconnect",
+			notification: nil,
+			mockFn: func() {
+				mock.ExpectQuery(`select (.+) from outbox where id > (.+) order by id limit`).
+					WithArgs(42, listenerCatchUpPage).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow(43, "order", "7", "order.created", []byte(`{"id":7}`), now).
+						AddRow(44, "order", "7", "order.cancelled", []byte(`{"id":7}`), now))
+			},
+			wantIDs:    []int64{43, 44},
+			wantLastID: 44,
+			wantErr:    false,
+		},
+		{
+			name:         "Failure bad payload",
+			notification: &pq.Notification{Channel: outboxChannel, Extra: "pet"},
+			mockFn:       func() {},
+			wantLastID:   44,
+			wantErr:      true,
+		},
+	}
+
+	s := newEventStream("", pool, nil)
+	s.lastID = 40
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockFn()
+
+			got, err := s.fetch(context.Background(), tt.notification)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("fetch() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			var ids []int64
+			for _, event := range got {
+				ids = append(ids, event.ID)
+			}
+
+			if !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Errorf("fetch() got ids = %v, want %v", ids, tt.wantIDs)
+			}
+
+			if s.lastID != tt.wantLastID {
+				t.Errorf("fetch() lastID = %v, want %v", s.lastID, tt.wantLastID)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
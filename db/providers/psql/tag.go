@@ -0,0 +1,160 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/IamStubborN/petstore/db/models"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+)
+
+func (d *Database) CreateTag(ctx context.Context, tag *models.Tag) (*models.Tag, error) {
+	err := d.pool.GetContext(ctx, &tag.ID, qm[tagCreateQ], tag.Name)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, errors.Wrapf(models.ErrConflict, "tag %s already exists", tag.Name)
+		}
+		return nil, errors.Wrap(err, "can't insert into tag")
+	}
+
+	return tag, nil
+}
+
+func (d *Database) GetTags(ctx context.Context) (models.TagList, error) {
+	tags := models.TagList{}
+	if err := d.pool.SelectContext(ctx, &tags, qm[tagGetAllQ]); err != nil {
+		return nil, errors.Wrap(err, "can't get data from tag")
+	}
+
+	return tags, nil
+}
+
+func (d *Database) RenameTag(ctx context.Context, id int64, name string) error {
+	res, err := d.pool.ExecContext(ctx, qm[tagRenameQ], id, name)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return errors.Wrapf(models.ErrConflict, "tag %s already exists", name)
+		}
+		return errors.Wrap(err, "can't rename tag")
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return errors.Wrapf(models.ErrNotFound, "tag № %d doesn't exist", id)
+	}
+
+	return nil
+}
+
+// DeleteTag refuses to delete a tag while pets are tagged with it,
+// the tag row is locked so no pet can pick it up in the meantime.
+func (d *Database) DeleteTag(ctx context.Context, id int64) error {
+	tx, err := d.pool.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return errors.Wrap(err, "can't start transaction")
+	}
+
+	defer func() {
+		if err != nil {
+			checkError(tx.Rollback)
+			return
+		}
+		checkError(tx.Commit)
+	}()
+
+	var tagID int64
+	err = tx.GetContext(ctx, &tagID, qm[tagLockByIDQ], id)
+	if err == sql.ErrNoRows {
+		return errors.Wrapf(models.ErrNotFound, "tag № %d doesn't exist", id)
+	}
+	if err != nil {
+		return errors.Wrap(err, "can't lock tag")
+	}
+
+	var isUsed bool
+	if err = tx.GetContext(ctx, &isUsed, qm[tagIsUsedQ], id); err != nil {
+		return errors.Wrap(err, "can't check tag usage")
+	}
+
+	if isUsed {
+		err = errors.Wrapf(models.ErrConflict, "tag № %d is used by pets", id)
+		return err
+	}
+
+	if _, err = tx.ExecContext(ctx, qm[tagDeleteQ], id); err != nil {
+		return errors.Wrap(err, "can't delete from tag")
+	}
+
+	return nil
+}
+
+// resolveTags returns the pet tags as they are stored in the tag table.
+// Tags given by id must exist, tags given only by name are looked up
+// by name and, with allowCreateTags on, created when they are missing.
+func (d *Database) resolveTags(ctx context.Context, tx *sqlx.Tx, tags []models.Tag) ([]models.Tag, error) {
+	if len(tags) == 0 {
+		return tags, nil
+	}
+
+	var tagIDs []int64
+	var tagNames []string
+	for _, tag := range tags {
+		if tag.ID != 0 {
+			tagIDs = append(tagIDs, tag.ID)
+			continue
+		}
+		tagNames = append(tagNames, tag.Name)
+	}
+
+	var found []models.Tag
+	err := tx.SelectContext(ctx, &found, qm[tagsGetByIDsOrNamesQ], pq.Array(tagIDs), pq.Array(tagNames))
+	if err != nil {
+		return nil, errors.Wrap(err, "can't get data from tag")
+	}
+
+	byID := make(map[int64]models.Tag, len(found))
+	byName := make(map[string]models.Tag, len(found))
+	for _, tag := range found {
+		byID[tag.ID] = tag
+		byName[tag.Name] = tag
+	}
+
+	resolved := make([]models.Tag, 0, len(tags))
+	isAdded := make(map[int64]bool, len(tags))
+	for _, tag := range tags {
+		var stored models.Tag
+		var ok bool
+
+		switch {
+		case tag.ID != 0:
+			if stored, ok = byID[tag.ID]; !ok {
+				return nil, errors.Wrapf(models.ErrNotFound, "tag № %d doesn't exist", tag.ID)
+			}
+		case d.allowCreateTags:
+			if stored, ok = byName[tag.Name]; !ok {
+				stored = models.Tag{Name: tag.Name}
+				if err = tx.GetContext(ctx, &stored.ID, qm[tagsUpsertByNameQ], tag.Name); err != nil {
+					return nil, errors.Wrap(err, "can't create tag")
+				}
+				byName[tag.Name] = stored
+			}
+		default:
+			if stored, ok = byName[tag.Name]; !ok {
+				return nil, errors.Wrapf(models.ErrNotFound, "tag %s doesn't exist", tag.Name)
+			}
+		}
+
+		if !isAdded[stored.ID] {
+			isAdded[stored.ID] = true
+			resolved = append(resolved, stored)
+		}
+	}
+
+	return resolved, nil
+}
@@ -0,0 +1,478 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/IamStubborN/petstore/db/models"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	pkgerrors "github.com/pkg/errors"
+)
+
+func TestDatabase_CreateTag(t *testing.T) {
+	pool, mock := generateMockedDB()
+	defer checkError(pool.Close)
+
+	type fields struct {
+		pool *sqlx.DB
+	}
+	type args struct {
+		ctx    context.Context
+		tag    *models.Tag
+		mockFn func()
+	}
+	tests := []struct {
+		name      string
+		fields    fields
+		args      args
+		want      *models.Tag
+		wantCause error
+		wantErr   bool
+	}{
+		{
+			name:   "Success 1",
+			fields: fields{pool: pool},
+			args: args{
+				ctx: context.Background(),
+				tag: &models.Tag{Name: "fluffy"},
+				mockFn: func() {
+					mock.ExpectQuery(`insert into tag (.+) returning id`).
+						WithArgs("fluffy").
+						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(6))
+				},
+			},
+			want:    &models.Tag{ID: 6, Name: "fluffy"},
+			wantErr: false,
+		},
+		{
+			name:   "Failure duplicate",
+			fields: fields{pool: pool},
+			args: args{
+				ctx: context.Background(),
+				tag: &models.Tag{Name: "small"},
+				mockFn: func() {
+					mock.ExpectQuery(`insert into tag (.+) returning id`).
+						WithArgs("small").
+						WillReturnError(&pq.Error{Code: "23505"})
+				},
+			},
+			want:      nil,
+			wantCause: models.ErrConflict,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Database{
+				pool: tt.fields.pool,
+			}
+
+			tt.args.mockFn()
+
+			got, err := d.CreateTag(tt.args.ctx, tt.args.tag)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CreateTag() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.wantCause != nil && pkgerrors.Cause(err) != tt.wantCause {
+				t.Errorf("CreateTag() error = %v, wantCause %v", err, tt.wantCause)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CreateTag() got = %v, want %v", got, tt.want)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func TestDatabase_GetTags(t *testing.T) {
+	pool, mock := generateMockedDB()
+	defer checkError(pool.Close)
+
+	type fields struct {
+		pool *sqlx.DB
+	}
+	type args struct {
+		ctx    context.Context
+		mockFn func()
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    models.TagList
+		wantErr bool
+	}{
+		{
+			name:   "Success 1",
+			fields: fields{pool: pool},
+			args: args{
+				ctx: context.Background(),
+				mockFn: func() {
+					mock.ExpectQuery(`select id, name from tag`).
+						WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).
+							AddRow(1, "small").AddRow(2, "average"))
+				},
+			},
+			want:    models.TagList{{ID: 1, Name: "small"}, {ID: 2, Name: "average"}},
+			wantErr: false,
+		},
+		{
+			name:   "Failure 1",
+			fields: fields{pool: pool},
+			args: args{
+				ctx: context.Background(),
+				mockFn: func() {
+					mock.ExpectQuery(`select id, name from tag`).
+						WillReturnError(errors.New("can't get data from tag"))
+				},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Database{
+				pool: tt.fields.pool,
+			}
+
+			tt.args.mockFn()
+
+			got, err := d.GetTags(tt.args.ctx)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetTags() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetTags() got = %v, want %v", got, tt.want)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func TestDatabase_RenameTag(t *testing.T) {
+	pool, mock := generateMockedDB()
+	defer checkError(pool.Close)
+
+	type fields struct {
+		pool *sqlx.DB
+	}
+	type args struct {
+		ctx    context.Context
+		id     int64
+		tag    string
+		mockFn func()
+	}
+	tests := []struct {
+		name      string
+		fields    fields
+		args      args
+		wantCause error
+		wantErr   bool
+	}{
+		{
+			name:   "Success 1",
+			fields: fields{pool: pool},
+			args: args{
+				ctx: context.Background(),
+				id:  5,
+				tag: "cute",
+				mockFn: func() {
+					mock.ExpectExec(`update tag set name=`).
+						WithArgs(5, "cute").
+						WillReturnResult(sqlmock.NewResult(0, 1))
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:   "Failure not found",
+			fields: fields{pool: pool},
+			args: args{
+				ctx: context.Background(),
+				id:  99,
+				tag: "cute",
+				mockFn: func() {
+					mock.ExpectExec(`update tag set name=`).
+						WithArgs(99, "cute").
+						WillReturnResult(sqlmock.NewResult(0, 0))
+				},
+			},
+			wantCause: models.ErrNotFound,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Database{
+				pool: tt.fields.pool,
+			}
+
+			tt.args.mockFn()
+
+			err := d.RenameTag(tt.args.ctx, tt.args.id, tt.args.tag)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("RenameTag() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantCause != nil && pkgerrors.Cause(err) != tt.wantCause {
+				t.Errorf("RenameTag() error = %v, wantCause %v", err, tt.wantCause)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func TestDatabase_DeleteTag(t *testing.T) {
+	pool, mock := generateMockedDB()
+	defer checkError(pool.Close)
+
+	type fields struct {
+		pool *sqlx.DB
+	}
+	type args struct {
+		ctx    context.Context
+		id     int64
+		mockFn func()
+	}
+	tests := []struct {
+		name      string
+		fields    fields
+		args      args
+		wantCause error
+		wantErr   bool
+	}{
+		{
+			name:   "Success 1",
+			fields: fields{pool: pool},
+			args: args{
+				ctx: context.Background(),
+				id:  6,
+				mockFn: func() {
+					mock.ExpectBegin()
+					mock.ExpectQuery(`select id from tag where id=(.+) for update`).
+						WithArgs(6).
+						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(6))
+					mock.ExpectQuery(`select exists`).
+						WithArgs(6).
+						WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+					mock.ExpectExec(`delete from tag`).
+						WithArgs(6).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectCommit()
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:   "Failure used by pets",
+			fields: fields{pool: pool},
+			args: args{
+				ctx: context.Background(),
+				id:  1,
+				mockFn: func() {
+					mock.ExpectBegin()
+					mock.ExpectQuery(`select id from tag where id=(.+) for update`).
+						WithArgs(1).
+						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+					mock.ExpectQuery(`select exists`).
+						WithArgs(1).
+						WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+					mock.ExpectRollback()
+				},
+			},
+			wantCause: models.ErrConflict,
+			wantErr:   true,
+		},
+		{
+			name:   "Failure not found",
+			fields: fields{pool: pool},
+			args: args{
+				ctx: context.Background(),
+				id:  99,
+				mockFn: func() {
+					mock.ExpectBegin()
+					mock.ExpectQuery(`select id from tag where id=(.+) for update`).
+						WithArgs(99).
+						WillReturnError(sql.ErrNoRows)
+					mock.ExpectRollback()
+				},
+			},
+			wantCause: models.ErrNotFound,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Database{
+				pool: tt.fields.pool,
+			}
+
+			tt.args.mockFn()
+
+			err := d.DeleteTag(tt.args.ctx, tt.args.id)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DeleteTag() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantCause != nil && pkgerrors.Cause(err) != tt.wantCause {
+				t.Errorf("DeleteTag() error = %v, wantCause %v", err, tt.wantCause)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func TestDatabase_resolveTags(t *testing.T) {
+	pool, mock := generateMockedDB()
+	defer checkError(pool.Close)
+
+	type fields struct {
+		pool            *sqlx.DB
+		allowCreateTags bool
+	}
+	type args struct {
+		ctx    context.Context
+		tags   []models.Tag
+		mockFn func()
+	}
+	tests := []struct {
+		name      string
+		fields    fields
+		args      args
+		want      []models.Tag
+		wantCause error
+		wantErr   bool
+	}{
+		{
+			name:   "Success by id and name",
+			fields: fields{pool: pool},
+			args: args{
+				ctx:  context.Background(),
+				tags: []models.Tag{{ID: 1}, {Name: "best"}, {ID: 4, Name: "best"}},
+				mockFn: func() {
+					mock.ExpectBegin()
+					mock.ExpectQuery(`select id, name from tag`).
+						WithArgs(pq.Array([]int64{1, 4}), pq.Array([]string{"best"})).
+						WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).
+							AddRow(1, "small").AddRow(4, "best"))
+					mock.ExpectCommit()
+				},
+			},
+			want:    []models.Tag{{ID: 1, Name: "small"}, {ID: 4, Name: "best"}},
+			wantErr: false,
+		},
+		{
+			name:   "Success create by name",
+			fields: fields{pool: pool, allowCreateTags: true},
+			args: args{
+				ctx:  context.Background(),
+				tags: []models.Tag{{Name: "fluffy"}},
+				mockFn: func() {
+					mock.ExpectBegin()
+					mock.ExpectQuery(`select id, name from tag`).
+						WithArgs(pq.Array([]int64(nil)), pq.Array([]string{"fluffy"})).
+						WillReturnRows(sqlmock.NewRows([]string{"id", "name"}))
+					mock.ExpectQuery(`insert into tag (.+) on conflict`).
+						WithArgs("fluffy").
+						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(6))
+					mock.ExpectCommit()
+				},
+			},
+			want:    []models.Tag{{ID: 6, Name: "fluffy"}},
+			wantErr: false,
+		},
+		{
+			name:   "Failure unknown name",
+			fields: fields{pool: pool},
+			args: args{
+				ctx:  context.Background(),
+				tags: []models.Tag{{Name: "fluffy"}},
+				mockFn: func() {
+					mock.ExpectBegin()
+					mock.ExpectQuery(`select id, name from tag`).
+						WithArgs(pq.Array([]int64(nil)), pq.Array([]string{"fluffy"})).
+						WillReturnRows(sqlmock.NewRows([]string{"id", "name"}))
+					mock.ExpectCommit()
+				},
+			},
+			want:      nil,
+			wantCause: models.ErrNotFound,
+			wantErr:   true,
+		},
+		{
+			name:   "Failure unknown id",
+			fields: fields{pool: pool, allowCreateTags: true},
+			args: args{
+				ctx:  context.Background(),
+				tags: []models.Tag{{ID: 99}},
+				mockFn: func() {
+					mock.ExpectBegin()
+					mock.ExpectQuery(`select id, name from tag`).
+						WithArgs(pq.Array([]int64{99}), pq.Array([]string(nil))).
+						WillReturnRows(sqlmock.NewRows([]string{"id", "name"}))
+					mock.ExpectCommit()
+				},
+			},
+			want:      nil,
+			wantCause: models.ErrNotFound,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Database{
+				pool:            tt.fields.pool,
+				allowCreateTags: tt.fields.allowCreateTags,
+			}
+
+			tt.args.mockFn()
+
+			tx, err := d.pool.Beginx()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := d.resolveTags(tt.args.ctx, tx, tt.args.tags)
+			checkError(tx.Commit)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("resolveTags() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.wantCause != nil && pkgerrors.Cause(err) != tt.wantCause {
+				t.Errorf("resolveTags() error = %v, wantCause %v", err, tt.wantCause)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("resolveTags() got = %v, want %v", got, tt.want)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
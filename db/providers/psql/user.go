@@ -19,12 +19,27 @@ func (d *Database) CreateUser(ctx context.Context, user *models.User) error {
 
 	user.Password = encryptedPass
 
-	_, err = d.pool.NamedExecContext(ctx, qm[userCreateQ], &user)
+	tx, err := d.pool.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return errors.Wrap(err, "can't start transaction")
+	}
+
+	defer func() {
+		if err != nil {
+			checkError(tx.Rollback)
+			return
+		}
+		checkError(tx.Commit)
+	}()
+
+	_, err = tx.NamedExecContext(ctx, qm[userCreateQ], &user)
 	if err != nil {
 		return errors.Wrap(err, "can't insert into user")
 	}
 
-	return nil
+	err = addEvent(ctx, tx, models.AggregateUser, user.Username, models.EventUserCreated, models.NewUserPayload(user))
+
+	return err
 }
 
 func (d *Database) CreateUsersFromList(ctx context.Context, list *models.UserList) error {
@@ -36,19 +51,21 @@ func (d *Database) CreateUsersFromList(ctx context.Context, list *models.UserLis
 	defer func() {
 		if err != nil {
 			checkError(tx.Rollback)
+			return
 		}
 		checkError(tx.Commit)
 	}()
 
 	var stmt *sqlx.NamedStmt
-	stmt, err = d.pool.PrepareNamedContext(ctx, qm[userCreateQ])
+	stmt, err = tx.PrepareNamedContext(ctx, qm[userCreateQ])
 	if err != nil {
 		return errors.Wrap(err, "can't prepare statement")
 	}
 	defer checkError(stmt.Close)
 
 	for _, user := range *list {
-		encryptedPass, err := encryptPassword(user.Password)
+		var encryptedPass string
+		encryptedPass, err = encryptPassword(user.Password)
 		if err != nil {
 			return errors.Wrap(err, "can't generate encrypted password")
 		}
@@ -59,6 +76,11 @@ func (d *Database) CreateUsersFromList(ctx context.Context, list *models.UserLis
 		if err != nil {
 			return errors.Wrap(err, "can't insert into user")
 		}
+
+		err = addEvent(ctx, tx, models.AggregateUser, user.Username, models.EventUserCreated, models.NewUserPayload(user))
+		if err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -99,7 +121,20 @@ func (d *Database) UpdateUser(ctx context.Context, user *models.User) error {
 
 	user.Password = encryptedPass
 
-	res, err := d.pool.NamedExecContext(ctx, qm[userUpdateQ], user)
+	tx, err := d.pool.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return errors.Wrap(err, "can't start transaction")
+	}
+
+	defer func() {
+		if err != nil {
+			checkError(tx.Rollback)
+			return
+		}
+		checkError(tx.Commit)
+	}()
+
+	res, err := tx.NamedExecContext(ctx, qm[userUpdateQ], user)
 	if err != nil {
 		return errors.Wrap(err, "can't exec update user")
 	}
@@ -110,14 +145,30 @@ func (d *Database) UpdateUser(ctx context.Context, user *models.User) error {
 	}
 
 	if count == 0 {
-		return errors.Errorf("user %s doesn't exist", user.Username)
+		err = errors.Errorf("user %s doesn't exist", user.Username)
+		return err
 	}
 
-	return nil
+	err = addEvent(ctx, tx, models.AggregateUser, user.Username, models.EventUserUpdated, models.NewUserPayload(user))
+
+	return err
 }
 
 func (d *Database) DeleteUser(ctx context.Context, username string) error {
-	res, err := d.pool.ExecContext(ctx, qm[userDeleteQ], username)
+	tx, err := d.pool.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return errors.Wrap(err, "can't start transaction")
+	}
+
+	defer func() {
+		if err != nil {
+			checkError(tx.Rollback)
+			return
+		}
+		checkError(tx.Commit)
+	}()
+
+	res, err := tx.ExecContext(ctx, qm[userDeleteQ], username)
 	if err != nil {
 		return errors.Wrap(err, "can't delete user")
 	}
@@ -128,10 +179,52 @@ func (d *Database) DeleteUser(ctx context.Context, username string) error {
 	}
 
 	if count == 0 {
-		return errors.Errorf("user %s doesn't exist", username)
+		err = errors.Errorf("user %s doesn't exist", username)
+		return err
 	}
 
-	return nil
+	payload := &models.UserPayload{Username: username}
+	err = addEvent(ctx, tx, models.AggregateUser, username, models.EventUserDeleted, payload)
+
+	return err
+}
+
+// SetUserStatus grants the status to the user, a status that
+// doesn't exist is models.ErrNotFound as well as the user.
+func (d *Database) SetUserStatus(ctx context.Context, username string, status int64) (*models.User, error) {
+	tx, err := d.pool.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "can't start transaction")
+	}
+
+	defer func() {
+		if err != nil {
+			checkError(tx.Rollback)
+			return
+		}
+		checkError(tx.Commit)
+	}()
+
+	var user models.User
+	err = tx.QueryRowxContext(ctx, qm[userSetStatusQ], username, status).StructScan(&user)
+	if err == sql.ErrNoRows {
+		err = errors.Wrapf(models.ErrNotFound, "user %s doesn't exist", username)
+		return nil, err
+	}
+	if isForeignKeyViolation(err) {
+		err = errors.Wrapf(models.ErrNotFound, "user status № %d doesn't exist", status)
+		return nil, err
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "can't set user status")
+	}
+
+	err = addEvent(ctx, tx, models.AggregateUser, user.Username, models.EventUserUpdated, models.NewUserPayload(&user))
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
 }
 
 func encryptPassword(password string) (string, error) {
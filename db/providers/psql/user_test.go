@@ -9,6 +9,8 @@ import (
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/IamStubborN/petstore/db/models"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	pkgerrors "github.com/pkg/errors"
 )
 
 func TestDatabase_CreateUser(t *testing.T) {
@@ -45,8 +47,11 @@ func TestDatabase_CreateUser(t *testing.T) {
 					UserStatus: 1,
 				},
 				mockFn: func() {
+					mock.ExpectBegin()
 					mock.ExpectExec(`insert into "user" (.+) values (.+)`).
 						WillReturnResult(sqlmock.NewResult(1, 1))
+					expectEvent(mock, "user", "username", "user.created")
+					mock.ExpectCommit()
 				},
 			},
 			wantErr: false,
@@ -67,8 +72,10 @@ func TestDatabase_CreateUser(t *testing.T) {
 					UserStatus: 1,
 				},
 				mockFn: func() {
+					mock.ExpectBegin()
 					mock.ExpectExec(`insert into "user" (.+) values (.+)`).
 						WillReturnError(errors.New("can't insert into user: pg duplicate user_name"))
+					mock.ExpectRollback()
 				},
 			},
 			wantErr: true,
@@ -144,8 +151,10 @@ func TestDatabase_CreateUsersFromList(t *testing.T) {
 					mock.ExpectPrepare(`insert into "user" (.+) values (.+)`)
 					mock.ExpectExec(`insert into "user" (.+) values (.+)`).
 						WillReturnResult(sqlmock.NewResult(1, 1))
+					expectEvent(mock, "user", "username", "user.created")
 					mock.ExpectExec(`insert into "user" (.+) values (.+)`).
 						WillReturnResult(sqlmock.NewResult(2, 2))
+					expectEvent(mock, "user", "username2", "user.created")
 					mock.ExpectCommit()
 				},
 			},
@@ -212,9 +221,12 @@ func TestDatabase_DeleteUser(t *testing.T) {
 				ctx:      context.Background(),
 				username: "DelUser",
 				mockFn: func() {
+					mock.ExpectBegin()
 					mock.ExpectExec(`delete from "user" where user_name=.`).
 						WithArgs("DelUser").
 						WillReturnResult(sqlmock.NewResult(0, 1))
+					expectEvent(mock, "user", "DelUser", "user.deleted")
+					mock.ExpectCommit()
 				},
 			},
 			wantErr: false,
@@ -226,9 +238,11 @@ func TestDatabase_DeleteUser(t *testing.T) {
 				ctx:      context.Background(),
 				username: "DelUser",
 				mockFn: func() {
+					mock.ExpectBegin()
 					mock.ExpectExec(`delete from "user" where user_name=.`).
 						WithArgs("DelUser").
 						WillReturnResult(sqlmock.NewResult(0, 0))
+					mock.ExpectRollback()
 				},
 			},
 			wantErr: true,
@@ -453,8 +467,11 @@ func TestDatabase_UpdateUser(t *testing.T) {
 					UserStatus: 1,
 				},
 				mockFn: func() {
+					mock.ExpectBegin()
 					mock.ExpectExec(`update "user" set (.+)`).
 						WillReturnResult(sqlmock.NewResult(1, 1))
+					expectEvent(mock, "user", "peterpandam", "user.updated")
+					mock.ExpectCommit()
 				},
 			},
 			wantErr: false,
@@ -475,8 +492,10 @@ func TestDatabase_UpdateUser(t *testing.T) {
 					UserStatus: 1,
 				},
 				mockFn: func() {
+					mock.ExpectBegin()
 					mock.ExpectExec(`update "user" set (.+)`).
 						WillReturnResult(sqlmock.NewResult(0, 0))
+					mock.ExpectRollback()
 				},
 			},
 			wantErr: true,
@@ -501,6 +520,118 @@ func TestDatabase_UpdateUser(t *testing.T) {
 	}
 }
 
+func TestDatabase_SetUserStatus(t *testing.T) {
+	pool, mock := generateMockedDB()
+	defer checkError(pool.Close)
+
+	columns := []string{"id", "user_name", "first_name", "last_name", "email", "phone", "user_status_id"}
+
+	type fields struct {
+		pool *sqlx.DB
+	}
+	type args struct {
+		ctx      context.Context
+		username string
+		status   int64
+		mockFn   func()
+	}
+	tests := []struct {
+		name      string
+		fields    fields
+		args      args
+		want      *models.User
+		wantCause error
+		wantErr   bool
+	}{
+		{
+			name:   "Success 1",
+			fields: fields{pool: pool},
+			args: args{
+				ctx:      context.Background(),
+				username: "peterpandam",
+				status:   1,
+				mockFn: func() {
+					mock.ExpectBegin()
+					mock.ExpectQuery(`update "user" set user_status_id`).
+						WithArgs("peterpandam", 1).
+						WillReturnRows(sqlmock.NewRows(columns).
+							AddRow(3, "peterpandam", "Peter", "Loot", "ppd@gmail.com", "3800000000", 1))
+					expectEvent(mock, "user", "peterpandam", "user.updated")
+					mock.ExpectCommit()
+				},
+			},
+			want: &models.User{ID: 3, Username: "peterpandam", FirstName: "Peter", LastName: "Loot",
+				Email: "ppd@gmail.com", Phone: "3800000000", UserStatus: 1},
+			wantErr: false,
+		},
+		{
+			name:   "Failure user not found",
+			fields: fields{pool: pool},
+			args: args{
+				ctx:      context.Background(),
+				username: "nobody",
+				status:   1,
+				mockFn: func() {
+					mock.ExpectBegin()
+					mock.ExpectQuery(`update "user" set user_status_id`).
+						WithArgs("nobody", 1).
+						WillReturnRows(sqlmock.NewRows(columns))
+					mock.ExpectRollback()
+				},
+			},
+			want:      nil,
+			wantCause: models.ErrNotFound,
+			wantErr:   true,
+		},
+		{
+			name:   "Failure status not found",
+			fields: fields{pool: pool},
+			args: args{
+				ctx:      context.Background(),
+				username: "peterpandam",
+				status:   42,
+				mockFn: func() {
+					mock.ExpectBegin()
+					mock.ExpectQuery(`update "user" set user_status_id`).
+						WithArgs("peterpandam", 42).
+						WillReturnError(&pq.Error{Code: "23503"})
+					mock.ExpectRollback()
+				},
+			},
+			want:      nil,
+			wantCause: models.ErrNotFound,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Database{
+				pool: tt.fields.pool,
+			}
+
+			tt.args.mockFn()
+
+			got, err := d.SetUserStatus(tt.args.ctx, tt.args.username, tt.args.status)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SetUserStatus() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.wantCause != nil && pkgerrors.Cause(err) != tt.wantCause {
+				t.Errorf("SetUserStatus() error = %v, wantCause %v", err, tt.wantCause)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SetUserStatus() got = %v, want %v", got, tt.want)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
 func Test_comparePassword(t *testing.T) {
 	type args struct {
 		encryptedPass string
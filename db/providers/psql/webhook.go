@@ -0,0 +1,262 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/IamStubborN/petstore/db/models"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// CreateWebhook subscribes the webhook to the events written
+// to the outbox from now on, the secret isn't returned.
+func (d *Database) CreateWebhook(ctx context.Context, webhook *models.Webhook) (*models.Webhook, error) {
+	err := d.pool.QueryRowxContext(ctx, qm[webhookCreateQ],
+		webhook.URL, pq.Array(webhook.Events), webhook.Secret).
+		Scan(&webhook.ID, &webhook.Active, &webhook.Failures, &webhook.CreatedAt)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't insert into webhook")
+	}
+
+	webhook.Secret = ""
+
+	return webhook, nil
+}
+
+func (d *Database) GetWebhooks(ctx context.Context) (models.WebhookList, error) {
+	rows, err := d.pool.QueryxContext(ctx, qm[webhookGetAllQ])
+	if err != nil {
+		return nil, errors.Wrap(err, "can't get data from webhook")
+	}
+	defer checkError(rows.Close)
+
+	webhooks := models.WebhookList{}
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		webhooks = append(webhooks, webhook)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "can't get data from webhook")
+	}
+
+	return webhooks, nil
+}
+
+// DeleteWebhook removes the webhook with its delivery history.
+func (d *Database) DeleteWebhook(ctx context.Context, id int64) error {
+	res, err := d.pool.ExecContext(ctx, qm[webhookDeleteQ], id)
+	if err != nil {
+		return errors.Wrap(err, "can't delete from webhook")
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return errors.Wrapf(models.ErrNotFound, "webhook № %d doesn't exist", id)
+	}
+
+	return nil
+}
+
+// EnableWebhook activates the disabled webhook and forgets its failures,
+// the deliveries left pending when it was disabled are made again.
+func (d *Database) EnableWebhook(ctx context.Context, id int64) (*models.Webhook, error) {
+	webhook, err := scanWebhook(d.pool.QueryRowxContext(ctx, qm[webhookEnableQ], id))
+	if errors.Cause(err) == sql.ErrNoRows {
+		return nil, errors.Wrapf(models.ErrNotFound, "webhook № %d doesn't exist", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return webhook, nil
+}
+
+// GetWebhookDeliveries returns the latest deliveries of the webhook first.
+func (d *Database) GetWebhookDeliveries(ctx context.Context, webhookID int64, limit int) (models.WebhookDeliveryList, error) {
+	var isExist bool
+	if err := d.pool.GetContext(ctx, &isExist, qm[webhookIsExistQ], webhookID); err != nil {
+		return nil, errors.Wrap(err, "can't check webhook")
+	}
+
+	if !isExist {
+		return nil, errors.Wrapf(models.ErrNotFound, "webhook № %d doesn't exist", webhookID)
+	}
+
+	deliveries := models.WebhookDeliveryList{}
+	if err := d.pool.SelectContext(ctx, &deliveries, qm[webhookDeliveriesQ], webhookID, limit); err != nil {
+		return nil, errors.Wrap(err, "can't get data from webhook_delivery")
+	}
+
+	return deliveries, nil
+}
+
+// DeliverWebhooks claims the due deliveries of active webhooks, hands them
+// to deliver one by one and saves the outcome like PublishEvents does.
+// A failed attempt counts against the webhook and the webhook is disabled
+// when it fails too often, its other deliveries in the batch are skipped
+// and released with the ones left when ctx is done.
+// It returns the number of deliveries handed to deliver.
+func (d *Database) DeliverWebhooks(ctx context.Context, policy models.WebhookPolicy,
+	deliver func(context.Context, *models.WebhookDelivery) error) (int, error) {
+	deliveries, err := d.claimDeliveries(ctx, policy.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	disabled := make(map[int64]bool)
+	unhandled := models.WebhookDeliveryList{}
+	var count int
+	for i, delivery := range deliveries {
+		if ctx.Err() != nil || err != nil {
+			unhandled = append(unhandled, deliveries[i:]...)
+			break
+		}
+
+		if disabled[delivery.WebhookID] {
+			unhandled = append(unhandled, delivery)
+			continue
+		}
+		count++
+
+		err = d.saveDelivery(delivery, policy, deliver(ctx, delivery), disabled)
+	}
+
+	if len(unhandled) > 0 {
+		d.releaseDeliveries(unhandled)
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// claimDeliveries locks the due deliveries and leases them until they are delivered.
+func (d *Database) claimDeliveries(ctx context.Context, limit int) (models.WebhookDeliveryList, error) {
+	tx, err := d.pool.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "can't start transaction")
+	}
+
+	defer func() {
+		if err != nil {
+			checkError(tx.Rollback)
+			return
+		}
+		checkError(tx.Commit)
+	}()
+
+	deliveries := models.WebhookDeliveryList{}
+	if err = tx.SelectContext(ctx, &deliveries, qm[webhookDeliveryLockDueQ], limit); err != nil {
+		return nil, errors.Wrap(err, "can't lock webhook deliveries")
+	}
+
+	if len(deliveries) == 0 {
+		return deliveries, nil
+	}
+
+	ids := make([]int64, len(deliveries))
+	leased := time.Now().Add(models.WebhookLease)
+	for i, delivery := range deliveries {
+		ids[i] = delivery.ID
+		delivery.NextAttemptAt = leased
+	}
+
+	if _, err = tx.ExecContext(ctx, qm[webhookDeliveryLeaseQ], pq.Array(ids), leased); err != nil {
+		return nil, errors.Wrap(err, "can't lease webhook deliveries")
+	}
+
+	return deliveries, nil
+}
+
+// saveDelivery saves the outcome of the delivery and counts it for its webhook
+// in a short transaction of its own.
+func (d *Database) saveDelivery(delivery *models.WebhookDelivery, policy models.WebhookPolicy,
+	deliverErr error, disabled map[int64]bool) error {
+	ctx, cancel := context.WithTimeout(context.Background(), publishSaveTimeout)
+	defer cancel()
+
+	tx, err := d.pool.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return errors.Wrap(err, "can't start transaction")
+	}
+
+	defer func() {
+		if err != nil {
+			checkError(tx.Rollback)
+			return
+		}
+		checkError(tx.Commit)
+	}()
+
+	now := time.Now().UTC()
+	if deliverErr != nil {
+		delivery.Fail(deliverErr, now, policy.MaxAttempts)
+
+		var isActive bool
+		if err = tx.GetContext(ctx, &isActive, qm[webhookFailQ], delivery.WebhookID, policy.MaxFailures); err != nil {
+			return errors.Wrapf(err, "can't count failure of webhook № %d", delivery.WebhookID)
+		}
+		disabled[delivery.WebhookID] = !isActive
+	} else {
+		delivery.Succeed(now)
+
+		if _, err = tx.ExecContext(ctx, qm[webhookSucceedQ], delivery.WebhookID); err != nil {
+			return errors.Wrapf(err, "can't reset failures of webhook № %d", delivery.WebhookID)
+		}
+	}
+
+	var deliveredAt time.Time
+	if delivery.DeliveredAt != nil {
+		deliveredAt = *delivery.DeliveredAt
+	}
+
+	_, err = tx.ExecContext(ctx, qm[webhookDeliverySaveQ], delivery.ID, delivery.Status, delivery.Attempts,
+		delivery.ResponseCode, delivery.LastError, delivery.NextAttemptAt, nullTime(deliveredAt))
+	if err != nil {
+		return errors.Wrapf(err, "can't save webhook delivery № %d", delivery.ID)
+	}
+
+	return nil
+}
+
+// releaseDeliveries ends the lease of the deliveries left undelivered,
+// a delivery that can't be released waits for its lease to end.
+func (d *Database) releaseDeliveries(deliveries models.WebhookDeliveryList) {
+	ctx, cancel := context.WithTimeout(context.Background(), publishSaveTimeout)
+	defer cancel()
+
+	ids := make([]int64, len(deliveries))
+	for i, delivery := range deliveries {
+		ids[i] = delivery.ID
+	}
+
+	if _, err := d.pool.ExecContext(ctx, qm[webhookDeliveryReleaseQ], pq.Array(ids)); err != nil {
+		zap.L().Warn("can't release webhook deliveries", zap.Int64s("ids", ids), zap.Error(err))
+	}
+}
+
+func scanWebhook(row sqlx.ColScanner) (*models.Webhook, error) {
+	var webhook models.Webhook
+	err := row.Scan(&webhook.ID, &webhook.URL, pq.Array(&webhook.Events), &webhook.Active,
+		&webhook.Failures, &webhook.CreatedAt, &webhook.DisabledAt)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't scan webhook")
+	}
+
+	return &webhook, nil
+}
@@ -0,0 +1,322 @@
+package psql
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/IamStubborN/petstore/db/models"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	pkgerrors "github.com/pkg/errors"
+)
+
+func TestDatabase_CreateWebhook(t *testing.T) {
+	pool, mock := generateMockedDB()
+	defer checkError(pool.Close)
+
+	now := time.Date(2020, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	type fields struct {
+		pool *sqlx.DB
+	}
+	type args struct {
+		ctx     context.Context
+		webhook *models.Webhook
+		mockFn  func()
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    *models.Webhook
+		wantErr bool
+	}{
+		{
+			name:   "Success 1",
+			fields: fields{pool: pool},
+			args: args{
+				ctx: context.Background(),
+				webhook: &models.Webhook{URL: "https://partner.example.com/hooks",
+					Events: []string{"order.created"}, Secret: "partner-secret-0001"},
+				mockFn: func() {
+					mock.ExpectQuery(`insert into webhook (.+) returning`).
+						WithArgs("https://partner.example.com/hooks", pq.Array([]string{"order.created"}),
+							"partner-secret-0001").
+						WillReturnRows(sqlmock.NewRows([]string{"id", "active", "failures", "created_at"}).
+							AddRow(3, true, 0, now))
+				},
+			},
+			want: &models.Webhook{ID: 3, URL: "https://partner.example.com/hooks",
+				Events: []string{"order.created"}, Active: true, CreatedAt: now},
+			wantErr: false,
+		},
+		{
+			name:   "Failure 1",
+			fields: fields{pool: pool},
+			args: args{
+				ctx: context.Background(),
+				webhook: &models.Webhook{URL: "https://partner.example.com/hooks",
+					Events: []string{"order.created"}, Secret: "partner-secret-0001"},
+				mockFn: func() {
+					mock.ExpectQuery(`insert into webhook (.+) returning`).
+						WillReturnError(errors.New("connection lost"))
+				},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Database{
+				pool: tt.fields.pool,
+			}
+
+			tt.args.mockFn()
+
+			got, err := d.CreateWebhook(tt.args.ctx, tt.args.webhook)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CreateWebhook() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CreateWebhook() got = %v, want %v", got, tt.want)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func TestDatabase_GetWebhookDeliveries(t *testing.T) {
+	pool, mock := generateMockedDB()
+	defer checkError(pool.Close)
+
+	now := time.Date(2020, 3, 1, 12, 0, 0, 0, time.UTC)
+	columns := []string{"id", "webhook_id", "event_id", "event", "status", "attempts",
+		"response_code", "last_error", "created_at", "next_attempt_at", "delivered_at"}
+
+	type fields struct {
+		pool *sqlx.DB
+	}
+	type args struct {
+		ctx       context.Context
+		webhookID int64
+		mockFn    func()
+	}
+	tests := []struct {
+		name      string
+		fields    fields
+		args      args
+		want      models.WebhookDeliveryList
+		wantCause error
+		wantErr   bool
+	}{
+		{
+			name:   "Success 1",
+			fields: fields{pool: pool},
+			args: args{
+				ctx:       context.Background(),
+				webhookID: 3,
+				mockFn: func() {
+					mock.ExpectQuery(`select exists`).
+						WithArgs(3).
+						WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+					mock.ExpectQuery(`select (.+) from webhook_delivery d (.+) order by d.id desc limit`).
+						WithArgs(3, 10).
+						WillReturnRows(sqlmock.NewRows(columns).
+							AddRow(8, 3, 41, "order.delivered", "delivered", 2, 200, "", now, now, now))
+				},
+			},
+			want: models.WebhookDeliveryList{{ID: 8, WebhookID: 3, EventID: 41, Event: "order.delivered",
+				Status: "delivered", Attempts: 2, ResponseCode: 200, CreatedAt: now, NextAttemptAt: now,
+				DeliveredAt: &now}},
+			wantErr: false,
+		},
+		{
+			name:   "Failure not found",
+			fields: fields{pool: pool},
+			args: args{
+				ctx:       context.Background(),
+				webhookID: 99,
+				mockFn: func() {
+					mock.ExpectQuery(`select exists`).
+						WithArgs(99).
+						WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+				},
+			},
+			want:      nil,
+			wantCause: models.ErrNotFound,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Database{
+				pool: tt.fields.pool,
+			}
+
+			tt.args.mockFn()
+
+			got, err := d.GetWebhookDeliveries(tt.args.ctx, tt.args.webhookID, 10)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetWebhookDeliveries() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.wantCause != nil && pkgerrors.Cause(err) != tt.wantCause {
+				t.Errorf("GetWebhookDeliveries() error = %v, wantCause %v", err, tt.wantCause)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetWebhookDeliveries() got = %v, want %v", got, tt.want)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func TestDatabase_DeliverWebhooks(t *testing.T) {
+	pool, mock := generateMockedDB()
+	defer checkError(pool.Close)
+
+	now := time.Date(2020, 3, 1, 12, 0, 0, 0, time.UTC)
+	columns := []string{"id", "webhook_id", "event_id", "event", "status", "attempts",
+		"response_code", "last_error", "created_at", "next_attempt_at", "delivered_at",
+		"aggregate", "aggregate_key", "payload", "event_created_at", "url", "secret"}
+	policy := models.WebhookPolicy{BatchSize: 10, MaxAttempts: 3, MaxFailures: 5}
+
+	type fields struct {
+		pool *sqlx.DB
+	}
+	type args struct {
+		ctx     context.Context
+		deliver func(context.Context, *models.WebhookDelivery) error
+		mockFn  func()
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    int
+		wantErr bool
+	}{
+		{
+			name:   "Success one is delivered, failing webhook is disabled",
+			fields: fields{pool: pool},
+			args: args{
+				ctx: context.Background(),
+				deliver: func(ctx context.Context, delivery *models.WebhookDelivery) error {
+					if delivery.WebhookID == 2 {
+						delivery.ResponseCode = 500
+						return errors.New("webhook answered 500")
+					}
+					delivery.ResponseCode = 200
+					return nil
+				},
+				mockFn: func() {
+					mock.ExpectBegin()
+					mock.ExpectQuery(`select (.+) from webhook_delivery d (.+) for update of d skip locked`).
+						WithArgs(10).
+						WillReturnRows(sqlmock.NewRows(columns).
+							AddRow(1, 1, 41, "order.created", "pending", 0, 0, "", now, now, nil,
+								"order", "7", []byte(`{"id":7}`), now, "https://a.example.com", "secret-a").
+							AddRow(2, 2, 41, "order.created", "pending", 2, 500, "timeout", now, now, nil,
+								"order", "7", []byte(`{"id":7}`), now, "https://b.example.com", "secret-b").
+							AddRow(3, 2, 42, "order.delivered", "pending", 0, 0, "", now, now, nil,
+								"order", "7", []byte(`{"id":7}`), now, "https://b.example.com", "secret-b"))
+					mock.ExpectExec(`update webhook_delivery set next_attempt_at=\$2 where id = any\(\$1\)`).
+						WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg()).
+						WillReturnResult(sqlmock.NewResult(0, 3))
+					mock.ExpectCommit()
+					mock.ExpectBegin()
+					mock.ExpectExec(`update webhook set failures=0`).
+						WithArgs(1).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectExec(`update webhook_delivery set status`).
+						WithArgs(1, "delivered", 1, 200, "", sqlmock.AnyArg(), sqlmock.AnyArg()).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectCommit()
+					mock.ExpectBegin()
+					mock.ExpectQuery(`update webhook set failures=failures \+ 1`).
+						WithArgs(2, 5).
+						WillReturnRows(sqlmock.NewRows([]string{"active"}).AddRow(false))
+					mock.ExpectExec(`update webhook_delivery set status`).
+						WithArgs(2, "failed", 3, 500, "webhook answered 500", sqlmock.AnyArg(), nil).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectCommit()
+					mock.ExpectExec(`update webhook_delivery set next_attempt_at=now\(\) where id = any\(\$1\)`).
+						WithArgs(sqlmock.AnyArg()).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+				},
+			},
+			want:    2,
+			wantErr: false,
+		},
+		{
+			name:   "Failure can't save outcome",
+			fields: fields{pool: pool},
+			args: args{
+				ctx:     context.Background(),
+				deliver: func(ctx context.Context, delivery *models.WebhookDelivery) error { return nil },
+				mockFn: func() {
+					mock.ExpectBegin()
+					mock.ExpectQuery(`select (.+) from webhook_delivery d (.+) for update of d skip locked`).
+						WithArgs(10).
+						WillReturnRows(sqlmock.NewRows(columns).
+							AddRow(1, 1, 41, "order.created", "pending", 0, 0, "", now, now, nil,
+								"order", "7", []byte(`{"id":7}`), now, "https://a.example.com", "secret-a").
+							AddRow(2, 1, 42, "order.delivered", "pending", 0, 0, "", now, now, nil,
+								"order", "7", []byte(`{"id":7}`), now, "https://a.example.com", "secret-a"))
+					mock.ExpectExec(`update webhook_delivery set next_attempt_at=\$2 where id = any\(\$1\)`).
+						WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg()).
+						WillReturnResult(sqlmock.NewResult(0, 2))
+					mock.ExpectCommit()
+					mock.ExpectBegin()
+					mock.ExpectExec(`update webhook set failures=0`).
+						WithArgs(1).
+						WillReturnError(errors.New("connection lost"))
+					mock.ExpectRollback()
+					mock.ExpectExec(`update webhook_delivery set next_attempt_at=now\(\) where id = any\(\$1\)`).
+						WithArgs(sqlmock.AnyArg()).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+				},
+			},
+			want:    0,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Database{
+				pool: tt.fields.pool,
+			}
+
+			tt.args.mockFn()
+
+			got, err := d.DeliverWebhooks(tt.args.ctx, policy, tt.args.deliver)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DeliverWebhooks() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if got != tt.want {
+				t.Errorf("DeliverWebhooks() got = %v, want %v", got, tt.want)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
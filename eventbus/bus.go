@@ -0,0 +1,149 @@
+package eventbus
+
+import (
+	"context"
+	"sync"
+
+	"github.com/IamStubborN/petstore/db/models"
+	"go.uber.org/zap"
+)
+
+// subscriberBuffer is the number of events a subscriber may fall behind,
+// a subscriber further behind is dropped and has to resume by event id.
+const subscriberBuffer = 256
+
+// Inventories reads the pets count by status, it's StoreDI.GetInventories.
+type Inventories func(ctx context.Context) (map[string]int64, error)
+
+// Bus fans the published events out to the subscribers in process.
+// While anyone is subscribed the bus keeps the inventory and sets
+// the change made by every inventory event on it, so the inventory
+// is read once per event and not once per subscriber.
+type Bus struct {
+	mu          sync.Mutex
+	subscribers map[chan *models.Event]struct{}
+	inventories Inventories
+	inventory   map[string]int64
+}
+
+// NewBus returns a bus reading the inventory with inventories,
+// a bus without inventories doesn't set the inventory changes.
+func NewBus(inventories Inventories) *Bus {
+	return &Bus{
+		subscribers: make(map[chan *models.Event]struct{}),
+		inventories: inventories,
+	}
+}
+
+// Subscribe returns the events published from now on, the channel is closed
+// when the context is done or when the subscriber falls too far behind.
+func (b *Bus) Subscribe(ctx context.Context) <-chan *models.Event {
+	events := make(chan *models.Event, subscriberBuffer)
+
+	b.mu.Lock()
+	b.subscribers[events] = struct{}{}
+	if b.inventory == nil {
+		b.inventory = b.readInventory(ctx)
+	}
+	b.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		b.unsubscribe(events)
+	}()
+
+	return events
+}
+
+// Publish hands the events to every subscriber without waiting for them.
+func (b *Bus) Publish(ctx context.Context, events ...*models.Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(b.subscribers) == 0 {
+		b.inventory = nil
+		return
+	}
+
+	for _, event := range events {
+		b.setInventoryChange(ctx, event)
+	}
+
+	for subscriber := range b.subscribers {
+		for _, event := range events {
+			select {
+			case subscriber <- event:
+				continue
+			default:
+			}
+
+			delete(b.subscribers, subscriber)
+			close(subscriber)
+			break
+		}
+	}
+}
+
+// Close drops all the subscribers.
+func (b *Bus) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for subscriber := range b.subscribers {
+		delete(b.subscribers, subscriber)
+		close(subscriber)
+	}
+}
+
+// setInventoryChange sets the change of the kept inventory made by the
+// inventory event, nothing is set when the inventory isn't changed or
+// when there was no inventory to compare with.
+func (b *Bus) setInventoryChange(ctx context.Context, event *models.Event) {
+	if !models.IsInventoryEvent(event.Type) {
+		return
+	}
+
+	after := b.readInventory(ctx)
+	if after == nil {
+		return
+	}
+
+	before := b.inventory
+	b.inventory = after
+	if before == nil {
+		return
+	}
+
+	if delta := models.InventoryDelta(before, after); delta != nil {
+		event.Inventory = &models.InventoryChange{
+			EventID:   event.ID,
+			Inventory: after,
+			Delta:     delta,
+		}
+	}
+}
+
+// readInventory returns nil when the bus has no inventories or they fail.
+func (b *Bus) readInventory(ctx context.Context) map[string]int64 {
+	if b.inventories == nil {
+		return nil
+	}
+
+	inventory, err := b.inventories(ctx)
+	if err != nil {
+		zap.L().Error("can't get inventories for event bus", zap.Error(err))
+		return nil
+	}
+
+	return inventory
+}
+
+func (b *Bus) unsubscribe(events chan *models.Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.subscribers[events]; ok {
+		delete(b.subscribers, events)
+		close(events)
+	}
+}
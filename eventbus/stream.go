@@ -0,0 +1,69 @@
+package eventbus
+
+import (
+	"context"
+
+	"github.com/IamStubborN/petstore/db/models"
+	"go.uber.org/zap"
+)
+
+// replayPage is the number of stored events read at a time on resume.
+const replayPage = 100
+
+// Replay reads up to limit stored events with ids above after, oldest first.
+type Replay func(ctx context.Context, after int64, limit int) (models.EventList, error)
+
+// Stream returns the events of the bus, the stored events with ids above
+// after are replayed first when after is set. The stream subscribes before
+// replaying, so no event is lost between them, and skips the live events
+// it has replayed already. The channel is closed when the context is done,
+// when the replay fails or when the bus drops the subscriber.
+func Stream(ctx context.Context, bus *Bus, after int64, replay Replay) <-chan *models.Event {
+	live := bus.Subscribe(ctx)
+	events := make(chan *models.Event)
+
+	go func() {
+		defer close(events)
+
+		send := func(event *models.Event) bool {
+			select {
+			case events <- event:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		replayed := after
+		for replayed > 0 {
+			stored, err := replay(ctx, replayed, replayPage)
+			if err != nil {
+				zap.L().Error("can't replay events", zap.Int64("after", replayed), zap.Error(err))
+				return
+			}
+
+			for _, event := range stored {
+				if !send(event) {
+					return
+				}
+				replayed = event.ID
+			}
+
+			if len(stored) < replayPage {
+				break
+			}
+		}
+
+		for event := range live {
+			if event.ID <= replayed {
+				continue
+			}
+
+			if !send(event) {
+				return
+			}
+		}
+	}()
+
+	return events
+}
@@ -0,0 +1,149 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/IamStubborN/petstore/db/models"
+)
+
+func TestStream(t *testing.T) {
+	stored := models.EventList{{ID: 1}, {ID: 2}, {ID: 3}}
+	replay := func(ctx context.Context, after int64, limit int) (models.EventList, error) {
+		events := models.EventList{}
+		for _, event := range stored {
+			if event.ID > after && len(events) < limit {
+				events = append(events, event)
+			}
+		}
+		return events, nil
+	}
+
+	tests := []struct {
+		name      string
+		after     int64
+		replay    Replay
+		published []int64
+		want      []int64
+	}{
+		{name: "live only", after: 0, replay: replay, published: []int64{4, 5}, want: []int64{4, 5}},
+		{name: "resume skips replayed", after: 1, replay: replay, published: []int64{3, 4}, want: []int64{2, 3, 4}},
+		{name: "resume at last", after: 3, replay: replay, published: []int64{4}, want: []int64{4}},
+		{name: "late commit", after: 0, replay: replay, published: []int64{5, 4}, want: []int64{5, 4}},
+		{name: "failed replay", after: 1, published: []int64{4},
+			replay: func(ctx context.Context, after int64, limit int) (models.EventList, error) {
+				return nil, errors.New("connection lost")
+			}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			bus := NewBus(nil)
+			events := Stream(ctx, bus, tt.after, tt.replay)
+			for _, id := range tt.published {
+				bus.Publish(ctx, &models.Event{ID: id})
+			}
+
+			var got []int64
+			for len(got) < len(tt.want) {
+				event, ok := <-events
+				if !ok {
+					break
+				}
+				got = append(got, event.ID)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Stream() got = %v, want %v", got, tt.want)
+			}
+
+			cancel()
+			if !isClosed(events) {
+				t.Error("Stream() isn't closed when the context is done")
+			}
+		})
+	}
+}
+
+func TestBus_Publish(t *testing.T) {
+	ctx := context.Background()
+	bus := NewBus(nil)
+	slow := bus.Subscribe(ctx)
+
+	for i := 0; i <= subscriberBuffer; i++ {
+		bus.Publish(ctx, &models.Event{ID: int64(i + 1)})
+	}
+
+	var count int
+	for range slow {
+		count++
+	}
+
+	if count != subscriberBuffer {
+		t.Errorf("Publish() slow subscriber got %d events, want %d", count, subscriberBuffer)
+	}
+}
+
+func TestBus_PublishInventory(t *testing.T) {
+	ctx := context.Background()
+	counts := []map[string]int64{
+		{"available": 10, "sold": 1},
+		{"available": 9, "sold": 2},
+		{"available": 9, "sold": 2},
+	}
+	var reads int
+	bus := NewBus(func(ctx context.Context) (map[string]int64, error) {
+		inventory := counts[reads]
+		reads++
+		return inventory, nil
+	})
+
+	first := bus.Subscribe(ctx)
+	second := bus.Subscribe(ctx)
+
+	created := &models.Event{ID: 1, Type: models.EventOrderCreated}
+	updated := &models.Event{ID: 2, Type: models.EventPetUpdated}
+	approved := &models.Event{ID: 3, Type: models.EventOrderApproved}
+	bus.Publish(ctx, created, updated, approved)
+
+	want := &models.InventoryChange{EventID: 1, Inventory: counts[1],
+		Delta: map[string]int64{"available": -1, "sold": 1}}
+	if !reflect.DeepEqual(created.Inventory, want) {
+		t.Errorf("Publish() inventory change = %v, want %v", created.Inventory, want)
+	}
+
+	if updated.Inventory != nil || approved.Inventory != nil {
+		t.Errorf("Publish() inventory change of unchanged inventory = %v, %v",
+			updated.Inventory, approved.Inventory)
+	}
+
+	if reads != 3 {
+		t.Errorf("Publish() read inventory %d times, want once per inventory event", reads)
+	}
+
+	for _, events := range []<-chan *models.Event{first, second} {
+		if event := <-events; event != created {
+			t.Errorf("Publish() subscriber got %v, want %v", event, created)
+		}
+	}
+}
+
+// isClosed drains the channel and tells whether it's closed in a second.
+func isClosed(events <-chan *models.Event) bool {
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case _, ok := <-events:
+			if !ok {
+				return true
+			}
+		case <-timeout:
+			return false
+		}
+	}
+}
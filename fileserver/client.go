@@ -1,6 +1,8 @@
 package fileserver
 
 import (
+	"fmt"
+	"io"
 	"strings"
 
 	"github.com/IamStubborN/petstore/config"
@@ -9,15 +11,22 @@ import (
 	"go.uber.org/zap"
 )
 
+// publicReadPolicy allows anonymous downloads of every object in a bucket.
+const publicReadPolicy = `{"Version":"2012-10-17","Statement":[{"Effect":"Allow",` +
+	`"Principal":{"AWS":["*"]},"Action":["s3:GetObject"],"Resource":["arn:aws:s3:::%s/*"]}]}`
+
 type FileManager interface {
 	PutFile(bucket, contentType, filePath string) error
+	PutObject(bucket, objectName, contentType string, reader io.Reader, size int64) (string, error)
+	PutPrivateObject(bucket, objectName, contentType string, reader io.Reader, size int64) (string, error)
+	GetObject(bucket, objectName string) (io.ReadCloser, error)
 }
 
 type fm struct {
 	client *minio.Client
 }
 
-var manager *fm
+var manager FileManager
 
 func InitMinio(cfg *config.Config) {
 	minioClient, err := minio.New(
@@ -36,16 +45,14 @@ func GetFM() FileManager {
 	return manager
 }
 
-func (fm fm) PutFile(bucket, contentType, filePath string) error {
-	isBucketExist, err := fm.client.BucketExists(bucket)
-	if err != nil {
-		return errors.Wrap(err, "can't check bucket exist")
-	}
+// SetFM replaces the file manager, the tests set an in-memory one.
+func SetFM(fm FileManager) {
+	manager = fm
+}
 
-	if !isBucketExist {
-		if err = fm.client.MakeBucket(bucket, ""); err != nil {
-			return errors.Wrap(err, "can't create bucket")
-		}
+func (fm fm) PutFile(bucket, contentType, filePath string) error {
+	if err := fm.ensureBucket(bucket, false); err != nil {
+		return err
 	}
 
 	opts := minio.PutObjectOptions{ContentType: contentType}
@@ -64,3 +71,89 @@ func (fm fm) PutFile(bucket, contentType, filePath string) error {
 
 	return nil
 }
+
+// PutObject streams reader into a publicly readable bucket and
+// returns the URL the object can be downloaded from.
+func (fm fm) PutObject(bucket, objectName, contentType string, reader io.Reader, size int64) (string, error) {
+	if err := fm.ensureBucket(bucket, true); err != nil {
+		return "", err
+	}
+
+	opts := minio.PutObjectOptions{ContentType: contentType}
+
+	n, err := fm.client.PutObject(bucket, objectName, reader, size, opts)
+	if err != nil {
+		return "", errors.Wrap(err, "can't put object")
+	}
+
+	zap.L().Info("object uploaded",
+		zap.String("object", objectName),
+		zap.Int64("written bytes", n))
+
+	objectURL := *fm.client.EndpointURL()
+	objectURL.Path = "/" + bucket + "/" + objectName
+
+	return objectURL.String(), nil
+}
+
+// PutPrivateObject streams reader into a private bucket and returns
+// the location of the object as bucket/objectName.
+func (fm fm) PutPrivateObject(bucket, objectName, contentType string, reader io.Reader, size int64) (string, error) {
+	if err := fm.ensureBucket(bucket, false); err != nil {
+		return "", err
+	}
+
+	opts := minio.PutObjectOptions{ContentType: contentType}
+
+	n, err := fm.client.PutObject(bucket, objectName, reader, size, opts)
+	if err != nil {
+		return "", errors.Wrap(err, "can't put object")
+	}
+
+	zap.L().Info("object uploaded",
+		zap.String("object", objectName),
+		zap.Int64("written bytes", n))
+
+	return bucket + "/" + objectName, nil
+}
+
+// GetObject opens the object for reading, the caller closes it.
+func (fm fm) GetObject(bucket, objectName string) (io.ReadCloser, error) {
+	object, err := fm.client.GetObject(bucket, objectName, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "can't get object")
+	}
+
+	// the object is requested on the first read, stat finds the missing ones
+	if _, err = object.Stat(); err != nil {
+		if closeErr := object.Close(); closeErr != nil {
+			zap.L().Error("can't close object", zap.Error(closeErr))
+		}
+		return nil, errors.Wrapf(err, "can't get object %s/%s", bucket, objectName)
+	}
+
+	return object, nil
+}
+
+func (fm fm) ensureBucket(bucket string, isPublic bool) error {
+	isBucketExist, err := fm.client.BucketExists(bucket)
+	if err != nil {
+		return errors.Wrap(err, "can't check bucket exist")
+	}
+
+	if isBucketExist {
+		return nil
+	}
+
+	if err = fm.client.MakeBucket(bucket, ""); err != nil {
+		return errors.Wrap(err, "can't create bucket")
+	}
+
+	if isPublic {
+		if err = fm.client.SetBucketPolicy(bucket, fmt.Sprintf(publicReadPolicy, bucket)); err != nil {
+			return errors.Wrap(err, "can't set bucket policy")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+// Package fileservertest provides an in-memory file manager for the tests.
+package fileservertest
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"path"
+	"sync"
+
+	"github.com/IamStubborN/petstore/fileserver"
+	"github.com/pkg/errors"
+)
+
+// mockFM keeps uploaded files in memory, it's used in tests
+// instead of a real minio server.
+type mockFM struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+// Init replaces the file manager of the fileserver package
+// with an empty in-memory one.
+func Init() {
+	fileserver.SetFM(&mockFM{objects: make(map[string][]byte)})
+}
+
+func (m *mockFM) PutFile(bucket, contentType, filePath string) error {
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+
+	_, err = m.PutObject(bucket, path.Base(filePath), contentType, bytes.NewReader(data), int64(len(data)))
+
+	return err
+}
+
+func (m *mockFM) PutObject(bucket, objectName, contentType string, reader io.Reader, size int64) (string, error) {
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return "", err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.objects[bucket+"/"+objectName] = data
+
+	return "http://mock/" + bucket + "/" + objectName, nil
+}
+
+func (m *mockFM) PutPrivateObject(bucket, objectName, contentType string, reader io.Reader, size int64) (string, error) {
+	if _, err := m.PutObject(bucket, objectName, contentType, reader, size); err != nil {
+		return "", err
+	}
+
+	return bucket + "/" + objectName, nil
+}
+
+func (m *mockFM) GetObject(bucket, objectName string) (io.ReadCloser, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	data, ok := m.objects[bucket+"/"+objectName]
+	if !ok {
+		return nil, errors.Errorf("object %s/%s doesn't exist", bucket, objectName)
+	}
+
+	return ioutil.NopCloser(bytes.NewReader(data)), nil
+}
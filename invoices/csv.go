@@ -0,0 +1,74 @@
+package invoices
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/IamStubborN/petstore/db/models"
+	"github.com/pkg/errors"
+)
+
+// csvHeader names the columns of the invoice lines.
+var csvHeader = []string{"order", "line", "user", "pet", "category",
+	"ship_date", "quantity", "price", "discount", "refund"}
+
+// CSV renders a row for every invoice line for spreadsheet imports,
+// unlike the printed invoices every row is complete and there are
+// no totals, the spreadsheet sums the rows.
+type CSV struct{}
+
+func NewCSV() *CSV {
+	return &CSV{}
+}
+
+func (c *CSV) Format() string {
+	return FormatCSV
+}
+
+func (c *CSV) ContentType() string {
+	return "text/csv; charset=utf-8"
+}
+
+func (c *CSV) Render(w io.Writer, invoice *models.Invoice) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return errors.Wrap(err, "can't render csv invoice")
+	}
+
+	for _, item := range invoice.Items {
+		record := []string{
+			strconv.FormatInt(item.ID, 10),
+			strconv.FormatInt(item.Line, 10),
+			csvText(item.User),
+			csvText(item.Pet),
+			csvText(item.Category),
+			item.ShipDate,
+			strconv.FormatInt(int64(item.Quantity), 10),
+			strconv.FormatFloat(item.Price, 'f', 2, 64),
+			strconv.FormatFloat(item.Discount, 'f', 2, 64),
+			strconv.FormatBool(item.Refund),
+		}
+		if err := writer.Write(record); err != nil {
+			return errors.Wrap(err, "can't render csv invoice")
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return errors.Wrap(err, "can't render csv invoice")
+	}
+
+	return nil
+}
+
+// csvText keeps spreadsheets from reading the names users choose
+// as formulas by quoting the ones starting like a formula.
+func csvText(value string) string {
+	if value != "" && strings.ContainsRune("=+-@", rune(value[0])) {
+		return "'" + value
+	}
+
+	return value
+}
@@ -0,0 +1,37 @@
+package invoices
+
+import (
+	"io"
+
+	"github.com/IamStubborN/petstore/db/models"
+	"github.com/IamStubborN/petstore/templates"
+	"github.com/pkg/errors"
+)
+
+// HTML renders the invoice as a standalone page.
+type HTML struct{}
+
+func NewHTML() *HTML {
+	return &HTML{}
+}
+
+func (h *HTML) Format() string {
+	return FormatHTML
+}
+
+func (h *HTML) ContentType() string {
+	return "text/html; charset=utf-8"
+}
+
+func (h *HTML) Render(w io.Writer, invoice *models.Invoice) error {
+	template, err := templates.GetInvoiceHTMLTemplate()
+	if err != nil {
+		return err
+	}
+
+	if err = template.Execute(w, invoice); err != nil {
+		return errors.Wrap(err, "can't render html invoice")
+	}
+
+	return nil
+}
@@ -0,0 +1,32 @@
+package invoices
+
+import (
+	"context"
+	"time"
+
+	"github.com/IamStubborN/petstore/db/models"
+)
+
+// Bucket keeps the invoice files, it isn't public.
+const Bucket = "invoices"
+
+// Items reads the invoice lines of the orders shipped from the RFC3339
+// time from up to, but not including, to, so periods that share a bound
+// don't share lines. It's StoreDI.CreateInvoiceByDates.
+type Items func(ctx context.Context, from, to string) ([]*models.InvoiceItem, error)
+
+// Generate reads the invoice lines of the period and sums them up.
+func Generate(ctx context.Context, items Items, from, to time.Time) (*models.Invoice, error) {
+	lines, err := items(ctx, from.UTC().Format(time.RFC3339), to.UTC().Format(time.RFC3339))
+	if err != nil {
+		return nil, err
+	}
+
+	return models.NewInvoice(from, to, lines)
+}
+
+// FileName names the invoice file by its period and the time it's generated.
+func FileName(invoice *models.Invoice, format string, generatedAt time.Time) string {
+	return "from_" + invoice.From + "_to_" + invoice.To +
+		"_generated_" + generatedAt.UTC().Format("15:04:05") + "." + format
+}
@@ -0,0 +1,32 @@
+package invoices
+
+import (
+	"io"
+
+	"github.com/IamStubborN/petstore/db/models"
+	"github.com/mailru/easyjson"
+	"github.com/pkg/errors"
+)
+
+// JSON renders the invoice with its items and totals.
+type JSON struct{}
+
+func NewJSON() *JSON {
+	return &JSON{}
+}
+
+func (j *JSON) Format() string {
+	return FormatJSON
+}
+
+func (j *JSON) ContentType() string {
+	return "application/json"
+}
+
+func (j *JSON) Render(w io.Writer, invoice *models.Invoice) error {
+	if _, err := easyjson.MarshalToWriter(invoice, w); err != nil {
+		return errors.Wrap(err, "can't render json invoice")
+	}
+
+	return nil
+}
@@ -0,0 +1,37 @@
+package invoices
+
+import (
+	"io"
+
+	"github.com/IamStubborN/petstore/db/models"
+	"github.com/IamStubborN/petstore/templates"
+	"github.com/pkg/errors"
+)
+
+// Markdown renders the invoice template of the templates package.
+type Markdown struct{}
+
+func NewMarkdown() *Markdown {
+	return &Markdown{}
+}
+
+func (m *Markdown) Format() string {
+	return FormatMarkdown
+}
+
+func (m *Markdown) ContentType() string {
+	return "text/markdown"
+}
+
+func (m *Markdown) Render(w io.Writer, invoice *models.Invoice) error {
+	template, err := templates.GetInvoiceTemplate()
+	if err != nil {
+		return err
+	}
+
+	if err = template.Execute(w, invoice); err != nil {
+		return errors.Wrap(err, "can't render markdown invoice")
+	}
+
+	return nil
+}
@@ -0,0 +1,219 @@
+package invoices
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/IamStubborN/petstore/db/models"
+	"github.com/pkg/errors"
+)
+
+// A4 page in points, the text is set in the standard Helvetica fonts,
+// so no font is embedded.
+const (
+	pdfPageWidth  = 595
+	pdfPageHeight = 842
+	pdfMargin     = 40
+	pdfFontSize   = 9
+	pdfLineHeight = 14
+	pdfTitleSize  = 16
+)
+
+// pdfColumn is a column of the invoice table, text longer than
+// the column is cut to size.
+type pdfColumn struct {
+	title    string
+	x        int
+	maxChars int
+}
+
+var pdfColumns = []pdfColumn{
+	{title: "No", x: 40, maxChars: 5},
+	{title: "User", x: 68, maxChars: 16},
+	{title: "Pet", x: 148, maxChars: 18},
+	{title: "Category", x: 238, maxChars: 19},
+	{title: "Ship Date", x: 333, maxChars: 19},
+	{title: "Quantity", x: 428, maxChars: 8},
+	{title: "Price", x: 470, maxChars: 10},
+	{title: "Discount", x: 515, maxChars: 10},
+}
+
+// PDF renders the printed invoice as a PDF document,
+// the table goes on over as many pages as it takes.
+type PDF struct{}
+
+func NewPDF() *PDF {
+	return &PDF{}
+}
+
+func (p *PDF) Format() string {
+	return FormatPDF
+}
+
+func (p *PDF) ContentType() string {
+	return "application/pdf"
+}
+
+func (p *PDF) Render(w io.Writer, invoice *models.Invoice) error {
+	doc := &pdfDocument{}
+	doc.newPage()
+
+	doc.text(pdfMargin, doc.y, pdfTitleSize, true, "Invoice orders")
+	doc.y -= pdfLineHeight * 2
+	doc.text(pdfMargin, doc.y, pdfFontSize+2, false, "from "+invoice.From+" to "+invoice.To)
+	doc.y -= pdfLineHeight * 2
+	doc.tableHeader()
+
+	for _, item := range invoice.Items {
+		if doc.y < pdfMargin+pdfLineHeight {
+			doc.newPage()
+			doc.tableHeader()
+		}
+
+		cells := []string{"", "", item.Pet, item.Category, "",
+			strconv.FormatInt(int64(item.Quantity), 10),
+			"$ " + strconv.FormatFloat(item.Price, 'f', 2, 64),
+			"$ " + strconv.FormatFloat(item.Discount, 'f', 2, 64)}
+		if item.Line == 1 {
+			cells[0], cells[1], cells[4] = strconv.FormatInt(item.ID, 10), item.User, item.ShipDate
+		}
+		doc.row(cells, false)
+	}
+
+	totals := []string{
+		"Total price: $ " + strconv.FormatFloat(invoice.TotalPrice, 'f', 2, 64),
+		"Discount: $ " + strconv.FormatFloat(invoice.TotalDiscount, 'f', 2, 64),
+		"Refunds: $ " + strconv.FormatFloat(invoice.TotalRefund, 'f', 2, 64),
+		"Total with discounts and refunds: $ " + strconv.FormatFloat(invoice.Total, 'f', 2, 64),
+	}
+	if doc.y < pdfMargin+pdfLineHeight*(len(totals)+1) {
+		doc.newPage()
+	}
+	doc.y -= pdfLineHeight
+	for i, total := range totals {
+		doc.text(pdfPageWidth/2, doc.y, pdfFontSize+1, i == len(totals)-1, total)
+		doc.y -= pdfLineHeight
+	}
+
+	if err := doc.write(w); err != nil {
+		return errors.Wrap(err, "can't render pdf invoice")
+	}
+
+	return nil
+}
+
+// pdfDocument lays the text out line by line from the top of the page,
+// y is the baseline of the next line on the last page.
+type pdfDocument struct {
+	pages []*bytes.Buffer
+	y     int
+}
+
+func (d *pdfDocument) newPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+	d.y = pdfPageHeight - pdfMargin - pdfTitleSize
+}
+
+func (d *pdfDocument) tableHeader() {
+	titles := make([]string, 0, len(pdfColumns))
+	for _, column := range pdfColumns {
+		titles = append(titles, column.title)
+	}
+
+	d.row(titles, true)
+}
+
+func (d *pdfDocument) row(cells []string, bold bool) {
+	for i, cell := range cells {
+		column := pdfColumns[i]
+		if len(cell) > column.maxChars {
+			cell = cell[:column.maxChars-1] + "."
+		}
+		d.text(column.x, d.y, pdfFontSize, bold, cell)
+	}
+
+	d.y -= pdfLineHeight
+}
+
+func (d *pdfDocument) text(x, y, size int, bold bool, text string) {
+	if text == "" {
+		return
+	}
+
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+
+	page := d.pages[len(d.pages)-1]
+	fmt.Fprintf(page, "BT /%s %d Tf %d %d Td (%s) Tj ET\n", font, size, x, y, pdfString(text))
+}
+
+// write puts the pages in a PDF 1.4 file: the catalog, the page tree,
+// the two fonts and a page with its content stream for every page,
+// then the cross-reference table of the object offsets.
+func (d *pdfDocument) write(w io.Writer) error {
+	var file bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, file.Len())
+		fmt.Fprintf(&file, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	file.WriteString("%PDF-1.4\n")
+
+	kids := make([]string, 0, len(d.pages))
+	for i := range d.pages {
+		kids = append(kids, fmt.Sprintf("%d 0 R", 5+i*2))
+	}
+
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+
+	for i, page := range d.pages {
+		fmt.Fprintf(page, "BT /F1 %d Tf %d %d Td (%s) Tj ET\n", pdfFontSize-1,
+			pdfPageWidth-pdfMargin-60, pdfMargin/2, pdfString(fmt.Sprintf("Page %d of %d", i+1, len(d.pages))))
+
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] "+
+			"/Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, 6+i*2))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.Len(), page.String()))
+	}
+
+	xref := file.Len()
+	fmt.Fprintf(&file, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&file, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&file, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	_, err := file.WriteTo(w)
+
+	return err
+}
+
+// pdfString escapes the text for a PDF string literal, the characters
+// the WinAnsi encoding has no code for are replaced by question marks.
+func pdfString(text string) string {
+	var s strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			s.WriteByte('\\')
+			s.WriteRune(r)
+		case r >= 0x20 && r < 0x7f:
+			s.WriteRune(r)
+		case r >= 0xa0 && r <= 0xff:
+			s.WriteByte(byte(r))
+		default:
+			s.WriteByte('?')
+		}
+	}
+
+	return s.String()
+}
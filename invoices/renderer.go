@@ -0,0 +1,66 @@
+package invoices
+
+import (
+	"io"
+
+	"github.com/IamStubborN/petstore/db/models"
+	"github.com/pkg/errors"
+)
+
+const (
+	FormatMarkdown = "md"
+	FormatCSV      = "csv"
+	FormatJSON     = "json"
+	FormatHTML     = "html"
+	FormatPDF      = "pdf"
+)
+
+// Renderer writes the invoice in one of the formats,
+// the format is the extension of the invoice files too.
+type Renderer interface {
+	Format() string
+	ContentType() string
+	Render(w io.Writer, invoice *models.Invoice) error
+}
+
+func NewRenderer(format string) (Renderer, error) {
+	switch format {
+	case FormatMarkdown:
+		return NewMarkdown(), nil
+	case FormatCSV:
+		return NewCSV(), nil
+	case FormatJSON:
+		return NewJSON(), nil
+	case FormatHTML:
+		return NewHTML(), nil
+	case FormatPDF:
+		return NewPDF(), nil
+	default:
+		return nil, errors.Errorf("unknown invoice format %s", format)
+	}
+}
+
+// NewRenderers returns the renderers of the formats in their order,
+// the invoices are rendered in markdown when no format is given.
+func NewRenderers(formats []string) ([]Renderer, error) {
+	if len(formats) == 0 {
+		formats = []string{FormatMarkdown}
+	}
+
+	renderers := make([]Renderer, 0, len(formats))
+	isAdded := make(map[string]bool, len(formats))
+	for _, format := range formats {
+		if isAdded[format] {
+			return nil, errors.Errorf("invoice format %s is repeated", format)
+		}
+		isAdded[format] = true
+
+		renderer, err := NewRenderer(format)
+		if err != nil {
+			return nil, err
+		}
+		renderers = append(renderers, renderer)
+	}
+
+	return renderers, nil
+}
@@ -0,0 +1,147 @@
+package invoices
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/IamStubborN/petstore/db/models"
+)
+
+func testInvoice(items int) *models.Invoice {
+	invoice := &models.Invoice{From: "2019-09-05", To: "2019-09-06",
+		TotalPrice: 60, TotalDiscount: 6, TotalRefund: 10, Total: 44}
+
+	for i := 1; i <= items; i++ {
+		invoice.Items = append(invoice.Items, &models.InvoiceItem{ID: 7, Line: int64(i), User: "=HYPERLINK(evil)",
+			Pet: "Rex (the dog)", Category: "Dogs", ShipDate: "2019-09-05 10:00:00", Quantity: 2, Price: 30, Discount: 3})
+	}
+	invoice.Items = append(invoice.Items, &models.InvoiceItem{ID: 7, Line: int64(items + 1),
+		Category: "damaged", ShipDate: "2019-09-06 09:00:00", Quantity: 1, Price: -10, Refund: true})
+
+	return invoice
+}
+
+func TestNewRenderers(t *testing.T) {
+	tests := []struct {
+		name    string
+		formats []string
+		want    []string
+		wantErr bool
+	}{
+		{name: "default", formats: nil, want: []string{FormatMarkdown}},
+		{name: "all", formats: []string{"pdf", "csv", "json", "html", "md"},
+			want: []string{FormatPDF, FormatCSV, FormatJSON, FormatHTML, FormatMarkdown}},
+		{name: "unknown", formats: []string{"md", "docx"}, wantErr: true},
+		{name: "repeated", formats: []string{"csv", "csv"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			renderers, err := NewRenderers(tt.formats)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewRenderers() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			var got []string
+			for _, renderer := range renderers {
+				got = append(got, renderer.Format())
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NewRenderers() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderer_Render(t *testing.T) {
+	tests := []struct {
+		format string
+		want   []string
+	}{
+		{format: FormatMarkdown, want: []string{"### from 2019-09-05 to 2019-09-06",
+			"|7|=HYPERLINK(evil)|Rex (the dog)|Dogs|2019-09-05 10:00:00|2|$ 30|$ 3|",
+			"Total with discounts and refunds: $ 44.00"}},
+		{format: FormatCSV, want: []string{"order,line,user,pet,category,ship_date,quantity,price,discount,refund\n",
+			"7,1,'=HYPERLINK(evil),Rex (the dog),Dogs,2019-09-05 10:00:00,2,30.00,3.00,false\n",
+			"7,2,,,damaged,2019-09-06 09:00:00,1,-10.00,0.00,true\n"}},
+		{format: FormatJSON, want: []string{`{"from":"2019-09-05","to":"2019-09-06","items":[{"id":7,"line":1,`,
+			`"total_price":60,"total_discount":6,"total_refund":10,"total":44}`}},
+		{format: FormatHTML, want: []string{"<!DOCTYPE html>", "<td>=HYPERLINK(evil)</td><td>Rex (the dog)</td>",
+			`<tr class="refund">`, "$ -10.00", "Total with discounts and refunds: $ 44.00"}},
+		{format: FormatPDF, want: []string{"%PDF-1.4\n", "(Rex \\(the dog\\)) Tj", "/Count 1 >>",
+			"(Total with discounts and refunds: $ 44.00) Tj", "%%EOF\n"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			renderer, err := NewRenderer(tt.format)
+			if err != nil {
+				t.Fatalf("NewRenderer() error = %v", err)
+			}
+
+			var out bytes.Buffer
+			if err = renderer.Render(&out, testInvoice(1)); err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+
+			for _, want := range tt.want {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("Render() doesn't contain %q:\n%s", want, out.String())
+				}
+			}
+		})
+	}
+}
+
+func TestCSV_Render(t *testing.T) {
+	var out bytes.Buffer
+	if err := NewCSV().Render(&out, testInvoice(3)); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	records, err := csv.NewReader(&out).ReadAll()
+	if err != nil {
+		t.Fatalf("can't read rendered csv: %v", err)
+	}
+
+	if len(records) != 5 {
+		t.Errorf("Render() got %d records, want header and 4 lines", len(records))
+	}
+}
+
+// TestPDF_Render checks the table goes on over pages and that
+// the cross-reference table points at the objects.
+func TestPDF_Render(t *testing.T) {
+	var out bytes.Buffer
+	if err := NewPDF().Render(&out, testInvoice(120)); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	file := out.String()
+
+	if !strings.Contains(file, "/Count 3 >>") || !strings.Contains(file, "(Page 3 of 3) Tj") {
+		t.Errorf("Render() isn't 3 pages long")
+	}
+
+	offsets := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllStringSubmatch(file, -1)
+	if len(offsets) != 4+3*2 {
+		t.Fatalf("Render() got %d objects, want 10", len(offsets))
+	}
+	for i, offset := range offsets {
+		at, _ := strconv.Atoi(offset[1])
+		if want := fmt.Sprintf("%d 0 obj\n", i+1); !strings.HasPrefix(file[at:], want) {
+			t.Errorf("Render() object %d isn't at offset %d", i+1, at)
+		}
+	}
+
+	startxref := regexp.MustCompile(`startxref\n(\d+)\n`).FindStringSubmatch(file)
+	at, _ := strconv.Atoi(startxref[1])
+	if !strings.HasPrefix(file[at:], "xref\n") {
+		t.Errorf("Render() startxref %d doesn't point at xref", at)
+	}
+}
@@ -0,0 +1,54 @@
+package invoices
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/IamStubborN/petstore/db/models"
+	"github.com/IamStubborN/petstore/fileserver"
+	uuid "github.com/nu7hatch/gouuid"
+	"github.com/pkg/errors"
+)
+
+// Registry records the stored invoice file, it's InvoiceDI.AddInvoiceFile.
+type Registry func(ctx context.Context, file *models.InvoiceFile) (*models.InvoiceFile, error)
+
+// Store renders the invoice, puts the file in the bucket and records it
+// under the next invoice number. Every file gets its own object key,
+// so the files of the same period don't overwrite each other.
+func Store(ctx context.Context, fm fileserver.FileManager, registry Registry,
+	invoice *models.Invoice, renderer Renderer) (*models.InvoiceFile, error) {
+	var content bytes.Buffer
+	if err := renderer.Render(&content, invoice); err != nil {
+		return nil, err
+	}
+
+	objectID, err := uuid.NewV4()
+	if err != nil {
+		return nil, errors.Wrap(err, "can't generate invoice object id")
+	}
+
+	checksum := sha256.Sum256(content.Bytes())
+	file := &models.InvoiceFile{
+		From:          invoice.PeriodFrom,
+		To:            invoice.PeriodTo,
+		Format:        renderer.Format(),
+		ObjectKey:     fmt.Sprintf("from_%s_to_%s/%s.%s", invoice.From, invoice.To, objectID, renderer.Format()),
+		Checksum:      hex.EncodeToString(checksum[:]),
+		Size:          int64(content.Len()),
+		TotalPrice:    invoice.TotalPrice,
+		TotalDiscount: invoice.TotalDiscount,
+		TotalRefund:   invoice.TotalRefund,
+		Total:         invoice.Total,
+	}
+
+	_, err = fm.PutPrivateObject(Bucket, file.ObjectKey, renderer.ContentType(), &content, file.Size)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't upload invoice")
+	}
+
+	return registry(ctx, file)
+}
@@ -0,0 +1,70 @@
+package invoices
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/IamStubborN/petstore/db/models"
+	"github.com/IamStubborN/petstore/fileserver"
+	"github.com/IamStubborN/petstore/fileserver/fileservertest"
+)
+
+func TestStore(t *testing.T) {
+	fileservertest.Init()
+	fm := fileserver.GetFM()
+
+	var recorded []*models.InvoiceFile
+	registry := func(ctx context.Context, file *models.InvoiceFile) (*models.InvoiceFile, error) {
+		file.Number = int64(len(recorded) + 1)
+		recorded = append(recorded, file)
+		return file, nil
+	}
+
+	invoice := testInvoice(2)
+	first, err := Store(context.Background(), fm, registry, invoice, NewCSV())
+	if err != nil {
+		t.Fatalf("Store() error = %v", err)
+	}
+
+	second, err := Store(context.Background(), fm, registry, invoice, NewCSV())
+	if err != nil {
+		t.Fatalf("Store() error = %v", err)
+	}
+
+	if first.ObjectKey == second.ObjectKey || !strings.HasPrefix(first.ObjectKey, "from_2019-09-05_to_2019-09-06/") {
+		t.Errorf("Store() object keys = %s, %s", first.ObjectKey, second.ObjectKey)
+	}
+
+	var rendered bytes.Buffer
+	if err = NewCSV().Render(&rendered, invoice); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	checksum := sha256.Sum256(rendered.Bytes())
+
+	if first.Checksum != hex.EncodeToString(checksum[:]) || first.Size != int64(rendered.Len()) ||
+		first.Total != invoice.Total || first.Format != FormatCSV {
+		t.Errorf("Store() recorded %+v", first)
+	}
+
+	object, err := fm.GetObject(Bucket, first.ObjectKey)
+	if err != nil {
+		t.Fatalf("GetObject() error = %v", err)
+	}
+	stored, _ := ioutil.ReadAll(object)
+	if !bytes.Equal(stored, rendered.Bytes()) {
+		t.Errorf("Store() stored file differs from rendered one")
+	}
+
+	failing := func(ctx context.Context, file *models.InvoiceFile) (*models.InvoiceFile, error) {
+		return nil, errors.New("connection lost")
+	}
+	if _, err = Store(context.Background(), fm, failing, invoice, NewCSV()); err == nil {
+		t.Errorf("Store() error = nil, want registry error")
+	}
+}
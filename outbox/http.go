@@ -0,0 +1,72 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/IamStubborN/petstore/db/models"
+	"github.com/mailru/easyjson"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// HTTP posts every event as JSON to the endpoint, the id and the type
+// of the event are repeated in the X-Event-ID and X-Event-Type headers.
+// Any answer other than 2xx is a failure and the event is retried.
+type HTTP struct {
+	endpoint string
+	client   *http.Client
+}
+
+func NewHTTP(endpoint string, timeout time.Duration) *HTTP {
+	return &HTTP{
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: timeout},
+	}
+}
+
+func (h *HTTP) Name() string {
+	return "http"
+}
+
+func (h *HTTP) Publish(ctx context.Context, event *models.Event) error {
+	data, err := easyjson.Marshal(event)
+	if err != nil {
+		return errors.Wrapf(err, "can't marshal event № %d", event.ID)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, h.endpoint, bytes.NewReader(data))
+	if err != nil {
+		return errors.Wrap(err, "can't create event request")
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-ID", strconv.FormatInt(event.ID, 10))
+	req.Header.Set("X-Event-Type", event.Type)
+
+	resp, err := h.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return errors.Wrapf(err, "can't post event № %d", event.ID)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			zap.L().Error("can't close event response body", zap.Error(err))
+		}
+	}()
+
+	// the body is drained, so the connection can be reused
+	if _, err = io.Copy(ioutil.Discard, resp.Body); err != nil {
+		zap.L().Error("can't read event response body", zap.Error(err))
+	}
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return errors.Errorf("event endpoint answered %s to event № %d", resp.Status, event.ID)
+	}
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package outbox
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/IamStubborN/petstore/db/models"
+)
+
+func TestHTTP_Publish(t *testing.T) {
+	event := &models.Event{
+		ID:        7,
+		Aggregate: models.AggregateOrder,
+		Key:       "1",
+		Type:      models.EventOrderCreated,
+		Payload:   []byte(`{"id":1}`),
+	}
+
+	tests := []struct {
+		name    string
+		status  int
+		wantErr bool
+	}{
+		{name: "ok", status: http.StatusOK},
+		{name: "accepted", status: http.StatusAccepted},
+		{name: "redirect", status: http.StatusFound, wantErr: true},
+		{name: "server error", status: http.StatusInternalServerError, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotID, gotType, gotBody string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotID = r.Header.Get("X-Event-ID")
+				gotType = r.Header.Get("X-Event-Type")
+				body, _ := ioutil.ReadAll(r.Body)
+				gotBody = string(body)
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			err := NewHTTP(server.URL, time.Second).Publish(context.Background(), event)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Publish() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if gotID != "7" || gotType != models.EventOrderCreated {
+				t.Errorf("Publish() headers = %s, %s, want 7, %s", gotID, gotType, models.EventOrderCreated)
+			}
+
+			want := `{"id":7,"aggregate":"order","key":"1","type":"order.created","payload":{"id":1},` +
+				`"created_at":"0001-01-01T00:00:00Z"}`
+			if gotBody != want {
+				t.Errorf("Publish() body = %s, want %s", gotBody, want)
+			}
+		})
+	}
+}
+
+func TestHTTP_Timeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	err := NewHTTP(server.URL, 50*time.Millisecond).Publish(context.Background(), &models.Event{ID: 1})
+	if err == nil {
+		t.Error("Publish() to slow endpoint, want error")
+	}
+}
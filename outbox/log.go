@@ -0,0 +1,29 @@
+package outbox
+
+import (
+	"context"
+
+	"github.com/IamStubborN/petstore/db/models"
+	"go.uber.org/zap"
+)
+
+// Log writes the events to the application log, it never fails.
+type Log struct{}
+
+func NewLog() *Log {
+	return &Log{}
+}
+
+func (l *Log) Name() string {
+	return "log"
+}
+
+func (l *Log) Publish(ctx context.Context, event *models.Event) error {
+	zap.L().Info("outbox event",
+		zap.Int64("id", event.ID),
+		zap.String("type", event.Type),
+		zap.String("key", event.Key),
+		zap.ByteString("payload", event.Payload))
+
+	return nil
+}
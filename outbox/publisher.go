@@ -0,0 +1,32 @@
+package outbox
+
+import (
+	"context"
+
+	"github.com/IamStubborN/petstore/config"
+	"github.com/IamStubborN/petstore/db/models"
+	"go.uber.org/zap"
+)
+
+// Publisher delivers outbox events to the world outside the API.
+// An event is delivered at least once, it's published again
+// after a failure or when the outbox worker stops before saving
+// the outcome, so the event id is passed on for deduplication.
+type Publisher interface {
+	Name() string
+	Publish(ctx context.Context, event *models.Event) error
+}
+
+func NewPublisher(cfg config.Outbox) Publisher {
+	var publisher Publisher
+	switch cfg.Publisher {
+	case "log":
+		publisher = NewLog()
+	case "http":
+		publisher = NewHTTP(cfg.Endpoint, cfg.Timeout)
+	default:
+		zap.L().Fatal("wrong outbox publisher")
+	}
+
+	return publisher
+}
@@ -0,0 +1,162 @@
+package payments
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Card tokens the fake provider declines, any other token is accepted.
+// A token like tok_delay_2s is accepted after the given delay.
+const (
+	TokenDeclined          = "tok_declined"
+	TokenInsufficientFunds = "tok_insufficient_funds"
+	tokenDelayPrefix       = "tok_delay_"
+)
+
+// Fake is an in-process payment provider, its answers depend only on
+// the card token and the calls made before, so tests and local runs
+// are reproducible. Every answer comes after the delay.
+type Fake struct {
+	delay time.Duration
+
+	mu       sync.Mutex
+	seq      int64
+	payments map[string]*fakePayment
+}
+
+type fakePayment struct {
+	authorized float64
+	captured   float64
+	refunded   float64
+	refunds    map[string]float64
+}
+
+func NewFake(delay time.Duration) *Fake {
+	return &Fake{
+		delay:    delay,
+		payments: make(map[string]*fakePayment),
+	}
+}
+
+func (f *Fake) Name() string {
+	return "fake"
+}
+
+func (f *Fake) Authorize(ctx context.Context, charge Charge) (string, error) {
+	delay := f.delay
+	if strings.HasPrefix(charge.Token, tokenDelayPrefix) {
+		tokenDelay, err := time.ParseDuration(strings.TrimPrefix(charge.Token, tokenDelayPrefix))
+		if err != nil {
+			return "", errors.Wrapf(err, "can't parse delay of token %s", charge.Token)
+		}
+		delay += tokenDelay
+	}
+
+	if err := wait(ctx, delay); err != nil {
+		return "", err
+	}
+
+	switch charge.Token {
+	case TokenDeclined:
+		return "", errors.Wrap(ErrDeclined, "card declined")
+	case TokenInsufficientFunds:
+		return "", errors.Wrap(ErrDeclined, "insufficient funds")
+	}
+
+	if charge.Amount <= 0 {
+		return "", errors.Errorf("can't authorize amount %.2f", charge.Amount)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.seq++
+	reference := fmt.Sprintf("fake_%d_%d", charge.OrderID, f.seq)
+	f.payments[reference] = &fakePayment{authorized: charge.Amount}
+
+	return reference, nil
+}
+
+func (f *Fake) Capture(ctx context.Context, reference string, amount float64) error {
+	if err := wait(ctx, f.delay); err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	payment, ok := f.payments[reference]
+	if !ok {
+		return errors.Wrapf(ErrUnknownPayment, "payment %s", reference)
+	}
+
+	if payment.captured > 0 {
+		return errors.Errorf("payment %s is already captured", reference)
+	}
+
+	if amount <= 0 || amount > payment.authorized {
+		return errors.Errorf("can't capture %.2f of %.2f authorized", amount, payment.authorized)
+	}
+
+	payment.captured = amount
+
+	return nil
+}
+
+func (f *Fake) Refund(ctx context.Context, reference, key string, amount float64) error {
+	if err := wait(ctx, f.delay); err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	payment, ok := f.payments[reference]
+	if !ok {
+		return errors.Wrapf(ErrUnknownPayment, "payment %s", reference)
+	}
+
+	if refunded, ok := payment.refunds[key]; ok && key != "" {
+		if refunded != amount {
+			return errors.Errorf("refund %s of %.2f is made, not %.2f", key, refunded, amount)
+		}
+		return nil
+	}
+
+	left := payment.captured - payment.refunded
+	if amount <= 0 || amount > left {
+		return errors.Errorf("can't refund %.2f of %.2f captured", amount, left)
+	}
+
+	payment.refunded += amount
+	if key != "" {
+		if payment.refunds == nil {
+			payment.refunds = make(map[string]float64)
+		}
+		payment.refunds[key] = amount
+	}
+
+	return nil
+}
+
+// wait sleeps for the delay unless the context is done first.
+func wait(ctx context.Context, delay time.Duration) error {
+	if delay <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err(), "payment provider didn't answer in time")
+	}
+}
@@ -0,0 +1,102 @@
+package payments
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestFake_Authorize(t *testing.T) {
+	tests := []struct {
+		name          string
+		delay         time.Duration
+		charge        Charge
+		wantReference string
+		wantCause     error
+		wantErr       bool
+	}{
+		{name: "accepted", charge: Charge{OrderID: 7, Amount: 35, Token: "tok_visa"},
+			wantReference: "fake_7_1"},
+		{name: "declined", charge: Charge{OrderID: 7, Amount: 35, Token: TokenDeclined},
+			wantCause: ErrDeclined, wantErr: true},
+		{name: "insufficient funds", charge: Charge{OrderID: 7, Amount: 35, Token: TokenInsufficientFunds},
+			wantCause: ErrDeclined, wantErr: true},
+		{name: "zero amount", charge: Charge{OrderID: 7, Token: "tok_visa"}, wantErr: true},
+		{name: "token delay", charge: Charge{OrderID: 7, Amount: 35, Token: "tok_delay_10ms"},
+			wantReference: "fake_7_1"},
+		{name: "answer after deadline", delay: time.Second, charge: Charge{OrderID: 7, Amount: 35, Token: "tok_visa"},
+			wantCause: context.DeadlineExceeded, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+
+			got, err := NewFake(tt.delay).Authorize(ctx, tt.charge)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Authorize() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.wantCause != nil && errors.Cause(err) != tt.wantCause {
+				t.Errorf("Authorize() error = %v, wantCause %v", err, tt.wantCause)
+			}
+
+			if got != tt.wantReference {
+				t.Errorf("Authorize() got = %v, want %v", got, tt.wantReference)
+			}
+		})
+	}
+}
+
+func TestFake_CaptureAndRefund(t *testing.T) {
+	ctx := context.Background()
+	fake := NewFake(0)
+
+	reference, err := fake.Authorize(ctx, Charge{OrderID: 1, Amount: 100, Token: "tok_visa"})
+	if err != nil {
+		t.Fatalf("Authorize() error = %v", err)
+	}
+
+	if err = fake.Refund(ctx, reference, "refund_1", 10); err == nil {
+		t.Error("Refund() of not captured payment, want error")
+	}
+
+	if err = fake.Capture(ctx, reference, 120); err == nil {
+		t.Error("Capture() over authorized amount, want error")
+	}
+
+	if err = fake.Capture(ctx, "fake_1_99", 100); errors.Cause(err) != ErrUnknownPayment {
+		t.Errorf("Capture() of unknown payment error = %v, want ErrUnknownPayment", err)
+	}
+
+	if err = fake.Capture(ctx, reference, 100); err != nil {
+		t.Errorf("Capture() error = %v", err)
+	}
+
+	if err = fake.Capture(ctx, reference, 100); err == nil {
+		t.Error("Capture() twice, want error")
+	}
+
+	if err = fake.Refund(ctx, reference, "refund_1", 60); err != nil {
+		t.Errorf("Refund() error = %v", err)
+	}
+
+	if err = fake.Refund(ctx, reference, "refund_1", 60); err != nil {
+		t.Errorf("Refund() repeated error = %v", err)
+	}
+
+	if err = fake.Refund(ctx, reference, "refund_1", 40); err == nil {
+		t.Error("Refund() repeated with another amount, want error")
+	}
+
+	if err = fake.Refund(ctx, reference, "refund_2", 60); err == nil {
+		t.Error("Refund() over captured amount, want error")
+	}
+
+	if err = fake.Refund(ctx, reference, "refund_3", 40); err != nil {
+		t.Errorf("Refund() of the rest error = %v", err)
+	}
+}
@@ -0,0 +1,132 @@
+package payments
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// HTTP calls a payment gateway with JSON requests to
+// the authorize, capture and refund paths of its endpoint.
+// The gateway answers 200 on success, 402 with a reason on
+// decline and any other code on failure, see StubHandler.
+type HTTP struct {
+	endpoint string
+	secret   string
+	client   *http.Client
+}
+
+// gatewayRequest is the body of capture and refund calls,
+// the gateway makes the refunds with the same key once.
+type gatewayRequest struct {
+	Reference string  `json:"reference"`
+	Key       string  `json:"key,omitempty"`
+	Amount    float64 `json:"amount"`
+}
+
+type gatewayResponse struct {
+	Reference string `json:"reference,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+func NewHTTP(endpoint, secret string, timeout time.Duration) *HTTP {
+	return &HTTP{
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		secret:   secret,
+		client:   &http.Client{Timeout: timeout},
+	}
+}
+
+func (h *HTTP) Name() string {
+	return "http"
+}
+
+func (h *HTTP) Authorize(ctx context.Context, charge Charge) (string, error) {
+	res, err := h.call(ctx, "/authorize", charge)
+	if err != nil {
+		return "", err
+	}
+
+	if res.Reference == "" {
+		return "", errors.New("gateway didn't return payment reference")
+	}
+
+	return res.Reference, nil
+}
+
+func (h *HTTP) Capture(ctx context.Context, reference string, amount float64) error {
+	_, err := h.call(ctx, "/capture", gatewayRequest{Reference: reference, Amount: amount})
+	return err
+}
+
+func (h *HTTP) Refund(ctx context.Context, reference, key string, amount float64) error {
+	_, err := h.call(ctx, "/refund", gatewayRequest{Reference: reference, Key: key, Amount: amount})
+	return err
+}
+
+func (h *HTTP) call(ctx context.Context, path string, body interface{}) (*gatewayResponse, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't marshal gateway request")
+	}
+
+	req, err := http.NewRequest(http.MethodPost, h.endpoint+path, bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.Wrap(err, "can't create gateway request")
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if h.secret != "" {
+		req.Header.Set("Authorization", "Bearer "+h.secret)
+	}
+
+	resp, err := h.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't call gateway %s", path)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			zap.L().Error("can't close gateway response body", zap.Error(err))
+		}
+	}()
+
+	var res gatewayResponse
+	if err = json.NewDecoder(resp.Body).Decode(&res); err != nil && resp.StatusCode == http.StatusOK {
+		return nil, errors.Wrap(err, "can't decode gateway response")
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return &res, nil
+	case http.StatusPaymentRequired:
+		return nil, withReason(ErrDeclined, res.Reason)
+	case http.StatusNotFound:
+		return nil, withReason(ErrUnknownPayment, res.Reason)
+	default:
+		return nil, errors.Errorf("gateway answered %s to %s: %s", resp.Status, path, res.Reason)
+	}
+}
+
+func withReason(err error, reason string) error {
+	if reason == "" {
+		return err
+	}
+
+	return errors.Wrap(err, reason)
+}
+
+// reason is the message of the error without the message of its cause.
+func reason(err error) string {
+	cause := errors.Cause(err)
+	if err == cause {
+		return ""
+	}
+
+	return strings.TrimSuffix(err.Error(), ": "+cause.Error())
+}
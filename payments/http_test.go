@@ -0,0 +1,77 @@
+package payments
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestHTTP_Stub(t *testing.T) {
+	server := httptest.NewServer(StubHandler(NewFake(0), "secret"))
+	defer server.Close()
+
+	ctx := context.Background()
+	gateway := NewHTTP(server.URL+"/", "secret", time.Second)
+
+	reference, err := gateway.Authorize(ctx, Charge{OrderID: 3, Amount: 49.99, Token: "tok_visa"})
+	if err != nil {
+		t.Fatalf("Authorize() error = %v", err)
+	}
+
+	if reference != "fake_3_1" {
+		t.Errorf("Authorize() got = %v, want fake_3_1", reference)
+	}
+
+	_, err = gateway.Authorize(ctx, Charge{OrderID: 3, Amount: 49.99, Token: TokenInsufficientFunds})
+	if errors.Cause(err) != ErrDeclined {
+		t.Errorf("Authorize() error = %v, want ErrDeclined", err)
+	}
+
+	if err != nil && err.Error() != "insufficient funds: payment declined" {
+		t.Errorf("Authorize() error = %v, want the decline reason", err)
+	}
+
+	if err = gateway.Capture(ctx, reference, 49.99); err != nil {
+		t.Errorf("Capture() error = %v", err)
+	}
+
+	if err = gateway.Capture(ctx, "fake_3_99", 49.99); errors.Cause(err) != ErrUnknownPayment {
+		t.Errorf("Capture() of unknown payment error = %v, want ErrUnknownPayment", err)
+	}
+
+	if err = gateway.Refund(ctx, reference, "refund_1", 100); err == nil {
+		t.Error("Refund() over captured amount, want error")
+	}
+
+	if err = gateway.Refund(ctx, reference, "refund_2", 49.99); err != nil {
+		t.Errorf("Refund() error = %v", err)
+	}
+
+	if err = gateway.Refund(ctx, reference, "refund_2", 49.99); err != nil {
+		t.Errorf("Refund() repeated error = %v", err)
+	}
+
+	stranger := NewHTTP(server.URL, "wrong", time.Second)
+	if _, err = stranger.Authorize(ctx, Charge{OrderID: 3, Amount: 49.99, Token: "tok_visa"}); err == nil {
+		t.Error("Authorize() with wrong secret, want error")
+	}
+}
+
+func TestHTTP_Timeout(t *testing.T) {
+	server := httptest.NewServer(StubHandler(NewFake(time.Second), ""))
+	defer server.Close()
+
+	gateway := NewHTTP(server.URL, "", 50*time.Millisecond)
+
+	_, err := gateway.Authorize(context.Background(), Charge{OrderID: 3, Amount: 49.99, Token: "tok_visa"})
+	if err == nil {
+		t.Error("Authorize() of slow gateway, want error")
+	}
+
+	if errors.Cause(err) == ErrDeclined {
+		t.Errorf("Authorize() error = %v, slow gateway isn't a decline", err)
+	}
+}
@@ -0,0 +1,59 @@
+package payments
+
+import (
+	"context"
+
+	"github.com/IamStubborN/petstore/config"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// Providers wrap these errors, ErrDeclined means the payment was
+// refused by the bank, other errors mean the payment state is unknown.
+var (
+	ErrDeclined       = errors.New("payment declined")
+	ErrUnknownPayment = errors.New("unknown payment")
+)
+
+// PaymentProvider charges customers in two steps, an authorization holds
+// the amount and a capture takes it, a captured amount can be refunded.
+// Payments are identified by the reference returned from Authorize.
+// Refunds with the same key are made once, so a refund that failed
+// with an unknown state can be repeated.
+type PaymentProvider interface {
+	Name() string
+	Authorize(ctx context.Context, charge Charge) (string, error)
+	Capture(ctx context.Context, reference string, amount float64) error
+	Refund(ctx context.Context, reference, key string, amount float64) error
+}
+
+// Charge asks to hold the amount of the order on the card behind the token.
+type Charge struct {
+	OrderID int64   `json:"order_id"`
+	Amount  float64 `json:"amount"`
+	Token   string  `json:"token"`
+}
+
+var provider PaymentProvider
+
+func InitProvider(cfg *config.Config) {
+	switch cfg.Payments.Provider {
+	case "fake":
+		provider = NewFake(cfg.Payments.Delay)
+	case "http":
+		provider = NewHTTP(cfg.Payments.Endpoint, cfg.Payments.Secret, cfg.Payments.Timeout)
+	default:
+		zap.L().Fatal("wrong payment provider")
+	}
+}
+
+func GetProvider() PaymentProvider {
+	return provider
+}
+
+// IsFinal tells whether the error is the final answer of the provider,
+// after other errors the state is unknown and the call may be repeated.
+func IsFinal(err error) bool {
+	cause := errors.Cause(err)
+	return cause == ErrDeclined || cause == ErrUnknownPayment
+}
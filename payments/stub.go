@@ -0,0 +1,95 @@
+package payments
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// StubHandler serves the gateway protocol of the HTTP provider with
+// another provider behind it, usually Fake, so the HTTP provider can
+// be pointed at a local stub server. Requests must carry the secret
+// when it's set.
+func StubHandler(p PaymentProvider, secret string) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/authorize", stub(secret, func(ctx context.Context, body []byte) (*gatewayResponse, error) {
+		var charge Charge
+		if err := json.Unmarshal(body, &charge); err != nil {
+			return nil, err
+		}
+
+		reference, err := p.Authorize(ctx, charge)
+		if err != nil {
+			return nil, err
+		}
+
+		return &gatewayResponse{Reference: reference}, nil
+	}))
+
+	mux.HandleFunc("/capture", stub(secret, func(ctx context.Context, body []byte) (*gatewayResponse, error) {
+		var req gatewayRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			return nil, err
+		}
+
+		return &gatewayResponse{Reference: req.Reference}, p.Capture(ctx, req.Reference, req.Amount)
+	}))
+
+	mux.HandleFunc("/refund", stub(secret, func(ctx context.Context, body []byte) (*gatewayResponse, error) {
+		var req gatewayRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			return nil, err
+		}
+
+		return &gatewayResponse{Reference: req.Reference}, p.Refund(ctx, req.Reference, req.Key, req.Amount)
+	}))
+
+	return mux
+}
+
+type stubCall func(ctx context.Context, body []byte) (*gatewayResponse, error)
+
+func stub(secret string, call stubCall) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeStub(w, http.StatusMethodNotAllowed, &gatewayResponse{Reason: "only POST is allowed"})
+			return
+		}
+
+		if secret != "" && r.Header.Get("Authorization") != "Bearer "+secret {
+			writeStub(w, http.StatusUnauthorized, &gatewayResponse{Reason: "wrong secret"})
+			return
+		}
+
+		var body json.RawMessage
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeStub(w, http.StatusBadRequest, &gatewayResponse{Reason: err.Error()})
+			return
+		}
+
+		res, err := call(r.Context(), body)
+		switch cause := errors.Cause(err); {
+		case err == nil:
+			writeStub(w, http.StatusOK, res)
+		case cause == ErrDeclined:
+			writeStub(w, http.StatusPaymentRequired, &gatewayResponse{Reason: reason(err)})
+		case cause == ErrUnknownPayment:
+			writeStub(w, http.StatusNotFound, &gatewayResponse{Reason: reason(err)})
+		default:
+			writeStub(w, http.StatusBadRequest, &gatewayResponse{Reason: err.Error()})
+		}
+	}
+}
+
+func writeStub(w http.ResponseWriter, code int, res *gatewayResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		zap.L().Error("can't write stub gateway response", zap.Error(err))
+	}
+}
@@ -0,0 +1,258 @@
+package schedule
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// searchYears is how far the times of a cron expression are looked for,
+// a leap day is found even across a century that isn't a leap year.
+const searchYears = 10
+
+// field is the set of values a cron field matches, bit n is the value n.
+type field uint64
+
+func (f field) has(n int) bool {
+	return f&(1<<uint(n)) != 0
+}
+
+type bounds struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	minutes = bounds{name: "minute", min: 0, max: 59}
+	hours   = bounds{name: "hour", min: 0, max: 23}
+	days    = bounds{name: "day of month", min: 1, max: 31}
+	months  = bounds{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// weekdays accepts 7 for Sunday too.
+	weekdays = bounds{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Cron is a standard 5-field cron expression: minute, hour, day of month,
+// month and day of week, the times are in the location of the expression.
+// When both days are restricted a day matching either of them matches.
+type Cron struct {
+	spec     string
+	minute   field
+	hour     field
+	day      field
+	month    field
+	weekday  field
+	anyDay   bool
+	anyWeek  bool
+	location *time.Location
+}
+
+// Parse parses the cron expression in UTC, the location is set by
+// a CRON_TZ= or TZ= prefix, like "CRON_TZ=Europe/Berlin 0 6 * * MON".
+// The @yearly, @monthly, @weekly, @daily and @hourly macros are accepted.
+func Parse(spec string) (*Cron, error) {
+	expr := strings.TrimSpace(spec)
+	location := time.UTC
+	if strings.HasPrefix(expr, "CRON_TZ=") || strings.HasPrefix(expr, "TZ=") {
+		i := strings.IndexAny(expr, " \t")
+		if i < 0 {
+			return nil, errors.Errorf("cron expression has no fields [%s]", spec)
+		}
+
+		zone := expr[strings.Index(expr, "=")+1 : i]
+		var err error
+		if location, err = time.LoadLocation(zone); err != nil {
+			return nil, errors.Wrapf(err, "can't load time zone [%s]", zone)
+		}
+		expr = strings.TrimSpace(expr[i:])
+	}
+
+	if macro, ok := macros[strings.ToLower(expr)]; ok {
+		expr = macro
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, errors.Errorf("cron expression must have 5 fields [%s]", spec)
+	}
+
+	c := &Cron{
+		spec:     spec,
+		anyDay:   strings.HasPrefix(fields[2], "*"),
+		anyWeek:  strings.HasPrefix(fields[4], "*"),
+		location: location,
+	}
+
+	targets := []struct {
+		field  *field
+		bounds bounds
+	}{
+		{&c.minute, minutes}, {&c.hour, hours}, {&c.day, days}, {&c.month, months}, {&c.weekday, weekdays},
+	}
+	for i, target := range targets {
+		var err error
+		if *target.field, err = parseField(fields[i], target.bounds); err != nil {
+			return nil, errors.Wrapf(err, "can't parse cron expression [%s]", spec)
+		}
+	}
+
+	if c.weekday.has(7) {
+		c.weekday |= 1
+	}
+
+	if c.Next(time.Date(1970, 1, 1, 0, 0, 0, 0, location)).IsZero() {
+		return nil, errors.Errorf("cron expression never matches [%s]", spec)
+	}
+
+	return c, nil
+}
+
+func (c *Cron) String() string {
+	return c.spec
+}
+
+// Next returns the first minute after t matching the expression.
+func (c *Cron) Next(t time.Time) time.Time {
+	t = truncate(t.In(c.location)).Add(time.Minute)
+
+	limit := t.Year() + searchYears
+	for t.Year() <= limit {
+		switch {
+		case !c.month.has(int(t.Month())):
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, c.location)
+		case !c.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, c.location)
+		case !c.hour.has(t.Hour()):
+			t = later(t, time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, c.location))
+		case !c.minute.has(t.Minute()):
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+// Prev returns the latest minute at or before t matching the expression.
+func (c *Cron) Prev(t time.Time) time.Time {
+	t = truncate(t.In(c.location))
+
+	limit := t.Year() - searchYears
+	for t.Year() >= limit {
+		switch {
+		case !c.month.has(int(t.Month())):
+			t = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, c.location).Add(-time.Minute)
+		case !c.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, c.location).Add(-time.Minute)
+		case !c.hour.has(t.Hour()):
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, c.location).Add(-time.Minute)
+		case !c.minute.has(t.Minute()):
+			t = t.Add(-time.Minute)
+		default:
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+func (c *Cron) dayMatches(t time.Time) bool {
+	day, weekday := c.day.has(t.Day()), c.weekday.has(int(t.Weekday()))
+	if c.anyDay || c.anyWeek {
+		return day && weekday
+	}
+
+	return day || weekday
+}
+
+// truncate drops the seconds of t, unlike building the minute
+// from the wall clock it keeps the hour turned back in place.
+func truncate(t time.Time) time.Time {
+	return t.Add(-time.Duration(t.Second())*time.Second - time.Duration(t.Nanosecond()))
+}
+
+// later keeps the search going forward when the wall clock is turned back.
+func later(t, next time.Time) time.Time {
+	if !next.After(t) {
+		return t.Add(time.Hour)
+	}
+
+	return next
+}
+
+// parseField parses the comma separated values, ranges and steps of a field.
+func parseField(raw string, b bounds) (field, error) {
+	var f field
+	for _, part := range strings.Split(raw, ",") {
+		step := 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			var err error
+			if step, err = strconv.Atoi(part[i+1:]); err != nil || step <= 0 {
+				return 0, errors.Errorf("wrong step of %s [%s]", b.name, part)
+			}
+			part = part[:i]
+		}
+
+		from, to := b.min, b.max
+		switch {
+		case part == "*":
+		case strings.Contains(part, "-"):
+			i := strings.Index(part, "-")
+			var err error
+			if from, err = b.value(part[:i]); err != nil {
+				return 0, err
+			}
+			if to, err = b.value(part[i+1:]); err != nil {
+				return 0, err
+			}
+			if from > to {
+				return 0, errors.Errorf("wrong range of %s [%s]", b.name, part)
+			}
+		default:
+			var err error
+			if from, err = b.value(part); err != nil {
+				return 0, err
+			}
+			if step == 1 {
+				to = from
+			}
+		}
+
+		for n := from; n <= to; n += step {
+			f |= 1 << uint(n)
+		}
+	}
+
+	return f, nil
+}
+
+func (b bounds) value(raw string) (int, error) {
+	if n, ok := b.names[strings.ToLower(raw)]; ok {
+		return n, nil
+	}
+
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < b.min || n > b.max {
+		return 0, errors.Errorf("%s must be between %d and %d [%s]", b.name, b.min, b.max, raw)
+	}
+
+	return n, nil
+}
@@ -0,0 +1,115 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCron_Next(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatalf("LoadLocation() error = %v", err)
+	}
+	utc := func(y int, m time.Month, d, h, min int) time.Time { return time.Date(y, m, d, h, min, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name string
+		spec string
+		t    time.Time
+		want time.Time
+	}{
+		{name: "every Monday", spec: "0 6 * * MON", t: utc(2019, 9, 7, 10, 0), want: utc(2019, 9, 9, 6, 0)},
+		{name: "Monday at the time", spec: "0 6 * * 1", t: utc(2019, 9, 9, 6, 0), want: utc(2019, 9, 16, 6, 0)},
+		{name: "first of month", spec: "0 0 1 * *", t: utc(2019, 12, 15, 8, 0), want: utc(2020, 1, 1, 0, 0)},
+		{name: "monthly macro", spec: "@monthly", t: utc(2019, 9, 7, 10, 0), want: utc(2019, 10, 1, 0, 0)},
+		{name: "steps", spec: "*/15 9-17 * * *", t: utc(2019, 9, 7, 17, 50), want: utc(2019, 9, 8, 9, 0)},
+		{name: "lists", spec: "5,35 * * * *", t: utc(2019, 9, 7, 10, 5), want: utc(2019, 9, 7, 10, 35)},
+		{name: "seconds are dropped", spec: "* * * * *", t: utc(2019, 9, 7, 10, 5).Add(30 * time.Second),
+			want: utc(2019, 9, 7, 10, 6)},
+		{name: "Sunday as 7", spec: "0 0 * * 7", t: utc(2019, 9, 7, 10, 0), want: utc(2019, 9, 8, 0, 0)},
+		{name: "either day", spec: "0 0 13 * FRI", t: utc(2019, 9, 7, 0, 0), want: utc(2019, 9, 13, 0, 0)},
+		{name: "either day weekday first", spec: "0 0 20 * FRI", t: utc(2019, 9, 13, 0, 0), want: utc(2019, 9, 20, 0, 0)},
+		{name: "leap day", spec: "0 0 29 FEB *", t: utc(2019, 3, 1, 0, 0), want: utc(2020, 2, 29, 0, 0)},
+		{name: "time zone", spec: "CRON_TZ=Europe/Berlin 0 6 * * *", t: utc(2019, 9, 7, 10, 0),
+			want: utc(2019, 9, 8, 4, 0)},
+		{name: "time zone in winter", spec: "TZ=Europe/Berlin 0 6 * * *", t: utc(2019, 12, 7, 10, 0),
+			want: utc(2019, 12, 8, 5, 0)},
+		{name: "clock turned forward", spec: "CRON_TZ=Europe/Berlin 30 * * * *",
+			t: time.Date(2019, 3, 31, 1, 45, 0, 0, berlin), want: time.Date(2019, 3, 31, 3, 30, 0, 0, berlin)},
+		{name: "clock turned back", spec: "CRON_TZ=Europe/Berlin 30 2 * * *", t: utc(2019, 10, 27, 0, 35),
+			want: utc(2019, 10, 27, 1, 30)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := Parse(tt.spec)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			got := c.Next(tt.t)
+			assert.True(t, tt.want.Equal(got), "Next() got = %v, want %v", got, tt.want)
+		})
+	}
+}
+
+func TestCron_Prev(t *testing.T) {
+	utc := func(y int, m time.Month, d, h, min int) time.Time { return time.Date(y, m, d, h, min, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name string
+		spec string
+		t    time.Time
+		want time.Time
+	}{
+		{name: "every Monday", spec: "0 6 * * MON", t: utc(2019, 9, 7, 10, 0), want: utc(2019, 9, 2, 6, 0)},
+		{name: "at the time", spec: "0 6 * * MON", t: utc(2019, 9, 9, 6, 0), want: utc(2019, 9, 9, 6, 0)},
+		{name: "first of month", spec: "0 0 1 * *", t: utc(2020, 1, 1, 0, 0).Add(-time.Second),
+			want: utc(2019, 12, 1, 0, 0)},
+		{name: "steps", spec: "*/15 9-17 * * *", t: utc(2019, 9, 8, 8, 0), want: utc(2019, 9, 7, 17, 45)},
+		{name: "leap day", spec: "0 0 29 2 *", t: utc(2023, 3, 1, 0, 0), want: utc(2020, 2, 29, 0, 0)},
+		{name: "time zone", spec: "CRON_TZ=Europe/Berlin 0 6 * * *", t: utc(2019, 9, 7, 3, 0),
+			want: utc(2019, 9, 6, 4, 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := Parse(tt.spec)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			got := c.Prev(tt.t)
+			assert.True(t, tt.want.Equal(got), "Prev() got = %v, want %v", got, tt.want)
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		wantErr bool
+	}{
+		{name: "names", spec: "0 6 * jan-mar mon-fri", wantErr: false},
+		{name: "macro", spec: "@daily", wantErr: false},
+		{name: "too few fields", spec: "0 6 * *", wantErr: true},
+		{name: "too many fields", spec: "0 0 6 * * *", wantErr: true},
+		{name: "minute out of range", spec: "60 * * * *", wantErr: true},
+		{name: "day out of range", spec: "0 0 0 * *", wantErr: true},
+		{name: "wrong name", spec: "0 0 * * someday", wantErr: true},
+		{name: "reversed range", spec: "0 10-5 * * *", wantErr: true},
+		{name: "zero step", spec: "*/0 * * * *", wantErr: true},
+		{name: "never matches", spec: "0 0 30 2 *", wantErr: true},
+		{name: "unknown zone", spec: "CRON_TZ=Mars/Olympus 0 6 * * *", wantErr: true},
+		{name: "zone without fields", spec: "CRON_TZ=UTC", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+// Package schedule tells the workers when to run, a schedule is either
+// a cron expression or a fixed interval from a time of the day.
+package schedule
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Schedule returns the times a job runs at, both return
+// the zero time when the job doesn't run anymore.
+type Schedule interface {
+	// Next returns the first time after t.
+	Next(t time.Time) time.Time
+	// Prev returns the latest time at or before t.
+	Prev(t time.Time) time.Time
+}
+
+// New returns the cron schedule of spec, an empty spec
+// falls back to the interval of every from the time of the day at.
+func New(spec string, at time.Time, every time.Duration) (Schedule, error) {
+	if spec != "" {
+		return Parse(spec)
+	}
+
+	if every <= 0 {
+		return nil, errors.Errorf("interval must be positive [%s]", every)
+	}
+
+	return NewInterval(at, every), nil
+}
+
+// Interval runs every freq, the runs are aligned
+// to the time of the day of its anchor in UTC.
+type Interval struct {
+	anchor time.Time
+	freq   time.Duration
+}
+
+func NewInterval(at time.Time, freq time.Duration) Interval {
+	return Interval{
+		anchor: time.Date(1970, 1, 1, at.Hour(), at.Minute(), 0, 0, time.UTC),
+		freq:   freq,
+	}
+}
+
+func (s Interval) Prev(t time.Time) time.Time {
+	since := t.Sub(s.anchor)
+	prev := s.anchor.Add(since - since%s.freq)
+	if prev.After(t) {
+		prev = prev.Add(-s.freq)
+	}
+
+	return prev
+}
+
+func (s Interval) Next(t time.Time) time.Time {
+	return s.Prev(t).Add(s.freq)
+}
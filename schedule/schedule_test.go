@@ -0,0 +1,53 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterval(t *testing.T) {
+	at, _ := time.Parse("15:04", "12:30")
+	day := func(d, h, m int) time.Time { return time.Date(2019, 9, d, h, m, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name     string
+		freq     time.Duration
+		t        time.Time
+		wantPrev time.Time
+		wantNext time.Time
+	}{
+		{name: "daily before the time", freq: 24 * time.Hour, t: day(9, 9, 0),
+			wantPrev: day(8, 12, 30), wantNext: day(9, 12, 30)},
+		{name: "daily at the time", freq: 24 * time.Hour, t: day(9, 12, 30),
+			wantPrev: day(9, 12, 30), wantNext: day(10, 12, 30)},
+		{name: "hourly", freq: time.Hour, t: day(9, 9, 0),
+			wantPrev: day(9, 8, 30), wantNext: day(9, 9, 30)},
+		{name: "before anchor", freq: 24 * time.Hour, t: time.Date(1969, 12, 31, 9, 0, 0, 0, time.UTC),
+			wantPrev: time.Date(1969, 12, 30, 12, 30, 0, 0, time.UTC),
+			wantNext: time.Date(1969, 12, 31, 12, 30, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewInterval(at, tt.freq)
+			assert.Equal(t, tt.wantPrev, s.Prev(tt.t))
+			assert.Equal(t, tt.wantNext, s.Next(tt.t))
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	at, _ := time.Parse("15:04", "12:00")
+
+	s, err := New("0 6 * * MON", at, 24*time.Hour)
+	assert.NoError(t, err)
+	assert.IsType(t, &Cron{}, s, "the cron expression comes first")
+
+	s, err = New("", at, 24*time.Hour)
+	assert.NoError(t, err)
+	assert.IsType(t, Interval{}, s, "no cron expression falls back to the interval")
+
+	_, err = New("", at, 0)
+	assert.Error(t, err)
+}
@@ -6,21 +6,72 @@ import (
 	"github.com/pkg/errors"
 )
 
+// invoice lists every item of the orders, the order number, user and
+// ship date are written on the first line of each order only.
+// Refunds are listed as lines with a negative price and the refund reason
+// in the category column.
 var invoice = `
 <img src="https://redocly.github.io/redoc/petstore-logo.png" alt="banner" style = zoom:50% />
 
 # Invoice orders
-### from {{.FromDate}} to {{.ToDate}}
+### from {{.From}} to {{.To}}
 
-| № | User | Pet | Category | Ship Date | Quantity | Price |
-| - | ---- | --- | -------- | ----------- | -----  | ----- |
-{{range $i := .InvItems}}|{{$i.ID}}|{{$i.User}}|{{$i.Pet}}|{{$i.Category}}|{{$i.ShipDate}}|{{$i.Quantity}}|$ {{$i.Price}}|
+| № | User | Pet | Category | Ship Date | Quantity | Price | Discount |
+| - | ---- | --- | -------- | ----------- | -----  | ----- | -------- |
+{{range $i := .Items}}{{if eq $i.Line 1}}|{{$i.ID}}|{{$i.User}}{{else}}||{{end}}|{{$i.Pet}}|{{$i.Category}}|{{if eq $i.Line 1}}{{$i.ShipDate}}{{end}}|{{$i.Quantity}}|$ {{$i.Price}}|$ {{$i.Discount}}|
 {{end}}
 
 <p style="text-align: end; margin-right: 5%">
-  <strong>Total price: $ {{.TotalPrice}}</strong>
+  Total price: $ {{printf "%.2f" .TotalPrice}}<br>
+  Discount: $ {{printf "%.2f" .TotalDiscount}}<br>
+  Refunds: $ {{printf "%.2f" .TotalRefund}}<br>
+  <strong>Total with discounts and refunds: $ {{printf "%.2f" .Total}}</strong>
 </p>`
 
+// invoiceHTML is the invoice as a standalone page, the styles are inline,
+// so the page is read without the files next to it.
+var invoiceHTML = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Invoice orders from {{.From}} to {{.To}}</title>
+<style>
+  body { font-family: Helvetica, Arial, sans-serif; margin: 2em 5%; color: #222; }
+  table { width: 100%; border-collapse: collapse; }
+  th, td { padding: 4px 8px; border-bottom: 1px solid #ddd; text-align: left; }
+  td.number, th.number { text-align: right; }
+  tr.refund td { color: #a33; }
+  .totals { text-align: right; margin-top: 1.5em; line-height: 1.6; }
+</style>
+</head>
+<body>
+<h1>Invoice orders</h1>
+<h3>from {{.From}} to {{.To}}</h3>
+<table>
+  <thead>
+    <tr><th>№</th><th>User</th><th>Pet</th><th>Category</th><th>Ship Date</th>
+      <th class="number">Quantity</th><th class="number">Price</th><th class="number">Discount</th></tr>
+  </thead>
+  <tbody>
+{{- range $i := .Items}}
+    <tr{{if $i.Refund}} class="refund"{{end}}>
+      {{- if eq $i.Line 1}}<td>{{$i.ID}}</td><td>{{$i.User}}</td>{{else}}<td></td><td></td>{{end -}}
+      <td>{{$i.Pet}}</td><td>{{$i.Category}}</td><td>{{if eq $i.Line 1}}{{$i.ShipDate}}{{end}}</td>
+      <td class="number">{{$i.Quantity}}</td><td class="number">$ {{printf "%.2f" $i.Price}}</td>
+      <td class="number">$ {{printf "%.2f" $i.Discount}}</td></tr>
+{{- end}}
+  </tbody>
+</table>
+<p class="totals">
+  Total price: $ {{printf "%.2f" .TotalPrice}}<br>
+  Discount: $ {{printf "%.2f" .TotalDiscount}}<br>
+  Refunds: $ {{printf "%.2f" .TotalRefund}}<br>
+  <strong>Total with discounts and refunds: $ {{printf "%.2f" .Total}}</strong>
+</p>
+</body>
+</html>
+`
+
 func GetInvoiceTemplate() (*template.Template, error) {
 	temp, err := template.New("invoice").Parse(invoice)
 	if err != nil {
@@ -29,3 +80,12 @@ func GetInvoiceTemplate() (*template.Template, error) {
 
 	return temp, nil
 }
+
+func GetInvoiceHTMLTemplate() (*template.Template, error) {
+	temp, err := template.New("invoice.html").Parse(invoiceHTML)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't parse template file")
+	}
+
+	return temp, nil
+}
@@ -0,0 +1,76 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/IamStubborN/petstore/db/models"
+	"github.com/mailru/easyjson"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// Client posts the events of deliveries to the webhook URLs. The body is
+// the outbox event JSON, the headers carry the event id and type, the
+// delivery id and the signature. Any answer other than 2xx is a failure.
+type Client struct {
+	client *http.Client
+	now    func() time.Time
+}
+
+func NewClient(timeout time.Duration) *Client {
+	return &Client{
+		client: &http.Client{Timeout: timeout},
+		now:    time.Now,
+	}
+}
+
+// Deliver posts the event of the delivery and records the answer code in it.
+func (c *Client) Deliver(ctx context.Context, delivery *models.WebhookDelivery) error {
+	delivery.ResponseCode = 0
+
+	body, err := easyjson.Marshal(delivery.Body())
+	if err != nil {
+		return errors.Wrapf(err, "can't marshal event № %d", delivery.EventID)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, delivery.URL, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "can't create webhook request")
+	}
+
+	timestamp := c.now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEventID, strconv.FormatInt(delivery.EventID, 10))
+	req.Header.Set(HeaderEventType, delivery.Event)
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(delivery.Secret, timestamp, body))
+
+	resp, err := c.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return errors.Wrapf(err, "can't post delivery № %d", delivery.ID)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			zap.L().Error("can't close webhook response body", zap.Error(err))
+		}
+	}()
+
+	// the body is drained, so the connection can be reused
+	if _, err = io.Copy(ioutil.Discard, resp.Body); err != nil {
+		zap.L().Error("can't read webhook response body", zap.Error(err))
+	}
+
+	delivery.ResponseCode = resp.StatusCode
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return errors.Errorf("webhook answered %s to delivery № %d", resp.Status, delivery.ID)
+	}
+
+	return nil
+}
@@ -0,0 +1,113 @@
+package webhooks
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/IamStubborN/petstore/db/models"
+)
+
+func TestClient_Deliver(t *testing.T) {
+	const secret = "partner-secret-0001"
+	now := time.Date(2020, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		status   int
+		secret   string
+		wantCode int
+		wantErr  bool
+	}{
+		{name: "delivered", status: http.StatusOK, secret: secret, wantCode: http.StatusOK},
+		{name: "accepted", status: http.StatusNoContent, secret: secret, wantCode: http.StatusNoContent},
+		{name: "wrong secret", status: http.StatusOK, secret: "another-secret-0001",
+			wantCode: http.StatusUnauthorized, wantErr: true},
+		{name: "gone", status: http.StatusGone, secret: secret, wantCode: http.StatusGone, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotEvent, gotDelivery string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := ioutil.ReadAll(r.Body)
+				timestamp, _ := strconv.ParseInt(r.Header.Get(HeaderTimestamp), 10, 64)
+				if !Verify(secret, r.Header.Get(HeaderSignature), timestamp, body) {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+
+				gotEvent = r.Header.Get(HeaderEventID) + " " + r.Header.Get(HeaderEventType)
+				gotDelivery = r.Header.Get(HeaderDelivery)
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			client := NewClient(time.Second)
+			client.now = func() time.Time { return now }
+
+			delivery := &models.WebhookDelivery{
+				ID:        3,
+				WebhookID: 1,
+				EventID:   7,
+				Event:     models.EventOrderDelivered,
+				Aggregate: models.AggregateOrder,
+				Key:       "1",
+				Payload:   []byte(`{"id":1}`),
+				URL:       server.URL,
+				Secret:    tt.secret,
+			}
+
+			err := client.Deliver(context.Background(), delivery)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Deliver() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if delivery.ResponseCode != tt.wantCode {
+				t.Errorf("Deliver() response code = %d, want %d", delivery.ResponseCode, tt.wantCode)
+			}
+
+			if tt.wantCode != http.StatusUnauthorized && (gotEvent != "7 order.delivered" || gotDelivery != "3") {
+				t.Errorf("Deliver() headers = %s, delivery %s", gotEvent, gotDelivery)
+			}
+		})
+	}
+}
+
+func TestClient_DeliverUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Close()
+
+	delivery := &models.WebhookDelivery{ID: 1, URL: server.URL, Secret: "partner-secret-0001"}
+	if err := NewClient(time.Second).Deliver(context.Background(), delivery); err == nil {
+		t.Error("Deliver() to closed server, want error")
+	}
+
+	if delivery.ResponseCode != 0 {
+		t.Errorf("Deliver() response code = %d, want 0", delivery.ResponseCode)
+	}
+}
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"id":7}`)
+	signature := Sign("secret", 1583064000, body)
+
+	if !Verify("secret", signature, 1583064000, body) {
+		t.Error("Verify() of own signature = false")
+	}
+
+	if Verify("secret", signature, 1583064001, body) {
+		t.Error("Verify() with other timestamp = true")
+	}
+
+	if Verify("other", signature, 1583064000, body) {
+		t.Error("Verify() with other secret = true")
+	}
+
+	if Verify("secret", signature, 1583064000, []byte(`{"id":8}`)) {
+		t.Error("Verify() of other body = true")
+	}
+}
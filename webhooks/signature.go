@@ -0,0 +1,36 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+)
+
+// Deliveries are signed with HMAC-SHA256 of the timestamp and the body
+// joined with a dot, the key is the webhook secret. Receivers check the
+// signature and reject old timestamps, so deliveries can't be replayed.
+const (
+	HeaderSignature = "X-Petstore-Signature"
+	HeaderTimestamp = "X-Petstore-Timestamp"
+	HeaderEventID   = "X-Event-ID"
+	HeaderEventType = "X-Event-Type"
+	HeaderDelivery  = "X-Petstore-Delivery"
+
+	signaturePrefix = "sha256="
+)
+
+// Sign returns the signature header value of the body sent at the timestamp.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify tells whether the signature is the one of the body sent at the timestamp.
+func Verify(secret, signature string, timestamp int64, body []byte) bool {
+	return hmac.Equal([]byte(signature), []byte(Sign(secret, timestamp, body)))
+}
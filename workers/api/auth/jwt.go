@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"context"
 	"crypto/rsa"
 	"errors"
 	"io/ioutil"
@@ -8,6 +9,7 @@ import (
 	"time"
 
 	"github.com/IamStubborN/petstore/config"
+	"github.com/IamStubborN/petstore/db/models"
 	"github.com/dgrijalva/jwt-go"
 	"github.com/patrickmn/go-cache"
 
@@ -22,9 +24,12 @@ type Claims struct {
 type Session struct {
 	SessionID    string
 	UserID       int64
+	UserStatus   int64
 	AllowMethods string
 }
 
+type sessionKey struct{}
+
 type JWTAuthService struct {
 	publicKey  *rsa.PublicKey
 	privateKey *rsa.PrivateKey
@@ -34,6 +39,21 @@ type JWTAuthService struct {
 
 var jwtAuth JWTAuthService
 
+func (s Session) IsAdmin() bool {
+	return s.UserStatus == models.UserStatusAdmin
+}
+
+// NewContext returns a copy of ctx that carries the session.
+func NewContext(ctx context.Context, session Session) context.Context {
+	return context.WithValue(ctx, sessionKey{}, session)
+}
+
+// FromContext returns the session stored in ctx by NewContext.
+func FromContext(ctx context.Context) (Session, bool) {
+	session, ok := ctx.Value(sessionKey{}).(Session)
+	return session, ok
+}
+
 func InitJWTAuth(cfg *config.Config) {
 	generatePEMKeys(cfg.JWT.KeysPath)
 	jwtAuth = JWTAuthService{
@@ -81,7 +101,7 @@ func loadPrivateKey(pathToFolder string) *rsa.PrivateKey {
 	return privateKey
 }
 
-func GenerateToken(sessionID string, userID int64, allowMethods string) (string, error) {
+func GenerateToken(sessionID string, userID, userStatus int64, allowMethods string) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodRS256, Claims{
 		StandardClaims: &jwt.StandardClaims{
 			ExpiresAt: time.Now().Add(jwtAuth.ttl).Unix(),
@@ -89,6 +109,7 @@ func GenerateToken(sessionID string, userID int64, allowMethods string) (string,
 		Session: Session{
 			SessionID:    sessionID,
 			UserID:       userID,
+			UserStatus:   userStatus,
 			AllowMethods: allowMethods,
 		},
 	})
@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/IamStubborN/petstore/db"
+	"github.com/IamStubborN/petstore/db/models"
+	"github.com/mailru/easyjson"
+	"github.com/pkg/errors"
+)
+
+// getSales reports units and revenue per category and period.
+func getSales(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := genContext(r)
+	defer cancel()
+
+	filter, err := readAnalyticsFilter(r)
+	if err != nil {
+		respond(w, err, http.StatusBadRequest, "invalid filter value")
+		return
+	}
+
+	sales, err := db.GetAnalyticsDI().GetSales(ctx, filter)
+	if err != nil {
+		respond(w, err, http.StatusInternalServerError, "providers error")
+		return
+	}
+
+	writeAnalytics(w, sales)
+}
+
+// getTopCategories ranks the categories by revenue.
+func getTopCategories(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := genContext(r)
+	defer cancel()
+
+	filter, err := readAnalyticsFilter(r)
+	if err != nil {
+		respond(w, err, http.StatusBadRequest, "invalid filter value")
+		return
+	}
+
+	sales, err := db.GetAnalyticsDI().GetTopCategories(ctx, filter)
+	if err != nil {
+		respond(w, err, http.StatusInternalServerError, "providers error")
+		return
+	}
+
+	writeAnalytics(w, sales)
+}
+
+// getOrderValue reports the number of sold orders and their average value.
+func getOrderValue(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := genContext(r)
+	defer cancel()
+
+	filter, err := readAnalyticsFilter(r)
+	if err != nil {
+		respond(w, err, http.StatusBadRequest, "invalid filter value")
+		return
+	}
+
+	value, err := db.GetAnalyticsDI().GetOrderValue(ctx, filter)
+	if err != nil {
+		respond(w, err, http.StatusInternalServerError, "providers error")
+		return
+	}
+
+	writeAnalytics(w, value)
+}
+
+// getUserValues ranks the customers by the revenue of their orders.
+func getUserValues(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := genContext(r)
+	defer cancel()
+
+	filter, err := readAnalyticsFilter(r)
+	if err != nil {
+		respond(w, err, http.StatusBadRequest, "invalid filter value")
+		return
+	}
+
+	values, err := db.GetAnalyticsDI().GetUserValues(ctx, filter)
+	if err != nil {
+		respond(w, err, http.StatusInternalServerError, "providers error")
+		return
+	}
+
+	writeAnalytics(w, values)
+}
+
+// readAnalyticsFilter reads the from and to dates, the period and the limit
+// from the query, plain dates cover whole days like ship dates of orders.
+func readAnalyticsFilter(r *http.Request) (models.AnalyticsFilter, error) {
+	query := r.URL.Query()
+	filter := models.AnalyticsFilter{Period: query.Get("period")}
+
+	var err error
+	if filter.From, err = parseShipDate(query.Get("from"), false); err != nil {
+		return filter, err
+	}
+
+	if filter.To, err = parseShipDate(query.Get("to"), true); err != nil {
+		return filter, err
+	}
+
+	if raw := query.Get("limit"); raw != "" {
+		if filter.Limit, err = strconv.Atoi(raw); err != nil {
+			return filter, errors.Wrapf(err, "can't cast limit to int [%s]", raw)
+		}
+	}
+
+	return filter, filter.Validate()
+}
+
+func writeAnalytics(w http.ResponseWriter, report easyjson.Marshaler) {
+	data, err := easyjson.Marshal(report)
+	if err != nil {
+		respond(w, errors.Wrap(err, "can't marshal data"),
+			http.StatusInternalServerError, "providers error")
+		return
+	}
+
+	if _, err := w.Write(data); err != nil {
+		respond(w, errors.Wrap(err, "can't write response"),
+			http.StatusInternalServerError, "providers error")
+		return
+	}
+}
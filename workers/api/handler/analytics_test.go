@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/IamStubborN/petstore/db"
+	"github.com/IamStubborN/petstore/db/models"
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_analytics(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		wantCode int
+		wantBody string
+	}{
+		{name: "daily sales", path: "/sales?from=2019-09-01&to=2019-09-30",
+			wantCode: http.StatusOK, wantBody: `[` +
+				`{"period":"2019-09-06","category_id":2,"category":"Dog","units":1,"revenue":49.99},` +
+				`{"period":"2019-09-07","category_id":1,"category":"Cat","units":1,"revenue":35},` +
+				`{"period":"2019-09-07","category_id":2,"category":"Dog","units":2,"revenue":99.98}]`},
+		{name: "weekly sales", path: "/sales?from=2019-09-01&to=2019-09-30&period=week",
+			wantCode: http.StatusOK, wantBody: `[` +
+				`{"period":"2019-09-02","category_id":1,"category":"Cat","units":1,"revenue":35},` +
+				`{"period":"2019-09-02","category_id":2,"category":"Dog","units":3,"revenue":149.97}]`},
+		{name: "sales out of range", path: "/sales?from=2019-10-01&to=2019-10-31",
+			wantCode: http.StatusOK, wantBody: `[]`},
+		{name: "unknown period", path: "/sales?from=2019-09-01&to=2019-09-30&period=year",
+			wantCode: http.StatusBadRequest},
+		{name: "open range", path: "/sales?from=2019-09-01",
+			wantCode: http.StatusBadRequest},
+		{name: "top category", path: "/categories?from=2019-09-01&to=2019-09-30&limit=1",
+			wantCode: http.StatusOK, wantBody: `[{"category_id":2,"category":"Dog","units":3,"revenue":149.97}]`},
+		{name: "bad limit", path: "/categories?from=2019-09-01&to=2019-09-30&limit=many",
+			wantCode: http.StatusBadRequest},
+		{name: "average order value", path: "/orders?from=2019-09-01&to=2019-09-06",
+			wantCode: http.StatusOK, wantBody: `{"orders":1,"revenue":49.99,"average":49.99}`},
+		{name: "no orders", path: "/orders?from=2019-10-01&to=2019-10-31",
+			wantCode: http.StatusOK, wantBody: `{"orders":0,"revenue":0,"average":0}`},
+		{name: "user values", path: "/users?from=2019-09-01T00:00:00Z&to=2019-09-30T00:00:00Z",
+			wantCode: http.StatusOK, wantBody: `[` +
+				`{"user_id":1,"user_name":"admin","orders":1,"revenue":134.98},` +
+				`{"user_id":2,"user_name":"customer","orders":1,"revenue":49.99}]`},
+	}
+
+	r := chi.NewRouter()
+	r.Get("/api/v2/store/analytics/sales", getSales)
+	r.Get("/api/v2/store/analytics/categories", getTopCategories)
+	r.Get("/api/v2/store/analytics/orders", getOrderValue)
+	r.Get("/api/v2/store/analytics/users", getUserValues)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request, err := http.NewRequest("GET", "/api/v2/store/analytics"+tt.path, nil)
+			if err != nil {
+				log.Println(err)
+			}
+
+			request = addToCtxWriteTimeout(request)
+			response := httptest.NewRecorder()
+
+			r.ServeHTTP(response, request)
+			assert.Equal(t, tt.wantCode, response.Code)
+			if tt.wantBody != "" {
+				assert.Equal(t, tt.wantBody, response.Body.String())
+			}
+		})
+	}
+}
+
+// TestHandler_analyticsRefunds checks that completed refunds are taken
+// from the revenue of their orders and pending ones aren't yet.
+func TestHandler_analyticsRefunds(t *testing.T) {
+	resetStock()
+
+	ctx := context.Background()
+	refunds := []struct {
+		orderID   int64
+		amount    float64
+		completed bool
+	}{
+		{orderID: 3, amount: 20, completed: true},
+		{orderID: 2, amount: 10, completed: false},
+	}
+	for _, tt := range refunds {
+		payment, err := db.GetPaymentDI().CreatePayment(ctx, &models.Payment{OrderID: tt.orderID,
+			Provider: models.PaymentProviderManual, Amount: 200, Status: models.PaymentStatusPending})
+		assert.NoError(t, err)
+
+		for _, status := range []string{models.PaymentStatusAuthorized, models.PaymentStatusCaptured} {
+			payment.Status = status
+			assert.NoError(t, db.GetPaymentDI().UpdatePayment(ctx, payment))
+		}
+
+		refund, err := db.GetRefundDI().ReserveRefund(ctx, &models.Refund{OrderID: tt.orderID,
+			PaymentID: payment.ID, Amount: tt.amount, Reason: "damaged cage"})
+		assert.NoError(t, err)
+
+		if tt.completed {
+			refund.Complete(time.Now().UTC())
+			assert.NoError(t, db.GetRefundDI().SaveRefund(ctx, refund))
+		}
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		wantBody string
+	}{
+		{name: "daily sales", path: "/sales?from=2019-09-07&to=2019-09-07",
+			wantBody: `[` +
+				`{"period":"2019-09-07","category_id":1,"category":"Cat","units":1,"revenue":29.81},` +
+				`{"period":"2019-09-07","category_id":2,"category":"Dog","units":2,"revenue":85.17}]`},
+		{name: "average order value", path: "/orders?from=2019-09-01&to=2019-09-30",
+			wantBody: `{"orders":2,"revenue":164.97,"average":82.49}`},
+		{name: "user values", path: "/users?from=2019-09-01&to=2019-09-30",
+			wantBody: `[` +
+				`{"user_id":1,"user_name":"admin","orders":1,"revenue":114.98},` +
+				`{"user_id":2,"user_name":"customer","orders":1,"revenue":49.99}]`},
+	}
+
+	r := chi.NewRouter()
+	r.Get("/api/v2/store/analytics/sales", getSales)
+	r.Get("/api/v2/store/analytics/orders", getOrderValue)
+	r.Get("/api/v2/store/analytics/users", getUserValues)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request, err := http.NewRequest("GET", "/api/v2/store/analytics"+tt.path, nil)
+			if err != nil {
+				log.Println(err)
+			}
+
+			request = addToCtxWriteTimeout(request)
+			response := httptest.NewRecorder()
+
+			r.ServeHTTP(response, request)
+			assert.Equal(t, http.StatusOK, response.Code)
+			assert.Equal(t, tt.wantBody, response.Body.String())
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package handler
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/IamStubborN/petstore/db"
+	"github.com/IamStubborN/petstore/db/models"
+	"github.com/pkg/errors"
+	"gopkg.in/validator.v2"
+)
+
+// getCart shows the cart of the user making the request.
+func getCart(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := genContext(r)
+	defer cancel()
+
+	cartDI := db.GetCartDI()
+	cart, err := cartDI.GetCart(ctx, actorID(r))
+	if err != nil {
+		respond(w, err, http.StatusInternalServerError, "providers error")
+		return
+	}
+
+	writeCart(w, cart)
+}
+
+func addCartItem(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := genContext(r)
+	defer cancel()
+
+	item, err := readCartItem(r)
+	if err != nil {
+		respond(w, err, http.StatusBadRequest, "invalid input")
+		return
+	}
+
+	cartDI := db.GetCartDI()
+	cart, err := cartDI.AddCartItem(ctx, actorID(r), item)
+	if err != nil {
+		respond(w, err, errorCode(err, http.StatusBadRequest), "can't add pet to cart")
+		return
+	}
+
+	writeCart(w, cart)
+}
+
+func removeCartItem(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := genContext(r)
+	defer cancel()
+
+	slug := strings.TrimPrefix(r.URL.Path, "/api/v2/store/cart/")
+	petID, err := strconv.ParseInt(slug, 10, 64)
+	if err != nil {
+		respond(w, errors.Wrapf(err, "can't cast slug to int [%s]", slug),
+			http.StatusBadRequest, "invalid ID supplied")
+		return
+	}
+
+	cartDI := db.GetCartDI()
+	if err := cartDI.RemoveCartItem(ctx, actorID(r), petID); err != nil {
+		respond(w, err, errorCode(err, http.StatusBadRequest), "can't remove pet from cart")
+		return
+	}
+
+	respond(w, nil, http.StatusOK, "success")
+}
+
+// checkoutCart places one order of everything in the cart,
+// the body may list the coupons to apply.
+func checkoutCart(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := genContext(r)
+	defer cancel()
+
+	var checkout models.CartCheckout
+	if r.Body != nil {
+		defer checkErrors(r.Body.Close)
+
+		bytesBody, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			respond(w, errors.Wrap(err, "can't read request body"),
+				http.StatusBadRequest, "invalid input")
+			return
+		}
+
+		if len(bytesBody) != 0 {
+			if err = checkout.UnmarshalJSON(bytesBody); err != nil {
+				respond(w, errors.Wrap(err, "can't decode request body to checkout"),
+					http.StatusBadRequest, "invalid input")
+				return
+			}
+		}
+	}
+
+	cartDI := db.GetCartDI()
+	order, err := cartDI.CheckoutCart(ctx, actorID(r), checkout.Coupons)
+	if err != nil {
+		respond(w, err, errorCode(err, http.StatusBadRequest), "can't checkout cart")
+		return
+	}
+
+	data, err := order.MarshalJSON()
+	if err != nil {
+		respond(w, errors.Wrap(err, "can't marshal data"),
+			http.StatusInternalServerError, "providers error")
+		return
+	}
+
+	if _, err := w.Write(data); err != nil {
+		respond(w, errors.Wrap(err, "can't write response"),
+			http.StatusInternalServerError, "providers error")
+		return
+	}
+}
+
+func writeCart(w http.ResponseWriter, cart *models.Cart) {
+	data, err := cart.MarshalJSON()
+	if err != nil {
+		respond(w, errors.Wrap(err, "can't marshal data"),
+			http.StatusInternalServerError, "providers error")
+		return
+	}
+
+	if _, err := w.Write(data); err != nil {
+		respond(w, errors.Wrap(err, "can't write response"),
+			http.StatusInternalServerError, "providers error")
+		return
+	}
+}
+
+func readCartItem(r *http.Request) (*models.CartItem, error) {
+	if r.Body == nil {
+		return nil, errors.New("request body is nil")
+	}
+	defer checkErrors(r.Body.Close)
+
+	bytesBody, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't read request body")
+	}
+
+	var item models.CartItem
+	if err = item.UnmarshalJSON(bytesBody); err != nil {
+		return nil, errors.Wrap(err, "can't decode request body to cart item")
+	}
+
+	if err = validator.Validate(item); err != nil {
+		return nil, errors.Wrap(err, "can't validate cart item from body")
+	}
+
+	return &item, nil
+}
@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/IamStubborN/petstore/workers/api/auth"
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_cart(t *testing.T) {
+	resetStock()
+
+	r := chi.NewRouter()
+	r.Get("/api/v2/store/cart", getCart)
+	r.Post("/api/v2/store/cart", addCartItem)
+	r.Delete("/api/v2/store/cart/{petID}", removeCartItem)
+	r.Post("/api/v2/store/cart/checkout", checkoutCart)
+
+	tests := []struct {
+		name     string
+		method   string
+		url      string
+		raw      string
+		wantCode int
+		wantBody string
+	}{
+		{name: "empty cart", method: "GET", url: "/api/v2/store/cart",
+			wantCode: http.StatusOK, wantBody: `{"user_id":7,"items":[]}`},
+		{name: "checkout empty cart", method: "POST", url: "/api/v2/store/cart/checkout",
+			wantCode: http.StatusConflict},
+		{name: "add unknown pet", method: "POST", url: "/api/v2/store/cart", raw: `{"pet_id": 99, "quantity": 1}`,
+			wantCode: http.StatusNotFound},
+		{name: "add zero quantity", method: "POST", url: "/api/v2/store/cart", raw: `{"pet_id": 2, "quantity": 0}`,
+			wantCode: http.StatusBadRequest},
+		{name: "add cat", method: "POST", url: "/api/v2/store/cart", raw: `{"pet_id": 1, "quantity": 1}`,
+			wantCode: http.StatusOK},
+		{name: "add dogs", method: "POST", url: "/api/v2/store/cart", raw: `{"pet_id": 2, "quantity": 2}`,
+			wantCode: http.StatusOK},
+		{name: "add more dogs", method: "POST", url: "/api/v2/store/cart", raw: `{"pet_id": 2, "quantity": 1}`,
+			wantCode: http.StatusOK, wantBody: `{"user_id":7,"items":[` +
+				`{"pet_id":1,"pet_name":"Soo","price":35,"quantity":1},` +
+				`{"pet_id":2,"pet_name":"Sylar","price":49.99,"quantity":3}]}`},
+		{name: "remove missing pet", method: "DELETE", url: "/api/v2/store/cart/99",
+			wantCode: http.StatusNotFound},
+		{name: "checkout with unknown coupon", method: "POST", url: "/api/v2/store/cart/checkout",
+			raw: `{"coupons": ["FREE"]}`, wantCode: http.StatusNotFound},
+		{name: "checkout with expired coupon", method: "POST", url: "/api/v2/store/cart/checkout",
+			raw: `{"coupons": ["WINTER50"]}`, wantCode: http.StatusConflict},
+		{name: "checkout with coupon which doesn't stack", method: "POST", url: "/api/v2/store/cart/checkout",
+			raw: `{"coupons": ["SPRING10", "SOLO20"]}`, wantCode: http.StatusConflict},
+		{name: "checkout", method: "POST", url: "/api/v2/store/cart/checkout",
+			raw: `{"coupons": ["SPRING10", "DOGS5"]}`, wantCode: http.StatusOK,
+			wantBody: `{"id":1,"user_id":7,"quantity":4,"items":[` +
+				`{"pet_id":1,"quantity":1,"price":35,"discount":3.5},` +
+				`{"pet_id":2,"quantity":3,"price":49.99,"discount":20}],` +
+				`"coupons":["SPRING10","DOGS5"],"gross":184.97,"discount":23.5,` +
+				`"ship_date":"","status":"placed","complete":false}`},
+		{name: "cart is empty after checkout", method: "GET", url: "/api/v2/store/cart",
+			wantCode: http.StatusOK, wantBody: `{"user_id":7,"items":[]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request, err := http.NewRequest(tt.method, tt.url, strings.NewReader(tt.raw))
+			if err != nil {
+				log.Println(err)
+			}
+
+			request = addToCtxWriteTimeout(request)
+			request = request.WithContext(auth.NewContext(request.Context(), auth.Session{UserID: 7}))
+			response := httptest.NewRecorder()
+
+			r.ServeHTTP(response, request)
+			assert.Equal(t, tt.wantCode, response.Code)
+			if tt.wantBody != "" {
+				assert.Equal(t, tt.wantBody, response.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandler_checkoutCartKeepsCartOnConflict(t *testing.T) {
+	resetStock()
+
+	r := chi.NewRouter()
+	r.Get("/api/v2/store/cart", getCart)
+	r.Post("/api/v2/store/cart", addCartItem)
+	r.Post("/api/v2/store/cart/checkout", checkoutCart)
+
+	serve := func(method, url, raw string) *httptest.ResponseRecorder {
+		request, err := http.NewRequest(method, url, strings.NewReader(raw))
+		if err != nil {
+			log.Println(err)
+		}
+
+		request = addToCtxWriteTimeout(request)
+		request = request.WithContext(auth.NewContext(request.Context(), auth.Session{UserID: 8}))
+		response := httptest.NewRecorder()
+
+		r.ServeHTTP(response, request)
+		return response
+	}
+
+	serve("POST", "/api/v2/store/cart", `{"pet_id": 2, "quantity": 1}`)
+	serve("POST", "/api/v2/store/cart", `{"pet_id": 1, "quantity": 2}`)
+
+	response := serve("POST", "/api/v2/store/cart/checkout", "")
+	assert.Equal(t, http.StatusConflict, response.Code, "only one cat is in the store")
+
+	response = serve("GET", "/api/v2/store/cart", "")
+	assert.Contains(t, response.Body.String(), `"pet_id":2`, "cart is kept after failed checkout")
+}
@@ -0,0 +1,190 @@
+package handler
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/IamStubborN/petstore/db"
+	"github.com/IamStubborN/petstore/db/models"
+	"github.com/IamStubborN/petstore/workers/api/mware"
+	"github.com/go-chi/chi"
+	"github.com/pkg/errors"
+	"gopkg.in/validator.v2"
+)
+
+func CategoryHandlers(r chi.Router) {
+	r.Use(mware.JWT)
+	r.Use(mware.Idempotency)
+	r.Get("/", getCategories)
+	r.Get("/{categoryID}", getCategoryByID)
+
+	adminGroup := r.Group(nil)
+	adminGroup.Use(mware.Admin)
+	adminGroup.Post("/", createCategory)
+	adminGroup.Put("/{categoryID}", updateCategory)
+	adminGroup.Delete("/{categoryID}", deleteCategory)
+}
+
+func getCategories(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := genContext(r)
+	defer cancel()
+
+	categoryDI := db.GetCategoryDI()
+	categories, err := categoryDI.GetCategories(ctx)
+	if err != nil {
+		respond(w, err, http.StatusInternalServerError, "providers error")
+		return
+	}
+
+	data, err := categories.MarshalJSON()
+	if err != nil {
+		respond(w, errors.Wrap(err, "can't marshal data"),
+			http.StatusInternalServerError, "providers error")
+		return
+	}
+
+	if _, err := w.Write(data); err != nil {
+		respond(w, errors.Wrap(err, "can't write response"),
+			http.StatusInternalServerError, "providers error")
+		return
+	}
+}
+
+func getCategoryByID(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := genContext(r)
+	defer cancel()
+
+	slug := strings.TrimPrefix(r.URL.Path, "/api/v2/category/")
+	id, err := strconv.ParseInt(slug, 10, 64)
+	if err != nil {
+		respond(w, errors.Wrapf(err, "can't cast slug to int [%s]", slug),
+			http.StatusBadRequest, "invalid ID supplied")
+		return
+	}
+
+	categoryDI := db.GetCategoryDI()
+	category, err := categoryDI.GetCategoryByID(ctx, id)
+	if err != nil {
+		respond(w, err, errorCode(err, http.StatusInternalServerError), "category not found")
+		return
+	}
+
+	data, err := category.MarshalJSON()
+	if err != nil {
+		respond(w, errors.Wrap(err, "can't marshal data"),
+			http.StatusInternalServerError, "providers error")
+		return
+	}
+
+	if _, err := w.Write(data); err != nil {
+		respond(w, errors.Wrap(err, "can't write response"),
+			http.StatusInternalServerError, "providers error")
+		return
+	}
+}
+
+func createCategory(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := genContext(r)
+	defer cancel()
+
+	category, err := readCategory(r)
+	if err != nil {
+		respond(w, err, http.StatusBadRequest, "invalid input")
+		return
+	}
+
+	categoryDI := db.GetCategoryDI()
+	createdCategory, err := categoryDI.CreateCategory(ctx, category)
+	if err != nil {
+		respond(w, err, errorCode(err, http.StatusBadRequest), "can't create category")
+		return
+	}
+
+	data, err := createdCategory.MarshalJSON()
+	if err != nil {
+		respond(w, errors.Wrap(err, "can't marshal data"),
+			http.StatusInternalServerError, "providers error")
+		return
+	}
+
+	if _, err := w.Write(data); err != nil {
+		respond(w, errors.Wrap(err, "can't write response"),
+			http.StatusInternalServerError, "providers error")
+		return
+	}
+}
+
+func updateCategory(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := genContext(r)
+	defer cancel()
+
+	slug := strings.TrimPrefix(r.URL.Path, "/api/v2/category/")
+	id, err := strconv.ParseInt(slug, 10, 64)
+	if err != nil {
+		respond(w, errors.Wrapf(err, "can't cast slug to int [%s]", slug),
+			http.StatusBadRequest, "invalid ID supplied")
+		return
+	}
+
+	category, err := readCategory(r)
+	if err != nil {
+		respond(w, err, http.StatusBadRequest, "invalid input")
+		return
+	}
+
+	category.ID = id
+
+	categoryDI := db.GetCategoryDI()
+	if err := categoryDI.UpdateCategory(ctx, category); err != nil {
+		respond(w, err, errorCode(err, http.StatusBadRequest), "can't update category")
+		return
+	}
+
+	respond(w, nil, http.StatusOK, "success")
+}
+
+func deleteCategory(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := genContext(r)
+	defer cancel()
+
+	slug := strings.TrimPrefix(r.URL.Path, "/api/v2/category/")
+	id, err := strconv.ParseInt(slug, 10, 64)
+	if err != nil {
+		respond(w, errors.Wrapf(err, "can't cast slug to int [%s]", slug),
+			http.StatusBadRequest, "invalid ID supplied")
+		return
+	}
+
+	categoryDI := db.GetCategoryDI()
+	if err := categoryDI.DeleteCategory(ctx, id); err != nil {
+		respond(w, err, errorCode(err, http.StatusBadRequest), "can't delete category")
+		return
+	}
+
+	respond(w, nil, http.StatusOK, "success")
+}
+
+func readCategory(r *http.Request) (*models.Category, error) {
+	if r.Body == nil {
+		return nil, errors.New("request body is nil")
+	}
+	defer checkErrors(r.Body.Close)
+
+	bytesBody, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't read request body")
+	}
+
+	var category models.Category
+	if err = category.UnmarshalJSON(bytesBody); err != nil {
+		return nil, errors.Wrap(err, "can't decode request body to category")
+	}
+
+	if err = validator.Validate(category); err != nil {
+		return nil, errors.Wrap(err, "can't validate category from body")
+	}
+
+	return &category, nil
+}
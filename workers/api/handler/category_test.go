@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_createCategory(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      string
+		wantCode int
+	}{
+		{
+			name:     "new category",
+			raw:      `{"name": "Parrot", "price": 15.50}`,
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "existing category",
+			raw:      `{"name": "Cat", "price": 35.00}`,
+			wantCode: http.StatusConflict,
+		},
+		{
+			name:     "negative price",
+			raw:      `{"name": "Parrot", "price": -1}`,
+			wantCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request, err := http.NewRequest("POST", "/api/v2/category", strings.NewReader(tt.raw))
+			if err != nil {
+				log.Println(err)
+			}
+
+			request = addToCtxWriteTimeout(request)
+			response := httptest.NewRecorder()
+
+			r := chi.NewRouter()
+			r.Post("/api/v2/category", createCategory)
+
+			r.ServeHTTP(response, request)
+			assert.Equal(t, tt.wantCode, response.Code)
+		})
+	}
+}
+
+func TestHandler_getCategories(t *testing.T) {
+	request, err := http.NewRequest("GET", "/api/v2/category", nil)
+	if err != nil {
+		log.Println(err)
+	}
+
+	request = addToCtxWriteTimeout(request)
+	response := httptest.NewRecorder()
+
+	r := chi.NewRouter()
+	r.Get("/api/v2/category", getCategories)
+
+	r.ServeHTTP(response, request)
+	assert.Equal(t, 200, response.Code, "OK response is expected")
+}
+
+func TestHandler_getCategoryByID(t *testing.T) {
+	request, err := http.NewRequest("GET", "/api/v2/category/1", nil)
+	if err != nil {
+		log.Println(err)
+	}
+
+	request = addToCtxWriteTimeout(request)
+	response := httptest.NewRecorder()
+
+	r := chi.NewRouter()
+	r.Get("/api/v2/category/{categoryID}", getCategoryByID)
+
+	r.ServeHTTP(response, request)
+	assert.Equal(t, 200, response.Code, "OK response is expected")
+}
+
+func TestHandler_updateCategory(t *testing.T) {
+	raw := `{"name": "Cat", "price": 39.99}`
+
+	request, err := http.NewRequest("PUT", "/api/v2/category/1", strings.NewReader(raw))
+	if err != nil {
+		log.Println(err)
+	}
+
+	request = addToCtxWriteTimeout(request)
+	response := httptest.NewRecorder()
+
+	r := chi.NewRouter()
+	r.Put("/api/v2/category/{categoryID}", updateCategory)
+
+	r.ServeHTTP(response, request)
+	assert.Equal(t, 200, response.Code, "OK response is expected")
+}
+
+func TestHandler_deleteCategory(t *testing.T) {
+	tests := []struct {
+		name     string
+		id       string
+		wantCode int
+	}{
+		{name: "unused category", id: "6", wantCode: http.StatusOK},
+		{name: "used category", id: "1", wantCode: http.StatusConflict},
+		{name: "missing category", id: "99", wantCode: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request, err := http.NewRequest("DELETE", "/api/v2/category/"+tt.id, nil)
+			if err != nil {
+				log.Println(err)
+			}
+
+			request = addToCtxWriteTimeout(request)
+			response := httptest.NewRecorder()
+
+			r := chi.NewRouter()
+			r.Delete("/api/v2/category/{categoryID}", deleteCategory)
+
+			r.ServeHTTP(response, request)
+			assert.Equal(t, tt.wantCode, response.Code)
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/IamStubborN/petstore/db"
+	"github.com/IamStubborN/petstore/db/models"
+	"github.com/IamStubborN/petstore/workers/api/mware"
+	"github.com/go-chi/chi"
+	"github.com/pkg/errors"
+	"gopkg.in/validator.v2"
+)
+
+// CouponHandlers manage the coupons, customers only apply them to orders.
+func CouponHandlers(r chi.Router) {
+	r.Use(mware.JWT)
+	r.Use(mware.Admin)
+	r.Use(mware.Idempotency)
+	r.Get("/", getCoupons)
+	r.Post("/", createCoupon)
+	r.Delete("/{couponID}", deleteCoupon)
+}
+
+func getCoupons(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := genContext(r)
+	defer cancel()
+
+	couponDI := db.GetCouponDI()
+	coupons, err := couponDI.GetCoupons(ctx)
+	if err != nil {
+		respond(w, err, http.StatusInternalServerError, "providers error")
+		return
+	}
+
+	data, err := coupons.MarshalJSON()
+	if err != nil {
+		respond(w, errors.Wrap(err, "can't marshal data"),
+			http.StatusInternalServerError, "providers error")
+		return
+	}
+
+	if _, err := w.Write(data); err != nil {
+		respond(w, errors.Wrap(err, "can't write response"),
+			http.StatusInternalServerError, "providers error")
+		return
+	}
+}
+
+func createCoupon(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := genContext(r)
+	defer cancel()
+
+	coupon, err := readCoupon(r)
+	if err != nil {
+		respond(w, err, http.StatusBadRequest, "invalid input")
+		return
+	}
+
+	couponDI := db.GetCouponDI()
+	createdCoupon, err := couponDI.CreateCoupon(ctx, coupon)
+	if err != nil {
+		respond(w, err, errorCode(err, http.StatusBadRequest), "can't create coupon")
+		return
+	}
+
+	data, err := createdCoupon.MarshalJSON()
+	if err != nil {
+		respond(w, errors.Wrap(err, "can't marshal data"),
+			http.StatusInternalServerError, "providers error")
+		return
+	}
+
+	if _, err := w.Write(data); err != nil {
+		respond(w, errors.Wrap(err, "can't write response"),
+			http.StatusInternalServerError, "providers error")
+		return
+	}
+}
+
+func deleteCoupon(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := genContext(r)
+	defer cancel()
+
+	slug := strings.TrimPrefix(r.URL.Path, "/api/v2/coupon/")
+	id, err := strconv.ParseInt(slug, 10, 64)
+	if err != nil {
+		respond(w, errors.Wrapf(err, "can't cast slug to int [%s]", slug),
+			http.StatusBadRequest, "invalid ID supplied")
+		return
+	}
+
+	couponDI := db.GetCouponDI()
+	if err := couponDI.DeleteCoupon(ctx, id); err != nil {
+		respond(w, err, errorCode(err, http.StatusBadRequest), "can't delete coupon")
+		return
+	}
+
+	respond(w, nil, http.StatusOK, "success")
+}
+
+func readCoupon(r *http.Request) (*models.Coupon, error) {
+	if r.Body == nil {
+		return nil, errors.New("request body is nil")
+	}
+	defer checkErrors(r.Body.Close)
+
+	bytesBody, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't read request body")
+	}
+
+	var coupon models.Coupon
+	if err = coupon.UnmarshalJSON(bytesBody); err != nil {
+		return nil, errors.Wrap(err, "can't decode request body to coupon")
+	}
+
+	if err = validator.Validate(coupon); err != nil {
+		return nil, errors.Wrap(err, "can't validate coupon from body")
+	}
+
+	if err = coupon.Validate(); err != nil {
+		return nil, errors.Wrap(err, "can't validate coupon from body")
+	}
+
+	return &coupon, nil
+}
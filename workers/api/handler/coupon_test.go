@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_createCoupon(t *testing.T) {
+	resetStock()
+
+	tests := []struct {
+		name     string
+		raw      string
+		wantCode int
+	}{
+		{name: "new coupon", raw: `{"code": "CATS15", "kind": "percent", "value": 15, "category_id": 1}`,
+			wantCode: http.StatusOK},
+		{name: "existing code", raw: `{"code": "SPRING10", "kind": "percent", "value": 10}`,
+			wantCode: http.StatusConflict},
+		{name: "unknown kind", raw: `{"code": "GIFT", "kind": "gift", "value": 10}`,
+			wantCode: http.StatusBadRequest},
+		{name: "percent over 100", raw: `{"code": "ALL", "kind": "percent", "value": 150}`,
+			wantCode: http.StatusBadRequest},
+		{name: "empty window", raw: `{"code": "NEVER", "kind": "fixed", "value": 5,` +
+			`"valid_from": "2020-01-02T00:00:00Z", "valid_to": "2020-01-01T00:00:00Z"}`,
+			wantCode: http.StatusBadRequest},
+		{name: "empty code", raw: `{"code": "", "kind": "fixed", "value": 5}`,
+			wantCode: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request, err := http.NewRequest("POST", "/api/v2/coupon", strings.NewReader(tt.raw))
+			if err != nil {
+				log.Println(err)
+			}
+
+			request = addToCtxWriteTimeout(request)
+			response := httptest.NewRecorder()
+
+			r := chi.NewRouter()
+			r.Post("/api/v2/coupon", createCoupon)
+
+			r.ServeHTTP(response, request)
+			assert.Equal(t, tt.wantCode, response.Code)
+		})
+	}
+}
+
+func TestHandler_getCoupons(t *testing.T) {
+	resetStock()
+
+	request, err := http.NewRequest("GET", "/api/v2/coupon", nil)
+	if err != nil {
+		log.Println(err)
+	}
+
+	request = addToCtxWriteTimeout(request)
+	response := httptest.NewRecorder()
+
+	r := chi.NewRouter()
+	r.Get("/api/v2/coupon", getCoupons)
+
+	r.ServeHTTP(response, request)
+	assert.Equal(t, 200, response.Code, "OK response is expected")
+	assert.Contains(t, response.Body.String(), `"code":"SPRING10"`)
+}
+
+func TestHandler_deleteCoupon(t *testing.T) {
+	resetStock()
+
+	tests := []struct {
+		name     string
+		id       string
+		wantCode int
+	}{
+		{name: "existing coupon", id: "4", wantCode: http.StatusOK},
+		{name: "missing coupon", id: "99", wantCode: http.StatusNotFound},
+		{name: "bad id", id: "four", wantCode: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request, err := http.NewRequest("DELETE", "/api/v2/coupon/"+tt.id, nil)
+			if err != nil {
+				log.Println(err)
+			}
+
+			request = addToCtxWriteTimeout(request)
+			response := httptest.NewRecorder()
+
+			r := chi.NewRouter()
+			r.Delete("/api/v2/coupon/{couponID}", deleteCoupon)
+
+			r.ServeHTTP(response, request)
+			assert.Equal(t, tt.wantCode, response.Code)
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/IamStubborN/petstore/db"
+	"github.com/IamStubborN/petstore/db/models"
+	"github.com/IamStubborN/petstore/workers/api/auth"
+	"github.com/IamStubborN/petstore/workers/api/mware"
+	"github.com/go-chi/chi"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+const (
+	eventInventory        = "inventory"
+	eventInventoryChanged = "inventory.changed"
+
+	// eventRetry is the reconnect delay sent to clients in milliseconds.
+	eventRetry = 3000
+	// eventPingInterval is the interval of the comments that keep the stream open.
+	eventPingInterval = 15 * time.Second
+)
+
+// streamedEvents lists the outbox events sent to clients as they are,
+// the created orders are sent only to admins and to their customers.
+var streamedEvents = map[string]bool{
+	models.EventPetStatusChanged: true,
+	models.EventOrderCreated:     true,
+}
+
+// EventHandlers stream the store changes to clients as server-sent events.
+func EventHandlers(r chi.Router) {
+	r.Use(mware.JWT)
+	r.Get("/", streamEvents)
+}
+
+// streamEvents sends the inventory first, then the pet status changes,
+// the created orders and the inventory changes they make. Events carry
+// the outbox event ids, so a reconnecting client resumes after the
+// Last-Event-ID it sends. The stream isn't bound by the write timeout
+// of the server, it ends when the server shuts down or when a write
+// to the gone client fails, the pings find such clients.
+func streamEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		respond(w, errors.New("response writer can't flush"),
+			http.StatusInternalServerError, "streaming unsupported")
+		return
+	}
+
+	var after int64
+	if raw := r.Header.Get("Last-Event-ID"); raw != "" {
+		var err error
+		after, err = strconv.ParseInt(raw, 10, 64)
+		if err != nil || after < 0 {
+			respond(w, errors.Errorf("can't parse Last-Event-ID [%s]", raw),
+				http.StatusBadRequest, "invalid Last-Event-ID value")
+			return
+		}
+	}
+
+	// the request context is the one of the API worker
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil {
+		zap.L().Warn("event stream ends with the write timeout", zap.Error(err))
+	}
+
+	events, err := db.GetEventStreamDI().StreamEvents(ctx, after)
+	if err != nil {
+		respond(w, err, http.StatusInternalServerError, "providers error")
+		return
+	}
+
+	inventory, err := db.GetStoreDI().GetInventories(ctx)
+	if err != nil {
+		respond(w, err, http.StatusInternalServerError, "providers error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	stream := &eventWriter{w: w, flusher: flusher}
+	stream.retry(eventRetry)
+	stream.send(0, eventInventory, inventory)
+
+	session, _ := auth.FromContext(r.Context())
+	ping := time.NewTicker(eventPingInterval)
+	defer ping.Stop()
+
+	for stream.err == nil {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ping.C:
+			stream.ping()
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+
+			if streamedEvents[event.Type] && isEventVisible(event, session) {
+				stream.send(event.ID, event.Type, event)
+			}
+
+			if event.Inventory != nil {
+				stream.send(event.ID, eventInventoryChanged, event.Inventory)
+			}
+		}
+	}
+
+	zap.L().Info("event stream is closed by client", zap.Error(stream.err))
+}
+
+// isEventVisible tells whether the user of the session may see the event,
+// a created order is seen by admins and by the customer who placed it.
+func isEventVisible(event *models.Event, session auth.Session) bool {
+	if event.Type != models.EventOrderCreated || session.IsAdmin() {
+		return true
+	}
+
+	var order models.Order
+	if err := order.UnmarshalJSON(event.Payload); err != nil {
+		zap.L().Error("can't decode order event payload", zap.Int64("id", event.ID), zap.Error(err))
+		return false
+	}
+
+	return session.UserID != 0 && order.UserID == session.UserID
+}
+
+// eventWriter writes server-sent events and flushes each of them,
+// the first write error is kept and the later writes are skipped.
+type eventWriter struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+	err     error
+}
+
+func (e *eventWriter) retry(milliseconds int) {
+	e.write(fmt.Sprintf("retry: %d\n\n", milliseconds))
+}
+
+func (e *eventWriter) ping() {
+	e.write(": ping\n\n")
+}
+
+// send writes the event, the id is left out when it's 0,
+// so such events don't move the Last-Event-ID of the client.
+func (e *eventWriter) send(id int64, name string, data interface{}) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		e.err = errors.Wrapf(err, "can't marshal %s event", name)
+		return
+	}
+
+	var message string
+	if id > 0 {
+		message = fmt.Sprintf("id: %d\n", id)
+	}
+	e.write(message + fmt.Sprintf("event: %s\ndata: %s\n\n", name, raw))
+}
+
+func (e *eventWriter) write(message string) {
+	if e.err != nil {
+		return
+	}
+
+	if _, err := e.w.Write([]byte(message)); err != nil {
+		e.err = errors.Wrap(err, "can't write event")
+		return
+	}
+
+	e.flusher.Flush()
+}
@@ -0,0 +1,213 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/IamStubborN/petstore/db"
+	"github.com/IamStubborN/petstore/db/models"
+	"github.com/IamStubborN/petstore/workers/api/auth"
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+)
+
+// streamRecorder records a response written while the test reads it.
+type streamRecorder struct {
+	sync.Mutex
+	header http.Header
+	code   int
+	body   bytes.Buffer
+}
+
+func newStreamRecorder() *streamRecorder {
+	return &streamRecorder{header: make(http.Header)}
+}
+
+func (s *streamRecorder) Header() http.Header {
+	return s.header
+}
+
+func (s *streamRecorder) WriteHeader(code int) {
+	s.Lock()
+	defer s.Unlock()
+	s.code = code
+}
+
+func (s *streamRecorder) Write(data []byte) (int, error) {
+	s.Lock()
+	defer s.Unlock()
+	return s.body.Write(data)
+}
+
+func (s *streamRecorder) Flush() {}
+
+func (s *streamRecorder) String() string {
+	s.Lock()
+	defer s.Unlock()
+	return s.body.String()
+}
+
+// waitFor waits a second for the recorded stream to contain the message.
+func (s *streamRecorder) waitFor(t *testing.T, message string) {
+	t.Helper()
+
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); {
+		if strings.Contains(s.String(), message) {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	t.Errorf("stream doesn't contain %q:\n%s", message, s.String())
+}
+
+// TestHandler_streamEvents streams the changes of the test data,
+// stops the stream like the API worker does on shutdown and resumes it.
+func TestHandler_streamEvents(t *testing.T) {
+	resetStock()
+
+	r := chi.NewRouter()
+	r.Get("/api/v2/events", streamEvents)
+
+	admin := auth.Session{UserID: 1, UserStatus: models.UserStatusAdmin}
+	owner := auth.Session{UserID: 2}
+	stranger := auth.Session{UserID: 3}
+
+	// serve streams until the worker context is cancelled
+	serve := func(lastEventID string, session auth.Session) (*streamRecorder, context.CancelFunc, chan struct{}) {
+		request, err := http.NewRequest("GET", "/api/v2/events", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if lastEventID != "" {
+			request.Header.Set("Last-Event-ID", lastEventID)
+		}
+
+		ctx, cancel := context.WithCancel(auth.NewContext(addToCtxWriteTimeout(request).Context(), session))
+		response := newStreamRecorder()
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			r.ServeHTTP(response, request.WithContext(ctx))
+		}()
+
+		return response, cancel, done
+	}
+
+	stop := func(cancel context.CancelFunc, done chan struct{}) {
+		cancel()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("stream isn't closed when the worker context is cancelled")
+		}
+	}
+
+	response, cancel, done := serve("", admin)
+	response.waitFor(t, "retry: 3000\n\nevent: inventory\ndata: {")
+	assert.Equal(t, "text/event-stream", response.Header().Get("Content-Type"))
+	strangerResponse, strangerCancel, strangerDone := serve("", stranger)
+	strangerResponse.waitFor(t, "event: inventory\n")
+
+	ctx := context.Background()
+	if err := db.GetPetDI().UpdatePetInStoreByForm(ctx, 1, "TestPet", models.PetStatusPending, 1); err != nil {
+		t.Fatalf("UpdatePetInStoreByForm() error = %v", err)
+	}
+	if _, err := db.GetStoreDI().CancelOrder(ctx, 1, 1); err != nil {
+		t.Fatalf("CancelOrder() error = %v", err)
+	}
+	order := &models.Order{ID: 1, PetID: 1, UserID: 2, Quantity: 1, Status: models.OrderStatusPlaced}
+	if _, err := db.GetStoreDI().CreateOrder(ctx, order); err != nil {
+		t.Fatalf("CreateOrder() error = %v", err)
+	}
+
+	response.waitFor(t, "id: 1\nevent: pet.status_changed\ndata: {\"id\":1,")
+	response.waitFor(t, "id: 4\nevent: order.created\ndata: {\"id\":4,")
+	stop(cancel, done)
+	assert.NotContains(t, response.String(), "pet.updated")
+	assert.NotContains(t, response.String(), "order.cancelled")
+
+	strangerResponse.waitFor(t, "id: 1\nevent: pet.status_changed")
+	stop(strangerCancel, strangerDone)
+	assert.NotContains(t, strangerResponse.String(), "order.created", "orders are sent to admins and owners")
+
+	response, cancel, done = serve("1", owner)
+	response.waitFor(t, "id: 4\nevent: order.created")
+	stop(cancel, done)
+	assert.NotContains(t, response.String(), "id: 1\n")
+}
+
+// TestHandler_streamEventsWriteTimeout checks that the stream outlives
+// the write timeout of the server.
+func TestHandler_streamEventsWriteTimeout(t *testing.T) {
+	resetStock()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		streamEvents(w, r.WithContext(ctx))
+	}))
+	server.Config.WriteTimeout = 100 * time.Millisecond
+	server.Start()
+	defer func() {
+		cancel()
+		server.Close()
+	}()
+
+	response, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	defer func() { assert.NoError(t, response.Body.Close()) }()
+
+	reader := bufio.NewReader(response.Body)
+	readEvent := func() string {
+		var event strings.Builder
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				t.Fatalf("ReadString() error = %v after %q", err, event.String())
+			}
+			if line == "\n" && event.Len() > 0 {
+				return event.String()
+			}
+			event.WriteString(line)
+		}
+	}
+
+	assert.Equal(t, "retry: 3000\n", readEvent())
+	assert.Contains(t, readEvent(), "event: inventory\n")
+
+	time.Sleep(3 * server.Config.WriteTimeout)
+	if err := db.GetPetDI().UpdatePetInStoreByForm(context.Background(), 1, "TestPet",
+		models.PetStatusPending, 1); err != nil {
+		t.Fatalf("UpdatePetInStoreByForm() error = %v", err)
+	}
+
+	assert.Contains(t, readEvent(), "event: pet.status_changed\n")
+}
+
+func TestHandler_streamEventsLastEventID(t *testing.T) {
+	resetStock()
+
+	request, err := http.NewRequest("GET", "/api/v2/events", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	request.Header.Set("Last-Event-ID", "pet")
+
+	response := httptest.NewRecorder()
+	r := chi.NewRouter()
+	r.Get("/api/v2/events", streamEvents)
+
+	r.ServeHTTP(response, addToCtxWriteTimeout(request))
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+}
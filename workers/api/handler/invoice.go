@@ -0,0 +1,228 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/IamStubborN/petstore/db"
+	"github.com/IamStubborN/petstore/db/models"
+	"github.com/IamStubborN/petstore/fileserver"
+	"github.com/IamStubborN/petstore/invoices"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"gopkg.in/validator.v2"
+)
+
+// createInvoice renders the invoice of a period on demand, the same way
+// the invoice worker does. The file is sent back as an attachment or,
+// when store is set, stored and recorded with the generated invoices
+// and its invoice record is sent back.
+func createInvoice(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := genContext(r)
+	defer cancel()
+
+	request, err := readInvoiceRequest(r)
+	if err != nil {
+		respond(w, err, http.StatusBadRequest, "invalid input")
+		return
+	}
+
+	from, to, err := invoicePeriod(request)
+	if err != nil {
+		respond(w, err, http.StatusBadRequest, "invalid period value")
+		return
+	}
+
+	format := request.Format
+	if format == "" {
+		format = invoices.FormatMarkdown
+	}
+
+	renderer, err := invoices.NewRenderer(format)
+	if err != nil {
+		respond(w, err, http.StatusBadRequest, "invalid format value")
+		return
+	}
+
+	invoice, err := invoices.Generate(ctx, db.GetStoreDI().CreateInvoiceByDates, from, to)
+	if err != nil {
+		respond(w, err, errorCode(err, http.StatusInternalServerError), "can't create invoice")
+		return
+	}
+
+	if request.Store {
+		storeInvoice(ctx, w, invoice, renderer)
+		return
+	}
+
+	var file bytes.Buffer
+	if err = renderer.Render(&file, invoice); err != nil {
+		respond(w, err, http.StatusInternalServerError, "can't render invoice")
+		return
+	}
+
+	fileName := invoices.FileName(invoice, renderer.Format(), time.Now())
+	w.Header().Set("Content-Type", renderer.ContentType())
+	w.Header().Set("Content-Disposition", `attachment; filename="`+fileName+`"`)
+	w.Header().Set("Content-Length", strconv.Itoa(file.Len()))
+
+	if _, err := file.WriteTo(w); err != nil {
+		respond(w, errors.Wrap(err, "can't write response"),
+			http.StatusInternalServerError, "providers error")
+		return
+	}
+}
+
+func storeInvoice(ctx context.Context, w http.ResponseWriter, invoice *models.Invoice, renderer invoices.Renderer) {
+	file, err := invoices.Store(ctx, fileserver.GetFM(), db.GetInvoiceDI().AddInvoiceFile, invoice, renderer)
+	if err != nil {
+		respond(w, err, http.StatusInternalServerError, "can't store invoice")
+		return
+	}
+
+	data, err := file.MarshalJSON()
+	if err != nil {
+		respond(w, errors.Wrap(err, "can't marshal data"),
+			http.StatusInternalServerError, "providers error")
+		return
+	}
+
+	if _, err := w.Write(data); err != nil {
+		respond(w, errors.Wrap(err, "can't write response"),
+			http.StatusInternalServerError, "providers error")
+		return
+	}
+}
+
+// getInvoiceFiles lists the generated invoice files, the latest come first.
+func getInvoiceFiles(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := genContext(r)
+	defer cancel()
+
+	limit := models.DefaultPageLimit
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		var err error
+		limit, err = strconv.Atoi(raw)
+		if err != nil || limit <= 0 || limit > models.MaxPageLimit {
+			respond(w, errors.Errorf("limit must be between 1 and %d [%s]", models.MaxPageLimit, raw),
+				http.StatusBadRequest, "invalid limit value")
+			return
+		}
+	}
+
+	files, err := db.GetInvoiceDI().GetInvoiceFiles(ctx, limit)
+	if err != nil {
+		respond(w, err, http.StatusInternalServerError, "providers error")
+		return
+	}
+
+	data, err := files.MarshalJSON()
+	if err != nil {
+		respond(w, errors.Wrap(err, "can't marshal data"),
+			http.StatusInternalServerError, "providers error")
+		return
+	}
+
+	if _, err := w.Write(data); err != nil {
+		respond(w, errors.Wrap(err, "can't write response"),
+			http.StatusInternalServerError, "providers error")
+		return
+	}
+}
+
+// downloadInvoice sends the invoice file with the number from the file server.
+func downloadInvoice(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := genContext(r)
+	defer cancel()
+
+	slug := strings.TrimPrefix(r.URL.Path, "/api/v2/store/invoice/")
+	number, err := strconv.ParseInt(slug, 10, 64)
+	if err != nil {
+		respond(w, errors.Wrapf(err, "can't cast slug to int [%s]", slug),
+			http.StatusBadRequest, "invalid number supplied")
+		return
+	}
+
+	file, err := db.GetInvoiceDI().GetInvoiceFile(ctx, number)
+	if err != nil {
+		respond(w, err, errorCode(err, http.StatusInternalServerError), "can't get invoice")
+		return
+	}
+
+	renderer, err := invoices.NewRenderer(file.Format)
+	if err != nil {
+		respond(w, err, http.StatusInternalServerError, "can't get invoice")
+		return
+	}
+
+	object, err := fileserver.GetFM().GetObject(invoices.Bucket, file.ObjectKey)
+	if err != nil {
+		respond(w, errors.Wrap(err, "can't download invoice"),
+			http.StatusInternalServerError, "file server error")
+		return
+	}
+	defer checkErrors(object.Close)
+
+	w.Header().Set("Content-Type", renderer.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="invoice_%d.%s"`, file.Number, file.Format))
+	w.Header().Set("Content-Length", strconv.FormatInt(file.Size, 10))
+
+	if _, err := io.Copy(w, object); err != nil {
+		zap.L().Error("can't write invoice", zap.Int64("number", file.Number), zap.Error(err))
+	}
+}
+
+// invoicePeriod parses the period of the invoice, a date to
+// takes in the whole day.
+func invoicePeriod(request *models.InvoiceRequest) (time.Time, time.Time, error) {
+	from, err := parseShipDate(request.From, false)
+	if err != nil {
+		return from, from, err
+	}
+
+	to, err := parseShipDate(request.To, false)
+	if err != nil {
+		return from, to, err
+	}
+
+	// the period doesn't include its end, a date covers the whole day
+	if _, err = time.Parse(time.RFC3339, request.To); err != nil {
+		to = to.AddDate(0, 0, 1)
+	}
+
+	if !to.After(from) {
+		return from, to, errors.Errorf("invoice period is empty [%s - %s]", request.From, request.To)
+	}
+
+	return from, to, nil
+}
+
+func readInvoiceRequest(r *http.Request) (*models.InvoiceRequest, error) {
+	if r.Body == nil {
+		return nil, errors.New("request body is nil")
+	}
+	defer checkErrors(r.Body.Close)
+
+	bytesBody, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't read request body")
+	}
+
+	var request models.InvoiceRequest
+	if err = request.UnmarshalJSON(bytesBody); err != nil {
+		return nil, errors.Wrap(err, "can't decode request body to invoice request")
+	}
+
+	if err = validator.Validate(request); err != nil {
+		return nil, errors.Wrap(err, "can't validate invoice request from body")
+	}
+
+	return &request, nil
+}
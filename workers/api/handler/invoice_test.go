@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_createInvoice(t *testing.T) {
+	resetStock()
+
+	tests := []struct {
+		name            string
+		raw             string
+		wantCode        int
+		wantContentType string
+		wantBody        string
+	}{
+		{name: "markdown by default", raw: `{"from": "2019-09-07", "to": "2019-09-08"}`,
+			wantCode: http.StatusOK, wantContentType: "text/markdown",
+			wantBody: "Total with discounts and refunds: $ 2224.66"},
+		{name: "csv", raw: `{"from": "2019-09-07", "to": "2019-09-07", "format": "csv"}`,
+			wantCode: http.StatusOK, wantContentType: "text/csv; charset=utf-8",
+			wantBody: "1,1,Jack The Ripper,John Snow,Cat,2019-09-07 15:35:04,15,35.00,0.00,false\n"},
+		{name: "end isn't included", raw: `{"from": "2019-09-07T15:35:04Z", "to": "2019-09-08T15:35:04Z"}`,
+			wantCode: http.StatusOK, wantContentType: "text/markdown",
+			wantBody: "Total with discounts and refunds: $ 525.00"},
+		{name: "start is included", raw: `{"from": "2019-09-08T15:35:04Z", "to": "2019-09-08", "format": "csv"}`,
+			wantCode: http.StatusOK, wantContentType: "text/csv; charset=utf-8",
+			wantBody: "2,1,Ginger,Phil Heat,Dog,2019-09-08 15:35:04,34,49.99,0.00,false\n"},
+		{name: "pdf", raw: `{"from": "2019-09-07T00:00:00Z", "to": "2019-09-08T23:59:59Z", "format": "pdf"}`,
+			wantCode: http.StatusOK, wantContentType: "application/pdf", wantBody: "%PDF-1.4"},
+		{name: "stored", raw: `{"from": "2019-09-07", "to": "2019-09-08", "format": "html", "store": true}`,
+			wantCode: http.StatusOK, wantContentType: "application/json",
+			wantBody: `"format":"html","object_key":"from_2019-09-07_to_2019-09-08/`},
+		{name: "no orders", raw: `{"from": "2020-01-01", "to": "2020-01-31"}`,
+			wantCode: http.StatusNotFound},
+		{name: "unknown format", raw: `{"from": "2019-09-07", "to": "2019-09-08", "format": "docx"}`,
+			wantCode: http.StatusBadRequest},
+		{name: "no period", raw: `{"format": "csv"}`, wantCode: http.StatusBadRequest},
+		{name: "bad date", raw: `{"from": "07.09.2019", "to": "2019-09-08"}`, wantCode: http.StatusBadRequest},
+		{name: "empty period", raw: `{"from": "2019-09-08", "to": "2019-09-07"}`, wantCode: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request, err := http.NewRequest("POST", "/api/v2/store/invoice", strings.NewReader(tt.raw))
+			if err != nil {
+				log.Println(err)
+			}
+
+			request = addToCtxWriteTimeout(request)
+			response := httptest.NewRecorder()
+			response.Header().Set("Content-Type", "application/json")
+
+			r := chi.NewRouter()
+			r.Post("/api/v2/store/invoice", createInvoice)
+
+			r.ServeHTTP(response, request)
+			assert.Equal(t, tt.wantCode, response.Code)
+
+			if tt.wantCode == http.StatusOK {
+				assert.Equal(t, tt.wantContentType, response.Header().Get("Content-Type"))
+				assert.Contains(t, response.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+// TestHandler_invoiceRegistry stores an invoice, finds it in the list
+// and downloads it by its number.
+func TestHandler_invoiceRegistry(t *testing.T) {
+	resetStock()
+
+	r := chi.NewRouter()
+	r.Post("/api/v2/store/invoice", createInvoice)
+	r.Get("/api/v2/store/invoice", getInvoiceFiles)
+	r.Get("/api/v2/store/invoice/{number}", downloadInvoice)
+
+	serve := func(method, url, body string) *httptest.ResponseRecorder {
+		request, err := http.NewRequest(method, url, strings.NewReader(body))
+		if err != nil {
+			log.Println(err)
+		}
+
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, addToCtxWriteTimeout(request))
+		return response
+	}
+
+	streamed := serve("POST", "/api/v2/store/invoice", `{"from": "2019-09-07", "to": "2019-09-08", "format": "csv"}`)
+	assert.Equal(t, http.StatusOK, streamed.Code)
+
+	for i := 0; i < 2; i++ {
+		response := serve("POST", "/api/v2/store/invoice",
+			`{"from": "2019-09-07", "to": "2019-09-08", "format": "csv", "store": true}`)
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.Contains(t, response.Body.String(), fmt.Sprintf(`{"number":%d,`, i+1))
+	}
+
+	checksum := sha256.Sum256(streamed.Body.Bytes())
+	response := serve("GET", "/api/v2/store/invoice?limit=1", "")
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), `[{"number":2,"from":"2019-09-07T00:00:00Z","to":"2019-09-09T00:00:00Z"`)
+	assert.Contains(t, response.Body.String(), `"checksum":"`+hex.EncodeToString(checksum[:])+`"`)
+	assert.Contains(t, response.Body.String(), `"total":2224.66`)
+	assert.NotContains(t, response.Body.String(), `"number":1`)
+
+	response = serve("GET", "/api/v2/store/invoice/1", "")
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", response.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="invoice_1.csv"`, response.Header().Get("Content-Disposition"))
+	assert.Equal(t, streamed.Body.String(), response.Body.String())
+
+	response = serve("GET", "/api/v2/store/invoice/3", "")
+	assert.Equal(t, http.StatusNotFound, response.Code)
+
+	response = serve("GET", "/api/v2/store/invoice/first", "")
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+
+	response = serve("GET", "/api/v2/store/invoice?limit=0", "")
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+}
@@ -0,0 +1,298 @@
+package handler
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/IamStubborN/petstore/db"
+	"github.com/IamStubborN/petstore/db/models"
+	"github.com/IamStubborN/petstore/payments"
+	"github.com/IamStubborN/petstore/workers/api/auth"
+	"github.com/pkg/errors"
+	"gopkg.in/validator.v2"
+)
+
+// paymentSaveTimeout limits saving the answer of the payment provider,
+// it's saved even when the provider answered after the request deadline.
+const paymentSaveTimeout = 5 * time.Second
+
+// getPayments lists the payment attempts of the order.
+func getPayments(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := genContext(r)
+	defer cancel()
+
+	id, err := paymentOrderID(r, "/payment")
+	if err != nil {
+		respond(w, err, http.StatusBadRequest, "invalid ID supplied")
+		return
+	}
+
+	if _, code, err := findOwnOrder(ctx, r, id); err != nil {
+		respond(w, err, code, "can't get payments")
+		return
+	}
+
+	paymentDI := db.GetPaymentDI()
+	list, err := paymentDI.GetPayments(ctx, id)
+	if err != nil {
+		respond(w, err, http.StatusInternalServerError, "providers error")
+		return
+	}
+
+	data, err := list.MarshalJSON()
+	if err != nil {
+		respond(w, errors.Wrap(err, "can't marshal data"),
+			http.StatusInternalServerError, "providers error")
+		return
+	}
+
+	if _, err := w.Write(data); err != nil {
+		respond(w, errors.Wrap(err, "can't write response"),
+			http.StatusInternalServerError, "providers error")
+		return
+	}
+}
+
+// payOrder authorizes the order total with the card token and captures
+// it at once when asked, only placed orders can be paid, a capture of
+// an order cancelled meanwhile is refunded.
+func payOrder(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := genContext(r)
+	defer cancel()
+
+	id, err := paymentOrderID(r, "/payment")
+	if err != nil {
+		respond(w, err, http.StatusBadRequest, "invalid ID supplied")
+		return
+	}
+
+	req, err := readPaymentRequest(r)
+	if err != nil {
+		respond(w, err, http.StatusBadRequest, "invalid input")
+		return
+	}
+
+	order, code, err := findOwnOrder(ctx, r, id)
+	if err != nil {
+		respond(w, err, code, "can't pay order")
+		return
+	}
+
+	if order.Status != models.OrderStatusPlaced {
+		respond(w, errors.Errorf("order № %d is %s", id, order.Status),
+			http.StatusConflict, "can't pay order")
+		return
+	}
+
+	provider := payments.GetProvider()
+	payment, err := db.GetPaymentDI().CreatePayment(ctx, &models.Payment{
+		OrderID:  id,
+		Provider: provider.Name(),
+		Amount:   order.Total(),
+		Status:   models.PaymentStatusPending,
+	})
+	if err != nil {
+		respond(w, err, errorCode(err, http.StatusBadRequest), "can't pay order")
+		return
+	}
+
+	payment.Reference, err = provider.Authorize(ctx, payments.Charge{
+		OrderID: id,
+		Amount:  payment.Amount,
+		Token:   req.Token,
+	})
+	if err != nil {
+		failPayment(w, payment, err)
+		return
+	}
+
+	if err = savePayment(payment, models.PaymentStatusAuthorized); err != nil {
+		respond(w, err, http.StatusInternalServerError, "providers error")
+		return
+	}
+
+	if req.Capture {
+		if err = provider.Capture(ctx, payment.Reference, payment.Amount); err != nil {
+			failCapture(w, payment, err)
+			return
+		}
+
+		if err = savePayment(payment, models.PaymentStatusCaptured); err != nil {
+			respond(w, err, errorCode(err, http.StatusInternalServerError), "can't capture payment")
+			return
+		}
+	}
+
+	writePayment(w, payment)
+}
+
+// capturePayment takes the authorized amount of the order, it's refunded
+// when the order isn't placed anymore.
+func capturePayment(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := genContext(r)
+	defer cancel()
+
+	payment, code, err := lastPayment(ctx, r, "/payment/capture", models.PaymentStatusAuthorized)
+	if err != nil {
+		respond(w, err, code, "can't capture payment")
+		return
+	}
+
+	if err = payments.GetProvider().Capture(ctx, payment.Reference, payment.Amount); err != nil {
+		failCapture(w, payment, err)
+		return
+	}
+
+	if err = savePayment(payment, models.PaymentStatusCaptured); err != nil {
+		respond(w, err, errorCode(err, http.StatusInternalServerError), "can't capture payment")
+		return
+	}
+
+	writePayment(w, payment)
+}
+
+// lastPayment finds the latest payment of the order with the status,
+// it must be made through the current payment provider.
+func lastPayment(ctx context.Context, r *http.Request, action, status string) (*models.Payment, int, error) {
+	id, err := paymentOrderID(r, action)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	list, err := db.GetPaymentDI().GetPayments(ctx, id)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	payment := list.Last(status)
+	if payment == nil {
+		return nil, http.StatusConflict, errors.Errorf("order № %d has no %s payment", id, status)
+	}
+
+	if name := payments.GetProvider().Name(); payment.Provider != name {
+		return nil, http.StatusConflict,
+			errors.Errorf("payment № %d is made through %s, not %s", payment.ID, payment.Provider, name)
+	}
+
+	return payment, 0, nil
+}
+
+// failPayment saves the declined or failed authorization.
+func failPayment(w http.ResponseWriter, payment *models.Payment, err error) {
+	status := models.PaymentStatusFailed
+	if errors.Cause(err) == payments.ErrDeclined {
+		status = models.PaymentStatusDeclined
+	}
+
+	payment.Reason = err.Error()
+	if saveErr := savePayment(payment, status); saveErr != nil {
+		respond(w, saveErr, http.StatusInternalServerError, "providers error")
+		return
+	}
+
+	respond(w, err, providerErrorCode(err), payment.Reason)
+}
+
+// failCapture saves the declined capture, other errors leave the
+// payment authorized, so the capture can be tried again.
+func failCapture(w http.ResponseWriter, payment *models.Payment, err error) {
+	if errors.Cause(err) == payments.ErrDeclined {
+		payment.Reason = err.Error()
+		if saveErr := savePayment(payment, models.PaymentStatusFailed); saveErr != nil {
+			respond(w, saveErr, http.StatusInternalServerError, "providers error")
+			return
+		}
+	}
+
+	respond(w, err, providerErrorCode(err), "can't capture payment")
+}
+
+func savePayment(payment *models.Payment, status string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), paymentSaveTimeout)
+	defer cancel()
+
+	payment.Status = status
+
+	return db.GetPaymentDI().UpdatePayment(ctx, payment)
+}
+
+// providerErrorCode tells declined payments from provider failures.
+func providerErrorCode(err error) int {
+	switch errors.Cause(err) {
+	case payments.ErrDeclined:
+		return http.StatusPaymentRequired
+	case payments.ErrUnknownPayment:
+		return http.StatusConflict
+	default:
+		return http.StatusBadGateway
+	}
+}
+
+// findOwnOrder returns the order when the user making the request
+// is an admin or the customer who placed it.
+func findOwnOrder(ctx context.Context, r *http.Request, id int64) (*models.Order, int, error) {
+	order, err := db.GetStoreDI().FindOrderByID(ctx, id)
+	if err != nil {
+		return nil, http.StatusNotFound, err
+	}
+
+	session, _ := auth.FromContext(r.Context())
+	if !session.IsAdmin() && order.UserID != session.UserID {
+		return nil, http.StatusForbidden, errors.Errorf("user № %d doesn't own order № %d", session.UserID, id)
+	}
+
+	return order, 0, nil
+}
+
+func paymentOrderID(r *http.Request, action string) (int64, error) {
+	slug := strings.TrimPrefix(r.URL.Path, "/api/v2/store/order/")
+	slug = strings.TrimSuffix(slug, action)
+	id, err := strconv.ParseInt(slug, 10, 64)
+	if err != nil {
+		return 0, errors.Wrapf(err, "can't cast slug to int [%s]", slug)
+	}
+
+	return id, nil
+}
+
+func readPaymentRequest(r *http.Request) (*models.PaymentRequest, error) {
+	if r.Body == nil {
+		return nil, errors.New("request body is nil")
+	}
+	defer checkErrors(r.Body.Close)
+
+	bytesBody, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't read request body")
+	}
+
+	var req models.PaymentRequest
+	if err = req.UnmarshalJSON(bytesBody); err != nil {
+		return nil, errors.Wrap(err, "can't decode request body to payment")
+	}
+
+	if err = validator.Validate(req); err != nil {
+		return nil, errors.Wrap(err, "can't validate payment from body")
+	}
+
+	return &req, nil
+}
+
+func writePayment(w http.ResponseWriter, payment *models.Payment) {
+	data, err := payment.MarshalJSON()
+	if err != nil {
+		respond(w, errors.Wrap(err, "can't marshal data"),
+			http.StatusInternalServerError, "providers error")
+		return
+	}
+
+	if _, err := w.Write(data); err != nil {
+		respond(w, errors.Wrap(err, "can't write response"),
+			http.StatusInternalServerError, "providers error")
+		return
+	}
+}
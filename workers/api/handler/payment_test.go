@@ -0,0 +1,284 @@
+package handler
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/IamStubborN/petstore/db"
+	"github.com/IamStubborN/petstore/db/models"
+	"github.com/IamStubborN/petstore/payments"
+	"github.com/IamStubborN/petstore/workers/api/auth"
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestHandler_payOrder walks order № 1 through payment and approval,
+// the steps depend on each other.
+func TestHandler_payOrder(t *testing.T) {
+	resetStock()
+
+	admin := auth.Session{UserID: 2, UserStatus: models.UserStatusAdmin}
+	owner := auth.Session{UserID: 1}
+	stranger := auth.Session{UserID: 3}
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		raw        string
+		session    auth.Session
+		wantCode   int
+		wantStatus string
+	}{
+		{name: "empty token", method: "POST", path: "/payment", raw: `{"token": ""}`, session: owner,
+			wantCode: http.StatusBadRequest},
+		{name: "foreign order", method: "POST", path: "/payment", raw: `{"token": "tok_visa"}`, session: stranger,
+			wantCode: http.StatusForbidden},
+		{name: "approve unpaid order", method: "POST", path: "/approve", session: admin,
+			wantCode: http.StatusConflict},
+		{name: "declined card", method: "POST", path: "/payment", raw: `{"token": "` + payments.TokenDeclined + `"}`,
+			session: owner, wantCode: http.StatusPaymentRequired},
+		{name: "authorize", method: "POST", path: "/payment", raw: `{"token": "tok_visa"}`, session: owner,
+			wantCode: http.StatusOK, wantStatus: `"amount":420,"status":"authorized"`},
+		{name: "pay twice", method: "POST", path: "/payment", raw: `{"token": "tok_visa"}`, session: owner,
+			wantCode: http.StatusConflict},
+		{name: "refund not captured", method: "POST", path: "/refund", raw: `{"reason": "changed mind"}`,
+			session: admin, wantCode: http.StatusConflict},
+		{name: "capture", method: "POST", path: "/payment/capture", session: admin,
+			wantCode: http.StatusOK, wantStatus: `"status":"captured"`},
+		{name: "list payments", method: "GET", path: "/payment", session: owner,
+			wantCode: http.StatusOK, wantStatus: `"status":"declined","reason":"card declined: payment declined"`},
+		{name: "approve paid order", method: "POST", path: "/approve", session: admin,
+			wantCode: http.StatusOK, wantStatus: `"status":"approved"`},
+		{name: "refund without reason", method: "POST", path: "/refund", raw: `{"amount": 20}`, session: admin,
+			wantCode: http.StatusBadRequest},
+		{name: "refund too much", method: "POST", path: "/refund", raw: `{"amount": 420.01, "reason": "changed mind"}`,
+			session: admin, wantCode: http.StatusConflict},
+		{name: "partial refund", method: "POST", path: "/refund", raw: `{"amount": 20, "reason": "scratched cage"}`,
+			session: admin, wantCode: http.StatusOK, wantStatus: `"amount":20,"reason":"scratched cage"`},
+		{name: "refund the rest", method: "POST", path: "/refund", raw: `{"reason": "changed mind"}`, session: admin,
+			wantCode: http.StatusOK, wantStatus: `"amount":400,"reason":"changed mind"`},
+		{name: "refund refunded order", method: "POST", path: "/refund", raw: `{"reason": "changed mind"}`,
+			session: admin, wantCode: http.StatusConflict},
+		{name: "list refunds", method: "GET", path: "/refund", session: owner,
+			wantCode: http.StatusOK, wantStatus: `"amount":400`},
+		{name: "payment is refunded", method: "GET", path: "/payment", session: owner,
+			wantCode: http.StatusOK, wantStatus: `"status":"refunded"`},
+	}
+
+	r := chi.NewRouter()
+	r.Get("/api/v2/store/order/{order_ID}/payment", getPayments)
+	r.Post("/api/v2/store/order/{order_ID}/payment", payOrder)
+	r.Post("/api/v2/store/order/{order_ID}/payment/capture", capturePayment)
+	r.Get("/api/v2/store/order/{order_ID}/refund", getRefunds)
+	r.Post("/api/v2/store/order/{order_ID}/refund", refundOrder)
+	r.Post("/api/v2/store/order/{order_ID}/approve", approveOrder)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request, err := http.NewRequest(tt.method, "/api/v2/store/order/1"+tt.path, strings.NewReader(tt.raw))
+			if err != nil {
+				log.Println(err)
+			}
+
+			request = request.WithContext(auth.NewContext(request.Context(), tt.session))
+			request = addToCtxWriteTimeout(request)
+			response := httptest.NewRecorder()
+
+			r.ServeHTTP(response, request)
+			assert.Equal(t, tt.wantCode, response.Code)
+			assert.Contains(t, response.Body.String(), tt.wantStatus)
+		})
+	}
+}
+
+func TestHandler_payOrderCapture(t *testing.T) {
+	resetStock()
+
+	request, err := http.NewRequest("POST", "/api/v2/store/order/1/payment",
+		strings.NewReader(`{"token": "tok_visa", "capture": true}`))
+	if err != nil {
+		log.Println(err)
+	}
+
+	request = request.WithContext(auth.NewContext(request.Context(), auth.Session{UserID: 1}))
+	request = addToCtxWriteTimeout(request)
+	response := httptest.NewRecorder()
+
+	r := chi.NewRouter()
+	r.Post("/api/v2/store/order/{order_ID}/payment", payOrder)
+
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), `"status":"captured"`)
+}
+
+// TestHandler_refundCancelledOrder checks that the captured payment
+// is returned when a paid order is cancelled or deleted.
+func TestHandler_refundCancelledOrder(t *testing.T) {
+	admin := auth.Session{UserID: 2, UserStatus: models.UserStatusAdmin}
+	owner := auth.Session{UserID: 1}
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		session    auth.Session
+		wantReason string
+	}{
+		{name: "cancel", method: "POST", path: "/cancel", session: owner, wantReason: `"reason":"order cancelled"`},
+		{name: "delete", method: "DELETE", session: admin, wantReason: `"reason":"order deleted"`},
+	}
+
+	r := chi.NewRouter()
+	r.Post("/api/v2/store/order/{order_ID}/payment", payOrder)
+	r.Get("/api/v2/store/order/{order_ID}/refund", getRefunds)
+	r.Post("/api/v2/store/order/{order_ID}/cancel", cancelOrder)
+	r.Delete("/api/v2/store/order/{order_ID}", deletePurchaseByID)
+
+	serve := func(method, path, raw string, session auth.Session) *httptest.ResponseRecorder {
+		request, err := http.NewRequest(method, "/api/v2/store/order/1"+path, strings.NewReader(raw))
+		if err != nil {
+			log.Println(err)
+		}
+
+		request = request.WithContext(auth.NewContext(request.Context(), session))
+		request = addToCtxWriteTimeout(request)
+		response := httptest.NewRecorder()
+
+		r.ServeHTTP(response, request)
+
+		return response
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetStock()
+
+			response := serve("POST", "/payment", `{"token": "tok_visa", "capture": true}`, owner)
+			assert.Equal(t, http.StatusOK, response.Code)
+
+			response = serve(tt.method, tt.path, "", tt.session)
+			assert.Equal(t, http.StatusOK, response.Code)
+
+			response = serve("GET", "/refund", "", owner)
+			assert.Equal(t, http.StatusOK, response.Code)
+			assert.Contains(t, response.Body.String(), `"amount":420,`+tt.wantReason)
+		})
+	}
+}
+
+// TestHandler_captureCancelledOrder checks that a capture saved after
+// the order is cancelled is refunded and reported as a conflict.
+func TestHandler_captureCancelledOrder(t *testing.T) {
+	resetStock()
+	defer resetStock()
+
+	admin := auth.Session{UserID: 2, UserStatus: models.UserStatusAdmin}
+	owner := auth.Session{UserID: 1}
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		raw        string
+		session    auth.Session
+		wantCode   int
+		wantStatus string
+	}{
+		{name: "authorize", method: "POST", path: "/payment", raw: `{"token": "tok_visa"}`, session: owner,
+			wantCode: http.StatusOK, wantStatus: `"status":"authorized"`},
+		{name: "cancel", method: "POST", path: "/cancel", session: owner,
+			wantCode: http.StatusOK, wantStatus: `"status":"cancelled"`},
+		{name: "capture", method: "POST", path: "/payment/capture", session: admin,
+			wantCode: http.StatusConflict, wantStatus: `"message":"can't capture payment"`},
+		{name: "payment is captured", method: "GET", path: "/payment", session: owner,
+			wantCode: http.StatusOK, wantStatus: `"status":"captured"`},
+		{name: "capture is refunded", method: "GET", path: "/refund", session: owner,
+			wantCode: http.StatusOK, wantStatus: `"amount":420,"reason":"order is cancelled before the capture"`},
+	}
+
+	r := chi.NewRouter()
+	r.Get("/api/v2/store/order/{order_ID}/payment", getPayments)
+	r.Post("/api/v2/store/order/{order_ID}/payment", payOrder)
+	r.Post("/api/v2/store/order/{order_ID}/payment/capture", capturePayment)
+	r.Get("/api/v2/store/order/{order_ID}/refund", getRefunds)
+	r.Post("/api/v2/store/order/{order_ID}/cancel", cancelOrder)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request, err := http.NewRequest(tt.method, "/api/v2/store/order/1"+tt.path, strings.NewReader(tt.raw))
+			if err != nil {
+				log.Println(err)
+			}
+
+			request = request.WithContext(auth.NewContext(request.Context(), tt.session))
+			request = addToCtxWriteTimeout(request)
+			response := httptest.NewRecorder()
+
+			r.ServeHTTP(response, request)
+			assert.Equal(t, tt.wantCode, response.Code)
+			assert.Contains(t, response.Body.String(), tt.wantStatus)
+		})
+	}
+
+	refunds, err := db.GetRefundDI().GetRefunds(context.Background(), 1)
+	assert.NoError(t, err)
+	if assert.Len(t, refunds, 1) {
+		assert.Equal(t, models.RefundStatusPending, refunds[0].Status)
+	}
+}
+
+// TestHandler_refundOrderRefused checks that a refund the provider
+// refuses fails in the ledger and leaves the payment captured.
+func TestHandler_refundOrderRefused(t *testing.T) {
+	resetStock()
+
+	ctx := context.Background()
+	payment, err := db.GetPaymentDI().CreatePayment(ctx, &models.Payment{OrderID: 1, Provider: "fake",
+		Amount: 420, Status: models.PaymentStatusPending})
+	assert.NoError(t, err)
+
+	for _, status := range []string{models.PaymentStatusAuthorized, models.PaymentStatusCaptured} {
+		payment.Status = status
+		payment.Reference = "fake_1_99"
+		assert.NoError(t, db.GetPaymentDI().UpdatePayment(ctx, payment))
+	}
+
+	admin := auth.Session{UserID: 2, UserStatus: models.UserStatusAdmin}
+
+	r := chi.NewRouter()
+	r.Get("/api/v2/store/order/{order_ID}/refund", getRefunds)
+	r.Post("/api/v2/store/order/{order_ID}/refund", refundOrder)
+
+	serve := func(method, raw string) *httptest.ResponseRecorder {
+		request, err := http.NewRequest(method, "/api/v2/store/order/1/refund", strings.NewReader(raw))
+		if err != nil {
+			log.Println(err)
+		}
+
+		request = request.WithContext(auth.NewContext(request.Context(), admin))
+		request = addToCtxWriteTimeout(request)
+		response := httptest.NewRecorder()
+
+		r.ServeHTTP(response, request)
+
+		return response
+	}
+
+	response := serve("POST", `{"reason": "damaged cage"}`)
+	assert.Equal(t, http.StatusConflict, response.Code)
+
+	response = serve("GET", "")
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), `"amount":420,"reason":"damaged cage"`)
+	assert.Contains(t, response.Body.String(), `"status":"failed","attempts":1`)
+
+	list, err := db.GetPaymentDI().GetPayments(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, models.PaymentStatusCaptured, list.Last(models.PaymentStatusCaptured).Status)
+}
@@ -2,28 +2,52 @@ package handler
 
 import (
 	"fmt"
+	"io"
 	"io/ioutil"
+	"mime/multipart"
 	"net/http"
 	"strconv"
 	"strings"
 
 	"github.com/IamStubborN/petstore/db"
 	"github.com/IamStubborN/petstore/db/models"
+	"github.com/IamStubborN/petstore/fileserver"
 	"github.com/IamStubborN/petstore/workers/api/mware"
 	"github.com/go-chi/chi"
+	uuid "github.com/nu7hatch/gouuid"
 	"github.com/pkg/errors"
 	"gopkg.in/validator.v2"
 )
 
+const (
+	petImagesBucket = "pets"
+	maxImageSize    = 5 << 20
+	maxImagesCount  = 10
+	// multipartOverhead leaves room for the boundaries
+	// and headers of the parts in the request body.
+	multipartOverhead = 1 << 20
+)
+
+// imageTypes maps allowed image content types to file extensions.
+var imageTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
 func PetHandlers(r chi.Router) {
 	r.Use(mware.JWT)
+	r.Use(mware.Idempotency)
 	r.Post("/", addPetToStore)
 	r.Put("/", updatePetInStore)
 	r.Get("/findByStatus", findPetsByStatus)
+	r.Get("/findByTags", findPetsByTags)
 	r.Get("/{petID}", getPetByID)
 	r.Post("/{petID}", updatePetByID)
 	r.Delete("/{petID}", deletePetByID)
 	r.Post("/{petID}/uploadImage", updatePetPhotosByID)
+	r.Get("/{petID}/history", getPetStatusHistory)
 }
 
 func addPetToStore(w http.ResponseWriter, r *http.Request) {
@@ -58,7 +82,7 @@ func addPetToStore(w http.ResponseWriter, r *http.Request) {
 	}
 
 	petDI := db.GetPetDI()
-	addedPet, err := petDI.AddPetToStore(ctx, &pet)
+	addedPet, err := petDI.AddPetToStore(ctx, &pet, actorID(r))
 	if err != nil {
 		respond(w, err, http.StatusMethodNotAllowed, "invalid input")
 		return
@@ -110,10 +134,10 @@ func updatePetInStore(w http.ResponseWriter, r *http.Request) {
 	}
 
 	petDI := db.GetPetDI()
-	updatedPet, err := petDI.UpdatePetInStoreByBody(ctx, &pet)
+	updatedPet, err := petDI.UpdatePetInStoreByBody(ctx, &pet, actorID(r))
 	if err != nil {
 		respond(w, errors.Wrap(err, "can't update pet in store"),
-			http.StatusNotFound, "pet not found")
+			errorCode(err, http.StatusNotFound), "can't update pet")
 		return
 	}
 
@@ -142,14 +166,92 @@ func findPetsByStatus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	status := strings.Split(statusQuery, ",")
+	filter := models.PetFilter{
+		Status:     strings.Split(statusQuery, ","),
+		Category:   r.URL.Query().Get("category"),
+		NamePrefix: r.URL.Query().Get("name"),
+		Tag:        r.URL.Query().Get("tag"),
+		SortBy:     r.URL.Query().Get("sort"),
+		After:      r.URL.Query().Get("after"),
+	}
+
+	if limitQuery := r.URL.Query().Get("limit"); limitQuery != "" {
+		limit, err := strconv.Atoi(limitQuery)
+		if err != nil {
+			respond(w, errors.Wrapf(err, "can't cast limit to int [%s]", limitQuery),
+				http.StatusBadRequest, "invalid limit value")
+			return
+		}
+		filter.Limit = limit
+	}
+
+	if err := filter.Validate(); err != nil {
+		respond(w, err, http.StatusBadRequest, "invalid filter value")
+		return
+	}
+
 	PetDI := db.GetPetDI()
-	pets, err := PetDI.FindPetsByStatus(ctx, status)
+	pets, next, err := PetDI.FindPetsByStatus(ctx, filter)
 	if err != nil {
 		respond(w, err, http.StatusBadRequest, "invalid status value")
 		return
 	}
 
+	if next != "" {
+		setNextPageHeaders(w, r, next)
+	}
+
+	data, err := pets.MarshalJSON()
+	if err != nil {
+		respond(w, errors.Wrap(err, "can't marshal data"),
+			http.StatusInternalServerError, "providers error")
+		return
+	}
+
+	if _, err := w.Write(data); err != nil {
+		respond(w, errors.Wrap(err, "can't write response"),
+			http.StatusInternalServerError, "providers error")
+		return
+	}
+}
+
+func findPetsByTags(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := genContext(r)
+	defer cancel()
+
+	var tags []string
+	for _, tagsQuery := range r.URL.Query()["tags"] {
+		for _, tag := range strings.Split(tagsQuery, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+	}
+
+	if len(tags) == 0 {
+		respond(w, errors.New("no tags in query"),
+			http.StatusBadRequest, "invalid tag value")
+		return
+	}
+
+	var matchAll bool
+	switch match := r.URL.Query().Get("match"); match {
+	case "", "any":
+	case "all":
+		matchAll = true
+	default:
+		respond(w, errors.Errorf("unknown match mode [%s]", match),
+			http.StatusBadRequest, "invalid match value")
+		return
+	}
+
+	PetDI := db.GetPetDI()
+	pets, err := PetDI.FindPetsByTags(ctx, tags, matchAll)
+	if err != nil {
+		respond(w, err, http.StatusBadRequest, "invalid tag value")
+		return
+	}
+
 	data, err := pets.MarshalJSON()
 	if err != nil {
 		respond(w, errors.Wrap(err, "can't marshal data"),
@@ -225,9 +327,9 @@ func updatePetByID(w http.ResponseWriter, r *http.Request) {
 	}
 
 	PetDI := db.GetPetDI()
-	if err := PetDI.UpdatePetInStoreByForm(ctx, id, name, status); err != nil {
+	if err := PetDI.UpdatePetInStoreByForm(ctx, id, name, status, actorID(r)); err != nil {
 		respond(w, errors.Wrap(err, "can't update pet"),
-			http.StatusMethodNotAllowed, "invalid input")
+			errorCode(err, http.StatusMethodNotAllowed), "invalid input")
 		return
 	}
 
@@ -265,12 +367,6 @@ func updatePetPhotosByID(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := genContext(r)
 	defer cancel()
 
-	if err := r.ParseForm(); err != nil {
-		respond(w, errors.New("can't parse form"),
-			http.StatusMethodNotAllowed, "invalid input")
-		return
-	}
-
 	slug := strings.TrimPrefix(r.URL.Path, "/api/v2/pet/")
 	slug = strings.TrimSuffix(slug, "/uploadImage")
 	id, err := strconv.ParseInt(slug, 10, 64)
@@ -280,13 +376,133 @@ func updatePetPhotosByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	images := strings.Split(r.FormValue("images"), ",")
+	r.Body = http.MaxBytesReader(w, r.Body, maxImagesCount*maxImageSize+multipartOverhead)
+	if err = r.ParseMultipartForm(maxImageSize); err != nil {
+		respond(w, errors.Wrap(err, "can't parse multipart form"),
+			http.StatusBadRequest, "invalid input")
+		return
+	}
+	defer checkErrors(r.MultipartForm.RemoveAll)
+
+	images := r.MultipartForm.File["file"]
+	if len(images) == 0 || len(images) > maxImagesCount {
+		respond(w, errors.Errorf("bad images count %d", len(images)),
+			http.StatusBadRequest, "invalid input")
+		return
+	}
+
+	for _, image := range images {
+		if image.Size > maxImageSize {
+			respond(w, errors.Errorf("image %s is too large", image.Filename),
+				http.StatusRequestEntityTooLarge, "image is too large")
+			return
+		}
+	}
 
 	PetDI := db.GetPetDI()
-	if err := PetDI.UpdatePetPhotosByID(ctx, id, images); err != nil {
+	if _, err = PetDI.GetPetByID(ctx, id); err != nil {
+		respond(w, err, http.StatusNotFound, "pet not found")
+		return
+	}
+
+	imagesURL := make([]string, 0, len(images))
+	for _, image := range images {
+		file, err := image.Open()
+		if err != nil {
+			respond(w, errors.Wrap(err, "can't open image"),
+				http.StatusBadRequest, "invalid input")
+			return
+		}
+
+		contentType, err := detectImageType(file)
+		if err != nil {
+			checkErrors(file.Close)
+			respond(w, errors.Wrapf(err, "bad image %s", image.Filename),
+				http.StatusUnsupportedMediaType, "unsupported image type")
+			return
+		}
+
+		imageURL, err := putPetImage(id, contentType, file, image.Size)
+		checkErrors(file.Close)
+		if err != nil {
+			respond(w, errors.Wrap(err, "can't upload image"),
+				http.StatusInternalServerError, "file server error")
+			return
+		}
+
+		imagesURL = append(imagesURL, imageURL)
+	}
+
+	if err := PetDI.AddPetPhotosByID(ctx, id, imagesURL); err != nil {
 		respond(w, err, http.StatusBadRequest, "invalid input")
 		return
 	}
 
-	respond(w, nil, http.StatusOK, "success")
+	respond(w, nil, http.StatusOK, strings.Join(imagesURL, ","))
+}
+
+func getPetStatusHistory(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := genContext(r)
+	defer cancel()
+
+	slug := strings.TrimPrefix(r.URL.Path, "/api/v2/pet/")
+	slug = strings.TrimSuffix(slug, "/history")
+	id, err := strconv.ParseInt(slug, 10, 64)
+	if err != nil {
+		respond(w, errors.Wrapf(err, "can't cast slug to int [%s]", slug),
+			http.StatusBadRequest, "invalid ID supplied")
+		return
+	}
+
+	PetDI := db.GetPetDI()
+	history, err := PetDI.GetPetStatusHistory(ctx, id)
+	if err != nil {
+		respond(w, err, errorCode(err, http.StatusInternalServerError), "pet not found")
+		return
+	}
+
+	data, err := history.MarshalJSON()
+	if err != nil {
+		respond(w, errors.Wrap(err, "can't marshal data"),
+			http.StatusInternalServerError, "providers error")
+		return
+	}
+
+	if _, err := w.Write(data); err != nil {
+		respond(w, errors.Wrap(err, "can't write response"),
+			http.StatusInternalServerError, "providers error")
+		return
+	}
+}
+
+// putPetImage uploads the image to the pet images bucket under a new name.
+func putPetImage(id int64, contentType string, file io.Reader, size int64) (string, error) {
+	objectID, err := uuid.NewV4()
+	if err != nil {
+		return "", errors.Wrap(err, "can't generate image name")
+	}
+
+	objectName := fmt.Sprintf("pet-%d/%s%s", id, objectID, imageTypes[contentType])
+
+	return fileserver.GetFM().PutObject(petImagesBucket, objectName, contentType, file, size)
+}
+
+// detectImageType sniffs the content type of an uploaded file and rewinds it.
+func detectImageType(file multipart.File) (string, error) {
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
+
+	contentType := http.DetectContentType(head[:n])
+	if _, ok := imageTypes[contentType]; !ok {
+		return "", errors.Errorf("content type %s is not allowed", contentType)
+	}
+
+	if _, err = file.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	return contentType, nil
 }
@@ -1,13 +1,19 @@
 package handler
 
 import (
+	"bytes"
+	"context"
 	"log"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"strings"
 	"testing"
 
+	"github.com/IamStubborN/petstore/db"
+	"github.com/IamStubborN/petstore/db/models"
+	"github.com/IamStubborN/petstore/workers/api/auth"
 	"github.com/go-chi/chi"
 	"github.com/stretchr/testify/assert"
 )
@@ -87,6 +93,69 @@ func TestHandler_findPetsByStatus(t *testing.T) {
 	assert.Equal(t, 200, response.Code, "OK response is expected")
 }
 
+func TestHandler_findPetsByStatusPaginated(t *testing.T) {
+	r := chi.NewRouter()
+	r.Get("/api/v2/pet/findByStatus", findPetsByStatus)
+
+	reqArgs := url.Values{}
+	reqArgs.Add("status", "available")
+	reqArgs.Add("sort", "price")
+	reqArgs.Add("limit", "1")
+
+	var names []string
+	for reqURL := "/api/v2/pet/findByStatus?" + reqArgs.Encode(); reqURL != ""; {
+		request, err := http.NewRequest("GET", reqURL, nil)
+		if err != nil {
+			log.Println(err)
+		}
+
+		request = addToCtxWriteTimeout(request)
+		response := httptest.NewRecorder()
+
+		r.ServeHTTP(response, request)
+		assert.Equal(t, 200, response.Code, "OK response is expected")
+
+		var pets models.PetList
+		assert.NoError(t, pets.UnmarshalJSON(response.Body.Bytes()))
+		for _, pet := range pets {
+			names = append(names, pet.Name)
+		}
+
+		reqURL = ""
+		if link := response.Header().Get("Link"); link != "" {
+			reqURL = strings.TrimSuffix(strings.TrimPrefix(link, "<"), `>; rel="next"`)
+		}
+	}
+
+	assert.Equal(t, []string{"Soo", "Sylar"}, names)
+}
+
+func TestHandler_findPetsByTags(t *testing.T) {
+	reqArgs := url.Values{}
+	reqArgs.Add("tags", "small,best")
+	reqArgs.Add("tags", "5")
+	reqArgs.Add("match", "all")
+	reqURL, _ := url.Parse(host)
+	reqURL.Path = "/api/v2/pet/findByTags"
+	reqURL.RawQuery = reqArgs.Encode()
+
+	request, err := http.NewRequest("GET", reqURL.String(), nil)
+	if err != nil {
+		log.Println(err)
+	}
+
+	request = addToCtxWriteTimeout(request)
+	response := httptest.NewRecorder()
+
+	r := chi.NewRouter()
+	r.Get("/api/v2/pet/findByTags", findPetsByTags)
+
+	r.ServeHTTP(response, request)
+	assert.Equal(t, 200, response.Code, "OK response is expected")
+	assert.Contains(t, response.Body.String(), `"name":"Soo"`)
+	assert.NotContains(t, response.Body.String(), `"name":"Sylar"`)
+}
+
 func TestHandler_getPetByID(t *testing.T) {
 	request, err := http.NewRequest("GET", "/api/v2/pet/1", nil)
 	if err != nil {
@@ -126,6 +195,121 @@ func TestHandler_updatePetByID(t *testing.T) {
 	assert.Equal(t, 200, response.Code, "OK response is expected")
 }
 
+func TestHandler_updatePetByIDIllegalStatus(t *testing.T) {
+	reqArgs := url.Values{}
+	reqArgs.Add("name", "TestPet")
+	reqArgs.Add("status", "sold")
+
+	request, err := http.NewRequest("POST", "/api/v2/pet/1", nil)
+	if err != nil {
+		log.Println(err)
+	}
+
+	request.PostForm = reqArgs
+
+	request = addToCtxWriteTimeout(request)
+	response := httptest.NewRecorder()
+
+	r := chi.NewRouter()
+	r.Post("/api/v2/pet/{petID}", updatePetByID)
+
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusConflict, response.Code, "Conflict response is expected")
+}
+
+// TestHandler_updateHeldPet checks that a customer can't release or sell
+// a pet an order holds by updating the pet.
+func TestHandler_updateHeldPet(t *testing.T) {
+	resetStock()
+	defer resetStock()
+
+	_, err := db.GetStoreDI().CreateOrder(context.Background(), &models.Order{ID: 1, PetID: 1, Quantity: 1,
+		UserID: 2, Status: models.OrderStatusPlaced})
+	assert.NoError(t, err)
+
+	customer := auth.Session{UserID: 2, UserStatus: models.UserStatusCustomer}
+
+	tests := []struct {
+		name   string
+		status string
+	}{
+		{name: "release", status: models.PetStatusAvailable},
+		{name: "sell", status: models.PetStatusSold},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reqArgs := url.Values{}
+			reqArgs.Add("name", "TestPet")
+			reqArgs.Add("status", tt.status)
+
+			request, err := http.NewRequest("POST", "/api/v2/pet/1", nil)
+			if err != nil {
+				log.Println(err)
+			}
+
+			request.PostForm = reqArgs
+
+			request = addToCtxWriteTimeout(request)
+			request = request.WithContext(auth.NewContext(request.Context(), customer))
+			response := httptest.NewRecorder()
+
+			r := chi.NewRouter()
+			r.Post("/api/v2/pet/{petID}", updatePetByID)
+
+			r.ServeHTTP(response, request)
+			assert.Equal(t, http.StatusConflict, response.Code)
+		})
+	}
+
+	raw := `{"id": 1, "category": {"id": 2, "name": "Cat"}, "name": "TestPet", "status": "available"}`
+	request, err := http.NewRequest("PUT", "/api/v2/pet", strings.NewReader(raw))
+	if err != nil {
+		log.Println(err)
+	}
+
+	request = addToCtxWriteTimeout(request)
+	request = request.WithContext(auth.NewContext(request.Context(), customer))
+	response := httptest.NewRecorder()
+
+	r := chi.NewRouter()
+	r.Put("/api/v2/pet", updatePetInStore)
+
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusConflict, response.Code)
+
+	inventories, err := db.GetStoreDI().GetInventories(context.Background())
+	assert.NoError(t, err)
+	assert.NotZero(t, inventories[models.PetStatusPending], "pet stays pending")
+}
+
+func TestHandler_getPetStatusHistory(t *testing.T) {
+	tests := []struct {
+		name     string
+		id       string
+		wantCode int
+	}{
+		{name: "existing pet", id: "1", wantCode: http.StatusOK},
+		{name: "missing pet", id: "99", wantCode: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request, err := http.NewRequest("GET", "/api/v2/pet/"+tt.id+"/history", nil)
+			if err != nil {
+				log.Println(err)
+			}
+
+			request = addToCtxWriteTimeout(request)
+			response := httptest.NewRecorder()
+
+			r := chi.NewRouter()
+			r.Get("/api/v2/pet/{petID}/history", getPetStatusHistory)
+
+			r.ServeHTTP(response, request)
+			assert.Equal(t, tt.wantCode, response.Code)
+		})
+	}
+}
+
 func TestHandler_updatePetInStore(t *testing.T) {
 	raw := `
 {
@@ -164,24 +348,74 @@ func TestHandler_updatePetInStore(t *testing.T) {
 }
 
 func TestHandler_updatePetPhotosByID(t *testing.T) {
-	reqArgs := url.Values{}
-	reqArgs.Add("images",
-		"https://i.ytimg.com/vi/YCaGYUIfdy4/maxresdefault.jpg,"+
-			"https://d17fnq9dkz9hgj.cloudfront.net/uploads/2012/11/152964589-welcome-home-new-cat-632x475.jpg")
-
-	request, err := http.NewRequest("POST", "/api/v2/pet/1/uploadImage", nil)
-	if err != nil {
-		log.Println(err)
+	tests := []struct {
+		name     string
+		fileName string
+		content  []byte
+		count    int
+		wantCode int
+	}{
+		{
+			name:     "png image",
+			fileName: "cat.png",
+			content:  append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 64)...),
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "text file",
+			fileName: "cat.txt",
+			content:  []byte("definitely not an image"),
+			wantCode: http.StatusUnsupportedMediaType,
+		},
+		{
+			name:     "most images of largest size",
+			fileName: "cat.png",
+			content:  append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, maxImageSize-8)...),
+			count:    maxImagesCount,
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "too many images",
+			fileName: "cat.png",
+			content:  append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 64)...),
+			count:    maxImagesCount + 1,
+			wantCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := &bytes.Buffer{}
+			writer := multipart.NewWriter(body)
+			for i := 0; i < tt.count || i == 0; i++ {
+				part, err := writer.CreateFormFile("file", tt.fileName)
+				if err != nil {
+					log.Println(err)
+				}
+
+				if _, err = part.Write(tt.content); err != nil {
+					log.Println(err)
+				}
+			}
+
+			if err := writer.Close(); err != nil {
+				log.Println(err)
+			}
+
+			request, err := http.NewRequest("POST", "/api/v2/pet/1/uploadImage", body)
+			if err != nil {
+				log.Println(err)
+			}
+
+			request.Header.Set("Content-Type", writer.FormDataContentType())
+
+			request = addToCtxWriteTimeout(request)
+			response := httptest.NewRecorder()
+
+			r := chi.NewRouter()
+			r.Post("/api/v2/pet/{petID}/uploadImage", updatePetPhotosByID)
+
+			r.ServeHTTP(response, request)
+			assert.Equal(t, tt.wantCode, response.Code)
+		})
 	}
-
-	request.PostForm = reqArgs
-
-	request = addToCtxWriteTimeout(request)
-	response := httptest.NewRecorder()
-
-	r := chi.NewRouter()
-	r.Post("/api/v2/pet/{petID}/uploadImage", updatePetPhotosByID)
-
-	r.ServeHTTP(response, request)
-	assert.Equal(t, 200, response.Code, "OK response is expected")
 }
@@ -0,0 +1,232 @@
+package handler
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/IamStubborN/petstore/db"
+	"github.com/IamStubborN/petstore/db/models"
+	"github.com/IamStubborN/petstore/payments"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"gopkg.in/validator.v2"
+)
+
+// getRefunds lists the refund ledger of the order.
+func getRefunds(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := genContext(r)
+	defer cancel()
+
+	id, err := paymentOrderID(r, "/refund")
+	if err != nil {
+		respond(w, err, http.StatusBadRequest, "invalid ID supplied")
+		return
+	}
+
+	if _, code, err := findOwnOrder(ctx, r, id); err != nil {
+		respond(w, err, code, "can't get refunds")
+		return
+	}
+
+	refundDI := db.GetRefundDI()
+	refunds, err := refundDI.GetRefunds(ctx, id)
+	if err != nil {
+		respond(w, err, http.StatusInternalServerError, "providers error")
+		return
+	}
+
+	data, err := refunds.MarshalJSON()
+	if err != nil {
+		respond(w, errors.Wrap(err, "can't marshal data"),
+			http.StatusInternalServerError, "providers error")
+		return
+	}
+
+	if _, err := w.Write(data); err != nil {
+		respond(w, errors.Wrap(err, "can't write response"),
+			http.StatusInternalServerError, "providers error")
+		return
+	}
+}
+
+// refundOrder returns the amount of the captured payment to the customer,
+// everything left is returned when the amount is 0. The order keeps its
+// status, so goodwill refunds of delivered orders are possible.
+// A refund left to the refund worker is accepted as pending.
+func refundOrder(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := genContext(r)
+	defer cancel()
+
+	id, err := paymentOrderID(r, "/refund")
+	if err != nil {
+		respond(w, err, http.StatusBadRequest, "invalid ID supplied")
+		return
+	}
+
+	refund, err := readRefund(r)
+	if err != nil {
+		respond(w, err, http.StatusBadRequest, "invalid input")
+		return
+	}
+
+	payment, refunded, err := findRefundable(ctx, id)
+	if err != nil {
+		respond(w, err, http.StatusInternalServerError, "providers error")
+		return
+	}
+
+	if payment == nil {
+		respond(w, errors.Errorf("order № %d has no captured payment", id),
+			http.StatusConflict, "can't refund order")
+		return
+	}
+
+	refund.OrderID = id
+	refund.PaymentID = payment.ID
+	refund.UserID = actorID(r)
+	if refund.Amount == 0 {
+		refund.Amount = models.RoundPrice(payment.Amount - refunded)
+	}
+
+	if err = makeRefund(ctx, payment, refunded, refund); err != nil {
+		respond(w, err, errorCode(err, providerErrorCode(err)), "can't refund order")
+		return
+	}
+
+	data, err := refund.MarshalJSON()
+	if err != nil {
+		respond(w, errors.Wrap(err, "can't marshal data"),
+			http.StatusInternalServerError, "providers error")
+		return
+	}
+
+	if refund.Status == models.RefundStatusPending {
+		w.WriteHeader(http.StatusAccepted)
+	}
+
+	if _, err := w.Write(data); err != nil {
+		respond(w, errors.Wrap(err, "can't write response"),
+			http.StatusInternalServerError, "providers error")
+		return
+	}
+}
+
+// refundRest returns everything left of the captured payment of the order,
+// orders without a captured payment have nothing to refund. A refund the
+// provider didn't answer is left to the refund worker.
+func refundRest(ctx context.Context, orderID int64, reason string, userID int64) error {
+	payment, refunded, err := findRefundable(ctx, orderID)
+	if err != nil || payment == nil {
+		return err
+	}
+
+	left := models.RoundPrice(payment.Amount - refunded)
+	if left <= 0 {
+		return nil
+	}
+
+	return makeRefund(ctx, payment, refunded, &models.Refund{
+		OrderID:   orderID,
+		PaymentID: payment.ID,
+		Amount:    left,
+		Reason:    reason,
+		UserID:    userID,
+	})
+}
+
+// findRefundable returns the captured payment of the order with the sum of
+// its refunds, the payment is nil when the order has no captured payment.
+func findRefundable(ctx context.Context, orderID int64) (*models.Payment, float64, error) {
+	list, err := db.GetPaymentDI().GetPayments(ctx, orderID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	payment := list.Last(models.PaymentStatusCaptured)
+	if payment == nil {
+		return nil, 0, nil
+	}
+
+	refunds, err := db.GetRefundDI().GetRefunds(ctx, orderID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return payment, refunds.Refunded(payment.ID), nil
+}
+
+// makeRefund reserves the refund in the ledger, returns the money through
+// the payment provider and completes the refund, payments made outside
+// the store are only recorded. A refund the provider refuses fails, so
+// its amount is free again. After other errors the refund is left pending
+// and the refund worker repeats it with the same key.
+func makeRefund(ctx context.Context, payment *models.Payment, refunded float64, refund *models.Refund) error {
+	if err := models.CheckRefund(payment, refunded, refund.Amount); err != nil {
+		return err
+	}
+
+	provider := payments.GetProvider()
+	if payment.Provider != models.PaymentProviderManual && payment.Provider != provider.Name() {
+		return errors.Wrapf(models.ErrConflict, "payment № %d is made through %s, not %s",
+			payment.ID, payment.Provider, provider.Name())
+	}
+
+	refundDI := db.GetRefundDI()
+	if _, err := refundDI.ReserveRefund(ctx, refund); err != nil {
+		return err
+	}
+
+	var err error
+	if refund.Provider != models.PaymentProviderManual {
+		err = provider.Refund(ctx, refund.Reference, refund.Key(), refund.Amount)
+	}
+
+	if err == nil {
+		refund.Complete(time.Now().UTC())
+	} else {
+		refund.Fail(err, !payments.IsFinal(err), time.Now().UTC())
+	}
+
+	saveCtx, cancel := context.WithTimeout(context.Background(), paymentSaveTimeout)
+	defer cancel()
+
+	if saveErr := refundDI.SaveRefund(saveCtx, refund); saveErr != nil {
+		zap.L().Error("can't save refund, it's left to the refund worker",
+			zap.Int64("id", refund.ID), zap.Error(saveErr))
+	}
+
+	switch refund.Status {
+	case models.RefundStatusFailed:
+		return err
+	case models.RefundStatusPending:
+		zap.L().Warn("refund is left to the refund worker",
+			zap.Int64("id", refund.ID), zap.Error(err))
+	}
+
+	return nil
+}
+
+func readRefund(r *http.Request) (*models.Refund, error) {
+	if r.Body == nil {
+		return nil, errors.New("request body is nil")
+	}
+	defer checkErrors(r.Body.Close)
+
+	bytesBody, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't read request body")
+	}
+
+	var refund models.Refund
+	if err = refund.UnmarshalJSON(bytesBody); err != nil {
+		return nil, errors.Wrap(err, "can't decode request body to refund")
+	}
+
+	if err = validator.Validate(refund); err != nil {
+		return nil, errors.Wrap(err, "can't validate refund from body")
+	}
+
+	return &refund, nil
+}
@@ -1,12 +1,15 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
 	"io/ioutil"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/IamStubborN/petstore/workers/api/auth"
 	"github.com/IamStubborN/petstore/workers/api/mware"
 	"gopkg.in/validator.v2"
 
@@ -18,13 +21,48 @@ import (
 
 func StoreHandlers(r chi.Router) {
 	r.Use(mware.JWT)
+	r.Use(mware.Idempotency)
 	r.Get("/inventory", inventoriesByStatus)
 	r.Post("/order", createOrder)
+	r.Get("/order", findOrders)
 	r.Get("/order/{order_ID}", findOrderByID)
-	r.Delete("/order/{order_ID}", deletePurchaseByID)
+	r.Post("/order/{order_ID}/cancel", cancelOrder)
+	r.Get("/order/{order_ID}/payment", getPayments)
+	r.Post("/order/{order_ID}/payment", payOrder)
+	r.Get("/order/{order_ID}/refund", getRefunds)
+	r.Get("/cart", getCart)
+	r.Post("/cart", addCartItem)
+	r.Delete("/cart/{petID}", removeCartItem)
+	r.Post("/cart/checkout", checkoutCart)
+
+	adminGroup := r.Group(nil)
+	adminGroup.Use(mware.Admin)
+	adminGroup.Post("/order/{order_ID}/approve", approveOrder)
+	adminGroup.Post("/order/{order_ID}/deliver", deliverOrder)
+	adminGroup.Post("/order/{order_ID}/payment/capture", capturePayment)
+	adminGroup.Post("/order/{order_ID}/refund", refundOrder)
+	adminGroup.Delete("/order/{order_ID}", deletePurchaseByID)
+	adminGroup.Get("/analytics/sales", getSales)
+	adminGroup.Get("/analytics/categories", getTopCategories)
+	adminGroup.Get("/analytics/orders", getOrderValue)
+	adminGroup.Get("/analytics/users", getUserValues)
+	adminGroup.Get("/invoice", getInvoiceFiles)
+	adminGroup.Post("/invoice", createInvoice)
+	adminGroup.Get("/invoice/{number}", downloadInvoice)
 }
 
+// orderStatusChanger is one of the StoreDI order workflow methods.
+type orderStatusChanger func(ctx context.Context, orderID, userID int64) (*models.Order, error)
+
+// inventoriesByStatus returns the flat ordered quantity per pet status,
+// the group_by, from or to parameters ask for the inventory breakdown.
 func inventoriesByStatus(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("group_by") != "" || query.Get("from") != "" || query.Get("to") != "" {
+		inventoryBreakdown(w, r)
+		return
+	}
+
 	ctx, cancel := genContext(r)
 	defer cancel()
 
@@ -49,6 +87,52 @@ func inventoriesByStatus(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// inventoryBreakdown returns the ordered and stocked pets per
+// category, status or tag, orders are selected by ship date.
+func inventoryBreakdown(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := genContext(r)
+	defer cancel()
+
+	query := r.URL.Query()
+	filter := models.InventoryFilter{GroupBy: models.ParseInventoryGroups(query.Get("group_by"))}
+
+	var err error
+	if filter.ShipFrom, err = parseShipDate(query.Get("from"), false); err != nil {
+		respond(w, err, http.StatusBadRequest, "invalid from value")
+		return
+	}
+
+	if filter.ShipTo, err = parseShipDate(query.Get("to"), true); err != nil {
+		respond(w, err, http.StatusBadRequest, "invalid to value")
+		return
+	}
+
+	if err = filter.Validate(); err != nil {
+		respond(w, err, http.StatusBadRequest, "invalid filter value")
+		return
+	}
+
+	storeDI := db.GetStoreDI()
+	inventory, err := storeDI.GetInventory(ctx, filter)
+	if err != nil {
+		respond(w, err, http.StatusInternalServerError, "providers error")
+		return
+	}
+
+	data, err := inventory.MarshalJSON()
+	if err != nil {
+		respond(w, errors.Wrap(err, "can't marshal data"),
+			http.StatusInternalServerError, "providers error")
+		return
+	}
+
+	if _, err := w.Write(data); err != nil {
+		respond(w, errors.Wrap(err, "can't write response"),
+			http.StatusInternalServerError, "providers error")
+		return
+	}
+}
+
 func createOrder(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := genContext(r)
 	defer cancel()
@@ -74,6 +158,18 @@ func createOrder(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// a new order is placed by the caller, only payment and delivery move it on
+	session, _ := auth.FromContext(r.Context())
+	order.UserID = session.UserID
+	order.Status = models.OrderStatusPlaced
+	order.Complete = false
+
+	if err = order.NormalizeItems(); err != nil {
+		respond(w, errors.Wrap(err, "can't validate order from body"),
+			http.StatusMethodNotAllowed, "invalid input")
+		return
+	}
+
 	if err = validator.Validate(order); err != nil {
 		respond(w, errors.Wrap(err, "can't validate order from body"),
 			http.StatusMethodNotAllowed, "invalid input")
@@ -83,7 +179,7 @@ func createOrder(w http.ResponseWriter, r *http.Request) {
 	orderQI := db.GetStoreDI()
 	createdOrder, err := orderQI.CreateOrder(ctx, &order)
 	if err != nil {
-		respond(w, err, http.StatusBadRequest, "invalid order")
+		respond(w, err, errorCode(err, http.StatusBadRequest), "invalid order")
 		return
 	}
 
@@ -101,6 +197,126 @@ func createOrder(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// findOrders lists orders page by page, customers see only their own orders.
+func findOrders(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := genContext(r)
+	defer cancel()
+
+	query := r.URL.Query()
+	filter := models.OrderFilter{
+		Status: query.Get("status"),
+		After:  query.Get("after"),
+	}
+
+	var err error
+	if raw := query.Get("user_id"); raw != "" {
+		if filter.UserID, err = strconv.ParseInt(raw, 10, 64); err != nil {
+			respond(w, errors.Wrapf(err, "can't cast user_id to int [%s]", raw),
+				http.StatusBadRequest, "invalid user_id value")
+			return
+		}
+	}
+
+	if raw := query.Get("pet_id"); raw != "" {
+		if filter.PetID, err = strconv.ParseInt(raw, 10, 64); err != nil {
+			respond(w, errors.Wrapf(err, "can't cast pet_id to int [%s]", raw),
+				http.StatusBadRequest, "invalid pet_id value")
+			return
+		}
+	}
+
+	if raw := query.Get("complete"); raw != "" {
+		isComplete, err := strconv.ParseBool(raw)
+		if err != nil {
+			respond(w, errors.Wrapf(err, "can't cast complete to bool [%s]", raw),
+				http.StatusBadRequest, "invalid complete value")
+			return
+		}
+		filter.Complete = &isComplete
+	}
+
+	if filter.ShipFrom, err = parseShipDate(query.Get("ship_from"), false); err != nil {
+		respond(w, err, http.StatusBadRequest, "invalid ship_from value")
+		return
+	}
+
+	if filter.ShipTo, err = parseShipDate(query.Get("ship_to"), true); err != nil {
+		respond(w, err, http.StatusBadRequest, "invalid ship_to value")
+		return
+	}
+
+	if raw := query.Get("limit"); raw != "" {
+		if filter.Limit, err = strconv.Atoi(raw); err != nil {
+			respond(w, errors.Wrapf(err, "can't cast limit to int [%s]", raw),
+				http.StatusBadRequest, "invalid limit value")
+			return
+		}
+	}
+
+	session, _ := auth.FromContext(r.Context())
+	if !session.IsAdmin() {
+		if filter.UserID != 0 && filter.UserID != session.UserID {
+			respond(w, errors.Errorf("user № %d can't list orders of user № %d", session.UserID, filter.UserID),
+				http.StatusForbidden, "not your orders")
+			return
+		}
+		filter.UserID = session.UserID
+	}
+
+	if err := filter.Validate(); err != nil {
+		respond(w, err, http.StatusBadRequest, "invalid filter value")
+		return
+	}
+
+	orderQI := db.GetStoreDI()
+	orders, next, err := orderQI.FindOrders(ctx, filter)
+	if err != nil {
+		respond(w, err, http.StatusBadRequest, "invalid filter value")
+		return
+	}
+
+	if next != "" {
+		setNextPageHeaders(w, r, next)
+	}
+
+	data, err := orders.MarshalJSON()
+	if err != nil {
+		respond(w, errors.Wrap(err, "can't marshal data"),
+			http.StatusInternalServerError, "providers error")
+		return
+	}
+
+	if _, err := w.Write(data); err != nil {
+		respond(w, errors.Wrap(err, "can't write response"),
+			http.StatusInternalServerError, "providers error")
+		return
+	}
+}
+
+// parseShipDate accepts RFC3339 times and plain dates,
+// a plain date that ends a range covers the whole day.
+func parseShipDate(value string, isRangeEnd bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, errors.Wrapf(err, "can't parse ship date [%s]", value)
+	}
+
+	if isRangeEnd {
+		t = t.AddDate(0, 0, 1).Add(-time.Microsecond)
+	}
+
+	return t, nil
+}
+
+// findOrderByID returns the order to its owner or an admin.
 func findOrderByID(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := genContext(r)
 	defer cancel()
@@ -120,6 +336,14 @@ func findOrderByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// customers can't tell the orders of others from missing ones
+	session, _ := auth.FromContext(r.Context())
+	if !session.IsAdmin() && order.UserID != session.UserID {
+		respond(w, errors.Errorf("user № %d doesn't own order № %d", session.UserID, id),
+			http.StatusNotFound, "order not found")
+		return
+	}
+
 	data, err := order.MarshalJSON()
 	if err != nil {
 		respond(w, errors.Wrap(err, "can't marshal data"),
@@ -134,6 +358,8 @@ func findOrderByID(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// deletePurchaseByID hides the order from the store, an active order
+// is cancelled and refunded first.
 func deletePurchaseByID(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := genContext(r)
 	defer cancel()
@@ -147,10 +373,88 @@ func deletePurchaseByID(w http.ResponseWriter, r *http.Request) {
 	}
 
 	orderQI := db.GetStoreDI()
-	if err := orderQI.DeleteOrderByID(ctx, id); err != nil {
+	order, err := orderQI.DeleteOrderByID(ctx, id, actorID(r))
+	if err != nil {
 		respond(w, err, http.StatusNotFound, "order not found")
 		return
 	}
 
+	if order.Status == models.OrderStatusCancelled {
+		if err = refundRest(ctx, id, "order deleted", actorID(r)); err != nil {
+			respond(w, err, errorCode(err, providerErrorCode(err)), "order is deleted, but can't be refunded")
+			return
+		}
+	}
+
 	respond(w, nil, http.StatusOK, "success")
 }
+
+func approveOrder(w http.ResponseWriter, r *http.Request) {
+	changeOrderStatus(w, r, "/approve", db.GetStoreDI().ApproveOrder)
+}
+
+func deliverOrder(w http.ResponseWriter, r *http.Request) {
+	changeOrderStatus(w, r, "/deliver", db.GetStoreDI().DeliverOrder)
+}
+
+func cancelOrder(w http.ResponseWriter, r *http.Request) {
+	changeOrderStatus(w, r, "/cancel", db.GetStoreDI().CancelOrder)
+}
+
+// changeOrderStatus moves the order along its workflow,
+// only admins and the customer who placed the order may do it.
+// The payment of a cancelled order is refunded.
+func changeOrderStatus(w http.ResponseWriter, r *http.Request, action string, change orderStatusChanger) {
+	ctx, cancel := genContext(r)
+	defer cancel()
+
+	slug := strings.TrimPrefix(r.URL.Path, "/api/v2/store/order/")
+	slug = strings.TrimSuffix(slug, action)
+	id, err := strconv.ParseInt(slug, 10, 64)
+	if err != nil {
+		respond(w, errors.Wrapf(err, "can't cast slug to int [%s]", slug),
+			http.StatusBadRequest, "invalid ID supplied")
+		return
+	}
+
+	session, _ := auth.FromContext(r.Context())
+	if !session.IsAdmin() {
+		order, err := db.GetStoreDI().FindOrderByID(ctx, id)
+		if err != nil {
+			respond(w, err, http.StatusNotFound, "order not found")
+			return
+		}
+
+		if order.UserID != session.UserID {
+			respond(w, errors.Errorf("user № %d doesn't own order № %d", session.UserID, id),
+				http.StatusForbidden, "not your order")
+			return
+		}
+	}
+
+	order, err := change(ctx, id, session.UserID)
+	if err != nil {
+		respond(w, err, errorCode(err, http.StatusBadRequest), "can't change order status")
+		return
+	}
+
+	if order.Status == models.OrderStatusCancelled {
+		if err = refundRest(ctx, id, "order cancelled", session.UserID); err != nil {
+			respond(w, err, errorCode(err, providerErrorCode(err)), "order is cancelled, but can't be refunded")
+			return
+		}
+	}
+
+	data, err := order.MarshalJSON()
+	if err != nil {
+		respond(w, errors.Wrap(err, "can't marshal data"),
+			http.StatusInternalServerError, "providers error")
+		return
+	}
+
+	if _, err := w.Write(data); err != nil {
+		respond(w, errors.Wrap(err, "can't write response"),
+			http.StatusInternalServerError, "providers error")
+		return
+	}
+}
@@ -1,17 +1,31 @@
 package handler
 
 import (
+	"fmt"
 	"log"
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync"
 	"testing"
 
+	"github.com/IamStubborN/petstore/config"
+	"github.com/IamStubborN/petstore/db"
+	"github.com/IamStubborN/petstore/db/models"
+	"github.com/IamStubborN/petstore/workers/api/auth"
+	"github.com/IamStubborN/petstore/workers/api/mware"
 	"github.com/go-chi/chi"
 	"github.com/stretchr/testify/assert"
 )
 
+// resetStock brings reserved mock pets back to the store.
+func resetStock() {
+	db.InitDatabase(&config.Config{DB: config.DB{Provider: "mockdb"}})
+}
+
 func TestHandler_createOrder(t *testing.T) {
+	resetStock()
+
 	raw := `
 {
   "id": 1,
@@ -29,6 +43,7 @@ func TestHandler_createOrder(t *testing.T) {
 	}
 
 	request = addToCtxWriteTimeout(request)
+	request = request.WithContext(auth.NewContext(request.Context(), auth.Session{UserID: 2}))
 	response := httptest.NewRecorder()
 
 	r := chi.NewRouter()
@@ -38,38 +53,243 @@ func TestHandler_createOrder(t *testing.T) {
 	assert.Equal(t, 200, response.Code, "OK response is expected")
 }
 
-func TestHandler_deletePurchaseByID(t *testing.T) {
-	request, err := http.NewRequest("DELETE", "/api/v2/store/order/1", nil)
+// TestHandler_createOrderPlaced checks that the stored order is placed
+// by the caller whatever status, completion and customer the request has.
+func TestHandler_createOrderPlaced(t *testing.T) {
+	resetStock()
+
+	raw := `{"id": 1, "pet_id": 2, "user_id": 1, "quantity": 1, "status": "delivered", "complete": true}`
+	request, err := http.NewRequest("POST", "/api/v2/store/order", strings.NewReader(raw))
 	if err != nil {
 		log.Println(err)
 	}
 
 	request = addToCtxWriteTimeout(request)
+	request = request.WithContext(auth.NewContext(request.Context(), auth.Session{UserID: 2}))
 	response := httptest.NewRecorder()
 
 	r := chi.NewRouter()
-	r.Delete("/api/v2/store/order/{orderID}", deletePurchaseByID)
+	r.Post("/api/v2/store/order", createOrder)
 
 	r.ServeHTTP(response, request)
-	assert.Equal(t, 200, response.Code, "OK response is expected")
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	var order models.Order
+	assert.NoError(t, order.UnmarshalJSON(response.Body.Bytes()))
+	assert.Equal(t, models.OrderStatusPlaced, order.Status)
+	assert.False(t, order.Complete)
+	assert.Equal(t, int64(2), order.UserID)
 }
 
-func TestHandler_findOrderByID(t *testing.T) {
-	request, err := http.NewRequest("GET", "/api/v2/store/order/1", nil)
+func TestHandler_createOrderParallel(t *testing.T) {
+	resetStock()
+
+	r := chi.NewRouter()
+	r.Post("/api/v2/store/order", createOrder)
+
+	const customers = 20
+	codes := make(chan int, customers)
+
+	var wg sync.WaitGroup
+	for userID := 1; userID <= customers; userID++ {
+		wg.Add(1)
+		go func(userID int) {
+			defer wg.Done()
+
+			raw := fmt.Sprintf(`{"id": 1, "pet_id": 1, "user_id": %d, "quantity": 1, "status": "placed"}`, userID)
+			request, err := http.NewRequest("POST", "/api/v2/store/order", strings.NewReader(raw))
+			if err != nil {
+				log.Println(err)
+			}
+
+			request = addToCtxWriteTimeout(request)
+			request = request.WithContext(auth.NewContext(request.Context(), auth.Session{UserID: int64(userID)}))
+			response := httptest.NewRecorder()
+
+			r.ServeHTTP(response, request)
+			codes <- response.Code
+		}(userID)
+	}
+
+	wg.Wait()
+	close(codes)
+
+	counts := make(map[int]int)
+	for code := range codes {
+		counts[code]++
+	}
+
+	assert.Equal(t, map[int]int{
+		http.StatusOK:       1,
+		http.StatusConflict: customers - 1,
+	}, counts, "only one customer can reserve the pet")
+}
+
+func TestHandler_createOrderNotEnoughQuantity(t *testing.T) {
+	resetStock()
+
+	raw := `{"id": 1, "pet_id": 2, "user_id": 2, "quantity": 13, "status": "placed"}`
+	request, err := http.NewRequest("POST", "/api/v2/store/order", strings.NewReader(raw))
 	if err != nil {
 		log.Println(err)
 	}
 
 	request = addToCtxWriteTimeout(request)
+	request = request.WithContext(auth.NewContext(request.Context(), auth.Session{UserID: 2}))
 	response := httptest.NewRecorder()
 
 	r := chi.NewRouter()
-	r.Get("/api/v2/store/order/{orderID}", findOrderByID)
+	r.Post("/api/v2/store/order", createOrder)
+
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusConflict, response.Code, "Conflict response is expected")
+}
+
+func TestHandler_createOrderIdempotent(t *testing.T) {
+	resetStock()
+
+	r := chi.NewRouter()
+	r.With(mware.Idempotency).Post("/api/v2/store/order", createOrder)
+
+	raw := `{"id": 1, "pet_id": 1, "user_id": 1, "quantity": 1, "status": "placed"}`
+	tests := []struct {
+		name         string
+		key          string
+		raw          string
+		wantCode     int
+		wantReplayed bool
+	}{
+		{name: "first request", key: "order-1", raw: raw, wantCode: http.StatusOK},
+		{name: "retried request", key: "order-1", raw: raw, wantCode: http.StatusOK, wantReplayed: true},
+		{name: "key reused for another body", key: "order-1", raw: strings.Replace(raw, `"quantity": 1`, `"quantity": 2`, 1),
+			wantCode: http.StatusUnprocessableEntity},
+		{name: "new key", key: "order-2", raw: raw, wantCode: http.StatusConflict},
+		{name: "too long key", key: strings.Repeat("k", 256), raw: raw, wantCode: http.StatusBadRequest},
+	}
+
+	var firstBody string
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request, err := http.NewRequest("POST", "/api/v2/store/order", strings.NewReader(tt.raw))
+			if err != nil {
+				log.Println(err)
+			}
+			request.Header.Set("Idempotency-Key", tt.key)
+
+			request = addToCtxWriteTimeout(request)
+			request = request.WithContext(auth.NewContext(request.Context(), auth.Session{UserID: 1}))
+			response := httptest.NewRecorder()
+
+			r.ServeHTTP(response, request)
+			assert.Equal(t, tt.wantCode, response.Code)
+			assert.Equal(t, tt.wantReplayed, response.Header().Get("Idempotent-Replayed") == "true")
+
+			if firstBody == "" {
+				firstBody = response.Body.String()
+			}
+			if tt.wantReplayed {
+				assert.Equal(t, firstBody, response.Body.String())
+			}
+		})
+	}
+}
+
+// TestHandler_createOrderIdempotentLimit checks that bodies over the limit
+// are refused and responses over it aren't replayed.
+func TestHandler_createOrderIdempotentLimit(t *testing.T) {
+	resetStock()
+
+	cfg := &config.Config{}
+	cfg.API.IdempotencyBodyLimit = 100
+	mware.InitIdempotency(cfg)
+	defer mware.InitIdempotency(&config.Config{})
+
+	r := chi.NewRouter()
+	r.With(mware.Idempotency).Post("/api/v2/store/order", createOrder)
+
+	raw := `{"id": 1, "pet_id": 1, "quantity": 1}`
+	tests := []struct {
+		name     string
+		key      string
+		raw      string
+		wantCode int
+	}{
+		{name: "body over the limit", key: "order-1", raw: raw + strings.Repeat(" ", 100),
+			wantCode: http.StatusRequestEntityTooLarge},
+		{name: "response over the limit", key: "order-1", raw: raw, wantCode: http.StatusOK},
+		{name: "retried request isn't replayed", key: "order-1", raw: raw, wantCode: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request, err := http.NewRequest("POST", "/api/v2/store/order", strings.NewReader(tt.raw))
+			if err != nil {
+				log.Println(err)
+			}
+			request.Header.Set("Idempotency-Key", tt.key)
+
+			request = addToCtxWriteTimeout(request)
+			request = request.WithContext(auth.NewContext(request.Context(), auth.Session{UserID: 1}))
+			response := httptest.NewRecorder()
+
+			r.ServeHTTP(response, request)
+			assert.Equal(t, tt.wantCode, response.Code)
+			assert.Empty(t, response.Header().Get("Idempotent-Replayed"))
+		})
+	}
+}
+
+func TestHandler_deletePurchaseByID(t *testing.T) {
+	resetStock()
+
+	request, err := http.NewRequest("DELETE", "/api/v2/store/order/1", nil)
+	if err != nil {
+		log.Println(err)
+	}
+
+	request = addToCtxWriteTimeout(request)
+	response := httptest.NewRecorder()
+
+	r := chi.NewRouter()
+	r.Delete("/api/v2/store/order/{orderID}", deletePurchaseByID)
 
 	r.ServeHTTP(response, request)
 	assert.Equal(t, 200, response.Code, "OK response is expected")
 }
 
+func TestHandler_findOrderByID(t *testing.T) {
+	tests := []struct {
+		name     string
+		session  auth.Session
+		wantCode int
+	}{
+		{name: "owner", session: auth.Session{UserID: 1, UserStatus: models.UserStatusCustomer},
+			wantCode: http.StatusOK},
+		{name: "admin", session: auth.Session{UserID: 2, UserStatus: models.UserStatusAdmin},
+			wantCode: http.StatusOK},
+		{name: "another customer", session: auth.Session{UserID: 2, UserStatus: models.UserStatusCustomer},
+			wantCode: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request, err := http.NewRequest("GET", "/api/v2/store/order/1", nil)
+			if err != nil {
+				log.Println(err)
+			}
+
+			request = addToCtxWriteTimeout(request)
+			request = request.WithContext(auth.NewContext(request.Context(), tt.session))
+			response := httptest.NewRecorder()
+
+			r := chi.NewRouter()
+			r.Get("/api/v2/store/order/{orderID}", findOrderByID)
+
+			r.ServeHTTP(response, request)
+			assert.Equal(t, tt.wantCode, response.Code)
+		})
+	}
+}
+
 func TestHandler_inventoriesByStatus(t *testing.T) {
 	request, err := http.NewRequest("GET", "/api/v2/store/order/inventory", nil)
 	if err != nil {
@@ -85,3 +305,193 @@ func TestHandler_inventoriesByStatus(t *testing.T) {
 	r.ServeHTTP(response, request)
 	assert.Equal(t, 200, response.Code, "OK response is expected")
 }
+
+func TestHandler_inventoryBreakdown(t *testing.T) {
+	resetStock()
+
+	tests := []struct {
+		name     string
+		query    string
+		wantCode int
+		wantBody string
+	}{
+		{name: "flat", query: "",
+			wantCode: http.StatusOK, wantBody: `{"available":353,"pending":853,"sold":351}`},
+		{name: "by status", query: "?group_by=status",
+			wantCode: http.StatusOK, wantBody: `[{"status":"available","ordered":16,"on_hand":13}]`},
+		{name: "by category and status", query: "?group_by=status,category",
+			wantCode: http.StatusOK, wantBody: `[` +
+				`{"category":"Cat","status":"available","ordered":13,"on_hand":1},` +
+				`{"category":"Dog","status":"available","ordered":3,"on_hand":12}]`},
+		{name: "by tag", query: "?group_by=tag",
+			wantCode: http.StatusOK, wantBody: `[` +
+				`{"tag":"average","ordered":3,"on_hand":12},` +
+				`{"tag":"best","ordered":13,"on_hand":1},` +
+				`{"tag":"cool","ordered":13,"on_hand":1},` +
+				`{"tag":"large","ordered":3,"on_hand":12},` +
+				`{"tag":"small","ordered":16,"on_hand":13}]`},
+		{name: "date range", query: "?from=2019-09-06&to=2019-09-06",
+			wantCode: http.StatusOK, wantBody: `[{"status":"available","ordered":1,"on_hand":13}]`},
+		{name: "unknown group", query: "?group_by=color",
+			wantCode: http.StatusBadRequest},
+		{name: "bad date", query: "?from=yesterday",
+			wantCode: http.StatusBadRequest},
+		{name: "empty range", query: "?from=2019-09-07&to=2019-09-06",
+			wantCode: http.StatusBadRequest},
+	}
+
+	r := chi.NewRouter()
+	r.Get("/api/v2/store/inventory", inventoriesByStatus)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request, err := http.NewRequest("GET", "/api/v2/store/inventory"+tt.query, nil)
+			if err != nil {
+				log.Println(err)
+			}
+
+			request = addToCtxWriteTimeout(request)
+			response := httptest.NewRecorder()
+
+			r.ServeHTTP(response, request)
+			assert.Equal(t, tt.wantCode, response.Code)
+			if tt.wantBody != "" {
+				assert.Equal(t, tt.wantBody, response.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandler_changeOrderStatus(t *testing.T) {
+	resetStock()
+
+	admin := auth.Session{UserID: 2, UserStatus: models.UserStatusAdmin}
+	owner := auth.Session{UserID: 1}
+	stranger := auth.Session{UserID: 3}
+
+	tests := []struct {
+		name     string
+		action   string
+		handler  http.HandlerFunc
+		session  auth.Session
+		id       string
+		wantCode int
+	}{
+		{name: "approve unpaid order", action: "approve", handler: approveOrder, session: admin, id: "1",
+			wantCode: http.StatusConflict},
+		{name: "deliver placed order", action: "deliver", handler: deliverOrder, session: admin, id: "1",
+			wantCode: http.StatusConflict},
+		{name: "approve missing order", action: "approve", handler: approveOrder, session: admin, id: "99",
+			wantCode: http.StatusNotFound},
+		{name: "cancel own order", action: "cancel", handler: cancelOrder, session: owner, id: "1", wantCode: http.StatusOK},
+		{name: "cancel foreign order", action: "cancel", handler: cancelOrder, session: stranger, id: "1",
+			wantCode: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reqURL := "/api/v2/store/order/" + tt.id + "/" + tt.action
+			request, err := http.NewRequest("POST", reqURL, nil)
+			if err != nil {
+				log.Println(err)
+			}
+
+			request = request.WithContext(auth.NewContext(request.Context(), tt.session))
+			request = addToCtxWriteTimeout(request)
+			response := httptest.NewRecorder()
+
+			r := chi.NewRouter()
+			r.Post("/api/v2/store/order/{order_ID}/"+tt.action, tt.handler)
+
+			r.ServeHTTP(response, request)
+			assert.Equal(t, tt.wantCode, response.Code)
+		})
+	}
+}
+
+func TestHandler_findOrders(t *testing.T) {
+	admin := auth.Session{UserID: 2, UserStatus: models.UserStatusAdmin}
+	customer := auth.Session{UserID: 1}
+
+	tests := []struct {
+		name     string
+		session  auth.Session
+		query    string
+		wantIDs  []int64
+		wantCode int
+	}{
+		{name: "admin sees all orders", session: admin, query: "", wantIDs: []int64{1, 2, 3}, wantCode: http.StatusOK},
+		{name: "admin filters by pet", session: admin, query: "pet_id=2&complete=false", wantIDs: []int64{2},
+			wantCode: http.StatusOK},
+		{name: "customer sees own orders", session: customer, query: "", wantIDs: []int64{1, 3}, wantCode: http.StatusOK},
+		{name: "customer filters by ship date", session: customer, query: "ship_from=2019-09-06&ship_to=2019-09-07",
+			wantIDs: []int64{3}, wantCode: http.StatusOK},
+		{name: "customer asks foreign orders", session: customer, query: "user_id=2", wantCode: http.StatusForbidden},
+		{name: "bad status", session: admin, query: "status=lost", wantCode: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request, err := http.NewRequest("GET", "/api/v2/store/order?"+tt.query, nil)
+			if err != nil {
+				log.Println(err)
+			}
+
+			request = request.WithContext(auth.NewContext(request.Context(), tt.session))
+			request = addToCtxWriteTimeout(request)
+			response := httptest.NewRecorder()
+
+			r := chi.NewRouter()
+			r.Get("/api/v2/store/order", findOrders)
+
+			r.ServeHTTP(response, request)
+			assert.Equal(t, tt.wantCode, response.Code)
+
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+
+			var orders models.OrderList
+			assert.NoError(t, orders.UnmarshalJSON(response.Body.Bytes()))
+
+			var ids []int64
+			for _, order := range orders {
+				ids = append(ids, order.ID)
+			}
+			assert.Equal(t, tt.wantIDs, ids)
+		})
+	}
+}
+
+func TestHandler_findOrdersPaginated(t *testing.T) {
+	r := chi.NewRouter()
+	r.Get("/api/v2/store/order", findOrders)
+
+	admin := auth.Session{UserID: 2, UserStatus: models.UserStatusAdmin}
+
+	var ids []int64
+	for reqURL := "/api/v2/store/order?limit=2"; reqURL != ""; {
+		request, err := http.NewRequest("GET", reqURL, nil)
+		if err != nil {
+			log.Println(err)
+		}
+
+		request = request.WithContext(auth.NewContext(request.Context(), admin))
+		request = addToCtxWriteTimeout(request)
+		response := httptest.NewRecorder()
+
+		r.ServeHTTP(response, request)
+		assert.Equal(t, 200, response.Code, "OK response is expected")
+
+		var orders models.OrderList
+		assert.NoError(t, orders.UnmarshalJSON(response.Body.Bytes()))
+		for _, order := range orders {
+			ids = append(ids, order.ID)
+		}
+
+		reqURL = ""
+		if link := response.Header().Get("Link"); link != "" {
+			reqURL = strings.TrimSuffix(strings.TrimPrefix(link, "<"), `>; rel="next"`)
+		}
+	}
+
+	assert.Equal(t, []int64{1, 2, 3}, ids)
+}
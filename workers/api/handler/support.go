@@ -3,9 +3,13 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"regexp"
 
+	"github.com/IamStubborN/petstore/db/models"
+	"github.com/IamStubborN/petstore/workers/api/auth"
+	"github.com/pkg/errors"
 	"go.uber.org/zap"
 )
 
@@ -33,6 +37,26 @@ func respond(w http.ResponseWriter, err error, code int, message string) {
 	}
 }
 
+// errorCode picks the response code for errors wrapped
+// around models errors, other errors get the fallback code.
+func errorCode(err error, fallback int) int {
+	switch errors.Cause(err) {
+	case models.ErrNotFound:
+		return http.StatusNotFound
+	case models.ErrConflict, models.ErrInvalidTransition:
+		return http.StatusConflict
+	default:
+		return fallback
+	}
+}
+
+// actorID returns the id of the user making the request,
+// requests without a session are made by nobody, id 0.
+func actorID(r *http.Request) int64 {
+	session, _ := auth.FromContext(r.Context())
+	return session.UserID
+}
+
 func checkErrors(f func() error) {
 	if err := f(); err != nil {
 		zap.L().Error("error in defer", zap.Error(err))
@@ -44,6 +68,18 @@ func isNotValid(str string) bool {
 	return !reg.MatchString(str)
 }
 
+// setNextPageHeaders points the client to the page after cursor next.
+func setNextPageHeaders(w http.ResponseWriter, r *http.Request, next string) {
+	query := r.URL.Query()
+	query.Set("after", next)
+
+	nextURL := *r.URL
+	nextURL.RawQuery = query.Encode()
+
+	w.Header().Set("X-Next-Cursor", next)
+	w.Header().Set("Link", fmt.Sprintf(`<%s>; rel="next"`, nextURL.RequestURI()))
+}
+
 // Create context 80% of WriteTimeout
 func genContext(r *http.Request) (context.Context, context.CancelFunc) {
 	writeTimeout := r.Context().Value(http.ServerContextKey).(*http.Server).WriteTimeout
@@ -0,0 +1,154 @@
+package handler
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/IamStubborN/petstore/db"
+	"github.com/IamStubborN/petstore/db/models"
+	"github.com/IamStubborN/petstore/workers/api/mware"
+	"github.com/go-chi/chi"
+	"github.com/pkg/errors"
+	"gopkg.in/validator.v2"
+)
+
+func TagHandlers(r chi.Router) {
+	r.Use(mware.JWT)
+	r.Use(mware.Idempotency)
+	r.Get("/", getTags)
+
+	adminGroup := r.Group(nil)
+	adminGroup.Use(mware.Admin)
+	adminGroup.Post("/", createTag)
+	adminGroup.Put("/{tagID}", renameTag)
+	adminGroup.Delete("/{tagID}", deleteTag)
+}
+
+func getTags(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := genContext(r)
+	defer cancel()
+
+	tagDI := db.GetTagDI()
+	tags, err := tagDI.GetTags(ctx)
+	if err != nil {
+		respond(w, err, http.StatusInternalServerError, "providers error")
+		return
+	}
+
+	data, err := tags.MarshalJSON()
+	if err != nil {
+		respond(w, errors.Wrap(err, "can't marshal data"),
+			http.StatusInternalServerError, "providers error")
+		return
+	}
+
+	if _, err := w.Write(data); err != nil {
+		respond(w, errors.Wrap(err, "can't write response"),
+			http.StatusInternalServerError, "providers error")
+		return
+	}
+}
+
+func createTag(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := genContext(r)
+	defer cancel()
+
+	tag, err := readTag(r)
+	if err != nil {
+		respond(w, err, http.StatusBadRequest, "invalid input")
+		return
+	}
+
+	tagDI := db.GetTagDI()
+	createdTag, err := tagDI.CreateTag(ctx, tag)
+	if err != nil {
+		respond(w, err, errorCode(err, http.StatusBadRequest), "can't create tag")
+		return
+	}
+
+	data, err := createdTag.MarshalJSON()
+	if err != nil {
+		respond(w, errors.Wrap(err, "can't marshal data"),
+			http.StatusInternalServerError, "providers error")
+		return
+	}
+
+	if _, err := w.Write(data); err != nil {
+		respond(w, errors.Wrap(err, "can't write response"),
+			http.StatusInternalServerError, "providers error")
+		return
+	}
+}
+
+func renameTag(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := genContext(r)
+	defer cancel()
+
+	slug := strings.TrimPrefix(r.URL.Path, "/api/v2/tag/")
+	id, err := strconv.ParseInt(slug, 10, 64)
+	if err != nil {
+		respond(w, errors.Wrapf(err, "can't cast slug to int [%s]", slug),
+			http.StatusBadRequest, "invalid ID supplied")
+		return
+	}
+
+	tag, err := readTag(r)
+	if err != nil {
+		respond(w, err, http.StatusBadRequest, "invalid input")
+		return
+	}
+
+	tagDI := db.GetTagDI()
+	if err := tagDI.RenameTag(ctx, id, tag.Name); err != nil {
+		respond(w, err, errorCode(err, http.StatusBadRequest), "can't rename tag")
+		return
+	}
+
+	respond(w, nil, http.StatusOK, "success")
+}
+
+func deleteTag(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := genContext(r)
+	defer cancel()
+
+	slug := strings.TrimPrefix(r.URL.Path, "/api/v2/tag/")
+	id, err := strconv.ParseInt(slug, 10, 64)
+	if err != nil {
+		respond(w, errors.Wrapf(err, "can't cast slug to int [%s]", slug),
+			http.StatusBadRequest, "invalid ID supplied")
+		return
+	}
+
+	tagDI := db.GetTagDI()
+	if err := tagDI.DeleteTag(ctx, id); err != nil {
+		respond(w, err, errorCode(err, http.StatusBadRequest), "can't delete tag")
+		return
+	}
+
+	respond(w, nil, http.StatusOK, "success")
+}
+
+func readTag(r *http.Request) (*models.Tag, error) {
+	if r.Body == nil {
+		return nil, errors.New("request body is nil")
+	}
+	defer checkErrors(r.Body.Close)
+
+	bytesBody, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't read request body")
+	}
+
+	var tag models.Tag
+	if err = tag.UnmarshalJSON(bytesBody); err != nil {
+		return nil, errors.Wrap(err, "can't decode request body to tag")
+	}
+
+	if err = validator.Validate(tag); err != nil {
+		return nil, errors.Wrap(err, "can't validate tag from body")
+	}
+
+	return &tag, nil
+}
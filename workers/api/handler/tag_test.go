@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_createTag(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      string
+		wantCode int
+	}{
+		{name: "new tag", raw: `{"name": "fluffy"}`, wantCode: http.StatusOK},
+		{name: "existing tag", raw: `{"name": "small"}`, wantCode: http.StatusConflict},
+		{name: "empty name", raw: `{"name": ""}`, wantCode: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request, err := http.NewRequest("POST", "/api/v2/tag", strings.NewReader(tt.raw))
+			if err != nil {
+				log.Println(err)
+			}
+
+			request = addToCtxWriteTimeout(request)
+			response := httptest.NewRecorder()
+
+			r := chi.NewRouter()
+			r.Post("/api/v2/tag", createTag)
+
+			r.ServeHTTP(response, request)
+			assert.Equal(t, tt.wantCode, response.Code)
+		})
+	}
+}
+
+func TestHandler_getTags(t *testing.T) {
+	request, err := http.NewRequest("GET", "/api/v2/tag", nil)
+	if err != nil {
+		log.Println(err)
+	}
+
+	request = addToCtxWriteTimeout(request)
+	response := httptest.NewRecorder()
+
+	r := chi.NewRouter()
+	r.Get("/api/v2/tag", getTags)
+
+	r.ServeHTTP(response, request)
+	assert.Equal(t, 200, response.Code, "OK response is expected")
+}
+
+func TestHandler_renameTag(t *testing.T) {
+	tests := []struct {
+		name     string
+		id       string
+		wantCode int
+	}{
+		{name: "existing tag", id: "6", wantCode: http.StatusOK},
+		{name: "missing tag", id: "99", wantCode: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request, err := http.NewRequest("PUT", "/api/v2/tag/"+tt.id, strings.NewReader(`{"name": "fluffy"}`))
+			if err != nil {
+				log.Println(err)
+			}
+
+			request = addToCtxWriteTimeout(request)
+			response := httptest.NewRecorder()
+
+			r := chi.NewRouter()
+			r.Put("/api/v2/tag/{tagID}", renameTag)
+
+			r.ServeHTTP(response, request)
+			assert.Equal(t, tt.wantCode, response.Code)
+		})
+	}
+}
+
+func TestHandler_deleteTag(t *testing.T) {
+	tests := []struct {
+		name     string
+		id       string
+		wantCode int
+	}{
+		{name: "unused tag", id: "6", wantCode: http.StatusOK},
+		{name: "used tag", id: "1", wantCode: http.StatusConflict},
+		{name: "missing tag", id: "99", wantCode: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request, err := http.NewRequest("DELETE", "/api/v2/tag/"+tt.id, nil)
+			if err != nil {
+				log.Println(err)
+			}
+
+			request = addToCtxWriteTimeout(request)
+			response := httptest.NewRecorder()
+
+			r := chi.NewRouter()
+			r.Delete("/api/v2/tag/{tagID}", deleteTag)
+
+			r.ServeHTTP(response, request)
+			assert.Equal(t, tt.wantCode, response.Code)
+		})
+	}
+}
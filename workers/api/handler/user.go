@@ -25,11 +25,17 @@ func UserHandlers(r chi.Router) {
 
 	securedGroup := r.Group(nil)
 	securedGroup.Use(mware.JWT)
+	securedGroup.Use(mware.Idempotency)
 	securedGroup.Get("/logout", logout)
 	securedGroup.Post("/createWithList", createUsersFromList)
 	securedGroup.Get("/{username}", getUserByName)
 	securedGroup.Put("/{username}", updateUserByName)
 	securedGroup.Delete("/{username}", deleteUserByName)
+
+	adminGroup := r.Group(nil)
+	adminGroup.Use(mware.JWT)
+	adminGroup.Use(mware.Admin)
+	adminGroup.Put("/{username}/status", setUserStatus)
 }
 
 func createUser(w http.ResponseWriter, r *http.Request) {
@@ -57,6 +63,9 @@ func createUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// users sign up as customers, only an admin grants another status.
+	user.UserStatus = models.UserStatusCustomer
+
 	if err = validator.Validate(user); err != nil {
 		respond(w, errors.Wrap(err, "can't validate user from body"),
 			http.StatusMethodNotAllowed, "invalid input")
@@ -98,6 +107,7 @@ func createUsersFromList(w http.ResponseWriter, r *http.Request) {
 	}
 
 	for _, user := range *users {
+		user.UserStatus = models.UserStatusCustomer
 		if err = validator.Validate(user); err != nil {
 			respond(w, errors.Wrap(err, "can't validate user from body"),
 				http.StatusMethodNotAllowed, "invalid input")
@@ -147,7 +157,7 @@ func login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	token, err := auth.GenerateToken(sessionID.String(), user.ID, allowMethods)
+	token, err := auth.GenerateToken(sessionID.String(), user.ID, user.UserStatus, allowMethods)
 	if err != nil {
 		respond(w, err, http.StatusInternalServerError, "providers server error")
 		return
@@ -237,13 +247,29 @@ func updateUserByName(w http.ResponseWriter, r *http.Request) {
 
 	user.Username = strings.TrimPrefix(r.URL.Path, "/api/v2/user/")
 
+	userDI := db.GetUserDI()
+	stored, err := userDI.GetUserByName(ctx, user.Username)
+	if err != nil {
+		respond(w, err, http.StatusNotFound, "user not found")
+		return
+	}
+
+	session, _ := auth.FromContext(r.Context())
+	if !session.IsAdmin() && session.UserID != stored.ID {
+		respond(w, errors.Errorf("user № %d can't update user %s", session.UserID, user.Username),
+			http.StatusForbidden, "not your user")
+		return
+	}
+
+	// the status is kept, only an admin changes it with setUserStatus.
+	user.UserStatus = stored.UserStatus
+
 	if err := validator.Validate(user); err != nil {
 		respond(w, errors.Wrap(err, "can't validate user from body"),
 			http.StatusBadRequest, "invalid username supplied")
 		return
 	}
 
-	userDI := db.GetUserDI()
 	if err := userDI.UpdateUser(ctx, &user); err != nil {
 		respond(w, err, http.StatusNotFound, "user not found")
 		return
@@ -272,3 +298,65 @@ func deleteUserByName(w http.ResponseWriter, r *http.Request) {
 
 	respond(w, nil, http.StatusOK, "success")
 }
+
+// setUserStatus grants the status to the user, it's the only way
+// to make a user an admin.
+func setUserStatus(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := genContext(r)
+	defer cancel()
+
+	username := strings.TrimPrefix(r.URL.Path, "/api/v2/user/")
+	username = strings.TrimSuffix(username, "/status")
+	if isNotValid(username) {
+		respond(w, fmt.Errorf("bad input %s", username),
+			http.StatusBadRequest, "invalid username supplied")
+		return
+	}
+
+	if r.Body == nil {
+		respond(w, errors.New("request body is nil"),
+			http.StatusBadRequest, "invalid input")
+		return
+	}
+	defer checkErrors(r.Body.Close)
+
+	bytesBody, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		respond(w, errors.Wrap(err, "can't read request body"),
+			http.StatusInternalServerError, "providers server error")
+		return
+	}
+
+	var change models.UserStatusChange
+	if err = change.UnmarshalJSON(bytesBody); err != nil {
+		respond(w, errors.Wrap(err, "can't decode request body to user status"),
+			http.StatusBadRequest, "invalid input")
+		return
+	}
+
+	if err = validator.Validate(change); err != nil {
+		respond(w, errors.Wrap(err, "can't validate user status from body"),
+			http.StatusBadRequest, "invalid input")
+		return
+	}
+
+	userDI := db.GetUserDI()
+	user, err := userDI.SetUserStatus(ctx, username, change.UserStatus)
+	if err != nil {
+		respond(w, err, errorCode(err, http.StatusBadRequest), "can't set user status")
+		return
+	}
+
+	data, err := user.MarshalJSON()
+	if err != nil {
+		respond(w, errors.Wrap(err, "can't marshal data"),
+			http.StatusInternalServerError, "providers error")
+		return
+	}
+
+	if _, err := w.Write(data); err != nil {
+		respond(w, errors.Wrap(err, "can't write response"),
+			http.StatusInternalServerError, "providers error")
+		return
+	}
+}
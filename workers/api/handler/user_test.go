@@ -2,16 +2,22 @@ package handler
 
 import (
 	"context"
+	"fmt"
+	"io/ioutil"
 	"log"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/IamStubborN/petstore/config"
 	"github.com/IamStubborN/petstore/db"
+	"github.com/IamStubborN/petstore/db/models"
+	"github.com/IamStubborN/petstore/fileserver/fileservertest"
+	"github.com/IamStubborN/petstore/payments"
 	"github.com/IamStubborN/petstore/workers/api/auth"
 	"github.com/stretchr/testify/assert"
 
@@ -22,13 +28,27 @@ const host = "http://localhost:5555"
 
 var jwtToken string
 
-//nolint:gochecknoinits
-func init() {
+// TestMain signs the test tokens with keys generated in a temporary
+// folder, so no key is left in the repository.
+func TestMain(m *testing.M) {
+	keysPath, err := ioutil.TempDir("", "petstore-keys")
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	db.InitDatabase(&config.Config{DB: config.DB{Provider: "mockdb"}})
+	fileservertest.Init()
+	payments.InitProvider(&config.Config{Payments: config.Payments{Provider: "fake"}})
 	auth.InitJWTAuth(&config.Config{JWT: config.JWT{
-		KeysPath: "../../../",
+		KeysPath: keysPath,
 		TTL:      time.Minute,
 	}})
+
+	code := m.Run()
+	if err := os.RemoveAll(keysPath); err != nil {
+		log.Println(err)
+	}
+	os.Exit(code)
 }
 
 func TestHandler_createUser(t *testing.T) {
@@ -182,19 +202,115 @@ func TestHandler_updateUserByName(t *testing.T) {
   "user_status_id": 2
 }`
 
-	request, err := http.NewRequest("POST", "/api/v2/user/admin", strings.NewReader(raw))
-	if err != nil {
-		log.Println(err)
+	tests := []struct {
+		name     string
+		session  auth.Session
+		wantCode int
+	}{
+		{name: "own user", session: auth.Session{UserID: 1}, wantCode: http.StatusOK},
+		{name: "other user", session: auth.Session{UserID: 7}, wantCode: http.StatusForbidden},
+		{name: "admin", session: auth.Session{UserID: 7, UserStatus: models.UserStatusAdmin},
+			wantCode: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request, err := http.NewRequest("POST", "/api/v2/user/admin", strings.NewReader(raw))
+			if err != nil {
+				log.Println(err)
+			}
+
+			request = addToCtxWriteTimeout(request)
+			request = request.WithContext(auth.NewContext(request.Context(), tt.session))
+			response := httptest.NewRecorder()
+
+			r := chi.NewRouter()
+			r.Post("/api/v2/user/{username}", updateUserByName)
+
+			r.ServeHTTP(response, request)
+			assert.Equal(t, tt.wantCode, response.Code)
+		})
 	}
+}
 
-	request = addToCtxWriteTimeout(request)
-	response := httptest.NewRecorder()
+// TestHandler_userStatusIgnored signs up and updates users asking for
+// the admin status, the users stay customers and keep their status.
+func TestHandler_userStatusIgnored(t *testing.T) {
+	resetStock()
 
 	r := chi.NewRouter()
-	r.Post("/api/v2/user/{username}", updateUserByName)
+	r.Post("/api/v2/user", createUser)
+	r.Post("/api/v2/user/createWithList", createUsersFromList)
+	r.Put("/api/v2/user/{username}", updateUserByName)
+
+	serve := func(method, url, body string) {
+		request, err := http.NewRequest(method, url, strings.NewReader(body))
+		if err != nil {
+			log.Println(err)
+		}
+
+		request = addToCtxWriteTimeout(request)
+		request = request.WithContext(auth.NewContext(request.Context(), auth.Session{UserID: 1}))
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, request)
+		assert.Equal(t, http.StatusOK, response.Code, "OK response is expected")
+	}
 
-	r.ServeHTTP(response, request)
-	assert.Equal(t, 200, response.Code, "OK response is expected")
+	user := `{"user_name": "%s", "email": "test@gmail.com", "password": "password",` +
+		`"phone": "+3809555555", "user_status_id": %d}`
+	serve("POST", "/api/v2/user", fmt.Sprintf(user, "intruder", models.UserStatusAdmin))
+	serve("POST", "/api/v2/user/createWithList", "["+fmt.Sprintf(user, "intruder2", models.UserStatusAdmin)+"]")
+	serve("PUT", "/api/v2/user/admin", fmt.Sprintf(user, "admin", 4))
+
+	var statuses []string
+	_, err := db.GetOutboxDI().PublishEvents(context.Background(), 10,
+		func(ctx context.Context, event *models.Event) error {
+			var payload models.UserPayload
+			if err := payload.UnmarshalJSON(event.Payload); err != nil {
+				return err
+			}
+			statuses = append(statuses, fmt.Sprintf("%s %s %d", event.Type, payload.Username, payload.UserStatus))
+			return nil
+		})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"user.created intruder 5", "user.created intruder2 5", "user.updated admin 1"},
+		statuses, "sign up makes customers, update keeps the stored status")
+}
+
+func TestHandler_setUserStatus(t *testing.T) {
+	resetStock()
+
+	tests := []struct {
+		name     string
+		url      string
+		raw      string
+		wantCode int
+		wantBody string
+	}{
+		{name: "grant admin", url: "/api/v2/user/admin/status", raw: `{"user_status_id": 1}`,
+			wantCode: http.StatusOK, wantBody: `"user_status_id":1`},
+		{name: "no status", url: "/api/v2/user/admin/status", raw: `{}`,
+			wantCode: http.StatusBadRequest},
+		{name: "unknown user", url: "/api/v2/user/nobody/status", raw: `{"user_status_id": 1}`,
+			wantCode: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request, err := http.NewRequest("PUT", tt.url, strings.NewReader(tt.raw))
+			if err != nil {
+				log.Println(err)
+			}
+
+			request = addToCtxWriteTimeout(request)
+			response := httptest.NewRecorder()
+
+			r := chi.NewRouter()
+			r.Put("/api/v2/user/{username}/status", setUserStatus)
+
+			r.ServeHTTP(response, request)
+			assert.Equal(t, tt.wantCode, response.Code)
+			assert.Contains(t, response.Body.String(), tt.wantBody)
+		})
+	}
 }
 
 func TestHandler_deleteUserByName(t *testing.T) {
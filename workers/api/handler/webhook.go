@@ -0,0 +1,215 @@
+package handler
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/IamStubborN/petstore/db"
+	"github.com/IamStubborN/petstore/db/models"
+	"github.com/IamStubborN/petstore/workers/api/mware"
+	"github.com/go-chi/chi"
+	"github.com/pkg/errors"
+	"gopkg.in/validator.v2"
+)
+
+// WebhookHandlers manage the webhooks partners are called back on.
+func WebhookHandlers(r chi.Router) {
+	r.Use(mware.JWT)
+	r.Use(mware.Admin)
+	r.Use(mware.Idempotency)
+	r.Get("/", getWebhooks)
+	r.Post("/", createWebhook)
+	r.Delete("/{webhookID}", deleteWebhook)
+	r.Post("/{webhookID}/enable", enableWebhook)
+	r.Get("/{webhookID}/delivery", getWebhookDeliveries)
+}
+
+func getWebhooks(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := genContext(r)
+	defer cancel()
+
+	webhookDI := db.GetWebhookDI()
+	webhooks, err := webhookDI.GetWebhooks(ctx)
+	if err != nil {
+		respond(w, err, http.StatusInternalServerError, "providers error")
+		return
+	}
+
+	data, err := webhooks.MarshalJSON()
+	if err != nil {
+		respond(w, errors.Wrap(err, "can't marshal data"),
+			http.StatusInternalServerError, "providers error")
+		return
+	}
+
+	if _, err := w.Write(data); err != nil {
+		respond(w, errors.Wrap(err, "can't write response"),
+			http.StatusInternalServerError, "providers error")
+		return
+	}
+}
+
+func createWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := genContext(r)
+	defer cancel()
+
+	webhook, err := readWebhook(r)
+	if err != nil {
+		respond(w, err, http.StatusBadRequest, "invalid input")
+		return
+	}
+
+	webhookDI := db.GetWebhookDI()
+	createdWebhook, err := webhookDI.CreateWebhook(ctx, webhook)
+	if err != nil {
+		respond(w, err, errorCode(err, http.StatusBadRequest), "can't create webhook")
+		return
+	}
+
+	data, err := createdWebhook.MarshalJSON()
+	if err != nil {
+		respond(w, errors.Wrap(err, "can't marshal data"),
+			http.StatusInternalServerError, "providers error")
+		return
+	}
+
+	if _, err := w.Write(data); err != nil {
+		respond(w, errors.Wrap(err, "can't write response"),
+			http.StatusInternalServerError, "providers error")
+		return
+	}
+}
+
+func deleteWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := genContext(r)
+	defer cancel()
+
+	id, err := webhookID(r, "")
+	if err != nil {
+		respond(w, err, http.StatusBadRequest, "invalid ID supplied")
+		return
+	}
+
+	webhookDI := db.GetWebhookDI()
+	if err := webhookDI.DeleteWebhook(ctx, id); err != nil {
+		respond(w, err, errorCode(err, http.StatusBadRequest), "can't delete webhook")
+		return
+	}
+
+	respond(w, nil, http.StatusOK, "success")
+}
+
+// enableWebhook turns the webhook disabled after failed deliveries back on.
+func enableWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := genContext(r)
+	defer cancel()
+
+	id, err := webhookID(r, "/enable")
+	if err != nil {
+		respond(w, err, http.StatusBadRequest, "invalid ID supplied")
+		return
+	}
+
+	webhookDI := db.GetWebhookDI()
+	webhook, err := webhookDI.EnableWebhook(ctx, id)
+	if err != nil {
+		respond(w, err, errorCode(err, http.StatusBadRequest), "can't enable webhook")
+		return
+	}
+
+	data, err := webhook.MarshalJSON()
+	if err != nil {
+		respond(w, errors.Wrap(err, "can't marshal data"),
+			http.StatusInternalServerError, "providers error")
+		return
+	}
+
+	if _, err := w.Write(data); err != nil {
+		respond(w, errors.Wrap(err, "can't write response"),
+			http.StatusInternalServerError, "providers error")
+		return
+	}
+}
+
+// getWebhookDeliveries returns the delivery history of the webhook,
+// the latest deliveries come first.
+func getWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := genContext(r)
+	defer cancel()
+
+	id, err := webhookID(r, "/delivery")
+	if err != nil {
+		respond(w, err, http.StatusBadRequest, "invalid ID supplied")
+		return
+	}
+
+	limit := models.DefaultPageLimit
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		limit, err = strconv.Atoi(raw)
+		if err != nil || limit <= 0 || limit > models.MaxPageLimit {
+			respond(w, errors.Errorf("limit must be between 1 and %d [%s]", models.MaxPageLimit, raw),
+				http.StatusBadRequest, "invalid limit value")
+			return
+		}
+	}
+
+	webhookDI := db.GetWebhookDI()
+	deliveries, err := webhookDI.GetWebhookDeliveries(ctx, id, limit)
+	if err != nil {
+		respond(w, err, errorCode(err, http.StatusInternalServerError), "can't get webhook deliveries")
+		return
+	}
+
+	data, err := deliveries.MarshalJSON()
+	if err != nil {
+		respond(w, errors.Wrap(err, "can't marshal data"),
+			http.StatusInternalServerError, "providers error")
+		return
+	}
+
+	if _, err := w.Write(data); err != nil {
+		respond(w, errors.Wrap(err, "can't write response"),
+			http.StatusInternalServerError, "providers error")
+		return
+	}
+}
+
+func webhookID(r *http.Request, action string) (int64, error) {
+	slug := strings.TrimPrefix(r.URL.Path, "/api/v2/webhook/")
+	slug = strings.TrimSuffix(slug, action)
+	id, err := strconv.ParseInt(slug, 10, 64)
+	if err != nil {
+		return 0, errors.Wrapf(err, "can't cast slug to int [%s]", slug)
+	}
+
+	return id, nil
+}
+
+func readWebhook(r *http.Request) (*models.Webhook, error) {
+	if r.Body == nil {
+		return nil, errors.New("request body is nil")
+	}
+	defer checkErrors(r.Body.Close)
+
+	bytesBody, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't read request body")
+	}
+
+	var webhook models.Webhook
+	if err = webhook.UnmarshalJSON(bytesBody); err != nil {
+		return nil, errors.Wrap(err, "can't decode request body to webhook")
+	}
+
+	if err = validator.Validate(webhook); err != nil {
+		return nil, errors.Wrap(err, "can't validate webhook from body")
+	}
+
+	if err = webhook.Validate(); err != nil {
+		return nil, errors.Wrap(err, "can't validate webhook from body")
+	}
+
+	return &webhook, nil
+}
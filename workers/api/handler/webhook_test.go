@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"context"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/IamStubborN/petstore/db"
+	"github.com/IamStubborN/petstore/db/models"
+	"github.com/IamStubborN/petstore/webhooks"
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_createWebhook(t *testing.T) {
+	resetStock()
+
+	tests := []struct {
+		name     string
+		raw      string
+		wantCode int
+	}{
+		{name: "new webhook", raw: `{"url": "https://partner.example.com/hooks",` +
+			`"events": ["order.created", "order.delivered"], "secret": "partner-secret-0001"}`,
+			wantCode: http.StatusOK},
+		{name: "unknown event", raw: `{"url": "https://partner.example.com/hooks",` +
+			`"events": ["order.lost"], "secret": "partner-secret-0001"}`,
+			wantCode: http.StatusBadRequest},
+		{name: "no events", raw: `{"url": "https://partner.example.com/hooks", "secret": "partner-secret-0001"}`,
+			wantCode: http.StatusBadRequest},
+		{name: "relative url", raw: `{"url": "/hooks", "events": ["order.created"], "secret": "partner-secret-0001"}`,
+			wantCode: http.StatusBadRequest},
+		{name: "short secret", raw: `{"url": "https://partner.example.com/hooks",` +
+			`"events": ["order.created"], "secret": "secret"}`,
+			wantCode: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request, err := http.NewRequest("POST", "/api/v2/webhook", strings.NewReader(tt.raw))
+			if err != nil {
+				log.Println(err)
+			}
+
+			request = addToCtxWriteTimeout(request)
+			response := httptest.NewRecorder()
+
+			r := chi.NewRouter()
+			r.Post("/api/v2/webhook", createWebhook)
+
+			r.ServeHTTP(response, request)
+			assert.Equal(t, tt.wantCode, response.Code)
+			assert.NotContains(t, response.Body.String(), "partner-secret-0001")
+		})
+	}
+}
+
+// TestHandler_webhookDeliveries registers a webhook at a test server,
+// delivers an order event to it until it's disabled and reads the history.
+func TestHandler_webhookDeliveries(t *testing.T) {
+	resetStock()
+
+	const secret = "partner-secret-0001"
+	var isDown bool
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		timestamp, _ := strconv.ParseInt(r.Header.Get(webhooks.HeaderTimestamp), 10, 64)
+		if !webhooks.Verify(secret, r.Header.Get(webhooks.HeaderSignature), timestamp, body) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		if isDown {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		received = append(received, r.Header.Get(webhooks.HeaderEventType))
+	}))
+	defer server.Close()
+
+	r := chi.NewRouter()
+	r.Post("/api/v2/webhook", createWebhook)
+	r.Get("/api/v2/webhook", getWebhooks)
+	r.Post("/api/v2/webhook/{webhookID}/enable", enableWebhook)
+	r.Get("/api/v2/webhook/{webhookID}/delivery", getWebhookDeliveries)
+
+	serve := func(method, url, body string) *httptest.ResponseRecorder {
+		request, err := http.NewRequest(method, url, strings.NewReader(body))
+		if err != nil {
+			log.Println(err)
+		}
+
+		response := httptest.NewRecorder()
+		r.ServeHTTP(response, addToCtxWriteTimeout(request))
+		return response
+	}
+
+	response := serve("POST", "/api/v2/webhook",
+		`{"url": "`+server.URL+`", "events": ["order.cancelled"], "secret": "`+secret+`"}`)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), `"id":1`)
+
+	ctx := context.Background()
+	client := webhooks.NewClient(time.Second)
+	policy := models.WebhookPolicy{BatchSize: 10, MaxAttempts: 5, MaxFailures: 2}
+
+	if _, err := db.GetStoreDI().CancelOrder(ctx, 1, 1); err != nil {
+		t.Fatalf("CancelOrder() error = %v", err)
+	}
+
+	count, err := db.GetWebhookDI().DeliverWebhooks(ctx, policy, client.Deliver)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, []string{models.EventOrderCancelled}, received)
+
+	isDown = true
+	for i := 0; i < 2; i++ {
+		if _, err = db.GetStoreDI().CancelOrder(ctx, 1, 1); err != nil {
+			t.Fatalf("CancelOrder() error = %v", err)
+		}
+	}
+
+	count, err = db.GetWebhookDI().DeliverWebhooks(ctx, policy, client.Deliver)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count, "both deliveries fail and the webhook is disabled")
+
+	response = serve("GET", "/api/v2/webhook", "")
+	assert.Contains(t, response.Body.String(), `"active":false`)
+	assert.Contains(t, response.Body.String(), `"failures":2`)
+
+	response = serve("GET", "/api/v2/webhook/1/delivery?limit=2", "")
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), `"status":"pending","attempts":1,"response_code":503`)
+	assert.NotContains(t, response.Body.String(), `"status":"delivered"`)
+
+	response = serve("GET", "/api/v2/webhook/1/delivery", "")
+	assert.Contains(t, response.Body.String(), `"status":"delivered"`)
+
+	isDown = false
+	response = serve("POST", "/api/v2/webhook/1/enable", "")
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), `"active":true`)
+
+	response = serve("GET", "/api/v2/webhook/2/delivery", "")
+	assert.Equal(t, http.StatusNotFound, response.Code)
+
+	response = serve("GET", "/api/v2/webhook/1/delivery?limit=0", "")
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+}
@@ -0,0 +1,201 @@
+package mware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/IamStubborN/petstore/config"
+	"github.com/IamStubborN/petstore/db"
+	"github.com/IamStubborN/petstore/db/models"
+	"github.com/IamStubborN/petstore/workers/api/auth"
+	"github.com/go-chi/chi/middleware"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+const (
+	idempotencyKeyHeader    = "Idempotency-Key"
+	idempotentReplayHeader  = "Idempotent-Replayed"
+	maxIdempotencyKeyLength = 255
+	idempotencyTimeout      = 5 * time.Second
+
+	defaultIdempotencyBodyLimit = 1 << 20
+)
+
+// idempotencyBodyLimit caps the request and response bodies kept in memory
+// for a request with an Idempotency-Key.
+var idempotencyBodyLimit int64 = defaultIdempotencyBodyLimit
+
+func InitIdempotency(cfg *config.Config) {
+	idempotencyBodyLimit = cfg.API.IdempotencyBodyLimit
+	if idempotencyBodyLimit <= 0 {
+		idempotencyBodyLimit = defaultIdempotencyBodyLimit
+	}
+}
+
+// Idempotency replays the first response to a POST request retried with
+// the same Idempotency-Key header by the same user. The key can't be reused
+// for another request, failed requests release the key for a retry.
+// Bodies over the limit are refused, responses over it aren't replayed,
+// uploads are bounded by their handlers and aren't replayed either.
+// It must be used after JWT.
+func Idempotency(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(idempotencyKeyHeader)
+		session, ok := auth.FromContext(r.Context())
+		if key == "" || r.Method != http.MethodPost || !ok || isMultipart(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if len(key) > maxIdempotencyKeyLength {
+			writeError(w, http.StatusBadRequest, "Idempotency-Key is too long")
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, idempotencyBodyLimit)
+		requestHash, err := hashRequest(r)
+		if err != nil {
+			zap.L().Info("can't hash request", zap.Error(err))
+			if _, isTooLarge := errors.Cause(err).(*http.MaxBytesError); isTooLarge {
+				writeError(w, http.StatusRequestEntityTooLarge, "request body is too large")
+				return
+			}
+			writeError(w, http.StatusBadRequest, "can't read request body")
+			return
+		}
+
+		// the handler may run longer than idempotencyTimeout, so every
+		// call to the store gets its own context.
+		ctx, cancel := context.WithTimeout(context.Background(), idempotencyTimeout)
+		idempotencyDI := db.GetIdempotencyDI()
+		stored, isReserved, err := idempotencyDI.ReserveIdempotencyKey(ctx, &models.IdempotencyKey{
+			UserID:      session.UserID,
+			Key:         key,
+			RequestHash: requestHash,
+		})
+		cancel()
+		if err != nil {
+			zap.L().Error("can't reserve idempotency key", zap.Error(err))
+			writeError(w, http.StatusConflict, "Idempotency-Key is busy, retry later")
+			return
+		}
+
+		if !isReserved {
+			switch {
+			case stored.RequestHash != requestHash:
+				writeError(w, http.StatusUnprocessableEntity, "Idempotency-Key is already used for another request")
+			case !stored.IsCompleted():
+				writeError(w, http.StatusConflict, "request with this Idempotency-Key is in progress")
+			default:
+				w.Header().Set(idempotentReplayHeader, "true")
+				w.WriteHeader(stored.StatusCode)
+				if _, err := w.Write(stored.Body); err != nil {
+					zap.L().Info("can't write to response", zap.Error(err))
+				}
+			}
+			return
+		}
+
+		var isSaved bool
+		defer func() {
+			if isSaved {
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), idempotencyTimeout)
+			defer cancel()
+			if err := idempotencyDI.ReleaseIdempotencyKey(ctx, session.UserID, key); err != nil {
+				zap.L().Error("can't release idempotency key", zap.Error(err))
+			}
+		}()
+
+		body := cappedBuffer{limit: idempotencyBodyLimit}
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		ww.Tee(&body)
+
+		next.ServeHTTP(ww, r)
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		// server errors are worth a retry, so they aren't replayed
+		if status >= http.StatusInternalServerError {
+			return
+		}
+
+		if body.isOverflown {
+			zap.L().Info("response is too large to be replayed", zap.String("key", key))
+			return
+		}
+
+		stored.StatusCode = status
+		stored.Body = body.Bytes()
+
+		ctx, cancel = context.WithTimeout(context.Background(), idempotencyTimeout)
+		defer cancel()
+		if err := idempotencyDI.SaveIdempotentResponse(ctx, stored); err != nil {
+			zap.L().Error("can't save idempotent response", zap.Error(err))
+			return
+		}
+
+		isSaved = true
+	})
+}
+
+// hashRequest returns the hex sha256 of the request method, URI and body,
+// the body is restored for the next handlers.
+func hashRequest(r *http.Request) (string, error) {
+	hash := sha256.New()
+	hash.Write([]byte(r.Method + " " + r.URL.RequestURI() + "\n"))
+
+	if r.Body != nil {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			return "", errors.Wrap(err, "can't read request body")
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		hash.Write(body)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// isMultipart tells whether the request uploads files.
+func isMultipart(r *http.Request) bool {
+	return strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/")
+}
+
+// cappedBuffer keeps the response up to limit bytes,
+// the rest is dropped and the response isn't kept.
+type cappedBuffer struct {
+	bytes.Buffer
+	limit       int64
+	isOverflown bool
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if b.isOverflown || int64(b.Len()+len(p)) > b.limit {
+		b.isOverflown = true
+		b.Reset()
+		return len(p), nil
+	}
+
+	return b.Buffer.Write(p)
+}
+
+func writeError(w http.ResponseWriter, code int, message string) {
+	w.WriteHeader(code)
+	if _, err := w.Write([]byte(`{"error":"` + message + `"}`)); err != nil {
+		zap.L().Info("can't write to response", zap.Error(err))
+	}
+}
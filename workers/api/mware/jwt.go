@@ -41,7 +41,24 @@ func JWT(next http.Handler) http.Handler {
 		}
 
 		if strings.Contains(claims.AllowMethods, r.Method) {
-			next.ServeHTTP(w, r)
+			next.ServeHTTP(w, r.WithContext(auth.NewContext(r.Context(), claims.Session)))
 		}
 	})
 }
+
+// Admin lets through only the sessions of admin users, it must be used after JWT.
+func Admin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		session, ok := auth.FromContext(r.Context())
+		if !ok || !session.IsAdmin() {
+			zap.L().Info("admin rights required", zap.Int64("user_id", session.UserID))
+			w.WriteHeader(http.StatusForbidden)
+			if _, err := w.Write([]byte(`{"error":"admin rights required"}`)); err != nil {
+				zap.L().Info("can't write to response", zap.Error(err))
+			}
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
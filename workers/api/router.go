@@ -13,7 +13,8 @@ func NewRouter() *chi.Mux {
 	router.Use(cors.New(cors.Options{
 		AllowedOrigins: []string{"*"},
 		AllowedMethods: []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders: []string{"Accept", "Authorization", "Content-Type"},
+		AllowedHeaders: []string{"Accept", "Authorization", "Content-Type", "Idempotency-Key", "Last-Event-ID"},
+		ExposedHeaders: []string{"Link", "X-Next-Cursor", "Idempotent-Replayed"},
 	}).Handler)
 
 	router.Use(middleware.Recoverer)
@@ -26,6 +27,11 @@ func NewRouter() *chi.Mux {
 		r.Route("/pet", handler.PetHandlers)
 		r.Route("/store", handler.StoreHandlers)
 		r.Route("/user", handler.UserHandlers)
+		r.Route("/category", handler.CategoryHandlers)
+		r.Route("/tag", handler.TagHandlers)
+		r.Route("/coupon", handler.CouponHandlers)
+		r.Route("/webhook", handler.WebhookHandlers)
+		r.Route("/events", handler.EventHandlers)
 	})
 
 	return router
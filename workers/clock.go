@@ -0,0 +1,23 @@
+package workers
+
+import "time"
+
+// clock tells the time to the scheduled workers,
+// the tests replace it to move the time on.
+type clock interface {
+	Now() time.Time
+	// NewTimer fires once after d, stop releases the timer
+	// when it isn't needed anymore.
+	NewTimer(d time.Duration) (fire <-chan time.Time, stop func() bool)
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now().UTC()
+}
+
+func (systemClock) NewTimer(d time.Duration) (<-chan time.Time, func() bool) {
+	timer := time.NewTimer(d)
+	return timer.C, timer.Stop
+}
@@ -0,0 +1,43 @@
+package workers
+
+import (
+	"context"
+	"time"
+
+	"github.com/IamStubborN/petstore/schedule"
+	"go.uber.org/zap"
+)
+
+// runScheduled runs job at once and then at every time of the schedule
+// until ctx is done. A job that fails runs again after retry at the latest.
+func runScheduled(ctx context.Context, name string, c clock, s schedule.Schedule, retry time.Duration,
+	job func(context.Context) error) {
+	for {
+		err := job(ctx)
+		if err != nil && ctx.Err() == nil {
+			zap.L().Error("scheduled job failed", zap.String("worker", name), zap.Error(err))
+		}
+
+		now := c.Now()
+		next := s.Next(now)
+		wait, scheduled := next.Sub(now), !next.IsZero()
+		if err != nil && (!scheduled || wait > retry) {
+			wait, scheduled = retry, true
+		}
+
+		fire, stop := make(<-chan time.Time), func() bool { return false }
+		if scheduled {
+			fire, stop = c.NewTimer(wait)
+		} else {
+			zap.L().Warn("schedule has no more runs", zap.String("worker", name))
+		}
+
+		select {
+		case <-fire:
+		case <-ctx.Done():
+			stop()
+			zap.L().Info(name + " worker closed")
+			return
+		}
+	}
+}
@@ -29,6 +29,12 @@ func generateWorker(workerName string, cfg *config.Config) Worker {
 		worker = newAPIWorker(cfg)
 	case "invoice":
 		worker = newInvoiceWorker(cfg)
+	case "outbox":
+		worker = newOutboxWorker(cfg)
+	case "refunds":
+		worker = newRefundWorker(cfg)
+	case "webhooks":
+		worker = newWebhookWorker(cfg)
 	}
 
 	return worker
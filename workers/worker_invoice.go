@@ -2,9 +2,6 @@ package workers
 
 import (
 	"context"
-	"fmt"
-	"os"
-	"path"
 	"strings"
 	"time"
 
@@ -12,145 +9,149 @@ import (
 	"github.com/IamStubborN/petstore/db"
 	"github.com/IamStubborN/petstore/db/models"
 	"github.com/IamStubborN/petstore/fileserver"
-	"github.com/IamStubborN/petstore/templates"
+	"github.com/IamStubborN/petstore/invoices"
+	"github.com/IamStubborN/petstore/schedule"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 )
 
+const (
+	defaultInvoiceFrequency = 24 * time.Hour
+	// invoiceRetryInterval is how soon the periods are tried again
+	// after the worker failed to complete them.
+	invoiceRetryInterval = time.Minute
+)
+
+// InvoiceWorker generates the invoice of every period of the schedule,
+// a period lasts from one time of the schedule to the next. The end of
+// the last completed period is kept in the database, so the periods
+// missed while the worker was down are generated when it's back.
 type InvoiceWorker struct {
-	freq    time.Duration
-	genTime time.Time
+	schedule  schedule.Schedule
+	renderers []invoices.Renderer
+	clock     clock
 }
 
 func newInvoiceWorker(cfg *config.Config) Worker {
-	genTime, err := time.Parse("15:04", cfg.Invoice.GenerateTime)
-	if err != nil {
-		zap.L().Error("can't parse duration", zap.Error(err))
-	}
-
-	return InvoiceWorker{
-		freq:    cfg.Invoice.Frequency,
-		genTime: genTime.UTC(),
-	}
-}
-
-func (iw InvoiceWorker) Run(ctx context.Context) {
-	for {
-		select {
-		case <-time.After(duration(iw.genTime)):
-			go invoiceCycle(iw.freq)
-		case <-ctx.Done():
-			zap.L().Info("invoice worker closed")
-			return
+	var genTime time.Time
+	var err error
+	if cfg.Invoice.Schedule == "" {
+		if genTime, err = time.Parse("15:04", cfg.Invoice.GenerateTime); err != nil {
+			zap.L().Error("can't parse duration", zap.Error(err))
 		}
 	}
-}
 
-func invoiceCycle(freq time.Duration) {
-	generateInvoice(freq)
-	for range time.Tick(freq) {
-		generateInvoice(freq)
+	freq := cfg.Invoice.Frequency
+	if freq <= 0 {
+		freq = defaultInvoiceFrequency
 	}
-}
-
-func generateInvoice(freq time.Duration) {
-	to := time.Now().UTC()
-	from := to.Add(-freq)
 
-	toFormatted := to.Format(time.RFC3339)
-	fromFormatted := from.Format(time.RFC3339)
-
-	storeI := db.GetStoreDI()
-	invoices, err := storeI.CreateInvoiceByDates(context.Background(), fromFormatted, toFormatted)
+	sched, err := schedule.New(cfg.Invoice.Schedule, genTime, freq)
 	if err != nil {
-		zap.L().Error("invoice", zap.Error(err))
-		return
+		zap.L().Fatal("wrong invoice schedule", zap.Error(err))
 	}
 
-	filePath, err := genInvoiceFile(fromFormatted, toFormatted, invoices)
+	renderers, err := invoices.NewRenderers(cfg.Invoice.Formats)
 	if err != nil {
-		zap.L().Error("can't generate template in filePath", zap.Error(err))
-		return
-	}
-
-	fm := fileserver.GetFM()
-	if err = fm.PutFile("invoices", "text/markdown", filePath); err != nil {
-		zap.L().Error("can't put filePath on filePath server", zap.Error(err))
-		return
+		zap.L().Fatal("wrong invoice formats", zap.Error(err))
 	}
 
-	if err = os.Remove(filePath); err != nil {
-		zap.L().Error("can't delete temp filePath", zap.Error(err))
+	return &InvoiceWorker{
+		schedule:  sched,
+		renderers: renderers,
+		clock:     systemClock{},
 	}
 }
 
-func genInvoiceFile(from, to string, invoices []*models.InvoiceItem) (string, error) {
-	fromDate := strings.Split(from, "T")[0]
-	toDate := strings.Split(to, "T")[0]
+func (iw *InvoiceWorker) Run(ctx context.Context) {
+	runScheduled(ctx, "invoice", iw.clock, iw.schedule, invoiceRetryInterval, iw.catchUp)
+}
 
-	invoicesRefactored, err := refactorShipDate(invoices)
+// catchUp generates the invoices of the periods ended since the last
+// completed one in order. The first run only remembers the end of the
+// latest period, nothing before the worker was started is generated.
+func (iw *InvoiceWorker) catchUp(ctx context.Context) error {
+	invoiceDI := db.GetInvoiceDI()
+	now := iw.clock.Now()
+
+	last, err := invoiceDI.GetInvoicePeriod(ctx)
+	if errors.Cause(err) == models.ErrNotFound {
+		first := iw.schedule.Prev(now)
+		if first.IsZero() {
+			first = now
+		}
+		return invoiceDI.CompleteInvoicePeriod(ctx, first)
+	}
 	if err != nil {
-		return "", err
+		return err
 	}
 
-	var data = struct {
-		FromDate   string
-		ToDate     string
-		InvItems   []*models.InvoiceItem
-		TotalPrice string
-	}{fromDate, toDate, invoicesRefactored, calcTotalPrice(invoices)}
+	for to := iw.schedule.Next(last); !to.IsZero() && !to.After(now) && ctx.Err() == nil; to = iw.schedule.Next(to) {
+		if err := generateInvoice(ctx, last, to, iw.renderers); err != nil {
+			return err
+		}
 
-	template, err := templates.GetInvoiceTemplate()
-	if err != nil {
-		return "", err
+		if err := invoiceDI.CompleteInvoicePeriod(ctx, to); err != nil {
+			return err
+		}
+		last = to
 	}
 
-	generatedTime := time.Now().UTC().Format("15:04:05")
-	filePath := path.Join(os.TempDir(), "from_"+fromDate+"_to_"+toDate+"_generated_"+generatedTime+".md")
+	return nil
+}
 
-	file, err := os.Create(filePath)
+// generateInvoice renders the invoice of the period in every format,
+// stores the files and records them in the invoice registry. A format
+// that fails doesn't stop the others, but fails the period, so it's
+// tried again with the formats the period has no file of. A period
+// without orders has no invoice.
+func generateInvoice(ctx context.Context, from, to time.Time, renderers []invoices.Renderer) error {
+	invoice, err := invoices.Generate(ctx, db.GetStoreDI().CreateInvoiceByDates, from, to)
+	if errors.Cause(err) == models.ErrNotFound {
+		zap.L().Info("no invoice for period",
+			zap.Time("from", from), zap.Time("to", to))
+		return nil
+	}
 	if err != nil {
-		return "", err
+		return errors.Wrapf(err, "can't generate invoice from %s to %s",
+			from.Format(time.RFC3339), to.Format(time.RFC3339))
 	}
 
-	if err := template.Execute(file, data); err != nil {
-		return "", err
+	invoiceDI := db.GetInvoiceDI()
+	stored, err := invoiceDI.GetPeriodInvoiceFiles(ctx, from, to)
+	if err != nil {
+		return err
 	}
 
-	return filePath, nil
-}
-
-func calcTotalPrice(invoices []*models.InvoiceItem) string {
-	var price float64
-	for _, item := range invoices {
-		price += float64(item.Quantity) * item.Price
+	formats := make(map[string]bool, len(stored))
+	for _, file := range stored {
+		formats[file.Format] = true
 	}
 
-	return fmt.Sprintf("%.2f", price)
-}
+	fm := fileserver.GetFM()
+	var failed []string
+	for _, renderer := range renderers {
+		if formats[renderer.Format()] {
+			continue
+		}
 
-func refactorShipDate(invoices []*models.InvoiceItem) ([]*models.InvoiceItem, error) {
-	for idx, item := range invoices {
-		shipDate, err := time.Parse(time.RFC3339, item.ShipDate)
+		file, err := invoices.Store(ctx, fm, invoiceDI.AddInvoiceFile, invoice, renderer)
 		if err != nil {
-			return nil, errors.Wrap(err, "can't parse time")
+			zap.L().Error("can't store invoice",
+				zap.String("format", renderer.Format()), zap.Error(err))
+			failed = append(failed, renderer.Format())
+			continue
 		}
 
-		invoices[idx].ShipDate = shipDate.Format("2006-01-02 15:04:05")
+		zap.L().Info("invoice stored",
+			zap.Int64("number", file.Number),
+			zap.String("object", file.ObjectKey))
 	}
 
-	return invoices, nil
-}
-
-func duration(generateTime time.Time) time.Duration {
-	t := time.Now().UTC()
-	n := time.Date(t.Year(), t.Month(), t.Day(), generateTime.Hour(), generateTime.Minute(), 0, 0, t.Location()).UTC()
-
-	if t.After(n) {
-		n = n.Add(24 * time.Hour)
+	if len(failed) > 0 {
+		return errors.Errorf("can't store invoice from %s to %s in %s",
+			from.Format(time.RFC3339), to.Format(time.RFC3339), strings.Join(failed, ", "))
 	}
 
-	d := n.Sub(t)
-
-	return d
+	return nil
 }
@@ -0,0 +1,193 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/IamStubborN/petstore/config"
+	"github.com/IamStubborN/petstore/db"
+	"github.com/IamStubborN/petstore/db/models"
+	"github.com/IamStubborN/petstore/fileserver/fileservertest"
+	"github.com/IamStubborN/petstore/invoices"
+	"github.com/IamStubborN/petstore/schedule"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClock stands still until the test moves it, every timer the worker
+// waits on is reported to the test and fired by it.
+type fakeClock struct {
+	now     time.Time
+	waits   chan time.Duration
+	fire    chan time.Time
+	stopped chan struct{}
+}
+
+func newFakeClock(now time.Time) *fakeClock {
+	return &fakeClock{
+		now:     now,
+		waits:   make(chan time.Duration),
+		fire:    make(chan time.Time),
+		stopped: make(chan struct{}, 1),
+	}
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) NewTimer(d time.Duration) (<-chan time.Time, func() bool) {
+	c.waits <- d
+	return c.fire, func() bool {
+		c.stopped <- struct{}{}
+		return true
+	}
+}
+
+func daily() schedule.Schedule {
+	noon, _ := time.Parse("15:04", "12:00")
+	return schedule.NewInterval(noon, 24*time.Hour)
+}
+
+func newTestInvoiceWorker(c clock, s schedule.Schedule) *InvoiceWorker {
+	db.InitDatabase(&config.Config{DB: config.DB{Provider: "mockdb"}})
+	fileservertest.Init()
+
+	return &InvoiceWorker{
+		schedule:  s,
+		renderers: []invoices.Renderer{invoices.NewCSV()},
+		clock:     c,
+	}
+}
+
+func TestInvoiceWorker_Run(t *testing.T) {
+	day := func(d, h int) time.Time { return time.Date(2019, 9, d, h, 0, 0, 0, time.UTC) }
+
+	c := newFakeClock(day(9, 13))
+	iw := newTestInvoiceWorker(c, daily())
+	ctx, cancel := context.WithCancel(context.Background())
+	invoiceDI := db.GetInvoiceDI()
+	if err := invoiceDI.CompleteInvoicePeriod(ctx, day(6, 12)); err != nil {
+		t.Fatalf("CompleteInvoicePeriod() error = %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		iw.Run(ctx)
+		close(done)
+	}()
+
+	assert.Equal(t, 23*time.Hour, <-c.waits, "waits for the end of the current period")
+
+	last, err := invoiceDI.GetInvoicePeriod(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, day(9, 12), last, "missed periods are completed")
+
+	files, err := invoiceDI.GetInvoiceFiles(ctx, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, files, 2, "the period without orders has no invoice") {
+		assert.Equal(t, []time.Time{day(8, 12), day(9, 12)}, []time.Time{files[0].From, files[0].To})
+		assert.Equal(t, []time.Time{day(7, 12), day(8, 12)}, []time.Time{files[1].From, files[1].To})
+	}
+
+	c.now = day(10, 12)
+	c.fire <- c.now
+	assert.Equal(t, 24*time.Hour, <-c.waits)
+
+	last, err = invoiceDI.GetInvoicePeriod(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, day(10, 12), last)
+
+	cancel()
+	<-done
+	assert.Len(t, c.stopped, 1, "the timer is stopped when the worker is closed")
+}
+
+func TestInvoiceWorker_catchUp(t *testing.T) {
+	now := time.Date(2019, 9, 9, 9, 30, 0, 0, time.UTC)
+	iw := newTestInvoiceWorker(newFakeClock(now), daily())
+	ctx := context.Background()
+	invoiceDI := db.GetInvoiceDI()
+
+	assert.NoError(t, iw.catchUp(ctx))
+	last, err := invoiceDI.GetInvoicePeriod(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2019, 9, 8, 12, 0, 0, 0, time.UTC), last,
+		"the first run starts from the latest period")
+
+	files, err := invoiceDI.GetInvoiceFiles(ctx, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, files, "nothing before the first run is generated")
+}
+
+func TestInvoiceWorker_catchUpCron(t *testing.T) {
+	weekly, err := schedule.Parse("0 6 * * MON")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	iw := newTestInvoiceWorker(newFakeClock(time.Date(2019, 9, 17, 9, 0, 0, 0, time.UTC)), weekly)
+	ctx := context.Background()
+	invoiceDI := db.GetInvoiceDI()
+	if err = invoiceDI.CompleteInvoicePeriod(ctx, time.Date(2019, 9, 2, 6, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("CompleteInvoicePeriod() error = %v", err)
+	}
+
+	assert.NoError(t, iw.catchUp(ctx))
+	last, err := invoiceDI.GetInvoicePeriod(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2019, 9, 16, 6, 0, 0, 0, time.UTC), last)
+
+	files, err := invoiceDI.GetInvoiceFiles(ctx, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, files, 1, "the week without orders has no invoice") {
+		assert.Equal(t, time.Date(2019, 9, 2, 6, 0, 0, 0, time.UTC), files[0].From)
+		assert.Equal(t, time.Date(2019, 9, 9, 6, 0, 0, 0, time.UTC), files[0].To)
+	}
+}
+
+// brokenRenderer fails until it's fixed.
+type brokenRenderer struct {
+	invoices.Renderer
+	broken bool
+}
+
+func (r *brokenRenderer) Render(w io.Writer, invoice *models.Invoice) error {
+	if r.broken {
+		return errors.New("renderer is broken")
+	}
+
+	return r.Renderer.Render(w, invoice)
+}
+
+func TestInvoiceWorker_catchUpFailedFormat(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2019, 9, d, 12, 0, 0, 0, time.UTC) }
+
+	iw := newTestInvoiceWorker(newFakeClock(day(8).Add(time.Hour)), daily())
+	broken := &brokenRenderer{Renderer: invoices.NewMarkdown(), broken: true}
+	iw.renderers = append(iw.renderers, broken)
+	ctx := context.Background()
+	invoiceDI := db.GetInvoiceDI()
+	if err := invoiceDI.CompleteInvoicePeriod(ctx, day(7)); err != nil {
+		t.Fatalf("CompleteInvoicePeriod() error = %v", err)
+	}
+
+	assert.Error(t, iw.catchUp(ctx), "a failed format fails the period")
+	last, err := invoiceDI.GetInvoicePeriod(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, day(7), last, "the failed period isn't completed")
+
+	broken.broken = false
+	assert.NoError(t, iw.catchUp(ctx))
+	last, err = invoiceDI.GetInvoicePeriod(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, day(8), last)
+
+	files, err := invoiceDI.GetInvoiceFiles(ctx, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, files, 2, "the retry stores only the failed format") {
+		assert.Equal(t, []string{"md", "csv"}, []string{files[0].Format, files[1].Format})
+	}
+}
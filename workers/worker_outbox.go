@@ -0,0 +1,87 @@
+package workers
+
+import (
+	"context"
+	"time"
+
+	"github.com/IamStubborN/petstore/config"
+	"github.com/IamStubborN/petstore/db"
+	"github.com/IamStubborN/petstore/db/models"
+	"github.com/IamStubborN/petstore/outbox"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultOutboxInterval  = 5 * time.Second
+	defaultOutboxBatchSize = 100
+)
+
+// OutboxWorker polls the outbox table and hands the due events to the
+// publisher, a full batch means more events are waiting, so batches
+// are published one after another until the outbox is drained.
+type OutboxWorker struct {
+	interval  time.Duration
+	batchSize int
+	publisher outbox.Publisher
+}
+
+func newOutboxWorker(cfg *config.Config) Worker {
+	ow := &OutboxWorker{
+		interval:  cfg.Outbox.Interval,
+		batchSize: cfg.Outbox.BatchSize,
+		publisher: outbox.NewPublisher(cfg.Outbox),
+	}
+
+	if ow.interval <= 0 {
+		ow.interval = defaultOutboxInterval
+	}
+
+	if ow.batchSize <= 0 {
+		ow.batchSize = defaultOutboxBatchSize
+	}
+
+	return ow
+}
+
+func (ow *OutboxWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(ow.interval)
+	defer ticker.Stop()
+
+	for {
+		ow.drain(ctx)
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			zap.L().Info("outbox worker closed")
+			return
+		}
+	}
+}
+
+func (ow *OutboxWorker) drain(ctx context.Context) {
+	for ctx.Err() == nil {
+		count, err := db.GetOutboxDI().PublishEvents(ctx, ow.batchSize, ow.publish)
+		if err != nil {
+			zap.L().Error("can't publish outbox events", zap.Error(err))
+			return
+		}
+
+		if count < ow.batchSize {
+			return
+		}
+	}
+}
+
+func (ow *OutboxWorker) publish(ctx context.Context, event *models.Event) error {
+	if err := ow.publisher.Publish(ctx, event); err != nil {
+		zap.L().Warn("outbox event isn't published",
+			zap.String("publisher", ow.publisher.Name()),
+			zap.Int64("id", event.ID),
+			zap.String("type", event.Type),
+			zap.Error(err))
+		return err
+	}
+
+	return nil
+}
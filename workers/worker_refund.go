@@ -0,0 +1,111 @@
+package workers
+
+import (
+	"context"
+	"time"
+
+	"github.com/IamStubborN/petstore/config"
+	"github.com/IamStubborN/petstore/db"
+	"github.com/IamStubborN/petstore/db/models"
+	"github.com/IamStubborN/petstore/payments"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultRefundInterval  = 30 * time.Second
+	defaultRefundBatchSize = 100
+	refundSaveTimeout      = 5 * time.Second
+)
+
+// RefundWorker repeats the pending refunds the payment provider didn't
+// answer, the provider makes a refund with the same key once. It drains
+// them batch after batch like the OutboxWorker drains the outbox.
+type RefundWorker struct {
+	interval  time.Duration
+	batchSize int
+}
+
+func newRefundWorker(cfg *config.Config) Worker {
+	rw := &RefundWorker{
+		interval:  cfg.Refunds.Interval,
+		batchSize: cfg.Refunds.BatchSize,
+	}
+
+	if rw.interval <= 0 {
+		rw.interval = defaultRefundInterval
+	}
+
+	if rw.batchSize <= 0 {
+		rw.batchSize = defaultRefundBatchSize
+	}
+
+	return rw
+}
+
+func (rw *RefundWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(rw.interval)
+	defer ticker.Stop()
+
+	for {
+		rw.drain(ctx)
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			zap.L().Info("refund worker closed")
+			return
+		}
+	}
+}
+
+func (rw *RefundWorker) drain(ctx context.Context) {
+	for ctx.Err() == nil {
+		refunds, err := db.GetRefundDI().ClaimRefunds(ctx, rw.batchSize)
+		if err != nil {
+			zap.L().Error("can't claim pending refunds", zap.Error(err))
+			return
+		}
+
+		for _, refund := range refunds {
+			rw.refund(ctx, refund)
+		}
+
+		if len(refunds) < rw.batchSize {
+			return
+		}
+	}
+}
+
+// refund makes the refund and saves the attempt, a refund of another
+// provider's payment waits for the provider to be configured again.
+func (rw *RefundWorker) refund(ctx context.Context, refund *models.Refund) {
+	provider := payments.GetProvider()
+
+	var err error
+	switch refund.Provider {
+	case models.PaymentProviderManual:
+	case provider.Name():
+		err = provider.Refund(ctx, refund.Reference, refund.Key(), refund.Amount)
+	default:
+		err = errors.Errorf("payment is made through %s, not %s", refund.Provider, provider.Name())
+	}
+
+	if err == nil {
+		refund.Complete(time.Now().UTC())
+	} else {
+		refund.Fail(err, !payments.IsFinal(err), time.Now().UTC())
+		zap.L().Warn("refund isn't made",
+			zap.Int64("id", refund.ID),
+			zap.Int("attempts", refund.Attempts),
+			zap.String("status", refund.Status),
+			zap.Error(err))
+	}
+
+	saveCtx, cancel := context.WithTimeout(context.Background(), refundSaveTimeout)
+	defer cancel()
+
+	if err = db.GetRefundDI().SaveRefund(saveCtx, refund); err != nil {
+		zap.L().Error("can't save refund", zap.Int64("id", refund.ID), zap.Error(err))
+	}
+}
@@ -0,0 +1,79 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/IamStubborN/petstore/config"
+	"github.com/IamStubborN/petstore/db"
+	"github.com/IamStubborN/petstore/db/models"
+	"github.com/IamStubborN/petstore/payments"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRefundWorker_drain(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.Config{DB: config.DB{Provider: "mockdb"}}
+	cfg.Payments.Provider = "fake"
+	cfg.Refunds.BatchSize = 10
+
+	// the provider is looked up by every refund, not by the worker
+	rw := newRefundWorker(cfg).(*RefundWorker)
+	payments.InitProvider(cfg)
+	fake := payments.GetProvider()
+
+	reference, err := fake.Authorize(ctx, payments.Charge{OrderID: 1, Amount: 420, Token: "tok_visa"})
+	assert.NoError(t, err)
+	assert.NoError(t, fake.Capture(ctx, reference, 420))
+
+	tests := []struct {
+		name        string
+		reference   string
+		wantStatus  string
+		wantPayment string
+	}{
+		{name: "retried", reference: reference,
+			wantStatus: models.RefundStatusCompleted, wantPayment: models.PaymentStatusRefunded},
+		{name: "unknown payment", reference: "fake_1_99",
+			wantStatus: models.RefundStatusFailed, wantPayment: models.PaymentStatusCaptured},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db.InitDatabase(cfg)
+
+			payment, err := db.GetPaymentDI().CreatePayment(ctx, &models.Payment{OrderID: 1, Provider: "fake",
+				Amount: 420, Status: models.PaymentStatusPending})
+			assert.NoError(t, err)
+
+			for _, status := range []string{models.PaymentStatusAuthorized, models.PaymentStatusCaptured} {
+				payment.Status = status
+				payment.Reference = tt.reference
+				assert.NoError(t, db.GetPaymentDI().UpdatePayment(ctx, payment))
+			}
+
+			refund, err := db.GetRefundDI().ReserveRefund(ctx, &models.Refund{OrderID: 1,
+				PaymentID: payment.ID, Amount: 420, Reason: "order cancelled"})
+			assert.NoError(t, err)
+
+			// the provider didn't answer the first attempt in time
+			refund.Fail(errors.New("payment provider didn't answer in time"), true,
+				time.Now().Add(-time.Hour))
+			assert.NoError(t, db.GetRefundDI().SaveRefund(ctx, refund))
+
+			rw.drain(ctx)
+			rw.drain(ctx)
+
+			refunds, err := db.GetRefundDI().GetRefunds(ctx, 1)
+			assert.NoError(t, err)
+			assert.Len(t, refunds, 1)
+			assert.Equal(t, tt.wantStatus, refunds[0].Status)
+			assert.Equal(t, 2, refunds[0].Attempts)
+
+			list, err := db.GetPaymentDI().GetPayments(ctx, 1)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantPayment, list[0].Status)
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package workers
+
+import (
+	"context"
+	"time"
+
+	"github.com/IamStubborN/petstore/config"
+	"github.com/IamStubborN/petstore/db"
+	"github.com/IamStubborN/petstore/db/models"
+	"github.com/IamStubborN/petstore/webhooks"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultWebhookInterval    = 5 * time.Second
+	defaultWebhookBatchSize   = 100
+	defaultWebhookMaxAttempts = 10
+	defaultWebhookMaxFailures = 20
+)
+
+// WebhookWorker makes the due webhook deliveries, it drains them
+// batch after batch like the OutboxWorker drains the outbox.
+type WebhookWorker struct {
+	interval time.Duration
+	policy   models.WebhookPolicy
+	client   *webhooks.Client
+}
+
+func newWebhookWorker(cfg *config.Config) Worker {
+	ww := &WebhookWorker{
+		interval: cfg.Webhooks.Interval,
+		policy: models.WebhookPolicy{
+			BatchSize:   cfg.Webhooks.BatchSize,
+			MaxAttempts: cfg.Webhooks.MaxAttempts,
+			MaxFailures: cfg.Webhooks.MaxFailures,
+		},
+		client: webhooks.NewClient(cfg.Webhooks.Timeout),
+	}
+
+	if ww.interval <= 0 {
+		ww.interval = defaultWebhookInterval
+	}
+
+	if ww.policy.BatchSize <= 0 {
+		ww.policy.BatchSize = defaultWebhookBatchSize
+	}
+
+	if ww.policy.MaxAttempts <= 0 {
+		ww.policy.MaxAttempts = defaultWebhookMaxAttempts
+	}
+
+	if ww.policy.MaxFailures <= 0 {
+		ww.policy.MaxFailures = defaultWebhookMaxFailures
+	}
+
+	return ww
+}
+
+func (ww *WebhookWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(ww.interval)
+	defer ticker.Stop()
+
+	for {
+		ww.drain(ctx)
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			zap.L().Info("webhook worker closed")
+			return
+		}
+	}
+}
+
+func (ww *WebhookWorker) drain(ctx context.Context) {
+	for ctx.Err() == nil {
+		count, err := db.GetWebhookDI().DeliverWebhooks(ctx, ww.policy, ww.deliver)
+		if err != nil {
+			zap.L().Error("can't make webhook deliveries", zap.Error(err))
+			return
+		}
+
+		if count < ww.policy.BatchSize {
+			return
+		}
+	}
+}
+
+func (ww *WebhookWorker) deliver(ctx context.Context, delivery *models.WebhookDelivery) error {
+	if err := ww.client.Deliver(ctx, delivery); err != nil {
+		zap.L().Warn("webhook delivery failed",
+			zap.Int64("webhook", delivery.WebhookID),
+			zap.Int64("delivery", delivery.ID),
+			zap.String("event", delivery.Event),
+			zap.Error(err))
+		return err
+	}
+
+	return nil
+}